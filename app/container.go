@@ -0,0 +1,181 @@
+package app
+
+import (
+	"context"
+	"log"
+	"sync"
+	config "tiketsepur/configs"
+	"tiketsepur/database/connection"
+	"tiketsepur/repository"
+	"tiketsepur/service"
+	"tiketsepur/utils"
+	"time"
+)
+
+// Container menyimpan repository dan service yang dipakai bersama oleh
+// binary api dan worker. Koneksi diambil dari package connection, jadi
+// InitDB, InitRedis, dan InitRabbitMQ harus dipanggil lebih dulu.
+type Container struct {
+	Config *config.Config
+
+	UserRepo                 repository.UserRepository
+	TrainRepo                repository.TrainRepository
+	ScheduleRepo             repository.ScheduleRepository
+	PublicScheduleRepo       repository.ScheduleRepository
+	TicketRepo               repository.TicketRepository
+	PaymentRepo              repository.PaymentRepository
+	RoleRepo                 repository.RoleRepository
+	APIKeyRepo               repository.APIKeyRepository
+	IdentityRepo             repository.UserIdentityRepository
+	JWTKeyRepo               repository.JWTSigningKeyRepository
+	NotificationRepo         repository.NotificationRepository
+	NotificationTemplateRepo repository.NotificationTemplateRepository
+	OutboxRepo               repository.OutboxRepository
+	TicketReminderRepo       repository.TicketReminderRepository
+	ScheduleDisruptionRepo   repository.ScheduleDisruptionRepository
+	ScheduleTemplateRepo     repository.ScheduleTemplateRepository
+	StationRepo              repository.StationRepository
+	TrainCarRepo             repository.TrainCarRepository
+	TrainUnavailabilityRepo  repository.TrainUnavailabilityRepository
+	StaffRepo                repository.StaffRepository
+	CrewAssignmentRepo       repository.CrewAssignmentRepository
+	BoardingScanRepo         repository.BoardingScanRepository
+	ScheduleCache            repository.ScheduleCache
+
+	JWTKeys                     *utils.JWTKeySet
+	JWTKeyService               service.JWTKeyService
+	RBACService                 service.RBACService
+	APIKeyService               service.APIKeyService
+	AuthService                 service.AuthService
+	OIDCService                 service.OIDCService
+	UserService                 service.UserService
+	TrainService                service.TrainService
+	StationService              service.StationService
+	TrainAvailabilityService    service.TrainAvailabilityService
+	StaffService                service.StaffService
+	CrewService                 service.CrewService
+	BoardingService             service.BoardingService
+	LiveScheduleService         service.LiveScheduleService
+	FareCalendarService         service.FareCalendarService
+	ScheduleValidator           service.ScheduleValidator
+	ScheduleService             service.ScheduleService
+	ScheduleTemplateService     service.ScheduleTemplateService
+	OutboxService               service.OutboxService
+	TicketService               service.TicketService
+	PaymentService              service.PaymentService
+	BookingExpiryService        service.BookingExpiryService
+	ReminderService             service.ReminderService
+	ScheduleDisruptionService   service.ScheduleDisruptionService
+	NotificationTemplateService service.NotificationTemplateService
+	NotificationService         service.NotificationService
+}
+
+func NewContainer(cfg *config.Config) *Container {
+	c := &Container{Config: cfg}
+
+	c.UserRepo = repository.NewUserRepository(connection.DB)
+	c.TrainRepo = repository.NewTrainRepository(connection.DB)
+	c.ScheduleRepo = repository.NewScheduleRepository(connection.DB)
+	// cache hanya dipakai jalur baca publik di ScheduleService
+	c.PublicScheduleRepo = c.ScheduleRepo
+	c.ScheduleCache = repository.NewDisabledScheduleCache()
+	if cfg.Cache.ScheduleEnabled {
+		c.PublicScheduleRepo, c.ScheduleCache = repository.NewCachedScheduleRepository(c.ScheduleRepo, connection.Redis, scheduleCacheTTL(cfg))
+	}
+	c.TicketRepo = repository.NewTicketRepository(connection.DB)
+	c.PaymentRepo = repository.NewPaymentRepository(connection.DB)
+	c.RoleRepo = repository.NewRoleRepository(connection.DB)
+	c.APIKeyRepo = repository.NewAPIKeyRepository(connection.DB)
+	c.IdentityRepo = repository.NewUserIdentityRepository(connection.DB)
+	c.JWTKeyRepo = repository.NewJWTSigningKeyRepository(connection.DB)
+	c.NotificationRepo = repository.NewNotificationRepository(connection.DB)
+	c.NotificationTemplateRepo = repository.NewNotificationTemplateRepository(connection.DB)
+	c.OutboxRepo = repository.NewOutboxRepository(connection.DB)
+	c.TicketReminderRepo = repository.NewTicketReminderRepository(connection.DB)
+	c.ScheduleDisruptionRepo = repository.NewScheduleDisruptionRepository(connection.DB)
+	c.ScheduleTemplateRepo = repository.NewScheduleTemplateRepository(connection.DB)
+	c.StationRepo = repository.NewStationRepository(connection.DB)
+	c.TrainCarRepo = repository.NewTrainCarRepository(connection.DB)
+	c.TrainUnavailabilityRepo = repository.NewTrainUnavailabilityRepository(connection.DB)
+	c.StaffRepo = repository.NewStaffRepository(connection.DB)
+	c.CrewAssignmentRepo = repository.NewCrewAssignmentRepository(connection.DB)
+	c.BoardingScanRepo = repository.NewBoardingScanRepository(connection.DB)
+
+	c.JWTKeys = utils.NewJWTKeySet(cfg.JWT.Secret, cfg.JWT.Algorithm, cfg.JWT.AcceptHS256)
+	c.JWTKeyService = service.NewJWTKeyService(connection.DB, c.JWTKeyRepo, connection.Redis, cfg, c.JWTKeys)
+	c.RBACService = service.NewRBACService(connection.DB, c.RoleRepo, c.UserRepo, connection.Redis, cfg)
+	c.APIKeyService = service.NewAPIKeyService(c.APIKeyRepo, c.UserRepo, c.RBACService, connection.Redis)
+	c.AuthService = service.NewAuthService(c.UserRepo, connection.Redis, connection.RabbitMQ, cfg, c.JWTKeys)
+	c.OIDCService = service.NewOIDCService(c.UserRepo, c.IdentityRepo, c.AuthService, connection.Redis, cfg)
+	c.UserService = service.NewUserService(c.UserRepo, c.RoleRepo)
+	c.TrainService = service.NewTrainService(connection.DB, c.TrainRepo, c.TrainCarRepo, c.ScheduleRepo, c.ScheduleCache)
+	c.TrainAvailabilityService = service.NewTrainAvailabilityService(connection.DB, c.TrainUnavailabilityRepo, c.TrainRepo, c.ScheduleRepo)
+	c.OutboxService = service.NewOutboxService(connection.DB, c.OutboxRepo, connection.RabbitMQ, cfg)
+	c.StationService = service.NewStationService(connection.DB, c.StationRepo, c.ScheduleRepo, c.ScheduleCache, c.OutboxService)
+	c.LiveScheduleService = service.NewLiveScheduleService(c.ScheduleRepo, connection.Redis)
+	c.FareCalendarService = service.NewFareCalendarService(c.ScheduleRepo, c.StationService, connection.Redis, cfg)
+	c.ScheduleDisruptionService = service.NewScheduleDisruptionService(connection.DB, c.ScheduleDisruptionRepo, c.ScheduleRepo, c.ScheduleCache, c.TicketRepo, c.PaymentRepo, c.OutboxService, c.LiveScheduleService, c.FareCalendarService)
+	c.ScheduleValidator = service.NewScheduleValidator(c.ScheduleRepo, c.TrainRepo, c.StationRepo, c.TrainUnavailabilityRepo)
+	c.CrewService = service.NewCrewService(connection.DB, c.CrewAssignmentRepo, c.StaffRepo, c.ScheduleRepo, cfg)
+	c.ScheduleService = service.NewScheduleService(connection.DB, c.ScheduleRepo, c.PublicScheduleRepo, c.ScheduleCache, c.TrainRepo, c.TicketRepo, c.ScheduleDisruptionService, c.OutboxService, c.LiveScheduleService, c.FareCalendarService, c.ScheduleValidator, c.StationService, c.CrewService)
+	c.ScheduleTemplateService = service.NewScheduleTemplateService(connection.DB, c.ScheduleTemplateRepo, c.ScheduleRepo, c.ScheduleCache, c.TrainRepo, c.OutboxService, c.FareCalendarService, c.StationService, c.StationRepo, c.TrainUnavailabilityRepo)
+	c.StaffService = service.NewStaffService(connection.DB, c.StaffRepo, c.CrewAssignmentRepo, c.UserRepo)
+	c.TicketService = service.NewTicketService(connection.DB, c.TicketRepo, c.ScheduleRepo, c.ScheduleCache, c.UserRepo, c.PaymentRepo, c.RBACService, c.OutboxService, c.LiveScheduleService, c.FareCalendarService, connection.Redis)
+	c.BoardingService = service.NewBoardingService(c.BoardingScanRepo, c.TicketRepo, c.StaffRepo, c.CrewAssignmentRepo, c.ScheduleRepo, cfg)
+	c.PaymentService = service.NewPaymentService(connection.DB, c.PaymentRepo, c.TicketRepo, c.ScheduleRepo, c.UserRepo, c.OutboxService)
+	c.BookingExpiryService = service.NewBookingExpiryService(connection.DB, c.TicketRepo, c.PaymentRepo, c.ScheduleRepo, c.ScheduleCache, c.OutboxService, c.LiveScheduleService, c.FareCalendarService, cfg)
+	c.ReminderService = service.NewReminderService(connection.DB, c.TicketReminderRepo, c.OutboxService, cfg)
+	c.NotificationTemplateService = service.NewNotificationTemplateService(connection.DB, c.NotificationTemplateRepo)
+	c.NotificationService = service.NewNotificationService(c.NotificationRepo, c.UserRepo, c.NotificationTemplateService, service.NewNotifiers(cfg), connection.RabbitMQ, cfg)
+
+	return c
+}
+
+// RunWorkers menjalankan konsumen notifikasi, relay outbox, sweep tiket
+// kedaluwarsa, dan pengingat keberangkatan sampai ctx selesai, lalu menunggu
+// semuanya berhenti. Putusnya koneksi broker ditangani di dalam konsumen;
+// error yang dikembalikan berarti konsumen tidak bisa dijalankan sama sekali.
+func (c *Container) RunWorkers(ctx context.Context) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	consumerOptions := utils.ConsumerOptions{
+		Prefetch:    c.Config.RabbitMQ.Prefetch,
+		Concurrency: c.Config.RabbitMQ.Concurrency,
+		RetryDelays: c.Config.RabbitMQ.RetryDelays,
+	}
+
+	var wg sync.WaitGroup
+	var consumerErr error
+
+	wg.Add(4)
+	go func() {
+		defer wg.Done()
+		// jika konsumen berhenti, worker lain ikut dihentikan
+		defer cancel()
+		consumerErr = connection.RabbitMQ.ConsumeNotifications(ctx, consumerOptions, c.NotificationService.Handle)
+	}()
+	go func() {
+		defer wg.Done()
+		c.OutboxService.Run(ctx)
+	}()
+	go func() {
+		defer wg.Done()
+		c.BookingExpiryService.Run(ctx)
+	}()
+	go func() {
+		defer wg.Done()
+		c.ReminderService.Run(ctx)
+	}()
+
+	log.Println("worker notifikasi, relay outbox, sweep tiket kedaluwarsa, dan pengingat keberangkatan berjalan")
+	wg.Wait()
+	return consumerErr
+}
+
+func scheduleCacheTTL(cfg *config.Config) time.Duration {
+	if cfg.Cache.ScheduleTTL > 0 {
+		return cfg.Cache.ScheduleTTL
+	}
+	return 5 * time.Minute
+}
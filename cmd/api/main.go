@@ -0,0 +1,120 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"tiketsepur/app"
+	config "tiketsepur/configs"
+	"tiketsepur/database/connection"
+	"tiketsepur/database/migrations"
+	_ "tiketsepur/docs"
+	"tiketsepur/routes"
+
+	"github.com/joho/godotenv"
+	_ "github.com/lib/pq"
+)
+
+// @title TiketSepur API
+// @version 1.0
+// @description API Server untuk aplikasi pemesanan tiket kereta api TiketSepur
+// @termsOfService http://swagger.io/terms/
+
+// @contact.name API Support
+// @contact.url http://www.swagger.io/support
+// @contact.email support@swagger.io
+
+// @license.name Apache 2.0
+// @license.url http://www.apache.org/licenses/LICENSE-2.0.html
+
+// @host localhost:8080
+// @BasePath /api/v1
+// @schemes http https
+
+// @securityDefinitions.apikey BearerAuth
+// @in header
+// @name Authorization
+// @description Type "Bearer" followed by a space and JWT token.
+func main() {
+	withWorker := flag.Bool("with-worker", false, "jalankan konsumen notifikasi, relay outbox, dan sweep tiket di proses yang sama (untuk development)")
+	flag.Parse()
+
+	if os.Getenv("ENVIRONMENT") != "production" {
+		godotenv.Load()
+	}
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		log.Fatal("gagal load config:", err)
+	}
+
+	connection.InitDB(cfg)
+	defer connection.CloseDB()
+
+	migrations.GetDBMigrate(connection.DB)
+
+	connection.InitRedis(cfg)
+	connection.InitRabbitMQ(cfg)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	container := app.NewContainer(cfg)
+
+	if err := container.JWTKeyService.Init(ctx); err != nil {
+		log.Fatal("gagal menyiapkan jwt signing key: ", err)
+	}
+	go container.JWTKeyService.Run(ctx)
+
+	// setiap instance api berlangganan update jadwal di redis untuk
+	// diteruskan ke client sse yang terhubung ke instance ini
+	go func() {
+		if err := container.LiveScheduleService.Run(ctx); err != nil {
+			log.Printf("live update jadwal berhenti: %v", err)
+		}
+	}()
+
+	workerDone := make(chan struct{})
+	if *withWorker {
+		go func() {
+			defer close(workerDone)
+			if err := container.RunWorkers(ctx); err != nil {
+				log.Printf("worker berhenti: %v", err)
+				stop()
+			}
+		}()
+	} else {
+		close(workerDone)
+	}
+
+	server := &http.Server{
+		Addr:    ":8080",
+		Handler: routes.StartServer(container),
+	}
+
+	go func() {
+		log.Println("server berjalan di port 8080")
+		log.Println("Swagger UI tersedia di http://localhost:8080/swagger/index.html")
+
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Fatal("gagal menjalankan server:", err)
+		}
+	}()
+
+	<-ctx.Done()
+	log.Println("menghentikan server...")
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		log.Printf("gagal menghentikan server: %v", err)
+	}
+	<-workerDone
+}
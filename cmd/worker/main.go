@@ -0,0 +1,47 @@
+package main
+
+import (
+	"context"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"tiketsepur/app"
+	config "tiketsepur/configs"
+	"tiketsepur/database/connection"
+
+	"github.com/joho/godotenv"
+	_ "github.com/lib/pq"
+)
+
+// Worker menjalankan konsumen notifikasi, relay outbox, dan sweep tiket
+// kedaluwarsa terpisah dari API sehingga keduanya bisa di-scale dan di-deploy
+// sendiri. Migrasi database tetap dijalankan oleh binary api.
+func main() {
+	if os.Getenv("ENVIRONMENT") != "production" {
+		godotenv.Load()
+	}
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		log.Fatal("gagal load config:", err)
+	}
+
+	connection.InitDB(cfg)
+	defer connection.CloseDB()
+
+	connection.InitRedis(cfg)
+	connection.InitRabbitMQ(cfg)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	container := app.NewContainer(cfg)
+	if err := container.RunWorkers(ctx); err != nil {
+		log.Printf("worker berhenti: %v", err)
+		connection.CloseDB()
+		os.Exit(1)
+	}
+	log.Println("worker dihentikan")
+}
@@ -26,7 +26,7 @@ type DatabaseConfig struct {
 }
 
 type RedisConfig struct {
-	URL 		  string
+	URL           string
 	Host          string
 	Port          string
 	Password      string
@@ -35,14 +35,28 @@ type RedisConfig struct {
 }
 
 type RabbitMQConfig struct {
-	URL 	  string	
-	URLLokal  string 
-	QueueName string 
+	URL         string
+	URLLokal    string
+	QueueName   string          `mapstructure:"queue_name"`
+	Exchange    string          `mapstructure:"events_exchange"`
+	Prefetch    int             `mapstructure:"prefetch"`
+	Concurrency int             `mapstructure:"concurrency"`
+	RetryDelays []time.Duration `mapstructure:"retry_delays"`
+
+	PublishTimeout    time.Duration `mapstructure:"publish_timeout"`
+	ChannelPoolSize   int           `mapstructure:"channel_pool_size"`
+	ReconnectMaxDelay time.Duration `mapstructure:"reconnect_max_delay"`
 }
 
 type JWTConfig struct {
-	Secret string
-	Exp time.Duration
+	Secret           string
+	Exp              time.Duration
+	Algorithm        string        `mapstructure:"algorithm"`
+	RotationInterval time.Duration `mapstructure:"rotation_interval"`
+	AcceptHS256      bool          `mapstructure:"accept_hs256"`
+	// KeyEncryptionKey adalah 32 byte base64 untuk mengenkripsi private key
+	// di tabel jwt_signing_keys.
+	KeyEncryptionKey string `mapstructure:"key_encryption_key"`
 }
 
 type PaymentConfig struct {
@@ -51,13 +65,104 @@ type PaymentConfig struct {
 	MidtransEnv       string
 }
 
+type LoginProtectionConfig struct {
+	MaxAttempts      int           `mapstructure:"max_attempts"`
+	MaxAttemptsPerIP int           `mapstructure:"max_attempts_per_ip"`
+	Window           time.Duration `mapstructure:"window"`
+	LockoutBase      time.Duration `mapstructure:"lockout_base"`
+	LockoutMax       time.Duration `mapstructure:"lockout_max"`
+}
+
+type OIDCProviderConfig struct {
+	Issuer       string   `mapstructure:"issuer"`
+	ClientID     string   `mapstructure:"client_id"`
+	ClientSecret string   `mapstructure:"client_secret"`
+	RedirectURL  string   `mapstructure:"redirect_url"`
+	Scopes       []string `mapstructure:"scopes"`
+}
+
+type OIDCConfig struct {
+	StateExpiry time.Duration                 `mapstructure:"state_expiry"`
+	Providers   map[string]OIDCProviderConfig `mapstructure:"providers"`
+}
+
+type SMTPConfig struct {
+	Host     string        `mapstructure:"host"`
+	Port     string        `mapstructure:"port"`
+	Username string        `mapstructure:"username"`
+	Password string        `mapstructure:"password"`
+	From     string        `mapstructure:"from"`
+	Timeout  time.Duration `mapstructure:"timeout"`
+}
+
+type SMSConfig struct {
+	Provider string        `mapstructure:"provider"`
+	URL      string        `mapstructure:"url"`
+	APIKey   string        `mapstructure:"api_key"`
+	Sender   string        `mapstructure:"sender"`
+	Timeout  time.Duration `mapstructure:"timeout"`
+}
+
+type WebhookConfig struct {
+	Secret  string        `mapstructure:"secret"`
+	Timeout time.Duration `mapstructure:"timeout"`
+}
+
+type NotificationConfig struct {
+	Channels map[string][]string `mapstructure:"channels"`
+	SMTP     SMTPConfig          `mapstructure:"smtp"`
+	SMS      SMSConfig           `mapstructure:"sms"`
+	Webhook  WebhookConfig       `mapstructure:"webhook"`
+}
+
+type OutboxConfig struct {
+	PollInterval time.Duration `mapstructure:"poll_interval"`
+	BatchSize    int           `mapstructure:"batch_size"`
+	RetryBase    time.Duration `mapstructure:"retry_base"`
+	RetryMax     time.Duration `mapstructure:"retry_max"`
+	Retention    time.Duration `mapstructure:"retention"`
+	// lama baris yang diklaim relay tidak diambil relay lain; sebaiknya lebih
+	// dari batch_size dikali rabbitmq.publish_timeout
+	ClaimLease time.Duration `mapstructure:"claim_lease"`
+}
+
+type BookingConfig struct {
+	PaymentTimeout      time.Duration `mapstructure:"payment_timeout"`
+	ExpirySweepInterval time.Duration `mapstructure:"expiry_sweep_interval"`
+}
+
+type ReminderConfig struct {
+	Offsets  []time.Duration `mapstructure:"offsets"`
+	Interval time.Duration   `mapstructure:"interval"`
+}
+
+type CacheConfig struct {
+	FareCalendarTTL time.Duration `mapstructure:"fare_calendar_ttl"`
+	ScheduleEnabled bool          `mapstructure:"schedule_enabled"`
+	ScheduleTTL     time.Duration `mapstructure:"schedule_ttl"`
+}
+
+type CrewConfig struct {
+	MinRest time.Duration `mapstructure:"min_rest"`
+	// berapa lama sebelum keberangkatan kondektur sudah boleh scan tiket
+	BoardingOpensBefore time.Duration `mapstructure:"boarding_opens_before"`
+}
+
 type Config struct {
-	Server   ServerConfig
-	Database DatabaseConfig
-	Redis    RedisConfig
-	RabbitMQ RabbitMQConfig
-	JWT      JWTConfig
-	Payment  PaymentConfig
+	Server          ServerConfig
+	Database        DatabaseConfig
+	Redis           RedisConfig
+	RabbitMQ        RabbitMQConfig
+	JWT             JWTConfig
+	Payment         PaymentConfig
+	LoginProtection LoginProtectionConfig `mapstructure:"login_protection"`
+	OIDC            OIDCConfig            `mapstructure:"oidc"`
+	Notification    NotificationConfig    `mapstructure:"notification"`
+	Outbox          OutboxConfig          `mapstructure:"outbox"`
+	Booking         BookingConfig         `mapstructure:"booking"`
+	Reminder        ReminderConfig        `mapstructure:"reminder"`
+	Cache           CacheConfig           `mapstructure:"cache"`
+	Crew            CrewConfig            `mapstructure:"crew"`
 }
 
 func LoadConfig() (*Config, error) {
@@ -77,21 +182,22 @@ func LoadConfig() (*Config, error) {
 	}
 
 	sessionExpiryStr := viper.GetString("redis.session_expiry")
-    duration, err := time.ParseDuration(sessionExpiryStr)
-    if err != nil {
-        return nil, fmt.Errorf("invalid session_expiry: %w", err)
-    }
-    config.Redis.SessionExpiry = duration
+	duration, err := time.ParseDuration(sessionExpiryStr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid session_expiry: %w", err)
+	}
+	config.Redis.SessionExpiry = duration
 
 	ConnMaxLifetimeStr := viper.GetString("database.conn_max_lifetime")
-    duration1, err := time.ParseDuration(ConnMaxLifetimeStr)
-    if err != nil {
-        return nil, fmt.Errorf("invalid session_expiry: %w", err)
-    }
-    config.Database.ConnMaxLifetime = duration1
+	duration1, err := time.ParseDuration(ConnMaxLifetimeStr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid session_expiry: %w", err)
+	}
+	config.Database.ConnMaxLifetime = duration1
 
 	config.Payment.MidtransClientKey = viper.GetString("MIDTRANS_CLIENT_KEY")
 	config.Payment.MidtransServerKey = viper.GetString("MIDTRANS_SERVER_KEY")
+	config.JWT.KeyEncryptionKey = viper.GetString("jwt.key_encryption_key")
 
 	return &config, nil
-}
\ No newline at end of file
+}
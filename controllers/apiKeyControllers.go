@@ -0,0 +1,106 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+	"tiketsepur/dto"
+	"tiketsepur/service"
+	"tiketsepur/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// @title API Key API
+// @description API for managing partner and machine API keys
+type APIKeyControllers struct {
+	apiKeyService service.APIKeyService
+}
+
+func NewAPIKeyControllers(apiKeyService service.APIKeyService) *APIKeyControllers {
+	return &APIKeyControllers{apiKeyService: apiKeyService}
+}
+
+// Create godoc
+// @Summary Buat api key
+// @Description Terbitkan api key baru. Key hanya ditampilkan sekali pada response ini
+// @Tags api-keys
+// @Accept json
+// @Produce json
+// @Param apiKey body dto.CreateAPIKeyRequest true "Detail api key"
+// @Success 201 {object} utils.Response{data=dto.CreateAPIKeyResponse} "Api key berhasil dibuat"
+// @Failure 400 {object} utils.Response "Request tidak valid"
+// @Router /api-keys [post]
+// @Security BearerAuth
+func (h *APIKeyControllers) Create(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	var req dto.CreateAPIKeyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "request tidak valid", err)
+		return
+	}
+
+	result, err := h.apiKeyService.Create(c.Request.Context(), userID.(int), req)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "gagal membuat api key", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusCreated, "api key berhasil dibuat", result)
+}
+
+// GetAll godoc
+// @Summary Semua api key
+// @Description Daftar semua api key tanpa nilai rahasianya
+// @Tags api-keys
+// @Accept json
+// @Produce json
+// @Param limit query int false "Jumlah data per halaman (default 20, maksimal 100)"
+// @Param offset query int false "Offset untuk pagination offset"
+// @Param cursor query string false "next_cursor dari response sebelumnya untuk pagination cursor"
+// @Param order query string false "Urutan sort (asc atau desc)"
+// @Param sort query string false "Field sort: created_at, name, id"
+// @Param status query string false "Filter status (active atau revoked)"
+// @Param q query string false "Cari nama api key"
+// @Success 200 {object} utils.Response{data=[]models.APIKey,meta=repository.Page} "Daftar api key"
+// @Failure 400 {object} utils.Response "Parameter query tidak valid"
+// @Failure 500 {object} utils.Response "Internal server error"
+// @Router /api-keys [get]
+// @Security BearerAuth
+func (h *APIKeyControllers) GetAll(c *gin.Context) {
+	var query dto.ListQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "parameter query tidak valid", err)
+		return
+	}
+
+	keys, page, err := h.apiKeyService.GetAll(query)
+	if err != nil {
+		utils.ErrorResponse(c, listErrorStatus(err), "gagal mendapatkan api key", err)
+		return
+	}
+
+	utils.PaginatedResponse(c, http.StatusOK, "api key berhasil didapatkan", keys, page)
+}
+
+// Revoke godoc
+// @Summary Cabut api key
+// @Description Cabut api key sehingga tidak bisa digunakan lagi
+// @Tags api-keys
+// @Accept json
+// @Produce json
+// @Param id path int true "API Key ID"
+// @Success 200 {object} utils.Response "Api key berhasil dicabut"
+// @Failure 400 {object} utils.Response "Gagal mencabut api key"
+// @Router /api-keys/{id} [delete]
+// @Security BearerAuth
+func (h *APIKeyControllers) Revoke(c *gin.Context) {
+	id, _ := strconv.Atoi(c.Param("id"))
+
+	if err := h.apiKeyService.Revoke(id); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "gagal mencabut api key", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "api key berhasil dicabut", nil)
+}
@@ -1,7 +1,9 @@
 package controllers
 
 import (
+	"errors"
 	"net/http"
+	"strconv"
 	"tiketsepur/dto"
 	"tiketsepur/service"
 	"tiketsepur/utils"
@@ -17,10 +19,10 @@ type AuthControllers struct {
 }
 
 func NewAuthControllers(authService service.AuthService, userService service.UserService) *AuthControllers {
-    return &AuthControllers{
-        authService: authService,
-        userService: userService,
-    }
+	return &AuthControllers{
+		authService: authService,
+		userService: userService,
+	}
 }
 
 // Register godoc
@@ -51,7 +53,7 @@ func (h *AuthControllers) Register(c *gin.Context) {
 
 // RegisterAdmin godoc
 // @Summary Register admin user
-// @Description Register akun admin baru
+// @Description Register akun admin baru, hanya untuk pemegang permission roles:manage
 // @Tags auth
 // @Accept json
 // @Produce json
@@ -61,19 +63,19 @@ func (h *AuthControllers) Register(c *gin.Context) {
 // @Router /auth/register-admin [post]
 // @Security BearerAuth
 func (h *AuthControllers) RegisterAdmin(c *gin.Context) {
-    var req dto.CreateUserRequest
-    if err := c.ShouldBindJSON(&req); err != nil {
-        utils.ErrorResponse(c, http.StatusBadRequest, "request tidak valid", err)
-        return
-    }
-
-    user, err := h.userService.Create(req)
-    if err != nil {
-        utils.ErrorResponse(c, http.StatusBadRequest, "registrasi gagal", err)
-        return
-    }
-
-    utils.SuccessResponse(c, http.StatusCreated, "admin registrasi berhasil", user)
+	var req dto.CreateUserRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "request tidak valid", err)
+		return
+	}
+
+	user, err := h.userService.Create(req, "admin")
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "registrasi gagal", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusCreated, "admin registrasi berhasil", user)
 }
 
 // Login godoc
@@ -86,6 +88,7 @@ func (h *AuthControllers) RegisterAdmin(c *gin.Context) {
 // @Success 200 {object} utils.Response{data=dto.LoginResponse} "Login sukses"
 // @Failure 400 {object} utils.Response "request tidak valid"
 // @Failure 401 {object} utils.Response "Authentication gagal"
+// @Failure 429 {object} utils.Response "Login dikunci sementara"
 // @Router /auth/login [post]
 func (h *AuthControllers) Login(c *gin.Context) {
 	var req dto.LoginRequest
@@ -94,8 +97,14 @@ func (h *AuthControllers) Login(c *gin.Context) {
 		return
 	}
 
-	result, err := h.authService.Login(c.Request.Context(), req)
+	result, err := h.authService.Login(c.Request.Context(), req, c.ClientIP())
 	if err != nil {
+		var lockedErr *service.LoginLockedError
+		if errors.As(err, &lockedErr) {
+			c.Header("Retry-After", strconv.Itoa(int(lockedErr.RetryAfter.Seconds())))
+			utils.ErrorResponse(c, http.StatusTooManyRequests, "login dikunci sementara", err)
+			return
+		}
 		utils.ErrorResponse(c, http.StatusUnauthorized, "login gagal", err)
 		return
 	}
@@ -109,11 +118,21 @@ func (h *AuthControllers) Login(c *gin.Context) {
 // @Accept json
 // @Produce json
 // @Success 200 {object} utils.Response "Logout sukses"
+// @Failure 400 {object} utils.Response "Request memakai api key"
 // @Failure 500 {object} utils.Response "Logout gagal"
 // @Router /auth/logout [post]
 // @Security BearerAuth
 func (h *AuthControllers) Logout(c *gin.Context) {
-	token, _ := c.Get("token")
+	// api key tidak punya sesi; key dicabut lewat DELETE /api-keys/{id}
+	if _, isAPIKey := c.Get("api_key_id"); isAPIKey {
+		utils.ErrorResponse(c, http.StatusBadRequest, "logout hanya berlaku untuk sesi JWT; cabut api key untuk menghentikan aksesnya", nil)
+		return
+	}
+	token, exists := c.Get("token")
+	if !exists {
+		utils.ErrorResponse(c, http.StatusBadRequest, "logout hanya berlaku untuk sesi JWT", nil)
+		return
+	}
 
 	if err := h.authService.Logout(c.Request.Context(), token.(string)); err != nil {
 		utils.ErrorResponse(c, http.StatusInternalServerError, "Logout failed", err)
@@ -144,4 +163,71 @@ func (h *AuthControllers) Me(c *gin.Context) {
 	}
 
 	utils.SuccessResponse(c, http.StatusOK, "user info didapatkan", user)
-}
\ No newline at end of file
+}
+
+// UpdateLanguage godoc
+// @Summary Atur bahasa notifikasi
+// @Description Pilih bahasa (id atau en) untuk notifikasi yang dikirim ke user saat ini
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param language body dto.UpdateLanguageRequest true "Bahasa notifikasi"
+// @Success 200 {object} utils.Response{data=models.User} "Bahasa berhasil diperbarui"
+// @Failure 400 {object} utils.Response "Request tidak valid"
+// @Router /auth/me/language [put]
+// @Security BearerAuth
+func (h *AuthControllers) UpdateLanguage(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	var req dto.UpdateLanguageRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "request tidak valid", err)
+		return
+	}
+
+	user, err := h.userService.UpdateLanguage(userID.(int), req.Language)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "gagal memperbarui bahasa", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "bahasa berhasil diperbarui", user)
+}
+
+// Unlock godoc
+// @Summary Buka kunci login
+// @Description Hapus penguncian login untuk email dan/atau IP tertentu
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param unlock body dto.UnlockLoginRequest true "Email atau IP yang akan dibuka"
+// @Success 200 {object} utils.Response "Kunci login berhasil dibuka"
+// @Failure 400 {object} utils.Response "Request tidak valid"
+// @Router /auth/unlock [post]
+// @Security BearerAuth
+func (h *AuthControllers) Unlock(c *gin.Context) {
+	var req dto.UnlockLoginRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "request tidak valid", err)
+		return
+	}
+
+	if err := h.authService.Unlock(c.Request.Context(), req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "gagal membuka kunci login", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "kunci login berhasil dibuka", nil)
+}
+
+// JWKS godoc
+// @Summary JSON Web Key Set
+// @Description Public key untuk memverifikasi JWT yang diterbitkan TiketSepur
+// @Tags auth
+// @Produce json
+// @Success 200 {object} utils.JWKS "Daftar public key"
+// @Router /.well-known/jwks.json [get]
+func (h *AuthControllers) JWKS(c *gin.Context) {
+	c.Header("Cache-Control", "public, max-age=300")
+	c.JSON(http.StatusOK, h.authService.JWKS())
+}
@@ -0,0 +1,29 @@
+package controllers
+
+import (
+	"net/http"
+	"tiketsepur/repository"
+	"tiketsepur/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+type CacheControllers struct {
+	scheduleCache repository.ScheduleCache
+}
+
+func NewCacheControllers(scheduleCache repository.ScheduleCache) *CacheControllers {
+	return &CacheControllers{scheduleCache: scheduleCache}
+}
+
+// ScheduleStats godoc
+// @Summary Metrik cache jadwal
+// @Description Jumlah hit, miss, dan error cache jadwal per operasi sejak instance api ini berjalan
+// @Tags schedules
+// @Produce json
+// @Success 200 {object} utils.Response{data=repository.ScheduleCacheStats} "Metrik cache"
+// @Router /cache/schedules [get]
+// @Security BearerAuth
+func (h *CacheControllers) ScheduleStats(c *gin.Context) {
+	utils.SuccessResponse(c, http.StatusOK, "metrik cache jadwal", h.scheduleCache.Stats())
+}
@@ -0,0 +1,126 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+	"tiketsepur/dto"
+	"tiketsepur/service"
+	"tiketsepur/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+type CrewControllers struct {
+	crewService     service.CrewService
+	boardingService service.BoardingService
+}
+
+func NewCrewControllers(crewService service.CrewService, boardingService service.BoardingService) *CrewControllers {
+	return &CrewControllers{crewService: crewService, boardingService: boardingService}
+}
+
+// Assign godoc
+// @Summary Tugaskan kru ke jadwal
+// @Description Staf harus aktif dan punya kualifikasi yang berlaku di tanggal keberangkatan, jadwal hanya punya satu masinis, tugas staf tidak boleh beririsan, dan jeda antar tugas minimal crew.min_rest (default 10 jam)
+// @Tags crew
+// @Accept json
+// @Produce json
+// @Param id path int true "Schedule ID"
+// @Param assignment body dto.CreateCrewAssignmentRequest true "Staf dan tugasnya"
+// @Success 201 {object} utils.Response{data=models.CrewAssignment} "Kru berhasil ditugaskan"
+// @Failure 400 {object} utils.Response "Request tidak valid atau jadwal sudah berangkat"
+// @Failure 422 {object} utils.Response "Penugasan melanggar aturan kru"
+// @Router /schedules/{id}/crew [post]
+// @Security BearerAuth
+func (h *CrewControllers) Assign(c *gin.Context) {
+	id, _ := strconv.Atoi(c.Param("id"))
+	userID, _ := c.Get("user_id")
+
+	var req dto.CreateCrewAssignmentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "request tidak valid", err)
+		return
+	}
+
+	assignment, err := h.crewService.Assign(id, req, userID.(int))
+	if err != nil {
+		utils.ErrorResponse(c, validationErrorStatus(err, http.StatusBadRequest), "gagal menugaskan kru", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusCreated, "kru berhasil ditugaskan", assignment)
+}
+
+// GetBySchedule godoc
+// @Summary Kru jadwal
+// @Description Masinis dan kondektur yang bertugas di jadwal, masinis lebih dulu
+// @Tags crew
+// @Produce json
+// @Param id path int true "Schedule ID"
+// @Success 200 {object} utils.Response{data=[]models.CrewAssignment} "Daftar kru"
+// @Failure 404 {object} utils.Response "Jadwal tidak ditemukan"
+// @Router /schedules/{id}/crew [get]
+// @Security BearerAuth
+func (h *CrewControllers) GetBySchedule(c *gin.Context) {
+	id, _ := strconv.Atoi(c.Param("id"))
+
+	crew, err := h.crewService.GetBySchedule(id)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusNotFound, "gagal mendapatkan kru jadwal", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "kru jadwal berhasil didapatkan", crew)
+}
+
+// Unassign godoc
+// @Summary Hapus penugasan kru
+// @Description Tugas di jadwal yang sudah berangkat tidak bisa dihapus
+// @Tags crew
+// @Produce json
+// @Param id path int true "Schedule ID"
+// @Param assignmentId path int true "Assignment ID"
+// @Success 200 {object} utils.Response "Penugasan berhasil dihapus"
+// @Failure 400 {object} utils.Response "Penugasan tidak ditemukan atau jadwal sudah berangkat"
+// @Router /schedules/{id}/crew/{assignmentId} [delete]
+// @Security BearerAuth
+func (h *CrewControllers) Unassign(c *gin.Context) {
+	id, _ := strconv.Atoi(c.Param("id"))
+	assignmentID, _ := strconv.Atoi(c.Param("assignmentId"))
+
+	if err := h.crewService.Unassign(id, assignmentID); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "gagal menghapus penugasan kru", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "penugasan kru berhasil dihapus", nil)
+}
+
+// Board godoc
+// @Summary Scan boarding tiket
+// @Description Kondektur mencatat penumpang naik kereta dengan kode booking. Hanya staf yang terhubung dengan akun ini dan ditugaskan sebagai kondektur di jadwal tiket yang bisa melakukan scan; setiap tiket hanya bisa discan sekali
+// @Tags crew
+// @Accept json
+// @Produce json
+// @Param scan body dto.BoardingScanRequest true "Kode booking tiket"
+// @Success 201 {object} utils.Response{data=dto.BoardingScanResponse} "Boarding berhasil dicatat"
+// @Failure 400 {object} utils.Response "Tiket tidak valid, sudah discan, atau kondektur tidak bertugas di jadwal ini"
+// @Router /boarding/scan [post]
+// @Security BearerAuth
+func (h *CrewControllers) Board(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	var req dto.BoardingScanRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "request tidak valid", err)
+		return
+	}
+
+	response, err := h.boardingService.Scan(userID.(int), req)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "boarding gagal", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusCreated, "boarding berhasil dicatat", response)
+}
@@ -0,0 +1,69 @@
+package controllers
+
+import (
+	"net/http"
+	"tiketsepur/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// @title Domain Event API
+// @description API for browsing the JSON Schema of every published domain event
+type EventControllers struct{}
+
+func NewEventControllers() *EventControllers {
+	return &EventControllers{}
+}
+
+// EventSchemaInfo adalah satu event yang dipublikasikan ke topic exchange.
+type EventSchemaInfo struct {
+	Name       string `json:"name"`
+	RoutingKey string `json:"routing_key"`
+	SchemaURL  string `json:"schema_url"`
+}
+
+// GetSchemas godoc
+// @Summary Daftar schema event
+// @Description Daftar semua domain event beserta routing key di topic exchange dan url JSON Schema-nya
+// @Tags events
+// @Produce json
+// @Success 200 {object} utils.Response{data=[]EventSchemaInfo} "Daftar schema event"
+// @Failure 500 {object} utils.Response "Internal server error"
+// @Router /events/schemas [get]
+func (h *EventControllers) GetSchemas(c *gin.Context) {
+	names, err := utils.EventSchemaNames()
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "gagal mendapatkan schema event", err)
+		return
+	}
+
+	schemas := make([]EventSchemaInfo, 0, len(names))
+	for _, name := range names {
+		schemas = append(schemas, EventSchemaInfo{
+			Name:       name,
+			RoutingKey: name,
+			SchemaURL:  "/api/events/schemas/" + name,
+		})
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "schema event berhasil didapatkan", schemas)
+}
+
+// GetSchema godoc
+// @Summary Schema event
+// @Description JSON Schema untuk satu versi event, misalnya ticket.booked.v1
+// @Tags events
+// @Produce json
+// @Param name path string true "Nama event beserta versinya"
+// @Success 200 {object} object "JSON Schema event"
+// @Failure 404 {object} utils.Response "Schema tidak ditemukan"
+// @Router /events/schemas/{name} [get]
+func (h *EventControllers) GetSchema(c *gin.Context) {
+	schema, err := utils.EventSchema(c.Param("name"))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusNotFound, "schema event tidak ditemukan", err)
+		return
+	}
+
+	c.Data(http.StatusOK, "application/schema+json", schema)
+}
@@ -0,0 +1,45 @@
+package controllers
+
+import (
+	"net/http"
+	"tiketsepur/dto"
+	"tiketsepur/service"
+	"tiketsepur/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+type FareCalendarControllers struct {
+	fareCalendarService service.FareCalendarService
+}
+
+func NewFareCalendarControllers(fareCalendarService service.FareCalendarService) *FareCalendarControllers {
+	return &FareCalendarControllers{fareCalendarService: fareCalendarService}
+}
+
+// Get godoc
+// @Summary Kalender tarif
+// @Description Harga termurah yang masih tersedia per hari untuk satu rute selama satu bulan. Hari tanpa keberangkatan atau tanpa kursi tersisa memiliki lowest_fare null
+// @Tags schedules
+// @Produce json
+// @Param from query string true "Stasiun keberangkatan"
+// @Param to query string true "Stasiun tujuan"
+// @Param month query string true "Bulan (YYYY-MM)"
+// @Success 200 {object} utils.Response{data=dto.FareCalendarResponse} "Kalender tarif"
+// @Failure 400 {object} utils.Response "Request tidak valid"
+// @Router /public/fare-calendar [get]
+func (h *FareCalendarControllers) Get(c *gin.Context) {
+	var req dto.FareCalendarRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "request tidak valid", err)
+		return
+	}
+
+	calendar, err := h.fareCalendarService.Get(c.Request.Context(), req)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "gagal mengambil kalender tarif", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "kalender tarif ditemukan", calendar)
+}
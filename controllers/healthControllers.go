@@ -0,0 +1,75 @@
+package controllers
+
+import (
+	"context"
+	"net/http"
+	"tiketsepur/utils"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jmoiron/sqlx"
+)
+
+const healthCheckTimeout = 2 * time.Second
+
+type HealthControllers struct {
+	db       *sqlx.DB
+	redis    *utils.RedisClient
+	rabbitmq *utils.RabbitMQ
+}
+
+func NewHealthControllers(db *sqlx.DB, redis *utils.RedisClient, rabbitmq *utils.RabbitMQ) *HealthControllers {
+	return &HealthControllers{db: db, redis: redis, rabbitmq: rabbitmq}
+}
+
+type HealthCheck struct {
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+type HealthResponse struct {
+	Status   string             `json:"status"`
+	Service  string             `json:"service"`
+	Database HealthCheck        `json:"database"`
+	Redis    HealthCheck        `json:"redis"`
+	RabbitMQ utils.BrokerStatus `json:"rabbitmq"`
+}
+
+// Health godoc
+// @Summary Status layanan
+// @Description Status database, Redis, dan RabbitMQ. Jika hanya RabbitMQ yang terputus status menjadi degraded karena event tetap tertampung di outbox sampai koneksi pulih
+// @Tags health
+// @Produce json
+// @Success 200 {object} HealthResponse "Layanan sehat atau degraded"
+// @Failure 503 {object} HealthResponse "Database atau Redis tidak dapat dihubungi"
+// @Router /health [get]
+func (h *HealthControllers) Health(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), healthCheckTimeout)
+	defer cancel()
+
+	resp := HealthResponse{
+		Status:   "healthy",
+		Service:  "train-ticket-api",
+		Database: toHealthCheck(h.db.PingContext(ctx)),
+		Redis:    toHealthCheck(h.redis.Ping(ctx)),
+		RabbitMQ: h.rabbitmq.Status(),
+	}
+
+	code := http.StatusOK
+	switch {
+	case resp.Database.Status != "up" || resp.Redis.Status != "up":
+		resp.Status = "unhealthy"
+		code = http.StatusServiceUnavailable
+	case !resp.RabbitMQ.Connected:
+		resp.Status = "degraded"
+	}
+
+	c.JSON(code, resp)
+}
+
+func toHealthCheck(err error) HealthCheck {
+	if err != nil {
+		return HealthCheck{Status: "down", Error: err.Error()}
+	}
+	return HealthCheck{Status: "up"}
+}
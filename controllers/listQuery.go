@@ -0,0 +1,16 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"tiketsepur/repository"
+)
+
+// listErrorStatus membedakan parameter list yang tidak valid (sort atau cursor
+// yang tidak dikenal) dari kegagalan server.
+func listErrorStatus(err error) int {
+	if errors.Is(err, repository.ErrInvalidQuerySpec) {
+		return http.StatusBadRequest
+	}
+	return http.StatusInternalServerError
+}
@@ -0,0 +1,117 @@
+package controllers
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"tiketsepur/service"
+	"tiketsepur/utils"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	maxLiveSchedules  = 20
+	liveHeartbeatTick = 15 * time.Second
+)
+
+type LiveControllers struct {
+	liveSchedules service.LiveScheduleService
+}
+
+func NewLiveControllers(liveSchedules service.LiveScheduleService) *LiveControllers {
+	return &LiveControllers{liveSchedules: liveSchedules}
+}
+
+// Schedules godoc
+// @Summary Live update jadwal
+// @Description Server-sent events berisi kondisi kursi, status, keterlambatan, dan peron jadwal. Event "schedule" dikirim sekali per jadwal saat terhubung (event=snapshot) lalu setiap kali jadwal berubah (event=updated atau deleted). Komentar ping dikirim setiap 15 detik
+// @Tags schedules
+// @Produce text/event-stream
+// @Param ids query string true "Daftar schedule ID dipisah koma, maksimal 20"
+// @Success 200 {object} utils.ScheduleUpdate "Stream update jadwal"
+// @Failure 400 {object} utils.Response "Request tidak valid"
+// @Failure 404 {object} utils.Response "Jadwal tidak ditemukan"
+// @Router /public/live/schedules [get]
+func (h *LiveControllers) Schedules(c *gin.Context) {
+	ids, err := parseScheduleIDs(c.Query("ids"))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "request tidak valid", err)
+		return
+	}
+
+	// berlangganan sebelum membaca snapshot supaya perubahan di antaranya
+	// tidak terlewat
+	updates, unsubscribe, err := h.liveSchedules.Subscribe(ids)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusServiceUnavailable, "live update tidak tersedia", err)
+		return
+	}
+	defer unsubscribe()
+
+	var snapshots []*utils.ScheduleUpdate
+	for _, id := range ids {
+		snapshot, err := h.liveSchedules.Snapshot(id)
+		if err != nil {
+			utils.ErrorResponse(c, http.StatusNotFound, "jadwal tidak ditemukan", err)
+			return
+		}
+		snapshots = append(snapshots, snapshot)
+	}
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+
+	for _, snapshot := range snapshots {
+		c.SSEvent("schedule", snapshot)
+	}
+	c.Writer.Flush()
+
+	heartbeat := time.NewTicker(liveHeartbeatTick)
+	defer heartbeat.Stop()
+
+	c.Stream(func(w io.Writer) bool {
+		select {
+		case update, ok := <-updates:
+			if !ok {
+				return false
+			}
+			c.SSEvent("schedule", update)
+			return true
+		case <-heartbeat.C:
+			_, err := io.WriteString(w, ": ping\n\n")
+			return err == nil
+		case <-c.Request.Context().Done():
+			return false
+		}
+	})
+}
+
+func parseScheduleIDs(raw string) ([]int, error) {
+	if strings.TrimSpace(raw) == "" {
+		return nil, errors.New("ids wajib diisi")
+	}
+
+	seen := make(map[int]struct{})
+	var ids []int
+	for _, part := range strings.Split(raw, ",") {
+		id, err := strconv.Atoi(strings.TrimSpace(part))
+		if err != nil || id <= 0 {
+			return nil, errors.New("ids harus berisi schedule id yang valid")
+		}
+		if _, ok := seen[id]; ok {
+			continue
+		}
+		seen[id] = struct{}{}
+		ids = append(ids, id)
+	}
+	if len(ids) > maxLiveSchedules {
+		return nil, errors.New("maksimal 20 jadwal per koneksi")
+	}
+	return ids, nil
+}
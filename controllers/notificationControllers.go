@@ -0,0 +1,159 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+	"tiketsepur/dto"
+	"tiketsepur/service"
+	"tiketsepur/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// @title Notification API
+// @description API for notification channel preferences and delivery history
+type NotificationControllers struct {
+	notificationService service.NotificationService
+}
+
+func NewNotificationControllers(notificationService service.NotificationService) *NotificationControllers {
+	return &NotificationControllers{notificationService: notificationService}
+}
+
+// GetPreferences godoc
+// @Summary Preferensi notifikasi
+// @Description Channel notifikasi yang dipilih pengguna saat ini per tipe notifikasi. Tipe yang tidak tercantum memakai channel default
+// @Tags notifications
+// @Accept json
+// @Produce json
+// @Success 200 {object} utils.Response{data=[]models.NotificationPreference} "Daftar preferensi notifikasi"
+// @Failure 500 {object} utils.Response "Internal server error"
+// @Router /notifications/preferences [get]
+// @Security BearerAuth
+func (h *NotificationControllers) GetPreferences(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	prefs, err := h.notificationService.GetPreferences(userID.(int))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "gagal mendapatkan preferensi notifikasi", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "preferensi notifikasi berhasil didapatkan", prefs)
+}
+
+// UpdatePreference godoc
+// @Summary Atur preferensi notifikasi
+// @Description Pilih channel (email, sms, webhook) untuk satu tipe notifikasi. Daftar channel kosong berarti tidak menerima notifikasi tipe tersebut
+// @Tags notifications
+// @Accept json
+// @Produce json
+// @Param preference body dto.UpdateNotificationPreferenceRequest true "Preferensi notifikasi"
+// @Success 200 {object} utils.Response{data=models.NotificationPreference} "Preferensi notifikasi berhasil disimpan"
+// @Failure 400 {object} utils.Response "Request tidak valid"
+// @Router /notifications/preferences [put]
+// @Security BearerAuth
+func (h *NotificationControllers) UpdatePreference(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	var req dto.UpdateNotificationPreferenceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "request tidak valid", err)
+		return
+	}
+
+	pref, err := h.notificationService.UpdatePreference(userID.(int), req)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "gagal menyimpan preferensi notifikasi", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "preferensi notifikasi berhasil disimpan", pref)
+}
+
+// GetDeliveries godoc
+// @Summary Riwayat pengiriman notifikasi
+// @Description Status pengiriman notifikasi terbaru milik pengguna saat ini
+// @Tags notifications
+// @Accept json
+// @Produce json
+// @Param limit query int false "Jumlah data per halaman (default 20, maksimal 100)"
+// @Param offset query int false "Offset untuk pagination offset"
+// @Param cursor query string false "next_cursor dari response sebelumnya untuk pagination cursor"
+// @Param order query string false "Urutan sort (asc atau desc)"
+// @Param status query string false "Filter status pengiriman (sent, failed, skipped)"
+// @Param from query string false "Tanggal kirim mulai (YYYY-MM-DD)"
+// @Param to query string false "Tanggal kirim sampai (YYYY-MM-DD)"
+// @Success 200 {object} utils.Response{data=[]models.NotificationDelivery,meta=repository.Page} "Riwayat pengiriman notifikasi"
+// @Failure 400 {object} utils.Response "Parameter query tidak valid"
+// @Failure 500 {object} utils.Response "Internal server error"
+// @Router /notifications/deliveries [get]
+// @Security BearerAuth
+func (h *NotificationControllers) GetDeliveries(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	var query dto.ListQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "parameter query tidak valid", err)
+		return
+	}
+
+	deliveries, page, err := h.notificationService.GetDeliveries(userID.(int), query)
+	if err != nil {
+		utils.ErrorResponse(c, listErrorStatus(err), "gagal mendapatkan riwayat notifikasi", err)
+		return
+	}
+
+	utils.PaginatedResponse(c, http.StatusOK, "riwayat notifikasi berhasil didapatkan", deliveries, page)
+}
+
+// GetDeadLetters godoc
+// @Summary Notifikasi di dead-letter queue
+// @Description Lihat notifikasi yang gagal diproses setelah semua percobaan ulang, tanpa menghapusnya dari queue
+// @Tags notifications
+// @Accept json
+// @Produce json
+// @Param limit query int false "Jumlah maksimal pesan (default 20)"
+// @Success 200 {object} utils.Response{data=[]utils.DeadLetter} "Daftar notifikasi gagal"
+// @Failure 500 {object} utils.Response "Internal server error"
+// @Router /notifications/dead-letters [get]
+// @Security BearerAuth
+func (h *NotificationControllers) GetDeadLetters(c *gin.Context) {
+	limit, _ := strconv.Atoi(c.Query("limit"))
+
+	letters, err := h.notificationService.GetDeadLetters(limit)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "gagal membaca dead-letter queue", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "dead-letter queue berhasil dibaca", letters)
+}
+
+// ReplayDeadLetters godoc
+// @Summary Kirim ulang notifikasi gagal
+// @Description Kembalikan notifikasi dari dead-letter queue ke queue utama. Jika message_ids kosong, pesan terlama sampai limit yang dikirim ulang
+// @Tags notifications
+// @Accept json
+// @Produce json
+// @Param replay body dto.ReplayDeadLettersRequest true "Pesan yang akan dikirim ulang"
+// @Success 200 {object} utils.Response{data=[]string} "Id pesan yang dikirim ulang"
+// @Failure 400 {object} utils.Response "Request tidak valid"
+// @Failure 500 {object} utils.Response "Internal server error"
+// @Router /notifications/dead-letters/replay [post]
+// @Security BearerAuth
+func (h *NotificationControllers) ReplayDeadLetters(c *gin.Context) {
+	var req dto.ReplayDeadLettersRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "request tidak valid", err)
+		return
+	}
+
+	replayed, err := h.notificationService.ReplayDeadLetters(c.Request.Context(), req)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "gagal mengirim ulang notifikasi", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "notifikasi berhasil dikirim ulang", replayed)
+}
@@ -0,0 +1,126 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+	"tiketsepur/dto"
+	"tiketsepur/service"
+	"tiketsepur/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// @title Notification Template API
+// @description API for managing versioned, localized notification templates
+type NotificationTemplateControllers struct {
+	templateService service.NotificationTemplateService
+}
+
+func NewNotificationTemplateControllers(templateService service.NotificationTemplateService) *NotificationTemplateControllers {
+	return &NotificationTemplateControllers{templateService: templateService}
+}
+
+// GetAll godoc
+// @Summary Semua template notifikasi
+// @Description Daftar semua versi template notifikasi, bisa difilter per tipe dan bahasa
+// @Tags notification-templates
+// @Accept json
+// @Produce json
+// @Param type query string false "Tipe notifikasi (booking, payment, cancellation, lockout, expiry, reminder, disruption)"
+// @Param language query string false "Bahasa (id, en)"
+// @Success 200 {object} utils.Response{data=[]models.NotificationTemplate} "Daftar template notifikasi"
+// @Failure 500 {object} utils.Response "Internal server error"
+// @Router /notification-templates [get]
+// @Security BearerAuth
+func (h *NotificationTemplateControllers) GetAll(c *gin.Context) {
+	templates, err := h.templateService.GetAll(c.Query("type"), c.Query("language"))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "gagal mendapatkan template notifikasi", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "template notifikasi berhasil didapatkan", templates)
+}
+
+// Create godoc
+// @Summary Buat versi template notifikasi
+// @Description Simpan template sebagai versi baru untuk tipe dan bahasa tersebut. Template divalidasi dengan data contoh sebelum disimpan
+// @Tags notification-templates
+// @Accept json
+// @Produce json
+// @Param template body dto.CreateNotificationTemplateRequest true "Isi template"
+// @Success 201 {object} utils.Response{data=models.NotificationTemplate} "Template notifikasi berhasil dibuat"
+// @Failure 400 {object} utils.Response "Request tidak valid"
+// @Router /notification-templates [post]
+// @Security BearerAuth
+func (h *NotificationTemplateControllers) Create(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	var req dto.CreateNotificationTemplateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "request tidak valid", err)
+		return
+	}
+
+	tpl, err := h.templateService.Create(userID.(int), req)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "gagal membuat template notifikasi", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusCreated, "template notifikasi berhasil dibuat", tpl)
+}
+
+// Activate godoc
+// @Summary Aktifkan versi template
+// @Description Jadikan versi template ini yang dipakai untuk tipe dan bahasanya
+// @Tags notification-templates
+// @Accept json
+// @Produce json
+// @Param id path int true "Template ID"
+// @Success 200 {object} utils.Response{data=models.NotificationTemplate} "Template notifikasi berhasil diaktifkan"
+// @Failure 400 {object} utils.Response "Gagal mengaktifkan template"
+// @Router /notification-templates/{id}/activate [put]
+// @Security BearerAuth
+func (h *NotificationTemplateControllers) Activate(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "id template tidak valid", err)
+		return
+	}
+
+	tpl, err := h.templateService.Activate(id)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "gagal mengaktifkan template notifikasi", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "template notifikasi berhasil diaktifkan", tpl)
+}
+
+// Preview godoc
+// @Summary Preview template notifikasi
+// @Description Render template tersimpan atau draft dengan data contoh atau data yang dikirim, tanpa mengirim notifikasi
+// @Tags notification-templates
+// @Accept json
+// @Produce json
+// @Param preview body dto.PreviewNotificationTemplateRequest true "Template dan data preview"
+// @Success 200 {object} utils.Response{data=utils.RenderedNotification} "Hasil render template"
+// @Failure 400 {object} utils.Response "Template tidak valid"
+// @Router /notification-templates/preview [post]
+// @Security BearerAuth
+func (h *NotificationTemplateControllers) Preview(c *gin.Context) {
+	var req dto.PreviewNotificationTemplateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "request tidak valid", err)
+		return
+	}
+
+	rendered, err := h.templateService.Preview(req)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "gagal merender template notifikasi", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "template notifikasi berhasil dirender", rendered)
+}
@@ -0,0 +1,70 @@
+package controllers
+
+import (
+	"net/http"
+	"tiketsepur/service"
+	"tiketsepur/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// @title OIDC API
+// @description API for social login through OpenID Connect providers
+type OIDCControllers struct {
+	oidcService service.OIDCService
+}
+
+func NewOIDCControllers(oidcService service.OIDCService) *OIDCControllers {
+	return &OIDCControllers{oidcService: oidcService}
+}
+
+// Login godoc
+// @Summary Login dengan provider OIDC
+// @Description Redirect ke halaman login provider (authorization code + PKCE)
+// @Tags auth
+// @Param provider path string true "Nama provider, contoh: google"
+// @Success 302 "Redirect ke provider"
+// @Failure 400 {object} utils.Response "Provider tidak dikenal"
+// @Router /auth/oidc/{provider}/login [get]
+func (h *OIDCControllers) Login(c *gin.Context) {
+	url, err := h.oidcService.LoginURL(c.Request.Context(), c.Param("provider"))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "gagal memulai login", err)
+		return
+	}
+
+	c.Redirect(http.StatusFound, url)
+}
+
+// Callback godoc
+// @Summary Callback provider OIDC
+// @Description Tukar authorization code dengan sesi JWT
+// @Tags auth
+// @Produce json
+// @Param provider path string true "Nama provider, contoh: google"
+// @Param code query string true "Authorization code"
+// @Param state query string true "State"
+// @Success 200 {object} utils.Response{data=dto.LoginResponse} "Login sukses"
+// @Failure 401 {object} utils.Response "Login gagal"
+// @Router /auth/oidc/{provider}/callback [get]
+func (h *OIDCControllers) Callback(c *gin.Context) {
+	if errParam := c.Query("error"); errParam != "" {
+		utils.ErrorResponse(c, http.StatusUnauthorized, "login dibatalkan oleh provider", nil)
+		return
+	}
+
+	code := c.Query("code")
+	state := c.Query("state")
+	if code == "" || state == "" {
+		utils.ErrorResponse(c, http.StatusBadRequest, "code dan state diperlukan", nil)
+		return
+	}
+
+	result, err := h.oidcService.Callback(c.Request.Context(), c.Param("provider"), code, state)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusUnauthorized, "login gagal", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "login sukses", result)
+}
@@ -0,0 +1,206 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+	"tiketsepur/dto"
+	"tiketsepur/service"
+	"tiketsepur/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// @title Role API
+// @description API for managing roles and permissions
+type RoleControllers struct {
+	rbacService service.RBACService
+}
+
+func NewRoleControllers(rbacService service.RBACService) *RoleControllers {
+	return &RoleControllers{rbacService: rbacService}
+}
+
+// Create godoc
+// @Summary Buat role baru
+// @Description Buat role baru beserta daftar permission
+// @Tags roles
+// @Accept json
+// @Produce json
+// @Param role body dto.CreateRoleRequest true "Detail role"
+// @Success 201 {object} utils.Response{data=models.Role} "Role berhasil dibuat"
+// @Failure 400 {object} utils.Response "Request tidak valid"
+// @Router /roles [post]
+// @Security BearerAuth
+func (h *RoleControllers) Create(c *gin.Context) {
+	var req dto.CreateRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "request tidak valid", err)
+		return
+	}
+
+	role, err := h.rbacService.CreateRole(c.Request.Context(), req)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "gagal membuat role", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusCreated, "role berhasil dibuat", role)
+}
+
+// GetAll godoc
+// @Summary Semua role
+// @Description Daftar semua role beserta permission
+// @Tags roles
+// @Accept json
+// @Produce json
+// @Param limit query int false "Jumlah data per halaman (default 20, maksimal 100)"
+// @Param offset query int false "Offset untuk pagination offset"
+// @Param cursor query string false "next_cursor dari response sebelumnya untuk pagination cursor"
+// @Param order query string false "Urutan sort (asc atau desc)"
+// @Param sort query string false "Field sort: name, created_at, id"
+// @Param q query string false "Cari nama role"
+// @Success 200 {object} utils.Response{data=[]models.Role,meta=repository.Page} "Daftar role"
+// @Failure 400 {object} utils.Response "Parameter query tidak valid"
+// @Failure 500 {object} utils.Response "Internal server error"
+// @Router /roles [get]
+// @Security BearerAuth
+func (h *RoleControllers) GetAll(c *gin.Context) {
+	var query dto.ListQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "parameter query tidak valid", err)
+		return
+	}
+
+	roles, page, err := h.rbacService.GetRoles(query)
+	if err != nil {
+		utils.ErrorResponse(c, listErrorStatus(err), "gagal mendapatkan role", err)
+		return
+	}
+
+	utils.PaginatedResponse(c, http.StatusOK, "role berhasil didapatkan", roles, page)
+}
+
+// GetByID godoc
+// @Summary Role by ID
+// @Description Detail role by ID
+// @Tags roles
+// @Accept json
+// @Produce json
+// @Param id path int true "Role ID"
+// @Success 200 {object} utils.Response{data=models.Role} "Detail role"
+// @Failure 404 {object} utils.Response "Role tidak ditemukan"
+// @Router /roles/{id} [get]
+// @Security BearerAuth
+func (h *RoleControllers) GetByID(c *gin.Context) {
+	id, _ := strconv.Atoi(c.Param("id"))
+
+	role, err := h.rbacService.GetRoleByID(id)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusNotFound, "role tidak ditemukan", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "role berhasil didapatkan", role)
+}
+
+// Delete godoc
+// @Summary Delete role
+// @Description Hapus role yang tidak lagi digunakan
+// @Tags roles
+// @Accept json
+// @Produce json
+// @Param id path int true "Role ID"
+// @Success 200 {object} utils.Response "Role berhasil dihapus"
+// @Failure 400 {object} utils.Response "Gagal hapus role"
+// @Router /roles/{id} [delete]
+// @Security BearerAuth
+func (h *RoleControllers) Delete(c *gin.Context) {
+	id, _ := strconv.Atoi(c.Param("id"))
+
+	if err := h.rbacService.DeleteRole(c.Request.Context(), id); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "gagal hapus role", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "role berhasil dihapus", nil)
+}
+
+// SetPermissions godoc
+// @Summary Atur permission role
+// @Description Ganti seluruh daftar permission milik role
+// @Tags roles
+// @Accept json
+// @Produce json
+// @Param id path int true "Role ID"
+// @Param permissions body dto.SetRolePermissionsRequest true "Daftar permission"
+// @Success 200 {object} utils.Response{data=models.Role} "Permission berhasil diatur"
+// @Failure 400 {object} utils.Response "Request tidak valid"
+// @Router /roles/{id}/permissions [put]
+// @Security BearerAuth
+func (h *RoleControllers) SetPermissions(c *gin.Context) {
+	id, _ := strconv.Atoi(c.Param("id"))
+
+	var req dto.SetRolePermissionsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "request tidak valid", err)
+		return
+	}
+
+	role, err := h.rbacService.SetRolePermissions(c.Request.Context(), id, req)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "gagal mengatur permission", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "permission berhasil diatur", role)
+}
+
+// GetPermissions godoc
+// @Summary Semua permission
+// @Description Daftar semua permission yang tersedia
+// @Tags roles
+// @Accept json
+// @Produce json
+// @Success 200 {object} utils.Response{data=[]models.Permission} "Daftar permission"
+// @Failure 500 {object} utils.Response "Internal server error"
+// @Router /permissions [get]
+// @Security BearerAuth
+func (h *RoleControllers) GetPermissions(c *gin.Context) {
+	permissions, err := h.rbacService.GetAllPermissions()
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "gagal mendapatkan permission", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "permission berhasil didapatkan", permissions)
+}
+
+// AssignUser godoc
+// @Summary Atur role user
+// @Description Ganti role yang dimiliki user
+// @Tags roles
+// @Accept json
+// @Produce json
+// @Param id path int true "User ID"
+// @Param role body dto.AssignRoleRequest true "Role baru"
+// @Success 200 {object} utils.Response{data=models.User} "Role user berhasil diatur"
+// @Failure 400 {object} utils.Response "Request tidak valid"
+// @Router /users/{id}/role [put]
+// @Security BearerAuth
+func (h *RoleControllers) AssignUser(c *gin.Context) {
+	id, _ := strconv.Atoi(c.Param("id"))
+
+	var req dto.AssignRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "request tidak valid", err)
+		return
+	}
+
+	user, err := h.rbacService.AssignUserRole(c.Request.Context(), id, req)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "gagal mengatur role user", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "role user berhasil diatur", user)
+}
@@ -22,13 +22,14 @@ func NewScheduleControllers(scheduleService service.ScheduleService) *ScheduleCo
 
 // Create godoc
 // @Summary Buat jadwal baru
-// @Description Buat jadwal kereta baru
+// @Description Buat jadwal kereta baru. Stasiun harus sudah terdaftar; departure_time dan arrival_time dikirim dalam RFC3339 dengan offset dan dikembalikan dalam jam lokal stasiun masing-masing
 // @Tags schedules
 // @Accept json
 // @Produce json
 // @Param schedule body dto.CreateScheduleRequest true "Detail jadwal"
 // @Success 201 {object} utils.Response{data=models.Schedule} "Jadwal berhasil dibuat"
 // @Failure 400 {object} utils.Response "Request tidak valid"
+// @Failure 422 {object} utils.Response "Jadwal melanggar aturan konsistensi, detail per field di errors"
 // @Router /schedules [post]
 // @Security BearerAuth
 func (h *ScheduleControllers) Create(c *gin.Context) {
@@ -40,7 +41,7 @@ func (h *ScheduleControllers) Create(c *gin.Context) {
 
 	schedule, err := h.scheduleService.Create(req)
 	if err != nil {
-		utils.ErrorResponse(c, http.StatusBadRequest, "jadwal gagal dibuat", err)
+		utils.ErrorResponse(c, validationErrorStatus(err, http.StatusBadRequest), "jadwal gagal dibuat", err)
 		return
 	}
 
@@ -53,18 +54,35 @@ func (h *ScheduleControllers) Create(c *gin.Context) {
 // @Tags schedules
 // @Accept json
 // @Produce json
-// @Success 200 {object} utils.Response{data=[]models.Schedule} "Daftar jadwal"
+// @Param limit query int false "Jumlah data per halaman (default 20, maksimal 100)"
+// @Param offset query int false "Offset untuk pagination offset"
+// @Param cursor query string false "next_cursor dari response sebelumnya untuk pagination cursor"
+// @Param order query string false "Urutan sort (asc atau desc)"
+// @Param sort query string false "Field sort: departure_time, price, available_seats, created_at, id"
+// @Param status query string false "Filter status jadwal (scheduled, delayed, cancelled)"
+// @Param train_id query int false "Filter train ID"
+// @Param train_type query string false "Filter tipe kereta"
+// @Param from query string false "Tanggal berangkat mulai (YYYY-MM-DD, waktu lokal stasiun keberangkatan)"
+// @Param to query string false "Tanggal berangkat sampai (YYYY-MM-DD, waktu lokal stasiun keberangkatan)"
+// @Param q query string false "Cari stasiun keberangkatan atau tujuan"
+// @Success 200 {object} utils.Response{data=[]models.Schedule,meta=repository.Page} "Daftar jadwal"
+// @Failure 400 {object} utils.Response "Parameter query tidak valid"
 // @Failure 500 {object} utils.Response "Gagal mendapatkan jadwal"
-// @Router /admin/schedules [get]
-// @Security BearerAuth
+// @Router /public/schedules [get]
 func (h *ScheduleControllers) GetAll(c *gin.Context) {
-	schedules, err := h.scheduleService.GetAll()
+	var query dto.ListQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "parameter query tidak valid", err)
+		return
+	}
+
+	schedules, page, err := h.scheduleService.GetAll(query)
 	if err != nil {
-		utils.ErrorResponse(c, http.StatusInternalServerError, "gagal mendapatkan jadwal", err)
+		utils.ErrorResponse(c, listErrorStatus(err), "gagal mendapatkan jadwal", err)
 		return
 	}
 
-	utils.SuccessResponse(c, http.StatusOK, "jadwal berhasil didapatkan", schedules)
+	utils.PaginatedResponse(c, http.StatusOK, "jadwal berhasil didapatkan", schedules, page)
 }
 
 // GetByID godoc
@@ -95,9 +113,16 @@ func (h *ScheduleControllers) GetByID(c *gin.Context) {
 // @Tags schedules
 // @Accept json
 // @Produce json
-// @Param origin query string false "Origin station"
-// @Param destination query string false "Destination station"
-// @Param date query string false "Travel date (YYYY-MM-DD)"
+// @Param departure_station query string false "Stasiun keberangkatan"
+// @Param arrival_station query string false "Stasiun tujuan"
+// @Param date query string false "Tanggal berangkat (YYYY-MM-DD, waktu lokal stasiun keberangkatan)"
+// @Param departure_after query string false "Jam berangkat paling awal (HH:MM, waktu lokal stasiun keberangkatan)"
+// @Param departure_before query string false "Jam berangkat paling akhir (HH:MM, waktu lokal stasiun keberangkatan)"
+// @Param train_type query []string false "Tipe/kelas kereta, boleh lebih dari satu" collectionFormat(multi)
+// @Param max_price query number false "Harga maksimal"
+// @Param min_seats query int false "Minimal kursi tersisa (default 1)"
+// @Param sort query string false "Urutkan berdasarkan departure_time, duration, atau price"
+// @Param order query string false "Urutan sort (asc atau desc)"
 // @Success 200 {object} utils.Response{data=[]models.Schedule} "Daftar jadwal sesuai kriteria"
 // @Failure 400 {object} utils.Response "Request tidak valid"
 // @Failure 500 {object} utils.Response "Internal server error"
@@ -129,20 +154,78 @@ func (h *ScheduleControllers) Update(c *gin.Context) {
 
 	schedule, err := h.scheduleService.Update(id, req)
 	if err != nil {
-		utils.ErrorResponse(c, http.StatusBadRequest, "gagal update schedule", err)
+		utils.ErrorResponse(c, validationErrorStatus(err, http.StatusBadRequest), "gagal update schedule", err)
 		return
 	}
 
 	utils.SuccessResponse(c, http.StatusOK, "jadwal berhasil diupdate", schedule)
 }
 
+// Delete godoc
+// @Summary Hapus jadwal
+// @Description Jadwal tanpa tiket dihapus. Jadwal yang sudah punya tiket dibatalkan (soft-cancel): tiket aktif dibatalkan dan di-refund, penumpang diberi notifikasi, dan gangguan yang tercatat dikembalikan
+// @Tags schedules
+// @Produce json
+// @Param id path int true "Schedule ID"
+// @Success 200 {object} utils.Response{data=models.ScheduleDisruption} "Jadwal dihapus atau dibatalkan"
+// @Failure 400 {object} utils.Response "Jadwal tidak ditemukan atau sudah dibatalkan"
+// @Router /schedules/{id} [delete]
+// @Security BearerAuth
 func (h *ScheduleControllers) Delete(c *gin.Context) {
 	id, _ := strconv.Atoi(c.Param("id"))
+	userID, _ := c.Get("user_id")
 
-	if err := h.scheduleService.Delete(id); err != nil {
+	disruption, err := h.scheduleService.Delete(id, userID.(int))
+	if err != nil {
 		utils.ErrorResponse(c, http.StatusBadRequest, "gagal menghapus schedule", err)
 		return
 	}
 
+	if disruption != nil {
+		utils.SuccessResponse(c, http.StatusOK, "jadwal memiliki tiket sehingga dibatalkan, bukan dihapus", disruption)
+		return
+	}
 	utils.SuccessResponse(c, http.StatusOK, "jadwal berhasil dihapus", nil)
-}
\ No newline at end of file
+}
+
+// RecomputeSeats godoc
+// @Summary Hitung ulang kursi tersedia
+// @Description Set available_seats menjadi kapasitas kereta dikurangi tiket aktif (pending dan confirmed)
+// @Tags schedules
+// @Produce json
+// @Param id path int true "Schedule ID"
+// @Success 200 {object} utils.Response{data=models.Schedule} "Kursi berhasil dihitung ulang"
+// @Failure 400 {object} utils.Response "Jadwal tidak ditemukan"
+// @Failure 422 {object} utils.Response "Tiket aktif melebihi kapasitas kereta"
+// @Router /schedules/{id}/recompute-seats [post]
+// @Security BearerAuth
+func (h *ScheduleControllers) RecomputeSeats(c *gin.Context) {
+	id, _ := strconv.Atoi(c.Param("id"))
+
+	schedule, err := h.scheduleService.RecomputeSeats(id)
+	if err != nil {
+		utils.ErrorResponse(c, validationErrorStatus(err, http.StatusBadRequest), "gagal menghitung ulang kursi", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "kursi tersedia berhasil dihitung ulang", schedule)
+}
+
+// GetViolations godoc
+// @Summary Laporan pelanggaran jadwal
+// @Description Jadwal tersimpan yang melanggar aturan konsistensi: waktu tiba tidak setelah berangkat, stasiun asal dan tujuan sama, stasiun belum terdaftar, kursi melebihi kapasitas kereta, kereta dipakai dua jadwal yang beririsan, atau kereta sedang dalam perawatan
+// @Tags schedules
+// @Produce json
+// @Success 200 {object} utils.Response{data=[]models.ScheduleViolation} "Daftar pelanggaran"
+// @Failure 500 {object} utils.Response "Internal server error"
+// @Router /schedules/violations [get]
+// @Security BearerAuth
+func (h *ScheduleControllers) GetViolations(c *gin.Context) {
+	violations, err := h.scheduleService.GetViolations()
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "gagal memeriksa jadwal", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "laporan pelanggaran jadwal", violations)
+}
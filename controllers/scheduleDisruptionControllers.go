@@ -0,0 +1,73 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+	"tiketsepur/dto"
+	"tiketsepur/service"
+	"tiketsepur/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ScheduleDisruptionControllers struct {
+	disruptionService service.ScheduleDisruptionService
+}
+
+func NewScheduleDisruptionControllers(disruptionService service.ScheduleDisruptionService) *ScheduleDisruptionControllers {
+	return &ScheduleDisruptionControllers{disruptionService: disruptionService}
+}
+
+// Create godoc
+// @Summary Catat gangguan jadwal
+// @Description Catat keterlambatan, perubahan peron, atau pembatalan jadwal. Semua pemegang tiket diberi notifikasi; untuk pembatalan tiket dibatalkan, pembayaran di-refund penuh, dan jadwal pengganti ditawarkan
+// @Tags schedules
+// @Accept json
+// @Produce json
+// @Param id path int true "Schedule ID"
+// @Param disruption body dto.CreateScheduleDisruptionRequest true "Detail gangguan"
+// @Success 201 {object} utils.Response{data=models.ScheduleDisruption} "Gangguan berhasil dicatat"
+// @Failure 400 {object} utils.Response "Request tidak valid"
+// @Router /schedules/{id}/disruptions [post]
+// @Security BearerAuth
+func (h *ScheduleDisruptionControllers) Create(c *gin.Context) {
+	id, _ := strconv.Atoi(c.Param("id"))
+	userID, _ := c.Get("user_id")
+
+	var req dto.CreateScheduleDisruptionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "request tidak valid", err)
+		return
+	}
+
+	disruption, err := h.disruptionService.Create(id, userID.(int), req)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "gangguan jadwal gagal dicatat", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusCreated, "gangguan jadwal berhasil dicatat", disruption)
+}
+
+// GetBySchedule godoc
+// @Summary Riwayat gangguan jadwal
+// @Description Daftar gangguan yang pernah dicatat untuk sebuah jadwal, terbaru lebih dulu
+// @Tags schedules
+// @Accept json
+// @Produce json
+// @Param id path int true "Schedule ID"
+// @Success 200 {object} utils.Response{data=[]models.ScheduleDisruption} "Daftar gangguan"
+// @Failure 404 {object} utils.Response "Jadwal tidak ditemukan"
+// @Router /schedules/{id}/disruptions [get]
+// @Security BearerAuth
+func (h *ScheduleDisruptionControllers) GetBySchedule(c *gin.Context) {
+	id, _ := strconv.Atoi(c.Param("id"))
+
+	disruptions, err := h.disruptionService.GetBySchedule(id)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusNotFound, "gagal mendapatkan gangguan jadwal", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "gangguan jadwal berhasil didapatkan", disruptions)
+}
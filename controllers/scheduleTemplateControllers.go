@@ -0,0 +1,187 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+	"tiketsepur/dto"
+	"tiketsepur/service"
+	"tiketsepur/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ScheduleTemplateControllers struct {
+	templateService service.ScheduleTemplateService
+}
+
+func NewScheduleTemplateControllers(templateService service.ScheduleTemplateService) *ScheduleTemplateControllers {
+	return &ScheduleTemplateControllers{templateService: templateService}
+}
+
+// Create godoc
+// @Summary Buat template jadwal
+// @Description Buat pola jadwal berulang: kereta, rute, jam berangkat/tiba, hari operasi (1 = Senin ... 7 = Minggu), masa berlaku, dan harga
+// @Tags schedule-templates
+// @Accept json
+// @Produce json
+// @Param template body dto.CreateScheduleTemplateRequest true "Detail template"
+// @Success 201 {object} utils.Response{data=models.ScheduleTemplate} "Template berhasil dibuat"
+// @Failure 400 {object} utils.Response "Request tidak valid"
+// @Router /schedule-templates [post]
+// @Security BearerAuth
+func (h *ScheduleTemplateControllers) Create(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	var req dto.CreateScheduleTemplateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "request tidak valid", err)
+		return
+	}
+
+	template, err := h.templateService.Create(req, userID.(int))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "template jadwal gagal dibuat", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusCreated, "template jadwal berhasil dibuat", template)
+}
+
+// GetAll godoc
+// @Summary Semua template jadwal
+// @Tags schedule-templates
+// @Produce json
+// @Param limit query int false "Jumlah data per halaman (default 20, maksimal 100)"
+// @Param offset query int false "Offset untuk pagination offset"
+// @Param cursor query string false "next_cursor dari response sebelumnya untuk pagination cursor"
+// @Param order query string false "Urutan sort (asc atau desc)"
+// @Param sort query string false "Field sort: id, created_at"
+// @Param train_id query int false "Filter train ID"
+// @Param status query string false "Filter status (active atau inactive)"
+// @Param q query string false "Cari stasiun keberangkatan atau tujuan"
+// @Success 200 {object} utils.Response{data=[]models.ScheduleTemplate,meta=repository.Page} "Daftar template"
+// @Failure 400 {object} utils.Response "Parameter query tidak valid"
+// @Router /schedule-templates [get]
+// @Security BearerAuth
+func (h *ScheduleTemplateControllers) GetAll(c *gin.Context) {
+	var query dto.ListQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "parameter query tidak valid", err)
+		return
+	}
+
+	templates, page, err := h.templateService.GetAll(query)
+	if err != nil {
+		utils.ErrorResponse(c, listErrorStatus(err), "gagal mendapatkan template jadwal", err)
+		return
+	}
+
+	utils.PaginatedResponse(c, http.StatusOK, "template jadwal berhasil didapatkan", templates, page)
+}
+
+// GetByID godoc
+// @Summary Detail template jadwal
+// @Tags schedule-templates
+// @Produce json
+// @Param id path int true "Template ID"
+// @Success 200 {object} utils.Response{data=models.ScheduleTemplate} "Detail template"
+// @Failure 404 {object} utils.Response "Template tidak ditemukan"
+// @Router /schedule-templates/{id} [get]
+// @Security BearerAuth
+func (h *ScheduleTemplateControllers) GetByID(c *gin.Context) {
+	id, _ := strconv.Atoi(c.Param("id"))
+
+	template, err := h.templateService.GetByID(id)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusNotFound, "template jadwal tidak ditemukan", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "template jadwal ditemukan", template)
+}
+
+// Update godoc
+// @Summary Update template jadwal
+// @Description Perubahan hanya berlaku untuk jadwal yang di-generate setelahnya
+// @Tags schedule-templates
+// @Accept json
+// @Produce json
+// @Param id path int true "Template ID"
+// @Param template body dto.UpdateScheduleTemplateRequest true "Field yang diubah"
+// @Success 200 {object} utils.Response{data=models.ScheduleTemplate} "Template berhasil diupdate"
+// @Failure 400 {object} utils.Response "Request tidak valid"
+// @Router /schedule-templates/{id} [put]
+// @Security BearerAuth
+func (h *ScheduleTemplateControllers) Update(c *gin.Context) {
+	id, _ := strconv.Atoi(c.Param("id"))
+
+	var req dto.UpdateScheduleTemplateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "request tidak valid", err)
+		return
+	}
+
+	template, err := h.templateService.Update(id, req)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "gagal update template jadwal", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "template jadwal berhasil diupdate", template)
+}
+
+// Delete godoc
+// @Summary Hapus template jadwal
+// @Description Jadwal yang sudah di-generate tetap ada
+// @Tags schedule-templates
+// @Produce json
+// @Param id path int true "Template ID"
+// @Success 200 {object} utils.Response "Template berhasil dihapus"
+// @Failure 400 {object} utils.Response "Template tidak ditemukan"
+// @Router /schedule-templates/{id} [delete]
+// @Security BearerAuth
+func (h *ScheduleTemplateControllers) Delete(c *gin.Context) {
+	id, _ := strconv.Atoi(c.Param("id"))
+
+	if err := h.templateService.Delete(id); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "gagal menghapus template jadwal", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "template jadwal berhasil dihapus", nil)
+}
+
+// Generate godoc
+// @Summary Generate jadwal dari template
+// @Description Buat jadwal untuk setiap hari operasi di rentang from sampai to (maksimal 366 hari). Tanggal yang sudah punya jadwal dilewati. Dengan dry_run hasilnya hanya dipratinjau tanpa disimpan
+// @Tags schedule-templates
+// @Accept json
+// @Produce json
+// @Param id path int true "Template ID"
+// @Param request body dto.GenerateSchedulesRequest true "Rentang tanggal"
+// @Success 200 {object} utils.Response{data=dto.GenerateSchedulesResponse} "Pratinjau jadwal (dry_run)"
+// @Success 201 {object} utils.Response{data=dto.GenerateSchedulesResponse} "Jadwal berhasil dibuat"
+// @Failure 400 {object} utils.Response "Request tidak valid"
+// @Router /schedule-templates/{id}/generate [post]
+// @Security BearerAuth
+func (h *ScheduleTemplateControllers) Generate(c *gin.Context) {
+	id, _ := strconv.Atoi(c.Param("id"))
+
+	var req dto.GenerateSchedulesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "request tidak valid", err)
+		return
+	}
+
+	result, err := h.templateService.Generate(id, req)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "gagal generate jadwal", err)
+		return
+	}
+
+	if req.DryRun {
+		utils.SuccessResponse(c, http.StatusOK, "pratinjau jadwal", result)
+		return
+	}
+	utils.SuccessResponse(c, http.StatusCreated, "jadwal berhasil di-generate", result)
+}
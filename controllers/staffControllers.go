@@ -0,0 +1,202 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+	"tiketsepur/dto"
+	"tiketsepur/service"
+	"tiketsepur/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+type StaffControllers struct {
+	staffService service.StaffService
+}
+
+func NewStaffControllers(staffService service.StaffService) *StaffControllers {
+	return &StaffControllers{staffService: staffService}
+}
+
+// Create godoc
+// @Summary Daftarkan staf
+// @Description Daftarkan masinis atau kondektur beserta kualifikasinya. Isi user_id untuk menghubungkan staf dengan akun login; kondektur perlu akun dengan role conductor untuk scan boarding
+// @Tags staff
+// @Accept json
+// @Produce json
+// @Param staff body dto.CreateStaffRequest true "Detail staf"
+// @Success 201 {object} utils.Response{data=models.Staff} "Staf berhasil didaftarkan"
+// @Failure 400 {object} utils.Response "Request tidak valid"
+// @Router /staff [post]
+// @Security BearerAuth
+func (h *StaffControllers) Create(c *gin.Context) {
+	var req dto.CreateStaffRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "request tidak valid", err)
+		return
+	}
+
+	staff, err := h.staffService.Create(req)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "staf gagal didaftarkan", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusCreated, "staf berhasil didaftarkan", staff)
+}
+
+// GetAll godoc
+// @Summary Semua staf
+// @Tags staff
+// @Produce json
+// @Param limit query int false "Jumlah data per halaman (default 20, maksimal 100)"
+// @Param offset query int false "Offset untuk pagination offset"
+// @Param cursor query string false "next_cursor dari response sebelumnya untuk pagination cursor"
+// @Param order query string false "Urutan sort (asc atau desc)"
+// @Param sort query string false "Field sort: name, employee_number, id"
+// @Param qualification query string false "Filter kualifikasi (driver atau conductor)"
+// @Param status query string false "Filter status (active atau inactive)"
+// @Param q query string false "Cari nama atau nomor pegawai"
+// @Success 200 {object} utils.Response{data=[]models.Staff,meta=repository.Page} "Daftar staf"
+// @Failure 400 {object} utils.Response "Parameter query tidak valid"
+// @Router /staff [get]
+// @Security BearerAuth
+func (h *StaffControllers) GetAll(c *gin.Context) {
+	var query dto.StaffQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "parameter query tidak valid", err)
+		return
+	}
+
+	staff, page, err := h.staffService.GetAll(query)
+	if err != nil {
+		utils.ErrorResponse(c, listErrorStatus(err), "gagal mendapatkan staf", err)
+		return
+	}
+
+	utils.PaginatedResponse(c, http.StatusOK, "staf berhasil didapatkan", staff, page)
+}
+
+// GetByID godoc
+// @Summary Detail staf
+// @Tags staff
+// @Produce json
+// @Param id path int true "Staff ID"
+// @Success 200 {object} utils.Response{data=models.Staff} "Detail staf"
+// @Failure 404 {object} utils.Response "Staf tidak ditemukan"
+// @Router /staff/{id} [get]
+// @Security BearerAuth
+func (h *StaffControllers) GetByID(c *gin.Context) {
+	id, _ := strconv.Atoi(c.Param("id"))
+
+	staff, err := h.staffService.GetByID(id)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusNotFound, "staf tidak ditemukan", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "staf berhasil didapatkan", staff)
+}
+
+// Update godoc
+// @Summary Update staf
+// @Description Staf yang masih punya tugas mendatang tidak bisa dinonaktifkan
+// @Tags staff
+// @Accept json
+// @Produce json
+// @Param id path int true "Staff ID"
+// @Param staff body dto.UpdateStaffRequest true "Data staf yang diubah"
+// @Success 200 {object} utils.Response{data=models.Staff} "Staf berhasil diupdate"
+// @Failure 400 {object} utils.Response "Request tidak valid"
+// @Router /staff/{id} [put]
+// @Security BearerAuth
+func (h *StaffControllers) Update(c *gin.Context) {
+	id, _ := strconv.Atoi(c.Param("id"))
+
+	var req dto.UpdateStaffRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "request tidak valid", err)
+		return
+	}
+
+	staff, err := h.staffService.Update(id, req)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "gagal update staf", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "staf berhasil diupdate", staff)
+}
+
+// ReplaceQualifications godoc
+// @Summary Ganti kualifikasi staf
+// @Description Ganti seluruh kualifikasi staf; hanya berlaku untuk penugasan baru
+// @Tags staff
+// @Accept json
+// @Produce json
+// @Param id path int true "Staff ID"
+// @Param qualifications body dto.ReplaceQualificationsRequest true "Daftar kualifikasi"
+// @Success 200 {object} utils.Response{data=models.Staff} "Kualifikasi berhasil disimpan"
+// @Failure 400 {object} utils.Response "Request tidak valid"
+// @Router /staff/{id}/qualifications [put]
+// @Security BearerAuth
+func (h *StaffControllers) ReplaceQualifications(c *gin.Context) {
+	id, _ := strconv.Atoi(c.Param("id"))
+
+	var req dto.ReplaceQualificationsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "request tidak valid", err)
+		return
+	}
+
+	staff, err := h.staffService.ReplaceQualifications(id, req)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "gagal menyimpan kualifikasi staf", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "kualifikasi staf berhasil disimpan", staff)
+}
+
+// GetDuties godoc
+// @Summary Tugas mendatang staf
+// @Description Tugas staf di jadwal yang belum tiba, tanpa jadwal yang dibatalkan, urut dari keberangkatan terdekat
+// @Tags staff
+// @Produce json
+// @Param id path int true "Staff ID"
+// @Success 200 {object} utils.Response{data=[]models.CrewAssignment} "Daftar tugas"
+// @Failure 404 {object} utils.Response "Staf tidak ditemukan"
+// @Router /staff/{id}/duties [get]
+// @Security BearerAuth
+func (h *StaffControllers) GetDuties(c *gin.Context) {
+	id, _ := strconv.Atoi(c.Param("id"))
+
+	duties, err := h.staffService.UpcomingDuties(id)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusNotFound, "gagal mendapatkan tugas staf", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "tugas staf berhasil didapatkan", duties)
+}
+
+// GetMyDuties godoc
+// @Summary Tugas mendatang saya
+// @Description Tugas mendatang staf yang terhubung dengan akun saat ini
+// @Tags staff
+// @Produce json
+// @Success 200 {object} utils.Response{data=[]models.CrewAssignment} "Daftar tugas"
+// @Failure 404 {object} utils.Response "Akun tidak terhubung dengan data staf"
+// @Router /crew/my-duties [get]
+// @Security BearerAuth
+func (h *StaffControllers) GetMyDuties(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	duties, err := h.staffService.MyDuties(userID.(int))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusNotFound, "gagal mendapatkan tugas", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "tugas berhasil didapatkan", duties)
+}
@@ -0,0 +1,127 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+	"tiketsepur/dto"
+	"tiketsepur/service"
+	"tiketsepur/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+type StationControllers struct {
+	stationService service.StationService
+}
+
+func NewStationControllers(stationService service.StationService) *StationControllers {
+	return &StationControllers{stationService: stationService}
+}
+
+// Create godoc
+// @Summary Daftarkan stasiun
+// @Description Daftarkan stasiun beserta zona waktu IANA-nya, misalnya Asia/Jakarta (WIB), Asia/Makassar (WITA), atau Asia/Jayapura (WIT). Jadwal hanya bisa dibuat untuk stasiun yang sudah terdaftar
+// @Tags stations
+// @Accept json
+// @Produce json
+// @Param station body dto.CreateStationRequest true "Detail stasiun"
+// @Success 201 {object} utils.Response{data=models.Station} "Stasiun berhasil didaftarkan"
+// @Failure 400 {object} utils.Response "Request tidak valid"
+// @Router /stations [post]
+// @Security BearerAuth
+func (h *StationControllers) Create(c *gin.Context) {
+	var req dto.CreateStationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "request tidak valid", err)
+		return
+	}
+
+	station, err := h.stationService.Create(req)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "stasiun gagal didaftarkan", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusCreated, "stasiun berhasil didaftarkan", station)
+}
+
+// GetAll godoc
+// @Summary Semua stasiun
+// @Description Daftar stasiun beserta zona waktunya
+// @Tags stations
+// @Produce json
+// @Param limit query int false "Jumlah data per halaman (default 20, maksimal 100)"
+// @Param offset query int false "Offset untuk pagination offset"
+// @Param cursor query string false "next_cursor dari response sebelumnya untuk pagination cursor"
+// @Param order query string false "Urutan sort (asc atau desc)"
+// @Param sort query string false "Field sort: name, id"
+// @Param q query string false "Cari nama stasiun"
+// @Success 200 {object} utils.Response{data=[]models.Station,meta=repository.Page} "Daftar stasiun"
+// @Failure 400 {object} utils.Response "Parameter query tidak valid"
+// @Router /public/stations [get]
+func (h *StationControllers) GetAll(c *gin.Context) {
+	var query dto.ListQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "parameter query tidak valid", err)
+		return
+	}
+
+	stations, page, err := h.stationService.GetAll(query)
+	if err != nil {
+		utils.ErrorResponse(c, listErrorStatus(err), "gagal mendapatkan stasiun", err)
+		return
+	}
+
+	utils.PaginatedResponse(c, http.StatusOK, "stasiun berhasil didapatkan", stations, page)
+}
+
+// Update godoc
+// @Summary Ganti zona waktu stasiun
+// @Description Waktu jadwal yang tersimpan tidak berubah; hanya jam lokal yang ditampilkan dan tanggal pencarian yang mengikuti zona waktu baru
+// @Tags stations
+// @Accept json
+// @Produce json
+// @Param id path int true "Station ID"
+// @Param station body dto.UpdateStationRequest true "Zona waktu baru"
+// @Success 200 {object} utils.Response{data=models.Station} "Stasiun berhasil diupdate"
+// @Failure 400 {object} utils.Response "Request tidak valid"
+// @Router /stations/{id} [put]
+// @Security BearerAuth
+func (h *StationControllers) Update(c *gin.Context) {
+	id, _ := strconv.Atoi(c.Param("id"))
+
+	var req dto.UpdateStationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "request tidak valid", err)
+		return
+	}
+
+	station, err := h.stationService.Update(id, req)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "gagal update stasiun", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "stasiun berhasil diupdate", station)
+}
+
+// Delete godoc
+// @Summary Hapus stasiun
+// @Description Stasiun yang masih dipakai jadwal atau template jadwal tidak bisa dihapus
+// @Tags stations
+// @Produce json
+// @Param id path int true "Station ID"
+// @Success 200 {object} utils.Response "Stasiun berhasil dihapus"
+// @Failure 400 {object} utils.Response "Stasiun tidak ditemukan atau masih dipakai"
+// @Router /stations/{id} [delete]
+// @Security BearerAuth
+func (h *StationControllers) Delete(c *gin.Context) {
+	id, _ := strconv.Atoi(c.Param("id"))
+
+	if err := h.stationService.Delete(id); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "gagal menghapus stasiun", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "stasiun berhasil dihapus", nil)
+}
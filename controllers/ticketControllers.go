@@ -4,6 +4,7 @@ import (
 	"net/http"
 	"strconv"
 	"tiketsepur/dto"
+	"tiketsepur/middleware"
 	"tiketsepur/service"
 	"tiketsepur/utils"
 
@@ -55,20 +56,38 @@ func (h *TicketControllers) Create(c *gin.Context) {
 // @Tags tickets
 // @Accept json
 // @Produce json
-// @Success 200 {object} utils.Response{data=[]models.Ticket} "Daftar tiket pengguna"
+// @Param limit query int false "Jumlah data per halaman (default 20, maksimal 100)"
+// @Param offset query int false "Offset untuk pagination offset"
+// @Param cursor query string false "next_cursor dari response sebelumnya untuk pagination cursor"
+// @Param order query string false "Urutan sort (asc atau desc)"
+// @Param sort query string false "Field sort: created_at, departure_time, total_price, id"
+// @Param status query string false "Filter status tiket"
+// @Param schedule_id query int false "Filter schedule ID"
+// @Param train_id query int false "Filter train ID"
+// @Param from query string false "Tanggal pemesanan mulai (YYYY-MM-DD)"
+// @Param to query string false "Tanggal pemesanan sampai (YYYY-MM-DD)"
+// @Param q query string false "Cari kode booking atau nama penumpang"
+// @Success 200 {object} utils.Response{data=[]models.Ticket,meta=repository.Page} "Daftar tiket pengguna"
+// @Failure 400 {object} utils.Response "Parameter query tidak valid"
 // @Failure 500 {object} utils.Response "Internal server error"
 // @Router /tickets/my-tickets [get]
 // @Security BearerAuth
 func (h *TicketControllers) GetMyTickets(c *gin.Context) {
 	userID, _ := c.Get("user_id")
 
-	tickets, err := h.ticketService.GetByUserID(userID.(int))
+	var query dto.ListQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "parameter query tidak valid", err)
+		return
+	}
+
+	tickets, page, err := h.ticketService.GetByUserID(userID.(int), query)
 	if err != nil {
-		utils.ErrorResponse(c, http.StatusInternalServerError, "gagal mendapatkan daftar tiket", err)
+		utils.ErrorResponse(c, listErrorStatus(err), "gagal mendapatkan daftar tiket", err)
 		return
 	}
 
-	utils.SuccessResponse(c, http.StatusOK, "daftar tiket berhasil didapatkan", tickets)
+	utils.PaginatedResponse(c, http.StatusOK, "daftar tiket berhasil didapatkan", tickets, page)
 }
 
 // GetAll godoc
@@ -77,18 +96,37 @@ func (h *TicketControllers) GetMyTickets(c *gin.Context) {
 // @Tags tickets
 // @Accept json
 // @Produce json
-// @Success 200 {object} utils.Response{data=[]models.Ticket} "Daftar semua tiket"
+// @Param limit query int false "Jumlah data per halaman (default 20, maksimal 100)"
+// @Param offset query int false "Offset untuk pagination offset"
+// @Param cursor query string false "next_cursor dari response sebelumnya untuk pagination cursor"
+// @Param order query string false "Urutan sort (asc atau desc)"
+// @Param sort query string false "Field sort: created_at, departure_time, total_price, id"
+// @Param status query string false "Filter status tiket"
+// @Param user_id query int false "Filter user ID"
+// @Param schedule_id query int false "Filter schedule ID"
+// @Param train_id query int false "Filter train ID"
+// @Param from query string false "Tanggal pemesanan mulai (YYYY-MM-DD)"
+// @Param to query string false "Tanggal pemesanan sampai (YYYY-MM-DD)"
+// @Param q query string false "Cari kode booking atau nama penumpang"
+// @Success 200 {object} utils.Response{data=[]models.Ticket,meta=repository.Page} "Daftar semua tiket"
+// @Failure 400 {object} utils.Response "Parameter query tidak valid"
 // @Failure 500 {object} utils.Response "Internal server error"
 // @Router /admin/tickets [get]
 // @Security BearerAuth
 func (h *TicketControllers) GetAll(c *gin.Context) {
-	tickets, err := h.ticketService.GetAll()
+	var query dto.ListQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "parameter query tidak valid", err)
+		return
+	}
+
+	tickets, page, err := h.ticketService.GetAll(query)
 	if err != nil {
-		utils.ErrorResponse(c, http.StatusInternalServerError, "gagal mendapatkan daftar tiket", err)
+		utils.ErrorResponse(c, listErrorStatus(err), "gagal mendapatkan daftar tiket", err)
 		return
 	}
 
-	utils.SuccessResponse(c, http.StatusOK, "daftar tiket berhasil didapatkan", tickets)
+	utils.PaginatedResponse(c, http.StatusOK, "daftar tiket berhasil didapatkan", tickets, page)
 }
 
 // GetByID godoc
@@ -130,10 +168,35 @@ func (h *TicketControllers) Cancel(c *gin.Context) {
 	userID, _ := c.Get("user_id")
 	role, _ := c.Get("user_role")
 
-	if err := h.ticketService.Cancel(c.Request.Context(), id, userID.(int), role.(string)); err != nil {
+	if err := h.ticketService.Cancel(c.Request.Context(), id, userID.(int), role.(string), middleware.APIKeyScopes(c)); err != nil {
 		utils.ErrorResponse(c, http.StatusBadRequest, "gagal membatalkan tiket", err)
 		return
 	}
 
 	utils.SuccessResponse(c, http.StatusOK, "tiket berhasil dibatalkan", nil)
-}
\ No newline at end of file
+}
+
+// GetAlternatives godoc
+// @Summary Jadwal pengganti
+// @Description Jadwal lain dengan rute yang sama yang bisa dipesan ulang untuk tiket yang jadwalnya dibatalkan
+// @Tags tickets
+// @Accept json
+// @Produce json
+// @Param id path int true "Ticket ID"
+// @Success 200 {object} utils.Response{data=[]models.Schedule} "Daftar jadwal pengganti"
+// @Failure 400 {object} utils.Response "Gagal mendapatkan jadwal pengganti"
+// @Router /tickets/{id}/alternatives [get]
+// @Security BearerAuth
+func (h *TicketControllers) GetAlternatives(c *gin.Context) {
+	id, _ := strconv.Atoi(c.Param("id"))
+	userID, _ := c.Get("user_id")
+	role, _ := c.Get("user_role")
+
+	schedules, err := h.ticketService.GetAlternatives(c.Request.Context(), id, userID.(int), role.(string), middleware.APIKeyScopes(c))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "gagal mendapatkan jadwal pengganti", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "jadwal pengganti berhasil didapatkan", schedules)
+}
@@ -0,0 +1,129 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+	"tiketsepur/dto"
+	"tiketsepur/service"
+	"tiketsepur/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+type TrainAvailabilityControllers struct {
+	availabilityService service.TrainAvailabilityService
+}
+
+func NewTrainAvailabilityControllers(availabilityService service.TrainAvailabilityService) *TrainAvailabilityControllers {
+	return &TrainAvailabilityControllers{availabilityService: availabilityService}
+}
+
+// Create godoc
+// @Summary Catat kereta tidak tersedia
+// @Description Catat masa perawatan (maintenance) atau tidak beroperasi (out_of_service). Jadwal baru yang beririsan ditolak; jadwal yang sudah ada dikembalikan di affected_schedules
+// @Tags trains
+// @Accept json
+// @Produce json
+// @Param id path int true "Train ID"
+// @Param unavailability body dto.CreateTrainUnavailabilityRequest true "Rentang ketidaktersediaan"
+// @Success 201 {object} utils.Response{data=dto.TrainUnavailabilityResponse} "Ketidaktersediaan berhasil dicatat"
+// @Failure 400 {object} utils.Response "Request tidak valid"
+// @Router /trains/{id}/unavailability [post]
+// @Security BearerAuth
+func (h *TrainAvailabilityControllers) Create(c *gin.Context) {
+	id, _ := strconv.Atoi(c.Param("id"))
+	userID, _ := c.Get("user_id")
+
+	var req dto.CreateTrainUnavailabilityRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "request tidak valid", err)
+		return
+	}
+
+	response, err := h.availabilityService.Create(id, req, userID.(int))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "gagal mencatat ketidaktersediaan kereta", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusCreated, "ketidaktersediaan kereta berhasil dicatat", response)
+}
+
+// GetByTrain godoc
+// @Summary Kalender ketidaktersediaan kereta
+// @Description Tanpa from, hanya rentang yang belum selesai yang dikembalikan. Tanggal dibaca dalam WIB
+// @Tags trains
+// @Produce json
+// @Param id path int true "Train ID"
+// @Param from query string false "Tanggal mulai (YYYY-MM-DD)"
+// @Param to query string false "Tanggal akhir (YYYY-MM-DD)"
+// @Success 200 {object} utils.Response{data=[]models.TrainUnavailability} "Daftar ketidaktersediaan"
+// @Failure 400 {object} utils.Response "Parameter query tidak valid"
+// @Router /trains/{id}/unavailability [get]
+// @Security BearerAuth
+func (h *TrainAvailabilityControllers) GetByTrain(c *gin.Context) {
+	id, _ := strconv.Atoi(c.Param("id"))
+
+	var query dto.TrainAvailabilityQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "parameter query tidak valid", err)
+		return
+	}
+
+	unavailabilities, err := h.availabilityService.GetByTrain(id, query)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "gagal mendapatkan ketidaktersediaan kereta", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "ketidaktersediaan kereta berhasil didapatkan", unavailabilities)
+}
+
+// Delete godoc
+// @Summary Hapus ketidaktersediaan kereta
+// @Tags trains
+// @Produce json
+// @Param id path int true "Train ID"
+// @Param unavailabilityId path int true "Unavailability ID"
+// @Success 200 {object} utils.Response "Ketidaktersediaan berhasil dihapus"
+// @Failure 400 {object} utils.Response "Data tidak ditemukan"
+// @Router /trains/{id}/unavailability/{unavailabilityId} [delete]
+// @Security BearerAuth
+func (h *TrainAvailabilityControllers) Delete(c *gin.Context) {
+	id, _ := strconv.Atoi(c.Param("id"))
+	unavailabilityID, _ := strconv.Atoi(c.Param("unavailabilityId"))
+
+	if err := h.availabilityService.Delete(id, unavailabilityID); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "gagal menghapus ketidaktersediaan kereta", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "ketidaktersediaan kereta berhasil dihapus", nil)
+}
+
+// Utilization godoc
+// @Summary Utilisasi kereta
+// @Description Jumlah jadwal, menit terjadwal, menit tidak tersedia, dan persentase utilisasi setiap kereta dari from sampai to (inklusif, WIB, maksimal 366 hari)
+// @Tags trains
+// @Produce json
+// @Param from query string true "Tanggal mulai (YYYY-MM-DD)"
+// @Param to query string true "Tanggal akhir (YYYY-MM-DD)"
+// @Success 200 {object} utils.Response{data=dto.TrainUtilizationResponse} "Utilisasi kereta"
+// @Failure 400 {object} utils.Response "Parameter query tidak valid"
+// @Router /trains/utilization [get]
+// @Security BearerAuth
+func (h *TrainAvailabilityControllers) Utilization(c *gin.Context) {
+	var req dto.TrainUtilizationRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "parameter query tidak valid", err)
+		return
+	}
+
+	utilization, err := h.availabilityService.Utilization(req)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "gagal menghitung utilisasi kereta", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "utilisasi kereta berhasil dihitung", utilization)
+}
@@ -53,17 +53,33 @@ func (h *TrainControllers) Create(c *gin.Context) {
 // @Tags trains
 // @Accept json
 // @Produce json
-// @Success 200 {object} utils.Response{data=[]models.Train} "Daftar semua kereta"
+// @Param limit query int false "Jumlah data per halaman (default 20, maksimal 100)"
+// @Param offset query int false "Offset untuk pagination offset"
+// @Param cursor query string false "next_cursor dari response sebelumnya untuk pagination cursor"
+// @Param order query string false "Urutan sort (asc atau desc)"
+// @Param sort query string false "Field sort: created_at, train_code, train_name, total_seats, id"
+// @Param train_type query string false "Filter tipe kereta"
+// @Param from query string false "Tanggal dibuat mulai (YYYY-MM-DD)"
+// @Param to query string false "Tanggal dibuat sampai (YYYY-MM-DD)"
+// @Param q query string false "Cari kode atau nama kereta"
+// @Success 200 {object} utils.Response{data=[]models.Train,meta=repository.Page} "Daftar semua kereta"
+// @Failure 400 {object} utils.Response "Parameter query tidak valid"
 // @Failure 500 {object} utils.Response "Internal server error"
 // @Router /trains [get]
 func (h *TrainControllers) GetAll(c *gin.Context) {
-	trains, err := h.trainService.GetAll()
+	var query dto.ListQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "parameter query tidak valid", err)
+		return
+	}
+
+	trains, page, err := h.trainService.GetAll(query)
 	if err != nil {
-		utils.ErrorResponse(c, http.StatusInternalServerError, "gagal mendapatkan kereta", err)
+		utils.ErrorResponse(c, listErrorStatus(err), "gagal mendapatkan kereta", err)
 		return
 	}
 
-	utils.SuccessResponse(c, http.StatusOK, "kereta berhasil didapatkan", trains)
+	utils.PaginatedResponse(c, http.StatusOK, "kereta berhasil didapatkan", trains, page)
 }
 
 // GetByID godoc
@@ -127,4 +143,57 @@ func (h *TrainControllers) Delete(c *gin.Context) {
 	}
 
 	utils.SuccessResponse(c, http.StatusOK, "kereta berhasil dihapus", nil)
-}
\ No newline at end of file
+}
+
+// GetComposition godoc
+// @Summary Komposisi rangkaian kereta
+// @Description Daftar kereta dalam rangkaian berurutan dari depan
+// @Tags trains
+// @Produce json
+// @Param id path int true "Train ID"
+// @Success 200 {object} utils.Response{data=[]models.TrainCar} "Komposisi rangkaian"
+// @Failure 404 {object} utils.Response "Kereta tidak ditemukan"
+// @Router /trains/{id}/composition [get]
+// @Security BearerAuth
+func (h *TrainControllers) GetComposition(c *gin.Context) {
+	id, _ := strconv.Atoi(c.Param("id"))
+
+	cars, err := h.trainService.GetComposition(id)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusNotFound, "kereta tidak ditemukan", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "komposisi rangkaian berhasil didapatkan", cars)
+}
+
+// ReplaceComposition godoc
+// @Summary Ganti komposisi rangkaian kereta
+// @Description Ganti seluruh rangkaian; total_seats kereta menjadi jumlah kursi kereta penumpang. Kapasitas tidak bisa dikurangi di bawah kursi yang sudah dialokasikan jadwal yang belum selesai
+// @Tags trains
+// @Accept json
+// @Produce json
+// @Param id path int true "Train ID"
+// @Param composition body dto.ReplaceCompositionRequest true "Rangkaian berurutan dari depan"
+// @Success 200 {object} utils.Response{data=[]models.TrainCar} "Komposisi berhasil disimpan"
+// @Failure 400 {object} utils.Response "Request tidak valid"
+// @Failure 422 {object} utils.Response "Komposisi tidak valid atau kapasitas terlalu kecil"
+// @Router /trains/{id}/composition [put]
+// @Security BearerAuth
+func (h *TrainControllers) ReplaceComposition(c *gin.Context) {
+	id, _ := strconv.Atoi(c.Param("id"))
+
+	var req dto.ReplaceCompositionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "request tidak valid", err)
+		return
+	}
+
+	cars, err := h.trainService.ReplaceComposition(id, req)
+	if err != nil {
+		utils.ErrorResponse(c, validationErrorStatus(err, http.StatusBadRequest), "gagal menyimpan komposisi rangkaian", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "komposisi rangkaian berhasil disimpan", cars)
+}
@@ -21,13 +21,13 @@ func NewUserControllers(userService service.UserService) *UserControllers {
 }
 
 // Create godoc
-// @Summary User admin
-// @Description Buat akun admin baru
+// @Summary Buat user
+// @Description Buat akun baru dengan role user; role diganti lewat PUT /users/{id}/role
 // @Tags users
 // @Accept json
 // @Produce json
-// @Param user body dto.CreateUserRequest true "Detail admin yang akan dibuat"
-// @Success 201 {object} utils.Response{data=models.User} "User admin berhasil dibuat"
+// @Param user body dto.CreateUserRequest true "Detail user yang akan dibuat"
+// @Success 201 {object} utils.Response{data=models.User} "User berhasil dibuat"
 // @Failure 400 {object} utils.Response "Invalid request"
 // @Router /users [post]
 func (h *UserControllers) Create(c *gin.Context) {
@@ -37,13 +37,13 @@ func (h *UserControllers) Create(c *gin.Context) {
 		return
 	}
 
-	user, err := h.userService.Create(req)
+	user, err := h.userService.Create(req, "user")
 	if err != nil {
 		utils.ErrorResponse(c, http.StatusBadRequest, "gagal membuat user", err)
 		return
 	}
 
-	utils.SuccessResponse(c, http.StatusCreated, "user admin berhasil dibuat", user)
+	utils.SuccessResponse(c, http.StatusCreated, "user berhasil dibuat", user)
 }
 
 // GetAll godoc
@@ -52,18 +52,34 @@ func (h *UserControllers) Create(c *gin.Context) {
 // @Tags users
 // @Accept json
 // @Produce json
-// @Success 200 {object} utils.Response{data=[]models.User} "Daftar user"
+// @Param limit query int false "Jumlah data per halaman (default 20, maksimal 100)"
+// @Param offset query int false "Offset untuk pagination offset"
+// @Param cursor query string false "next_cursor dari response sebelumnya untuk pagination cursor"
+// @Param order query string false "Urutan sort (asc atau desc)"
+// @Param sort query string false "Field sort: created_at, email, full_name, id"
+// @Param role query string false "Filter role"
+// @Param from query string false "Tanggal daftar mulai (YYYY-MM-DD)"
+// @Param to query string false "Tanggal daftar sampai (YYYY-MM-DD)"
+// @Param q query string false "Cari email atau nama"
+// @Success 200 {object} utils.Response{data=[]models.User,meta=repository.Page} "Daftar user"
+// @Failure 400 {object} utils.Response "Parameter query tidak valid"
 // @Failure 500 {object} utils.Response "Internal server error"
 // @Router /admin/users [get]
 // @Security BearerAuth
 func (h *UserControllers) GetAll(c *gin.Context) {
-	users, err := h.userService.GetAll()
+	var query dto.ListQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "parameter query tidak valid", err)
+		return
+	}
+
+	users, page, err := h.userService.GetAll(query)
 	if err != nil {
-		utils.ErrorResponse(c, http.StatusInternalServerError, "gagal mendapatkan user", err)
+		utils.ErrorResponse(c, listErrorStatus(err), "gagal mendapatkan user", err)
 		return
 	}
 
-	utils.SuccessResponse(c, http.StatusOK, "user berhasil didapatkan", users)
+	utils.PaginatedResponse(c, http.StatusOK, "user berhasil didapatkan", users, page)
 }
 
 // GetByID godoc
@@ -0,0 +1,17 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"tiketsepur/utils"
+)
+
+// validationErrorStatus mengembalikan 422 untuk pelanggaran aturan data yang
+// dilaporkan per field dan fallback untuk error lainnya.
+func validationErrorStatus(err error, fallback int) int {
+	var validationErr *utils.ValidationError
+	if errors.As(err, &validationErr) {
+		return http.StatusUnprocessableEntity
+	}
+	return fallback
+}
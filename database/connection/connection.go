@@ -50,13 +50,18 @@ func InitRedis(cfg *config.Config) {
 }
 
 func InitRabbitMQ(cfg *config.Config) {
-	rabbitmq, err := utils.NewRabbitMQ(cfg.RabbitMQ.URL, cfg.RabbitMQ.QueueName)
+	rabbitmq, err := utils.NewRabbitMQ(cfg.RabbitMQ.URL, utils.RabbitMQOptions{
+		QueueName:         cfg.RabbitMQ.QueueName,
+		Exchange:          cfg.RabbitMQ.Exchange,
+		PublishTimeout:    cfg.RabbitMQ.PublishTimeout,
+		ChannelPoolSize:   cfg.RabbitMQ.ChannelPoolSize,
+		ReconnectMaxDelay: cfg.RabbitMQ.ReconnectMaxDelay,
+	})
 	if err != nil {
 		log.Fatal("Failed to initialize RabbitMQ: ", err)
 	}
 	
 	RabbitMQ = rabbitmq
-	rabbitmq.ConsumeNotifications()
 	log.Println("Koneksi ke RabbitMQ sukses")
 }
 
@@ -0,0 +1,59 @@
+-- +migrate Up
+CREATE TABLE roles (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(50) UNIQUE NOT NULL,
+    description VARCHAR(255),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    modified_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE permissions (
+    id SERIAL PRIMARY KEY,
+    code VARCHAR(100) UNIQUE NOT NULL,
+    description VARCHAR(255),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE role_permissions (
+    role_id INT NOT NULL,
+    permission_id INT NOT NULL,
+    PRIMARY KEY (role_id, permission_id),
+    CONSTRAINT fk_role_permissions_roles FOREIGN KEY (role_id) REFERENCES roles(id) ON DELETE CASCADE,
+    CONSTRAINT fk_role_permissions_permissions FOREIGN KEY (permission_id) REFERENCES permissions(id) ON DELETE CASCADE
+);
+
+INSERT INTO roles (name, description) VALUES
+    ('admin', 'Administrator dengan akses penuh'),
+    ('user', 'Pelanggan'),
+    ('schedule_manager', 'Pengelola kereta dan jadwal'),
+    ('finance', 'Tim keuangan'),
+    ('customer_support', 'Layanan pelanggan'),
+    ('station_staff', 'Petugas stasiun');
+
+INSERT INTO permissions (code, description) VALUES
+    ('users:manage', 'Kelola data user'),
+    ('trains:manage', 'Kelola data kereta'),
+    ('schedules:manage', 'Kelola jadwal kereta'),
+    ('tickets:read', 'Lihat semua tiket'),
+    ('tickets:refund', 'Batalkan dan refund tiket milik user lain'),
+    ('roles:manage', 'Kelola role dan permission');
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id FROM roles r CROSS JOIN permissions p WHERE r.name = 'admin';
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id FROM roles r JOIN permissions p ON p.code IN ('schedules:manage', 'trains:manage')
+WHERE r.name = 'schedule_manager';
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id FROM roles r JOIN permissions p ON p.code IN ('tickets:read', 'tickets:refund')
+WHERE r.name IN ('finance', 'customer_support');
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id FROM roles r JOIN permissions p ON p.code = 'tickets:read'
+WHERE r.name = 'station_staff';
+
+-- +migrate Down
+DROP TABLE IF EXISTS role_permissions;
+DROP TABLE IF EXISTS permissions;
+DROP TABLE IF EXISTS roles;
@@ -0,0 +1,29 @@
+-- +migrate Up
+CREATE TABLE api_keys (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    key_prefix VARCHAR(16) UNIQUE NOT NULL,
+    key_hash VARCHAR(64) NOT NULL,
+    user_id INT NOT NULL,
+    permissions TEXT[] NOT NULL DEFAULT '{}',
+    rate_limit INT NOT NULL DEFAULT 60,
+    expires_at TIMESTAMP,
+    last_used_at TIMESTAMP,
+    revoked_at TIMESTAMP,
+    created_by INT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    modified_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT fk_api_keys_users FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    CONSTRAINT fk_api_keys_creators FOREIGN KEY (created_by) REFERENCES users(id) ON DELETE SET NULL
+);
+
+INSERT INTO permissions (code, description) VALUES
+    ('api_keys:manage', 'Kelola API key partner dan mesin');
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id FROM roles r JOIN permissions p ON p.code = 'api_keys:manage'
+WHERE r.name = 'admin';
+
+-- +migrate Down
+DELETE FROM permissions WHERE code = 'api_keys:manage';
+DROP TABLE IF EXISTS api_keys;
@@ -0,0 +1,12 @@
+-- +migrate Up
+CREATE TABLE jwt_signing_keys (
+    kid VARCHAR(64) PRIMARY KEY,
+    algorithm VARCHAR(20) NOT NULL,
+    private_key TEXT NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    retired_at TIMESTAMP,
+    expires_at TIMESTAMP
+);
+
+-- +migrate Down
+DROP TABLE IF EXISTS jwt_signing_keys;
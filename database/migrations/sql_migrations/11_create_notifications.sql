@@ -0,0 +1,32 @@
+-- +migrate Up
+CREATE TABLE notification_preferences (
+    id SERIAL PRIMARY KEY,
+    user_id INT NOT NULL,
+    notification_type VARCHAR(50) NOT NULL,
+    channels TEXT[] NOT NULL DEFAULT '{}',
+    webhook_url TEXT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    modified_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT unique_user_notification_type UNIQUE (user_id, notification_type),
+    CONSTRAINT fk_notification_preferences_users FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE TABLE notification_deliveries (
+    id SERIAL PRIMARY KEY,
+    user_id INT,
+    notification_type VARCHAR(50) NOT NULL,
+    channel VARCHAR(20) NOT NULL,
+    recipient TEXT NOT NULL,
+    booking_code VARCHAR(50),
+    status VARCHAR(20) NOT NULL,
+    error TEXT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    sent_at TIMESTAMP,
+    CONSTRAINT fk_notification_deliveries_users FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE SET NULL
+);
+
+CREATE INDEX idx_notification_deliveries_user ON notification_deliveries (user_id, created_at DESC);
+
+-- +migrate Down
+DROP TABLE IF EXISTS notification_deliveries;
+DROP TABLE IF EXISTS notification_preferences;
@@ -0,0 +1,33 @@
+-- +migrate Up
+ALTER TABLE users ADD COLUMN language VARCHAR(5) NOT NULL DEFAULT 'id';
+
+CREATE TABLE notification_templates (
+    id SERIAL PRIMARY KEY,
+    notification_type VARCHAR(50) NOT NULL,
+    language VARCHAR(5) NOT NULL,
+    version INT NOT NULL,
+    subject TEXT NOT NULL,
+    body_text TEXT NOT NULL,
+    body_html TEXT NOT NULL DEFAULT '',
+    body_short TEXT NOT NULL DEFAULT '',
+    is_active BOOLEAN NOT NULL DEFAULT FALSE,
+    created_by INT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT unique_notification_template_version UNIQUE (notification_type, language, version),
+    CONSTRAINT fk_notification_templates_users FOREIGN KEY (created_by) REFERENCES users(id) ON DELETE SET NULL
+);
+
+CREATE UNIQUE INDEX idx_notification_templates_active
+    ON notification_templates (notification_type, language) WHERE is_active;
+
+INSERT INTO permissions (code, description) VALUES
+    ('notifications:manage', 'Kelola template notifikasi');
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id FROM roles r JOIN permissions p ON p.code = 'notifications:manage'
+WHERE r.name = 'admin';
+
+-- +migrate Down
+DELETE FROM permissions WHERE code = 'notifications:manage';
+DROP TABLE IF EXISTS notification_templates;
+ALTER TABLE users DROP COLUMN IF EXISTS language;
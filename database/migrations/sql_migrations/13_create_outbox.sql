@@ -0,0 +1,21 @@
+-- +migrate Up
+CREATE TABLE outbox (
+    id BIGSERIAL PRIMARY KEY,
+    aggregate_type VARCHAR(50) NOT NULL,
+    aggregate_id VARCHAR(100) NOT NULL,
+    event_type VARCHAR(50) NOT NULL,
+    payload JSONB NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending',
+    attempts INT NOT NULL DEFAULT 0,
+    last_error TEXT,
+    -- TIMESTAMPTZ karena waktu retry dan lease diisi dari aplikasi lalu
+    -- dibandingkan dengan NOW() di database
+    next_attempt_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+    sent_at TIMESTAMPTZ
+);
+
+CREATE INDEX idx_outbox_pending ON outbox (next_attempt_at) WHERE status = 'pending';
+
+-- +migrate Down
+DROP TABLE IF EXISTS outbox;
@@ -0,0 +1,8 @@
+-- +migrate Up
+ALTER TABLE notification_deliveries ADD COLUMN message_id VARCHAR(100);
+
+CREATE INDEX idx_notification_deliveries_message ON notification_deliveries (message_id, channel) WHERE status = 'sent';
+
+-- +migrate Down
+DROP INDEX IF EXISTS idx_notification_deliveries_message;
+ALTER TABLE notification_deliveries DROP COLUMN IF EXISTS message_id;
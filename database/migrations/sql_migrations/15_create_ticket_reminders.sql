@@ -0,0 +1,16 @@
+-- +migrate Up
+ALTER TABLE schedules ADD COLUMN platform VARCHAR(10);
+ALTER TABLE tickets ADD COLUMN coach VARCHAR(10);
+
+CREATE TABLE ticket_reminders (
+    id SERIAL PRIMARY KEY,
+    ticket_id INT NOT NULL REFERENCES tickets(id) ON DELETE CASCADE,
+    offset_minutes INT NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    UNIQUE (ticket_id, offset_minutes)
+);
+
+-- +migrate Down
+DROP TABLE IF EXISTS ticket_reminders;
+ALTER TABLE tickets DROP COLUMN IF EXISTS coach;
+ALTER TABLE schedules DROP COLUMN IF EXISTS platform;
@@ -0,0 +1,25 @@
+-- +migrate Up
+ALTER TABLE schedules ADD COLUMN status VARCHAR(20) NOT NULL DEFAULT 'scheduled';
+ALTER TABLE schedules ADD COLUMN delay_minutes INT NOT NULL DEFAULT 0;
+ALTER TABLE payments ADD COLUMN refunded_at TIMESTAMP;
+
+CREATE TABLE schedule_disruptions (
+    id SERIAL PRIMARY KEY,
+    schedule_id INT NOT NULL REFERENCES schedules(id) ON DELETE CASCADE,
+    disruption_type VARCHAR(20) NOT NULL,
+    delay_minutes INT,
+    old_platform VARCHAR(10),
+    new_platform VARCHAR(10),
+    reason TEXT,
+    affected_tickets INT NOT NULL DEFAULT 0,
+    created_by INT REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_schedule_disruptions_schedule ON schedule_disruptions (schedule_id, created_at DESC);
+
+-- +migrate Down
+DROP TABLE IF EXISTS schedule_disruptions;
+ALTER TABLE payments DROP COLUMN IF EXISTS refunded_at;
+ALTER TABLE schedules DROP COLUMN IF EXISTS delay_minutes;
+ALTER TABLE schedules DROP COLUMN IF EXISTS status;
@@ -0,0 +1,17 @@
+-- +migrate Up
+CREATE INDEX idx_tickets_created ON tickets (created_at DESC, id DESC);
+CREATE INDEX idx_tickets_user_created ON tickets (user_id, created_at DESC, id DESC);
+CREATE INDEX idx_tickets_status_created ON tickets (status, created_at DESC, id DESC);
+CREATE INDEX idx_schedules_departure ON schedules (departure_time, id);
+CREATE INDEX idx_schedules_train_departure ON schedules (train_id, departure_time);
+CREATE INDEX idx_users_created ON users (created_at DESC, id DESC);
+CREATE INDEX idx_trains_created ON trains (created_at DESC, id DESC);
+
+-- +migrate Down
+DROP INDEX IF EXISTS idx_trains_created;
+DROP INDEX IF EXISTS idx_users_created;
+DROP INDEX IF EXISTS idx_schedules_train_departure;
+DROP INDEX IF EXISTS idx_schedules_departure;
+DROP INDEX IF EXISTS idx_tickets_status_created;
+DROP INDEX IF EXISTS idx_tickets_user_created;
+DROP INDEX IF EXISTS idx_tickets_created;
@@ -0,0 +1,26 @@
+-- +migrate Up
+-- pencarian stasiun memakai ILIKE '%...%' sehingga butuh index trigram.
+-- Extension pg_trgm tidak dibuat di sini karena butuh hak superuser; buat
+-- lebih dulu oleh DBA (lihat README). Tanpa extension, index trigram dilewati
+-- dan pencarian tetap jalan dengan sequential scan.
+-- +migrate StatementBegin
+DO $$
+BEGIN
+    IF EXISTS (SELECT 1 FROM pg_extension WHERE extname = 'pg_trgm') THEN
+        CREATE INDEX IF NOT EXISTS idx_schedules_departure_station_trgm ON schedules USING gin (departure_station gin_trgm_ops);
+        CREATE INDEX IF NOT EXISTS idx_schedules_arrival_station_trgm ON schedules USING gin (arrival_station gin_trgm_ops);
+    ELSE
+        RAISE NOTICE 'extension pg_trgm belum terpasang, index trigram pencarian stasiun dilewati';
+    END IF;
+END
+$$;
+-- +migrate StatementEnd
+
+CREATE INDEX idx_schedules_searchable ON schedules (departure_time, price) WHERE status != 'cancelled' AND available_seats > 0;
+CREATE INDEX idx_trains_type ON trains (train_type);
+
+-- +migrate Down
+DROP INDEX IF EXISTS idx_trains_type;
+DROP INDEX IF EXISTS idx_schedules_searchable;
+DROP INDEX IF EXISTS idx_schedules_arrival_station_trgm;
+DROP INDEX IF EXISTS idx_schedules_departure_station_trgm;
@@ -0,0 +1,6 @@
+-- +migrate Up
+-- kalender tarif mencocokkan rute tanpa memperhatikan huruf besar/kecil per bulan
+CREATE INDEX idx_schedules_route_lower ON schedules (LOWER(departure_station), LOWER(arrival_station), departure_time) WHERE status != 'cancelled';
+
+-- +migrate Down
+DROP INDEX IF EXISTS idx_schedules_route_lower;
@@ -0,0 +1,32 @@
+-- +migrate Up
+CREATE TABLE schedule_templates (
+    id SERIAL PRIMARY KEY,
+    train_id INT NOT NULL REFERENCES trains(id) ON DELETE CASCADE,
+    departure_station VARCHAR(255) NOT NULL,
+    arrival_station VARCHAR(255) NOT NULL,
+    departure_time TIME NOT NULL,
+    arrival_time TIME NOT NULL,
+    -- jumlah hari setelah keberangkatan saat kereta tiba, untuk perjalanan malam
+    arrival_day_offset INT NOT NULL DEFAULT 0,
+    -- hari ISO: 1 = Senin ... 7 = Minggu
+    days_of_week SMALLINT[] NOT NULL,
+    valid_from DATE NOT NULL,
+    valid_to DATE NOT NULL,
+    price DECIMAL(13,2) NOT NULL,
+    platform VARCHAR(10),
+    is_active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_by INT REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    modified_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    CHECK (valid_to >= valid_from)
+);
+
+CREATE INDEX idx_schedule_templates_train ON schedule_templates (train_id);
+
+ALTER TABLE schedules ADD COLUMN template_id INT REFERENCES schedule_templates(id) ON DELETE SET NULL;
+CREATE UNIQUE INDEX idx_schedules_template_departure ON schedules (template_id, departure_time) WHERE template_id IS NOT NULL;
+
+-- +migrate Down
+DROP INDEX IF EXISTS idx_schedules_template_departure;
+ALTER TABLE schedules DROP COLUMN IF EXISTS template_id;
+DROP TABLE IF EXISTS schedule_templates;
@@ -0,0 +1,47 @@
+-- +migrate Up
+CREATE TABLE stations (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    -- nama zona waktu IANA, misalnya Asia/Jakarta (WIB), Asia/Makassar (WITA), Asia/Jayapura (WIT)
+    timezone VARCHAR(64) NOT NULL DEFAULT 'Asia/Jakarta',
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    modified_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+-- jadwal mereferensikan stasiun lewat nama tanpa memperhatikan huruf besar/kecil
+CREATE UNIQUE INDEX idx_stations_name_lower ON stations (LOWER(name));
+
+INSERT INTO stations (name)
+SELECT DISTINCT ON (LOWER(name)) name FROM (
+    SELECT departure_station AS name FROM schedules
+    UNION SELECT arrival_station FROM schedules
+    UNION SELECT departure_station FROM schedule_templates
+    UNION SELECT arrival_station FROM schedule_templates
+) existing
+ORDER BY LOWER(name), name;
+
+-- jam lokal asli jadwal lama disimpan karena semua stasiun di atas masih
+-- berzona Asia/Jakarta; saat zona waktu stasiun diatur ke WITA atau WIT,
+-- waktu jadwal ini dibaca ulang di zona waktu yang benar. Barisnya dihapus
+-- begitu waktu atau stasiun jadwal diubah lewat API.
+CREATE TABLE schedule_legacy_times (
+    schedule_id INT PRIMARY KEY REFERENCES schedules(id) ON DELETE CASCADE,
+    departure_local TIMESTAMP NOT NULL,
+    arrival_local TIMESTAMP NOT NULL
+);
+
+INSERT INTO schedule_legacy_times (schedule_id, departure_local, arrival_local)
+SELECT id, departure_time, arrival_time FROM schedules;
+
+-- data lama disimpan tanpa zona waktu dan selama ini dianggap WIB
+ALTER TABLE schedules
+    ALTER COLUMN departure_time TYPE TIMESTAMPTZ USING departure_time AT TIME ZONE 'Asia/Jakarta',
+    ALTER COLUMN arrival_time TYPE TIMESTAMPTZ USING arrival_time AT TIME ZONE 'Asia/Jakarta';
+
+-- +migrate Down
+ALTER TABLE schedules
+    ALTER COLUMN departure_time TYPE TIMESTAMP USING departure_time AT TIME ZONE 'Asia/Jakarta',
+    ALTER COLUMN arrival_time TYPE TIMESTAMP USING arrival_time AT TIME ZONE 'Asia/Jakarta';
+
+DROP TABLE IF EXISTS schedule_legacy_times;
+DROP TABLE IF EXISTS stations;
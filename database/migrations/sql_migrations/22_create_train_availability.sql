@@ -0,0 +1,32 @@
+-- +migrate Up
+-- susunan rangkaian kereta; kapasitas kereta adalah jumlah kursi kereta penumpang
+CREATE TABLE train_cars (
+    id SERIAL PRIMARY KEY,
+    train_id INT NOT NULL REFERENCES trains(id) ON DELETE CASCADE,
+    position INT NOT NULL CHECK (position >= 1),
+    car_code VARCHAR(10) NOT NULL,
+    car_type VARCHAR(20) NOT NULL CHECK (car_type IN ('passenger', 'dining', 'power', 'baggage')),
+    car_class VARCHAR(50),
+    seats INT NOT NULL DEFAULT 0 CHECK (seats >= 0),
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    UNIQUE (train_id, position),
+    UNIQUE (train_id, car_code)
+);
+
+CREATE TABLE train_unavailabilities (
+    id SERIAL PRIMARY KEY,
+    train_id INT NOT NULL REFERENCES trains(id) ON DELETE CASCADE,
+    reason VARCHAR(20) NOT NULL CHECK (reason IN ('maintenance', 'out_of_service')),
+    starts_at TIMESTAMPTZ NOT NULL,
+    ends_at TIMESTAMPTZ NOT NULL,
+    notes TEXT,
+    created_by INT REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    CHECK (ends_at > starts_at)
+);
+
+CREATE INDEX idx_train_unavailabilities_train ON train_unavailabilities (train_id, starts_at, ends_at);
+
+-- +migrate Down
+DROP TABLE IF EXISTS train_unavailabilities;
+DROP TABLE IF EXISTS train_cars;
@@ -0,0 +1,71 @@
+-- +migrate Up
+-- staf operasional; user_id menghubungkan staf dengan akun login untuk scan boarding
+CREATE TABLE staff (
+    id SERIAL PRIMARY KEY,
+    user_id INT UNIQUE REFERENCES users(id) ON DELETE SET NULL,
+    employee_number VARCHAR(20) UNIQUE NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    phone VARCHAR(20),
+    is_active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    modified_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+-- valid_until kosong berarti sertifikasi tidak kedaluwarsa
+CREATE TABLE staff_qualifications (
+    staff_id INT NOT NULL REFERENCES staff(id) ON DELETE CASCADE,
+    qualification VARCHAR(20) NOT NULL CHECK (qualification IN ('driver', 'conductor')),
+    valid_until DATE,
+    PRIMARY KEY (staff_id, qualification)
+);
+
+CREATE TABLE crew_assignments (
+    id SERIAL PRIMARY KEY,
+    schedule_id INT NOT NULL REFERENCES schedules(id) ON DELETE CASCADE,
+    staff_id INT NOT NULL REFERENCES staff(id),
+    duty VARCHAR(20) NOT NULL CHECK (duty IN ('driver', 'conductor')),
+    created_by INT REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    UNIQUE (schedule_id, staff_id)
+);
+
+-- satu jadwal hanya punya satu masinis
+CREATE UNIQUE INDEX idx_crew_assignments_driver ON crew_assignments (schedule_id) WHERE duty = 'driver';
+CREATE INDEX idx_crew_assignments_staff ON crew_assignments (staff_id);
+
+CREATE TABLE boarding_scans (
+    id SERIAL PRIMARY KEY,
+    ticket_id INT UNIQUE NOT NULL REFERENCES tickets(id) ON DELETE CASCADE,
+    schedule_id INT NOT NULL REFERENCES schedules(id) ON DELETE CASCADE,
+    staff_id INT REFERENCES staff(id) ON DELETE SET NULL,
+    scanned_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_boarding_scans_schedule ON boarding_scans (schedule_id);
+
+INSERT INTO roles (name, description) VALUES
+    ('conductor', 'Kondektur');
+
+INSERT INTO permissions (code, description) VALUES
+    ('crew:manage', 'Kelola staf dan penugasan kru'),
+    ('tickets:board', 'Scan boarding tiket');
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id FROM roles r JOIN permissions p ON p.code IN ('crew:manage', 'tickets:board')
+WHERE r.name = 'admin';
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id FROM roles r JOIN permissions p ON p.code = 'crew:manage'
+WHERE r.name = 'schedule_manager';
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id FROM roles r JOIN permissions p ON p.code = 'tickets:board'
+WHERE r.name = 'conductor';
+
+-- +migrate Down
+DELETE FROM permissions WHERE code IN ('crew:manage', 'tickets:board');
+DELETE FROM roles WHERE name = 'conductor';
+DROP TABLE IF EXISTS boarding_scans;
+DROP TABLE IF EXISTS crew_assignments;
+DROP TABLE IF EXISTS staff_qualifications;
+DROP TABLE IF EXISTS staff;
@@ -0,0 +1,14 @@
+-- +migrate Up
+-- users.role mengikuti panjang roles.name dan hanya boleh berisi role yang terdaftar
+ALTER TABLE users ALTER COLUMN role TYPE VARCHAR(50);
+
+INSERT INTO roles (name, description)
+SELECT DISTINCT role, 'Role dari data user lama' FROM users
+WHERE role NOT IN (SELECT name FROM roles);
+
+ALTER TABLE users
+    ADD CONSTRAINT fk_users_roles FOREIGN KEY (role) REFERENCES roles(name);
+
+-- +migrate Down
+ALTER TABLE users DROP CONSTRAINT IF EXISTS fk_users_roles;
+ALTER TABLE users ALTER COLUMN role TYPE VARCHAR(20);
@@ -0,0 +1,16 @@
+package dto
+
+import "time"
+
+type CreateAPIKeyRequest struct {
+	Name        string     `json:"name" binding:"required,max=100"`
+	UserID      int        `json:"user_id"`
+	Permissions []string   `json:"permissions"`
+	RateLimit   int        `json:"rate_limit" binding:"omitempty,min=1"`
+	ExpiresAt   *time.Time `json:"expires_at"`
+}
+
+type CreateAPIKeyResponse struct {
+	Key    string      `json:"key"`
+	APIKey interface{} `json:"api_key"`
+}
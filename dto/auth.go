@@ -5,6 +5,7 @@ type RegisterRequest struct {
 	Password string `json:"password" binding:"required,min=6"`
 	FullName string `json:"full_name" binding:"required"`
 	Phone    string `json:"phone" binding:"required"`
+	Language string `json:"language" binding:"omitempty,oneof=id en"`
 }
 
 type LoginRequest struct {
@@ -16,3 +17,8 @@ type LoginResponse struct {
 	Token string      `json:"token"`
 	User  interface{} `json:"user"`
 }
+
+type UnlockLoginRequest struct {
+	Email string `json:"email" binding:"omitempty,email"`
+	IP    string `json:"ip" binding:"omitempty,ip"`
+}
@@ -0,0 +1,61 @@
+package dto
+
+import "tiketsepur/models"
+
+// StaffQualificationRequest adalah satu sertifikasi staf. ValidUntil
+// (YYYY-MM-DD) dikosongkan untuk sertifikasi yang tidak kedaluwarsa.
+type StaffQualificationRequest struct {
+	Qualification string  `json:"qualification" binding:"required,oneof=driver conductor"`
+	ValidUntil    *string `json:"valid_until" binding:"omitempty,datetime=2006-01-02"`
+}
+
+// CreateStaffRequest menghubungkan staf dengan akun login lewat user_id.
+// Kondektur perlu akun supaya bisa melakukan scan boarding.
+type CreateStaffRequest struct {
+	UserID         *int                        `json:"user_id"`
+	EmployeeNumber string                      `json:"employee_number" binding:"required,max=20"`
+	Name           string                      `json:"name" binding:"required"`
+	Phone          *string                     `json:"phone" binding:"omitempty,max=20"`
+	Qualifications []StaffQualificationRequest `json:"qualifications" binding:"dive"`
+}
+
+type UpdateStaffRequest struct {
+	UserID   *int    `json:"user_id"`
+	Name     *string `json:"name"`
+	Phone    *string `json:"phone" binding:"omitempty,max=20"`
+	IsActive *bool   `json:"is_active"`
+}
+
+// ReplaceQualificationsRequest mengganti seluruh kualifikasi staf; array
+// kosong menghapus semuanya.
+type ReplaceQualificationsRequest struct {
+	Qualifications []StaffQualificationRequest `json:"qualifications" binding:"dive"`
+}
+
+type StaffQuery struct {
+	ListQuery
+	Qualification string `form:"qualification" binding:"omitempty,oneof=driver conductor"`
+}
+
+type CreateCrewAssignmentRequest struct {
+	StaffID int    `json:"staff_id" binding:"required"`
+	Duty    string `json:"duty" binding:"required,oneof=driver conductor"`
+}
+
+type BoardingScanRequest struct {
+	BookingCode string `json:"booking_code" binding:"required"`
+}
+
+// BoardingScanResponse berisi data penumpang yang perlu dicocokkan kondektur
+// dengan identitas penumpang.
+type BoardingScanResponse struct {
+	Scan              models.BoardingScan `json:"scan"`
+	BookingCode       string              `json:"booking_code"`
+	PassengerName     string              `json:"passenger_name"`
+	PassengerIDNumber string              `json:"passenger_id_number"`
+	Coach             *string             `json:"coach"`
+	SeatNumber        string              `json:"seat_number"`
+	TrainName         string              `json:"train_name"`
+	DepartureStation  string              `json:"departure_station"`
+	ArrivalStation    string              `json:"arrival_station"`
+}
@@ -0,0 +1,23 @@
+package dto
+
+import "time"
+
+// ListQuery adalah query string yang diterima semua endpoint list. Pagination
+// offset memakai limit dan offset; pagination cursor memakai next_cursor dari
+// meta response sebelumnya. Filter yang tidak relevan untuk endpoint diabaikan.
+type ListQuery struct {
+	Limit      int        `form:"limit" binding:"omitempty,min=1,max=100"`
+	Offset     int        `form:"offset" binding:"omitempty,min=0"`
+	Cursor     string     `form:"cursor"`
+	Sort       string     `form:"sort"`
+	Order      string     `form:"order" binding:"omitempty,oneof=asc desc"`
+	Status     string     `form:"status"`
+	Role       string     `form:"role"`
+	TrainType  string     `form:"train_type"`
+	TrainID    int        `form:"train_id" binding:"omitempty,min=1"`
+	UserID     int        `form:"user_id" binding:"omitempty,min=1"`
+	ScheduleID int        `form:"schedule_id" binding:"omitempty,min=1"`
+	From       *time.Time `form:"from" time_format:"2006-01-02"`
+	To         *time.Time `form:"to" time_format:"2006-01-02"`
+	Q          string     `form:"q" binding:"max=100"`
+}
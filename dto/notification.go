@@ -0,0 +1,35 @@
+package dto
+
+import "encoding/json"
+
+type UpdateNotificationPreferenceRequest struct {
+	NotificationType string   `json:"notification_type" binding:"required,oneof=booking payment cancellation lockout expiry reminder disruption"`
+	Channels         []string `json:"channels" binding:"omitempty,dive,oneof=email sms webhook"`
+	WebhookURL       *string  `json:"webhook_url" binding:"omitempty,url"`
+}
+
+type CreateNotificationTemplateRequest struct {
+	NotificationType string `json:"notification_type" binding:"required,oneof=booking payment cancellation lockout expiry reminder disruption"`
+	Language         string `json:"language" binding:"required,oneof=id en"`
+	Subject          string `json:"subject" binding:"required"`
+	BodyText         string `json:"body_text" binding:"required"`
+	BodyHTML         string `json:"body_html"`
+	BodyShort        string `json:"body_short"`
+	Activate         bool   `json:"activate"`
+}
+
+type PreviewNotificationTemplateRequest struct {
+	NotificationType string          `json:"notification_type" binding:"required,oneof=booking payment cancellation lockout expiry reminder disruption"`
+	Language         string          `json:"language" binding:"required,oneof=id en"`
+	TemplateID       *int            `json:"template_id"`
+	Subject          *string         `json:"subject"`
+	BodyText         *string         `json:"body_text"`
+	BodyHTML         *string         `json:"body_html"`
+	BodyShort        *string         `json:"body_short"`
+	Data             json.RawMessage `json:"data" swaggertype:"object"`
+}
+
+type ReplayDeadLettersRequest struct {
+	MessageIDs []string `json:"message_ids"`
+	Limit      int      `json:"limit" binding:"omitempty,min=1,max=500"`
+}
@@ -0,0 +1,15 @@
+package dto
+
+type CreateRoleRequest struct {
+	Name        string   `json:"name" binding:"required,max=50"`
+	Description string   `json:"description"`
+	Permissions []string `json:"permissions"`
+}
+
+type SetRolePermissionsRequest struct {
+	Permissions []string `json:"permissions" binding:"required"`
+}
+
+type AssignRoleRequest struct {
+	Role string `json:"role" binding:"required,max=50"`
+}
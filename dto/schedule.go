@@ -1,6 +1,9 @@
 package dto
 
-import "time"
+import (
+	"tiketsepur/models"
+	"time"
+)
 
 type CreateScheduleRequest struct {
 	TrainID          int       `json:"train_id" binding:"required"`
@@ -10,6 +13,7 @@ type CreateScheduleRequest struct {
 	ArrivalTime      time.Time `json:"arrival_time" binding:"required"`
 	Price            float64   `json:"price" binding:"required,min=0"`
 	AvailableSeats   int       `json:"available_seats" binding:"required,min=0"`
+	Platform         *string   `json:"platform" binding:"omitempty,max=10"`
 }
 
 type UpdateScheduleRequest struct {
@@ -20,10 +24,92 @@ type UpdateScheduleRequest struct {
 	ArrivalTime      *time.Time `json:"arrival_time"`
 	Price            *float64   `json:"price" binding:"omitempty,min=0"`
 	AvailableSeats   *int       `json:"available_seats" binding:"omitempty,min=0"`
+	Platform         *string    `json:"platform" binding:"omitempty,max=10"`
 }
 
 type SearchScheduleRequest struct {
-	DepartureStation string `form:"departure_station"`
-	ArrivalStation   string `form:"arrival_station"`
-	Date             string `form:"date"`
-}
\ No newline at end of file
+	DepartureStation string   `form:"departure_station"`
+	ArrivalStation   string   `form:"arrival_station"`
+	Date             string   `form:"date" binding:"omitempty,datetime=2006-01-02"`
+	DepartureAfter   string   `form:"departure_after" binding:"omitempty,datetime=15:04"`
+	DepartureBefore  string   `form:"departure_before" binding:"omitempty,datetime=15:04"`
+	TrainTypes       []string `form:"train_type"`
+	MaxPrice         *float64 `form:"max_price" binding:"omitempty,min=0"`
+	MinSeats         int      `form:"min_seats" binding:"omitempty,min=1"`
+	Sort             string   `form:"sort" binding:"omitempty,oneof=departure_time duration price"`
+	Order            string   `form:"order" binding:"omitempty,oneof=asc desc"`
+}
+
+type CreateScheduleDisruptionRequest struct {
+	DisruptionType string  `json:"disruption_type" binding:"required,oneof=delay platform_change cancellation"`
+	DelayMinutes   *int    `json:"delay_minutes" binding:"omitempty,min=0,max=1440"`
+	Platform       *string `json:"platform" binding:"omitempty,min=1,max=10"`
+	Reason         string  `json:"reason" binding:"max=500"`
+}
+
+type FareCalendarRequest struct {
+	From  string `form:"from" binding:"required"`
+	To    string `form:"to" binding:"required"`
+	Month string `form:"month" binding:"required,datetime=2006-01"`
+}
+
+type FareCalendarResponse struct {
+	From  string                   `json:"from"`
+	To    string                   `json:"to"`
+	Month string                   `json:"month"`
+	Days  []models.FareCalendarDay `json:"days"`
+}
+
+type CreateScheduleTemplateRequest struct {
+	TrainID          int     `json:"train_id" binding:"required"`
+	DepartureStation string  `json:"departure_station" binding:"required"`
+	ArrivalStation   string  `json:"arrival_station" binding:"required"`
+	DepartureTime    string  `json:"departure_time" binding:"required,datetime=15:04"`
+	ArrivalTime      string  `json:"arrival_time" binding:"required,datetime=15:04"`
+	ArrivalDayOffset int     `json:"arrival_day_offset" binding:"omitempty,min=0,max=3"`
+	DaysOfWeek       []int64 `json:"days_of_week" binding:"required,min=1,max=7,dive,min=1,max=7"`
+	ValidFrom        string  `json:"valid_from" binding:"required,datetime=2006-01-02"`
+	ValidTo          string  `json:"valid_to" binding:"required,datetime=2006-01-02"`
+	Price            float64 `json:"price" binding:"required,min=0"`
+	Platform         *string `json:"platform" binding:"omitempty,max=10"`
+}
+
+type UpdateScheduleTemplateRequest struct {
+	TrainID          *int     `json:"train_id"`
+	DepartureStation *string  `json:"departure_station"`
+	ArrivalStation   *string  `json:"arrival_station"`
+	DepartureTime    *string  `json:"departure_time" binding:"omitempty,datetime=15:04"`
+	ArrivalTime      *string  `json:"arrival_time" binding:"omitempty,datetime=15:04"`
+	ArrivalDayOffset *int     `json:"arrival_day_offset" binding:"omitempty,min=0,max=3"`
+	DaysOfWeek       []int64  `json:"days_of_week" binding:"omitempty,min=1,max=7,dive,min=1,max=7"`
+	ValidFrom        *string  `json:"valid_from" binding:"omitempty,datetime=2006-01-02"`
+	ValidTo          *string  `json:"valid_to" binding:"omitempty,datetime=2006-01-02"`
+	Price            *float64 `json:"price" binding:"omitempty,min=0"`
+	Platform         *string  `json:"platform" binding:"omitempty,max=10"`
+	IsActive         *bool    `json:"is_active"`
+}
+
+type GenerateSchedulesRequest struct {
+	From   string `json:"from" binding:"required,datetime=2006-01-02"`
+	To     string `json:"to" binding:"required,datetime=2006-01-02"`
+	DryRun bool   `json:"dry_run"`
+}
+
+// SkippedScheduleDate adalah tanggal yang tidak dibuat. Reason bernilai
+// "exists" jika jadwalnya sudah ada, "overlap" jika kereta sudah dipakai
+// jadwal lain pada waktu yang beririsan (ScheduleID diisi untuk keduanya jika
+// jadwal itu sudah tersimpan), "unavailable" jika kereta sedang dalam masa
+// perawatan atau tidak beroperasi, atau "past" jika waktu berangkat sudah
+// lewat.
+type SkippedScheduleDate struct {
+	Date       string `json:"date"`
+	Reason     string `json:"reason"`
+	ScheduleID int    `json:"schedule_id,omitempty"`
+}
+
+type GenerateSchedulesResponse struct {
+	TemplateID int                   `json:"template_id"`
+	DryRun     bool                  `json:"dry_run"`
+	Created    []models.Schedule     `json:"created"`
+	Skipped    []SkippedScheduleDate `json:"skipped"`
+}
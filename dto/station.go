@@ -0,0 +1,12 @@
+package dto
+
+type CreateStationRequest struct {
+	Name     string `json:"name" binding:"required"`
+	Timezone string `json:"timezone" binding:"required"`
+}
+
+// UpdateStationRequest hanya mengubah zona waktu; nama stasiun dipakai
+// jadwal sebagai referensi sehingga tidak bisa diganti.
+type UpdateStationRequest struct {
+	Timezone string `json:"timezone" binding:"required"`
+}
@@ -5,6 +5,7 @@ type CreateTicketRequest struct {
 	SeatNumber        string `json:"seat_number" binding:"required"`
 	PassengerName     string `json:"passenger_name" binding:"required"`
 	PassengerIDNumber string `json:"passenger_id_number" binding:"required"`
+	Coach             *string `json:"coach" binding:"omitempty,max=10"`
 	PaymentMethod     string `json:"payment_method" binding:"required,oneof=bank_transfer e-wallet credit_card"`
 }
 
@@ -1,5 +1,10 @@
 package dto
 
+import (
+	"tiketsepur/models"
+	"time"
+)
+
 type CreateTrainRequest struct {
 	TrainCode  string `json:"train_code" binding:"required"`
 	TrainName  string `json:"train_name" binding:"required"`
@@ -12,4 +17,52 @@ type UpdateTrainRequest struct {
 	TrainName  *string `json:"train_name"`
 	TrainType  *string `json:"train_type"`
 	TotalSeats *int    `json:"total_seats" binding:"omitempty,min=1"`
-}
\ No newline at end of file
+}
+
+// TrainCarRequest adalah satu kereta dalam rangkaian. Kereta penumpang wajib
+// punya kursi, kereta lain tidak boleh.
+type TrainCarRequest struct {
+	CarCode  string  `json:"car_code" binding:"required,max=10"`
+	CarType  string  `json:"car_type" binding:"required,oneof=passenger dining power baggage"`
+	CarClass *string `json:"car_class" binding:"omitempty,max=50"`
+	Seats    int     `json:"seats" binding:"min=0"`
+}
+
+// ReplaceCompositionRequest berisi seluruh rangkaian berurutan dari depan;
+// posisi kereta diambil dari urutan di array.
+type ReplaceCompositionRequest struct {
+	Cars []TrainCarRequest `json:"cars" binding:"required,min=1,dive"`
+}
+
+type CreateTrainUnavailabilityRequest struct {
+	Reason   string    `json:"reason" binding:"required,oneof=maintenance out_of_service"`
+	StartsAt time.Time `json:"starts_at" binding:"required"`
+	EndsAt   time.Time `json:"ends_at" binding:"required"`
+	Notes    *string   `json:"notes"`
+}
+
+// TrainUnavailabilityResponse menyertakan jadwal yang sudah terlanjur dibuat
+// di rentang tersebut supaya bisa dipindahkan lewat gangguan jadwal.
+type TrainUnavailabilityResponse struct {
+	Unavailability    models.TrainUnavailability `json:"unavailability"`
+	AffectedSchedules []models.Schedule          `json:"affected_schedules"`
+}
+
+// TrainAvailabilityQuery membatasi daftar ketidaktersediaan kereta; tanpa
+// from dan to semua yang belum selesai dikembalikan.
+type TrainAvailabilityQuery struct {
+	From string `form:"from" binding:"omitempty,datetime=2006-01-02"`
+	To   string `form:"to" binding:"omitempty,datetime=2006-01-02"`
+}
+
+type TrainUtilizationRequest struct {
+	From string `form:"from" binding:"required,datetime=2006-01-02"`
+	To   string `form:"to" binding:"required,datetime=2006-01-02"`
+}
+
+type TrainUtilizationResponse struct {
+	From     string                    `json:"from"`
+	To       string                    `json:"to"`
+	Timezone string                    `json:"timezone"`
+	Trains   []models.TrainUtilization `json:"trains"`
+}
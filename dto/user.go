@@ -5,12 +5,16 @@ type CreateUserRequest struct {
 	Password string `json:"password" binding:"required,min=6"`
 	FullName string `json:"full_name" binding:"required"`
 	Phone    string `json:"phone"`
-	Role     string `json:"role" binding:"required,oneof=admin user"`
+	Language string `json:"language" binding:"omitempty,oneof=id en"`
 }
 
 type UpdateUserRequest struct {
 	Email    *string `json:"email" binding:"omitempty,email"`
 	FullName *string `json:"full_name"`
 	Phone    *string `json:"phone"`
-	Role     *string `json:"role" binding:"omitempty,oneof=admin user"`
+	Language *string `json:"language" binding:"omitempty,oneof=id en"`
+}
+
+type UpdateLanguageRequest struct {
+	Language string `json:"language" binding:"required,oneof=id en"`
 }
@@ -9,8 +9,13 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
-func JWTAuthMiddleware(authService service.AuthService) gin.HandlerFunc {
+func JWTAuthMiddleware(authService service.AuthService, apiKeyService service.APIKeyService) gin.HandlerFunc {
 	return func(c *gin.Context) {
+		if apiKey := c.GetHeader("X-API-Key"); apiKey != "" {
+			authenticateAPIKey(c, apiKeyService, apiKey)
+			return
+		}
+
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" {
 			c.JSON(http.StatusUnauthorized, gin.H{
@@ -41,4 +46,33 @@ func JWTAuthMiddleware(authService service.AuthService) gin.HandlerFunc {
 
 		c.Next()
 	}
-}
\ No newline at end of file
+}
+
+func authenticateAPIKey(c *gin.Context, apiKeyService service.APIKeyService, rawKey string) {
+	key, user, err := apiKeyService.Authenticate(c.Request.Context(), rawKey)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusUnauthorized, "api key tidak valid", err)
+		c.Abort()
+		return
+	}
+
+	allowed, err := apiKeyService.Allow(c.Request.Context(), key)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "gagal memeriksa rate limit", err)
+		c.Abort()
+		return
+	}
+	if !allowed {
+		utils.ErrorResponse(c, http.StatusTooManyRequests, "batas request api key terlampaui", nil)
+		c.Abort()
+		return
+	}
+
+	c.Set("user_id", user.ID)
+	c.Set("user_email", user.Email)
+	c.Set("user_role", user.Role)
+	c.Set("api_key_id", key.ID)
+	c.Set("api_key_permissions", []string(key.Permissions))
+
+	c.Next()
+}
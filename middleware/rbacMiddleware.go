@@ -2,12 +2,13 @@ package middleware
 
 import (
 	"net/http"
+	"tiketsepur/service"
 	"tiketsepur/utils"
 
 	"github.com/gin-gonic/gin"
 )
 
-func RoleMiddleware(allowedRoles ...string) gin.HandlerFunc {
+func RequirePermission(rbacService service.RBACService, permission string) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		userRole, exists := c.Get("user_role")
 		if !exists {
@@ -16,14 +17,11 @@ func RoleMiddleware(allowedRoles ...string) gin.HandlerFunc {
 			return
 		}
 
-		role := userRole.(string)
-
-		allowed := false
-		for _, allowedRole := range allowedRoles {
-			if role == allowedRole {
-				allowed = true
-				break
-			}
+		allowed, err := rbacService.Authorize(c.Request.Context(), userRole.(string), APIKeyScopes(c), permission)
+		if err != nil {
+			utils.ErrorResponse(c, http.StatusInternalServerError, "gagal memeriksa permission", err)
+			c.Abort()
+			return
 		}
 
 		if !allowed {
@@ -36,10 +34,17 @@ func RoleMiddleware(allowedRoles ...string) gin.HandlerFunc {
 	}
 }
 
-func AdminOnly() gin.HandlerFunc {
-	return RoleMiddleware("admin")
+// APIKeyScopes mengembalikan scope api key yang dipakai request, atau nil
+// untuk request dengan sesi JWT. Hasilnya dipakai di RBACService.Authorize,
+// termasuk oleh service yang memeriksa permission di luar middleware.
+func APIKeyScopes(c *gin.Context) []string {
+	scopes, ok := c.Get("api_key_permissions")
+	if !ok {
+		return nil
+	}
+	if scopes, _ := scopes.([]string); scopes != nil {
+		return scopes
+	}
+	// key tanpa scope tidak boleh diperlakukan seperti sesi JWT
+	return []string{}
 }
-
-func UserOrAdmin() gin.HandlerFunc {
-	return RoleMiddleware("user", "admin")
-}
\ No newline at end of file
@@ -0,0 +1,23 @@
+package models
+
+import (
+	"time"
+
+	"github.com/lib/pq"
+)
+
+type APIKey struct {
+	ID          int            `json:"id" db:"id"`
+	Name        string         `json:"name" db:"name"`
+	KeyPrefix   string         `json:"key_prefix" db:"key_prefix"`
+	KeyHash     string         `json:"-" db:"key_hash"`
+	UserID      int            `json:"user_id" db:"user_id"`
+	Permissions pq.StringArray `json:"permissions" db:"permissions"`
+	RateLimit   int            `json:"rate_limit" db:"rate_limit"`
+	ExpiresAt   *time.Time     `json:"expires_at" db:"expires_at"`
+	LastUsedAt  *time.Time     `json:"last_used_at" db:"last_used_at"`
+	RevokedAt   *time.Time     `json:"revoked_at" db:"revoked_at"`
+	CreatedBy   *int           `json:"created_by" db:"created_by"`
+	CreatedAt   time.Time      `json:"created_at" db:"created_at"`
+	ModifiedAt  time.Time      `json:"modified_at" db:"modified_at"`
+}
@@ -0,0 +1,71 @@
+package models
+
+import "time"
+
+const (
+	DutyDriver    = "driver"
+	DutyConductor = "conductor"
+
+	ViolationStaffInactive         = "staff_inactive"
+	ViolationNotQualified          = "not_qualified"
+	ViolationDriverAlreadyAssigned = "driver_already_assigned"
+	ViolationCrewOverlap           = "crew_overlap"
+	ViolationCrewRestTooShort      = "crew_rest_too_short"
+	ViolationStaffAlreadyAssigned  = "staff_already_assigned"
+)
+
+// Staff adalah masinis atau kondektur. UserID diisi jika staf punya akun
+// login, dan wajib untuk kondektur yang melakukan scan boarding.
+type Staff struct {
+	ID             int       `json:"id" db:"id"`
+	UserID         *int      `json:"user_id" db:"user_id"`
+	EmployeeNumber string    `json:"employee_number" db:"employee_number"`
+	Name           string    `json:"name" db:"name"`
+	Phone          *string   `json:"phone" db:"phone"`
+	IsActive       bool      `json:"is_active" db:"is_active"`
+	CreatedAt      time.Time `json:"created_at" db:"created_at"`
+	ModifiedAt     time.Time `json:"modified_at" db:"modified_at"`
+
+	Qualifications []StaffQualification `json:"qualifications" db:"-"`
+}
+
+// StaffQualification adalah sertifikasi staf untuk satu jenis tugas.
+// ValidUntil kosong berarti tidak kedaluwarsa.
+type StaffQualification struct {
+	StaffID       int        `json:"-" db:"staff_id"`
+	Qualification string     `json:"qualification" db:"qualification"`
+	ValidUntil    *time.Time `json:"valid_until" db:"valid_until"`
+}
+
+// CrewAssignment menugaskan staf ke satu jadwal. Kolom jadwal dan staf
+// diisi dari join untuk daftar kru dan daftar tugas.
+type CrewAssignment struct {
+	ID         int       `json:"id" db:"id"`
+	ScheduleID int       `json:"schedule_id" db:"schedule_id"`
+	StaffID    int       `json:"staff_id" db:"staff_id"`
+	Duty       string    `json:"duty" db:"duty"`
+	CreatedBy  *int      `json:"created_by" db:"created_by"`
+	CreatedAt  time.Time `json:"created_at" db:"created_at"`
+
+	StaffName         string    `json:"staff_name" db:"staff_name"`
+	EmployeeNumber    string    `json:"employee_number" db:"employee_number"`
+	TrainCode         string    `json:"train_code" db:"train_code"`
+	TrainName         string    `json:"train_name" db:"train_name"`
+	DepartureStation  string    `json:"departure_station" db:"departure_station"`
+	ArrivalStation    string    `json:"arrival_station" db:"arrival_station"`
+	DepartureTime     time.Time `json:"departure_time" db:"departure_time"`
+	ArrivalTime       time.Time `json:"arrival_time" db:"arrival_time"`
+	DepartureTimezone string    `json:"departure_timezone" db:"departure_timezone"`
+	ArrivalTimezone   string    `json:"arrival_timezone" db:"arrival_timezone"`
+	Platform          *string   `json:"platform" db:"platform"`
+	ScheduleStatus    string    `json:"schedule_status" db:"schedule_status"`
+}
+
+// BoardingScan mencatat tiket yang sudah discan kondektur saat naik kereta.
+type BoardingScan struct {
+	ID         int       `json:"id" db:"id"`
+	TicketID   int       `json:"ticket_id" db:"ticket_id"`
+	ScheduleID int       `json:"schedule_id" db:"schedule_id"`
+	StaffID    *int      `json:"staff_id" db:"staff_id"`
+	ScannedAt  time.Time `json:"scanned_at" db:"scanned_at"`
+}
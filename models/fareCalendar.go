@@ -0,0 +1,8 @@
+package models
+
+type FareCalendarDay struct {
+	Date           string   `json:"date" db:"date"`
+	LowestFare     *float64 `json:"lowest_fare" db:"lowest_fare"`
+	SeatsAvailable bool     `json:"seats_available" db:"seats_available"`
+	Departures     int      `json:"departures" db:"departures"`
+}
@@ -0,0 +1,12 @@
+package models
+
+import "time"
+
+type JWTSigningKey struct {
+	KID        string     `json:"kid" db:"kid"`
+	Algorithm  string     `json:"algorithm" db:"algorithm"`
+	PrivateKey string     `json:"-" db:"private_key"`
+	CreatedAt  time.Time  `json:"created_at" db:"created_at"`
+	RetiredAt  *time.Time `json:"retired_at" db:"retired_at"`
+	ExpiresAt  *time.Time `json:"expires_at" db:"expires_at"`
+}
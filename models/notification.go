@@ -0,0 +1,31 @@
+package models
+
+import (
+	"time"
+
+	"github.com/lib/pq"
+)
+
+type NotificationPreference struct {
+	ID               int            `json:"id" db:"id"`
+	UserID           int            `json:"user_id" db:"user_id"`
+	NotificationType string         `json:"notification_type" db:"notification_type"`
+	Channels         pq.StringArray `json:"channels" db:"channels"`
+	WebhookURL       *string        `json:"webhook_url" db:"webhook_url"`
+	CreatedAt        time.Time      `json:"created_at" db:"created_at"`
+	ModifiedAt       time.Time      `json:"modified_at" db:"modified_at"`
+}
+
+type NotificationDelivery struct {
+	ID               int        `json:"id" db:"id"`
+	MessageID        *string    `json:"message_id" db:"message_id"`
+	UserID           *int       `json:"user_id" db:"user_id"`
+	NotificationType string     `json:"notification_type" db:"notification_type"`
+	Channel          string     `json:"channel" db:"channel"`
+	Recipient        string     `json:"recipient" db:"recipient"`
+	BookingCode      *string    `json:"booking_code" db:"booking_code"`
+	Status           string     `json:"status" db:"status"`
+	Error            *string    `json:"error" db:"error"`
+	CreatedAt        time.Time  `json:"created_at" db:"created_at"`
+	SentAt           *time.Time `json:"sent_at" db:"sent_at"`
+}
@@ -0,0 +1,17 @@
+package models
+
+import "time"
+
+type NotificationTemplate struct {
+	ID               int       `json:"id" db:"id"`
+	NotificationType string    `json:"notification_type" db:"notification_type"`
+	Language         string    `json:"language" db:"language"`
+	Version          int       `json:"version" db:"version"`
+	Subject          string    `json:"subject" db:"subject"`
+	BodyText         string    `json:"body_text" db:"body_text"`
+	BodyHTML         string    `json:"body_html" db:"body_html"`
+	BodyShort        string    `json:"body_short" db:"body_short"`
+	IsActive         bool      `json:"is_active" db:"is_active"`
+	CreatedBy        *int      `json:"created_by" db:"created_by"`
+	CreatedAt        time.Time `json:"created_at" db:"created_at"`
+}
@@ -0,0 +1,21 @@
+package models
+
+import (
+	"time"
+
+	"github.com/jmoiron/sqlx/types"
+)
+
+type OutboxMessage struct {
+	ID            int64          `json:"id" db:"id"`
+	AggregateType string         `json:"aggregate_type" db:"aggregate_type"`
+	AggregateID   string         `json:"aggregate_id" db:"aggregate_id"`
+	EventType     string         `json:"event_type" db:"event_type"`
+	Payload       types.JSONText `json:"payload" db:"payload"`
+	Status        string         `json:"status" db:"status"`
+	Attempts      int            `json:"attempts" db:"attempts"`
+	LastError     *string        `json:"last_error" db:"last_error"`
+	NextAttemptAt time.Time      `json:"next_attempt_at" db:"next_attempt_at"`
+	CreatedAt     time.Time      `json:"created_at" db:"created_at"`
+	SentAt        *time.Time     `json:"sent_at" db:"sent_at"`
+}
@@ -10,6 +10,7 @@ type Payment struct {
 	PaymentStatus string     `json:"payment_status" db:"payment_status"`
 	PaymentCode   string     `json:"payment_code" db:"payment_code"`
 	PaidAt        *time.Time `json:"paid_at" db:"paid_at"`
+	RefundedAt    *time.Time `json:"refunded_at" db:"refunded_at"`
 	CreatedAt     time.Time  `json:"created_at" db:"created_at"`
 	ModifiedAt    time.Time  `json:"modified_at" db:"modified_at"`
-}
\ No newline at end of file
+}
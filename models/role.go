@@ -0,0 +1,20 @@
+package models
+
+import "time"
+
+type Role struct {
+	ID          int       `json:"id" db:"id"`
+	Name        string    `json:"name" db:"name"`
+	Description *string   `json:"description" db:"description"`
+	CreatedAt   time.Time `json:"created_at" db:"created_at"`
+	ModifiedAt  time.Time `json:"modified_at" db:"modified_at"`
+
+	Permissions []string `json:"permissions,omitempty" db:"-"`
+}
+
+type Permission struct {
+	ID          int       `json:"id" db:"id"`
+	Code        string    `json:"code" db:"code"`
+	Description *string   `json:"description" db:"description"`
+	CreatedAt   time.Time `json:"created_at" db:"created_at"`
+}
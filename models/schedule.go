@@ -8,12 +8,21 @@ type Schedule struct {
 	DepartureStation string    `json:"departure_station" db:"departure_station"`
 	ArrivalStation   string    `json:"arrival_station" db:"arrival_station"`
 	DepartureTime    time.Time `json:"departure_time" db:"departure_time"`
-	ArrivalTime 	 time.Time `json:"arrival_time" db:"arrival_time"`
-	Price 			 float64   `json:"price" db:"price"`
-	AvailableSeats 	 int 	   `json:"available_seats" db:"available_seats"`
-	TrainCode        string    `db:"train_code"`
-    TrainName        string    `db:"train_name"`
-    TrainType        string    `db:"train_type"`
-	CreatedAt  	 	 time.Time `json:"created_at" db:"created_at"`
-	ModifiedAt 		 time.Time `json:"modified_at" db:"modified_at"`
-}
\ No newline at end of file
+	ArrivalTime      time.Time `json:"arrival_time" db:"arrival_time"`
+	// zona waktu IANA stasiun; departure_time dan arrival_time ditampilkan
+	// sebagai jam lokal di stasiun masing-masing
+	DepartureTimezone string    `json:"departure_timezone" db:"departure_timezone"`
+	ArrivalTimezone   string    `json:"arrival_timezone" db:"arrival_timezone"`
+	Price             float64   `json:"price" db:"price"`
+	AvailableSeats    int       `json:"available_seats" db:"available_seats"`
+	Platform          *string   `json:"platform" db:"platform"`
+	Status            string    `json:"status" db:"status"`
+	DelayMinutes      int       `json:"delay_minutes" db:"delay_minutes"`
+	TemplateID        *int      `json:"template_id" db:"template_id"`
+	DurationMinutes   int       `json:"duration_minutes" db:"duration_minutes"`
+	TrainCode         string    `db:"train_code"`
+	TrainName         string    `db:"train_name"`
+	TrainType         string    `db:"train_type"`
+	CreatedAt         time.Time `json:"created_at" db:"created_at"`
+	ModifiedAt        time.Time `json:"modified_at" db:"modified_at"`
+}
@@ -0,0 +1,26 @@
+package models
+
+import "time"
+
+const (
+	DisruptionDelay          = "delay"
+	DisruptionPlatformChange = "platform_change"
+	DisruptionCancellation   = "cancellation"
+
+	ScheduleStatusScheduled = "scheduled"
+	ScheduleStatusDelayed   = "delayed"
+	ScheduleStatusCancelled = "cancelled"
+)
+
+type ScheduleDisruption struct {
+	ID              int       `json:"id" db:"id"`
+	ScheduleID      int       `json:"schedule_id" db:"schedule_id"`
+	DisruptionType  string    `json:"disruption_type" db:"disruption_type"`
+	DelayMinutes    *int      `json:"delay_minutes" db:"delay_minutes"`
+	OldPlatform     *string   `json:"old_platform" db:"old_platform"`
+	NewPlatform     *string   `json:"new_platform" db:"new_platform"`
+	Reason          *string   `json:"reason" db:"reason"`
+	AffectedTickets int       `json:"affected_tickets" db:"affected_tickets"`
+	CreatedBy       *int      `json:"created_by" db:"created_by"`
+	CreatedAt       time.Time `json:"created_at" db:"created_at"`
+}
@@ -0,0 +1,29 @@
+package models
+
+import (
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// ScheduleTemplate adalah pola jadwal berulang. Jam berangkat dan tiba memakai
+// format HH:MM, tanggal berlaku memakai YYYY-MM-DD, dan DaysOfWeek berisi hari
+// ISO (1 = Senin ... 7 = Minggu).
+type ScheduleTemplate struct {
+	ID               int           `json:"id" db:"id"`
+	TrainID          int           `json:"train_id" db:"train_id"`
+	DepartureStation string        `json:"departure_station" db:"departure_station"`
+	ArrivalStation   string        `json:"arrival_station" db:"arrival_station"`
+	DepartureTime    string        `json:"departure_time" db:"departure_time"`
+	ArrivalTime      string        `json:"arrival_time" db:"arrival_time"`
+	ArrivalDayOffset int           `json:"arrival_day_offset" db:"arrival_day_offset"`
+	DaysOfWeek       pq.Int64Array `json:"days_of_week" db:"days_of_week" swaggertype:"array,integer"`
+	ValidFrom        string        `json:"valid_from" db:"valid_from"`
+	ValidTo          string        `json:"valid_to" db:"valid_to"`
+	Price            float64       `json:"price" db:"price"`
+	Platform         *string       `json:"platform" db:"platform"`
+	IsActive         bool          `json:"is_active" db:"is_active"`
+	CreatedBy        *int          `json:"created_by" db:"created_by"`
+	CreatedAt        time.Time     `json:"created_at" db:"created_at"`
+	ModifiedAt       time.Time     `json:"modified_at" db:"modified_at"`
+}
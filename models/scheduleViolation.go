@@ -0,0 +1,27 @@
+package models
+
+// Kode aturan konsistensi jadwal, dipakai untuk error validasi dan laporan
+// pelanggaran.
+const (
+	ViolationTrainNotFound          = "train_not_found"
+	ViolationArrivalBeforeDeparture = "arrival_before_departure"
+	ViolationSameStation            = "same_station"
+	ViolationSeatsExceedCapacity    = "seats_exceed_capacity"
+	ViolationTrainOverlap           = "train_overlap"
+	ViolationStationNotFound        = "station_not_found"
+	ViolationTrainUnavailable       = "train_unavailable"
+
+	// hanya untuk error validasi: field yang tidak bisa diubah selama jadwal
+	// masih punya tiket aktif
+	ViolationRequiresReaccommodation = "requires_reaccommodation"
+)
+
+// ScheduleViolation adalah jadwal tersimpan yang melanggar aturan konsistensi.
+// ConflictingScheduleID diisi untuk train_overlap.
+type ScheduleViolation struct {
+	ScheduleID            int    `json:"schedule_id" db:"schedule_id"`
+	Rule                  string `json:"rule" db:"rule"`
+	Field                 string `json:"field" db:"field"`
+	Message               string `json:"message" db:"-"`
+	ConflictingScheduleID *int   `json:"conflicting_schedule_id,omitempty" db:"conflicting_schedule_id"`
+}
@@ -0,0 +1,13 @@
+package models
+
+import "time"
+
+// Station menyimpan zona waktu IANA sebuah stasiun. Jadwal mereferensikan
+// stasiun lewat nama tanpa memperhatikan huruf besar/kecil.
+type Station struct {
+	ID         int       `json:"id" db:"id"`
+	Name       string    `json:"name" db:"name"`
+	Timezone   string    `json:"timezone" db:"timezone"`
+	CreatedAt  time.Time `json:"created_at" db:"created_at"`
+	ModifiedAt time.Time `json:"modified_at" db:"modified_at"`
+}
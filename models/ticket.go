@@ -12,6 +12,7 @@ type Ticket struct {
 	Status            string    `json:"status" db:"status"`
 	BookingCode       string    `json:"booking_code" db:"booking_code"`
 	TotalPrice        float64   `json:"total_price" db:"total_price"`
+	Coach             *string   `json:"coach" db:"coach"`
 	CreatedAt         time.Time `json:"created_at" db:"created_at"`
 	ModifiedAt        *time.Time `json:"modified_at" db:"modified_at"`
 
@@ -30,11 +31,14 @@ type TicketWithDetails struct {
 	Status            string     `json:"status" db:"status"`
 	BookingCode       string     `json:"booking_code" db:"booking_code"`
 	TotalPrice        float64    `json:"total_price" db:"total_price"`
+	Coach             *string    `json:"coach" db:"coach"`
 	CreatedAt         time.Time  `json:"created_at" db:"created_at"`
 	ModifiedAt        *time.Time `json:"modified_at" db:"modified_at"`
 	DepartureStation  string     `db:"departure_station"`
 	ArrivalStation    string     `db:"arrival_station"`
 	DepartureTime     time.Time  `db:"departure_time"`
+	DepartureTimezone string     `db:"departure_timezone"`
+	Platform          *string    `db:"platform"`
 	TrainName         string     `db:"train_name"`
 	TrainCode         string     `db:"train_code"`
 	TrainType         string     `db:"train_type"`
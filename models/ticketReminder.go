@@ -0,0 +1,10 @@
+package models
+
+import "time"
+
+type TicketReminder struct {
+	ID            int       `json:"id" db:"id"`
+	TicketID      int       `json:"ticket_id" db:"ticket_id"`
+	OffsetMinutes int       `json:"offset_minutes" db:"offset_minutes"`
+	CreatedAt     time.Time `json:"created_at" db:"created_at"`
+}
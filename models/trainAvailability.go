@@ -0,0 +1,53 @@
+package models
+
+import "time"
+
+const (
+	CarTypePassenger = "passenger"
+	CarTypeDining    = "dining"
+	CarTypePower     = "power"
+	CarTypeBaggage   = "baggage"
+
+	UnavailabilityMaintenance  = "maintenance"
+	UnavailabilityOutOfService = "out_of_service"
+)
+
+// TrainCar adalah satu kereta dalam rangkaian. Hanya kereta penumpang yang
+// punya kursi; CarCode sama dengan coach di tiket, misalnya EKS-1.
+type TrainCar struct {
+	ID        int       `json:"id" db:"id"`
+	TrainID   int       `json:"train_id" db:"train_id"`
+	Position  int       `json:"position" db:"position"`
+	CarCode   string    `json:"car_code" db:"car_code"`
+	CarType   string    `json:"car_type" db:"car_type"`
+	CarClass  *string   `json:"car_class" db:"car_class"`
+	Seats     int       `json:"seats" db:"seats"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+}
+
+// TrainUnavailability adalah rentang waktu kereta tidak bisa dijadwalkan,
+// misalnya perawatan berkala atau kereta rusak.
+type TrainUnavailability struct {
+	ID        int       `json:"id" db:"id"`
+	TrainID   int       `json:"train_id" db:"train_id"`
+	Reason    string    `json:"reason" db:"reason"`
+	StartsAt  time.Time `json:"starts_at" db:"starts_at"`
+	EndsAt    time.Time `json:"ends_at" db:"ends_at"`
+	Notes     *string   `json:"notes" db:"notes"`
+	CreatedBy *int      `json:"created_by" db:"created_by"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+}
+
+// TrainUtilization adalah pemakaian satu kereta dalam rentang tanggal.
+// UtilizationPercent adalah menit terjadwal dibagi menit kereta tersedia.
+type TrainUtilization struct {
+	TrainID            int     `json:"train_id" db:"train_id"`
+	TrainCode          string  `json:"train_code" db:"train_code"`
+	TrainName          string  `json:"train_name" db:"train_name"`
+	TotalSeats         int     `json:"total_seats" db:"total_seats"`
+	Schedules          int     `json:"schedules" db:"schedules"`
+	ScheduledMinutes   int     `json:"scheduled_minutes" db:"scheduled_minutes"`
+	UnavailableMinutes int     `json:"unavailable_minutes" db:"-"`
+	AvailableMinutes   int     `json:"available_minutes" db:"-"`
+	UtilizationPercent float64 `json:"utilization_percent" db:"-"`
+}
@@ -7,6 +7,7 @@ type User struct {
 	FullName   string `json:"full_name" db:"full_name"`
 	Phone      string `db:"phone" json:"phone"`
 	Role       string `db:"role" json:"role"`
+	Language   string `db:"language" json:"language"`
 	CreatedAt  string `json:"created_at" db:"created_at"`
 	ModifiedAt string `json:"modified_at" db:"modified_at"`
 }
\ No newline at end of file
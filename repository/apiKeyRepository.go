@@ -0,0 +1,95 @@
+package repository
+
+import (
+	"tiketsepur/models"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type APIKeyRepository interface {
+	Create(key *models.APIKey) error
+	FindByID(id int) (*models.APIKey, error)
+	FindByPrefix(prefix string) (*models.APIKey, error)
+	FindAll(qs QuerySpec) ([]models.APIKey, Page, error)
+	Revoke(id int) error
+	TouchLastUsed(id int) error
+}
+
+type apiKeyRepository struct {
+	db *sqlx.DB
+}
+
+func NewAPIKeyRepository(db *sqlx.DB) APIKeyRepository {
+	return &apiKeyRepository{db: db}
+}
+
+func (r *apiKeyRepository) Create(key *models.APIKey) error {
+	query := `INSERT INTO api_keys (name, key_prefix, key_hash, user_id, permissions,
+			  rate_limit, expires_at, created_by, created_at, modified_at)
+			  VALUES ($1, $2, $3, $4, $5, $6, $7, $8, NOW(), NOW()) RETURNING id, created_at, modified_at`
+	return r.db.QueryRow(query, key.Name, key.KeyPrefix, key.KeyHash, key.UserID, key.Permissions,
+		key.RateLimit, key.ExpiresAt, key.CreatedBy).Scan(&key.ID, &key.CreatedAt, &key.ModifiedAt)
+}
+
+func (r *apiKeyRepository) FindByID(id int) (*models.APIKey, error) {
+	var key models.APIKey
+	query := `SELECT * FROM api_keys WHERE id = $1`
+	err := r.db.Get(&key, query, id)
+	if err != nil {
+		return nil, err
+	}
+	return &key, nil
+}
+
+func (r *apiKeyRepository) FindByPrefix(prefix string) (*models.APIKey, error) {
+	var key models.APIKey
+	query := `SELECT * FROM api_keys WHERE key_prefix = $1`
+	err := r.db.Get(&key, query, prefix)
+	if err != nil {
+		return nil, err
+	}
+	return &key, nil
+}
+
+var apiKeyListSpec = listSpec{
+	sorts: map[string]sortField{
+		"created_at": {Column: "created_at", Field: "created_at", Cast: "timestamp"},
+		"name":       {Column: "name", Field: "name", Cast: "text"},
+		"id":         {Column: "id", Field: "id", Cast: "int"},
+	},
+	defaultSort: "created_at",
+	defaultDesc: true,
+	idColumn:    "id",
+}
+
+// FindAll mendukung filter status (active atau revoked) dan pencarian nama.
+func (r *apiKeyRepository) FindAll(qs QuerySpec) ([]models.APIKey, Page, error) {
+	var keys []models.APIKey
+	from := `FROM api_keys`
+
+	filters := &listQuery{}
+	switch qs.Status {
+	case "active":
+		filters.add("revoked_at IS NULL")
+	case "revoked":
+		filters.add("revoked_at IS NOT NULL")
+	}
+	if qs.Search != "" {
+		filters.add("name ILIKE ?", likePattern(qs.Search))
+	}
+
+	page, err := selectPage(r.db, &keys, apiKeyListSpec, qs, `SELECT * `+from, from, filters)
+	return keys, page, err
+}
+
+func (r *apiKeyRepository) Revoke(id int) error {
+	query := `UPDATE api_keys SET revoked_at = NOW(), modified_at = NOW() WHERE id = $1 AND revoked_at IS NULL`
+	_, err := r.db.Exec(query, id)
+	return err
+}
+
+func (r *apiKeyRepository) TouchLastUsed(id int) error {
+	query := `UPDATE api_keys SET last_used_at = NOW() WHERE id = $1`
+	_, err := r.db.Exec(query, id)
+	return err
+}
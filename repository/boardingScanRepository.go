@@ -0,0 +1,44 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"tiketsepur/models"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type BoardingScanRepository interface {
+	Create(scan *models.BoardingScan) (bool, error)
+	FindByTicket(ticketID int) (*models.BoardingScan, error)
+}
+
+type boardingScanRepository struct {
+	db *sqlx.DB
+}
+
+func NewBoardingScanRepository(db *sqlx.DB) BoardingScanRepository {
+	return &boardingScanRepository{db: db}
+}
+
+// Create mengembalikan false tanpa error jika tiket sudah pernah discan,
+// sehingga dua scan bersamaan untuk tiket yang sama hanya tercatat sekali.
+func (r *boardingScanRepository) Create(scan *models.BoardingScan) (bool, error) {
+	query := `INSERT INTO boarding_scans (ticket_id, schedule_id, staff_id, scanned_at)
+			  VALUES ($1, $2, $3, NOW())
+			  ON CONFLICT (ticket_id) DO NOTHING
+			  RETURNING id, scanned_at`
+	err := r.db.QueryRow(query, scan.TicketID, scan.ScheduleID, scan.StaffID).Scan(&scan.ID, &scan.ScannedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+func (r *boardingScanRepository) FindByTicket(ticketID int) (*models.BoardingScan, error) {
+	var scan models.BoardingScan
+	if err := r.db.Get(&scan, `SELECT * FROM boarding_scans WHERE ticket_id = $1`, ticketID); err != nil {
+		return nil, err
+	}
+	return &scan, nil
+}
@@ -0,0 +1,364 @@
+package repository
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"tiketsepur/models"
+	"tiketsepur/utils"
+	"time"
+)
+
+const (
+	scheduleCachePrefix     = "schedule_cache:"
+	scheduleCacheGeneration = scheduleCachePrefix + "generation"
+	scheduleCacheListsDirty = scheduleCachePrefix + "dirty:lists"
+	scheduleCacheSeatsTag   = scheduleCachePrefix + "tag:seats"
+
+	// selama jendela ini hasil query tidak disimpan ke cache karena pembacaan
+	// yang dimulai sebelum commit masih bisa membawa data lama
+	scheduleCacheDirtyWindow = 10 * time.Second
+
+	scheduleCacheFindByID = "find_by_id"
+	scheduleCacheFindAll  = "find_all"
+	scheduleCacheSearch   = "search"
+)
+
+// ScheduleCache dipakai untuk invalidasi dari luar repository jadwal dan
+// untuk membaca metrik cache. Semua invalidasi dipanggil service setelah
+// transaksi commit, bukan dari dalam transaksi.
+type ScheduleCache interface {
+	// InvalidateSchedules dipakai untuk perubahan yang bisa memasukkan atau
+	// mengeluarkan jadwal dari hasil list, termasuk jadwal baru tanpa id.
+	InvalidateSchedules(scheduleIDs ...int)
+	// InvalidateSeats dipakai saat kursi jadwal hanya berkurang.
+	InvalidateSeats(scheduleIDs ...int)
+	InvalidateTrain(trainID int)
+	InvalidateStation(name string)
+	Stats() ScheduleCacheStats
+}
+
+type ScheduleCacheCounter struct {
+	Hits     uint64  `json:"hits"`
+	Misses   uint64  `json:"misses"`
+	Errors   uint64  `json:"errors"`
+	HitRatio float64 `json:"hit_ratio"`
+}
+
+// ScheduleCacheStats adalah metrik cache per operasi sejak proses berjalan.
+type ScheduleCacheStats struct {
+	Enabled    bool                            `json:"enabled"`
+	TTL        string                          `json:"ttl,omitempty"`
+	Operations map[string]ScheduleCacheCounter `json:"operations"`
+}
+
+type scheduleCacheCounter struct {
+	hits   atomic.Uint64
+	misses atomic.Uint64
+	errors atomic.Uint64
+}
+
+// cachedScheduleRepository adalah cache read-through di depan
+// ScheduleRepository untuk FindByID, FindAll, dan Search. Hanya jalur baca
+// publik yang memakainya; logika bisnis membaca jadwal langsung dari
+// repository tanpa cache.
+//
+// Setiap entri ditandai dengan id jadwal, id kereta, dan nama stasiun yang
+// dikandungnya.
+// Pengurangan kursi (jalur booking) hanya menghapus entri yang memuat jadwal
+// tersebut. Perubahan lain bisa memasukkan jadwal ke hasil list yang
+// sebelumnya tidak memuatnya, jadi selain menghapus tag, generasi cache list
+// juga dinaikkan.
+type cachedScheduleRepository struct {
+	ScheduleRepository
+	redis    *utils.RedisClient
+	ttl      time.Duration
+	counters map[string]*scheduleCacheCounter
+}
+
+func NewCachedScheduleRepository(inner ScheduleRepository, redis *utils.RedisClient, ttl time.Duration) (ScheduleRepository, ScheduleCache) {
+	r := &cachedScheduleRepository{
+		ScheduleRepository: inner,
+		redis:              redis,
+		ttl:                ttl,
+		counters: map[string]*scheduleCacheCounter{
+			scheduleCacheFindByID: {},
+			scheduleCacheFindAll:  {},
+			scheduleCacheSearch:   {},
+		},
+	}
+	return r, r
+}
+
+type cachedSchedulePage struct {
+	Schedules []models.Schedule `json:"schedules"`
+	Page      Page              `json:"page"`
+}
+
+func (r *cachedScheduleRepository) FindByID(id int) (*models.Schedule, error) {
+	ctx := context.Background()
+	key := scheduleCachePrefix + "id:" + strconv.Itoa(id)
+
+	var schedule models.Schedule
+	if r.get(ctx, scheduleCacheFindByID, key, &schedule) {
+		return &schedule, nil
+	}
+
+	result, err := r.ScheduleRepository.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+	r.set(ctx, scheduleCacheFindByID, key, result, []models.Schedule{*result}, false)
+	return result, nil
+}
+
+func (r *cachedScheduleRepository) FindAll(qs QuerySpec) ([]models.Schedule, Page, error) {
+	ctx := context.Background()
+	key, ok := r.listKey(ctx, scheduleCacheFindAll, qs)
+
+	var cached cachedSchedulePage
+	if ok && r.get(ctx, scheduleCacheFindAll, key, &cached) {
+		return cached.Schedules, cached.Page, nil
+	}
+
+	schedules, page, err := r.ScheduleRepository.FindAll(qs)
+	if err != nil || !ok {
+		return schedules, page, err
+	}
+	// urutan berdasarkan kursi tersisa bisa berubah antar halaman setiap ada booking
+	bySeats := qs.Sort == "available_seats"
+	r.set(ctx, scheduleCacheFindAll, key, cachedSchedulePage{Schedules: schedules, Page: page}, schedules, bySeats)
+	return schedules, page, nil
+}
+
+func (r *cachedScheduleRepository) Search(criteria ScheduleSearch) ([]models.Schedule, error) {
+	ctx := context.Background()
+	key, ok := r.listKey(ctx, scheduleCacheSearch, criteria)
+
+	var schedules []models.Schedule
+	if ok && r.get(ctx, scheduleCacheSearch, key, &schedules) {
+		return schedules, nil
+	}
+
+	result, err := r.ScheduleRepository.Search(criteria)
+	if err != nil || !ok {
+		return result, err
+	}
+	r.set(ctx, scheduleCacheSearch, key, result, result, false)
+	return result, nil
+}
+
+func (r *cachedScheduleRepository) InvalidateSchedules(scheduleIDs ...int) {
+	r.invalidate(true, scheduleIDs...)
+}
+
+func (r *cachedScheduleRepository) InvalidateSeats(scheduleIDs ...int) {
+	r.invalidate(false, scheduleIDs...)
+}
+
+// InvalidateTrain dipanggil saat data kereta berubah karena setiap jadwal
+// menyimpan kode, nama, dan tipe kereta.
+func (r *cachedScheduleRepository) InvalidateTrain(trainID int) {
+	ctx := context.Background()
+	r.bumpLists(ctx)
+	if err := r.redis.DeleteTags(ctx, scheduleTrainTag(trainID)); err != nil {
+		log.Printf("gagal invalidasi cache jadwal kereta %d: %v", trainID, err)
+	}
+}
+
+// InvalidateStation dipanggil saat zona waktu stasiun berubah karena waktu
+// jadwal ditampilkan dalam jam lokal stasiun dan pencarian per tanggal ikut
+// bergeser.
+func (r *cachedScheduleRepository) InvalidateStation(name string) {
+	ctx := context.Background()
+	r.bumpLists(ctx)
+	if err := r.redis.DeleteTags(ctx, scheduleStationTag(name)); err != nil {
+		log.Printf("gagal invalidasi cache jadwal stasiun %s: %v", name, err)
+	}
+}
+
+func (r *cachedScheduleRepository) Stats() ScheduleCacheStats {
+	stats := ScheduleCacheStats{
+		Enabled:    true,
+		TTL:        r.ttl.String(),
+		Operations: make(map[string]ScheduleCacheCounter, len(r.counters)),
+	}
+	for name, counter := range r.counters {
+		c := ScheduleCacheCounter{
+			Hits:   counter.hits.Load(),
+			Misses: counter.misses.Load(),
+			Errors: counter.errors.Load(),
+		}
+		if total := c.Hits + c.Misses; total > 0 {
+			c.HitRatio = float64(c.Hits) / float64(total)
+		}
+		stats.Operations[name] = c
+	}
+	return stats
+}
+
+// invalidate menandai jadwal sebagai dirty lalu menghapus entri yang
+// memuatnya. Penanda dirty mencegah pembacaan yang sempat mengambil data
+// sebelum commit menyimpan data lama itu kembali ke cache.
+func (r *cachedScheduleRepository) invalidate(lists bool, scheduleIDs ...int) {
+	ctx := context.Background()
+	if lists {
+		r.bumpLists(ctx)
+	}
+
+	var tags []string
+	for _, id := range scheduleIDs {
+		if err := r.redis.Set(ctx, scheduleDirtyKey(id), 1, scheduleCacheDirtyWindow); err != nil {
+			log.Printf("gagal menandai cache jadwal %d: %v", id, err)
+		}
+		tags = append(tags, scheduleTag(id))
+	}
+	if !lists && len(scheduleIDs) > 0 {
+		tags = append(tags, scheduleCacheSeatsTag)
+	}
+	if len(tags) == 0 {
+		return
+	}
+	if err := r.redis.DeleteTags(ctx, tags...); err != nil {
+		log.Printf("gagal invalidasi cache jadwal %v: %v", scheduleIDs, err)
+	}
+}
+
+func (r *cachedScheduleRepository) bumpLists(ctx context.Context) {
+	if err := r.redis.Set(ctx, scheduleCacheListsDirty, 1, scheduleCacheDirtyWindow); err != nil {
+		log.Printf("gagal menandai cache list jadwal: %v", err)
+	}
+	if _, err := r.redis.Incr(ctx, scheduleCacheGeneration); err != nil {
+		log.Printf("gagal menaikkan generasi cache jadwal: %v", err)
+	}
+}
+
+// listKey membentuk key dari generasi cache list dan hash parameter query.
+// false dikembalikan jika generasi tidak bisa dibaca sehingga cache dilewati.
+func (r *cachedScheduleRepository) listKey(ctx context.Context, operation string, params interface{}) (string, bool) {
+	generation, err := r.redis.Get(ctx, scheduleCacheGeneration)
+	if utils.IsNil(err) {
+		generation = "0"
+	} else if err != nil {
+		r.counters[operation].errors.Add(1)
+		return "", false
+	}
+
+	body, err := json.Marshal(params)
+	if err != nil {
+		r.counters[operation].errors.Add(1)
+		return "", false
+	}
+	sum := sha1.Sum(body)
+	return fmt.Sprintf("%s%s:%s:%s", scheduleCachePrefix, operation, generation, hex.EncodeToString(sum[:])), true
+}
+
+func (r *cachedScheduleRepository) get(ctx context.Context, operation, key string, dest interface{}) bool {
+	counter := r.counters[operation]
+	cached, err := r.redis.Get(ctx, key)
+	if err != nil {
+		if !utils.IsNil(err) {
+			counter.errors.Add(1)
+		}
+		counter.misses.Add(1)
+		return false
+	}
+	if err := json.Unmarshal([]byte(cached), dest); err != nil {
+		counter.errors.Add(1)
+		counter.misses.Add(1)
+		return false
+	}
+	counter.hits.Add(1)
+	return true
+}
+
+func (r *cachedScheduleRepository) set(ctx context.Context, operation, key string, value interface{}, schedules []models.Schedule, bySeats bool) {
+	counter := r.counters[operation]
+
+	dirty := make([]string, 0, len(schedules)+1)
+	if operation != scheduleCacheFindByID {
+		dirty = append(dirty, scheduleCacheListsDirty)
+	}
+	tags := make([]string, 0, len(schedules)*2+1)
+	trains := make(map[int]struct{})
+	stations := make(map[string]struct{})
+	for _, schedule := range schedules {
+		dirty = append(dirty, scheduleDirtyKey(schedule.ID))
+		tags = append(tags, scheduleTag(schedule.ID))
+		if _, ok := trains[schedule.TrainID]; !ok {
+			trains[schedule.TrainID] = struct{}{}
+			tags = append(tags, scheduleTrainTag(schedule.TrainID))
+		}
+		for _, station := range []string{schedule.DepartureStation, schedule.ArrivalStation} {
+			tag := scheduleStationTag(station)
+			if _, ok := stations[tag]; !ok {
+				stations[tag] = struct{}{}
+				tags = append(tags, tag)
+			}
+		}
+	}
+	if bySeats {
+		tags = append(tags, scheduleCacheSeatsTag)
+	}
+
+	if len(dirty) > 0 {
+		isDirty, err := r.redis.ExistsAny(ctx, dirty...)
+		if err != nil {
+			counter.errors.Add(1)
+			return
+		}
+		if isDirty {
+			return
+		}
+	}
+
+	body, err := json.Marshal(value)
+	if err != nil {
+		counter.errors.Add(1)
+		return
+	}
+	if err := r.redis.SetWithTags(ctx, key, body, r.ttl, tags...); err != nil {
+		counter.errors.Add(1)
+	}
+}
+
+func scheduleTag(id int) string {
+	return scheduleCachePrefix + "tag:schedule:" + strconv.Itoa(id)
+}
+
+func scheduleTrainTag(trainID int) string {
+	return scheduleCachePrefix + "tag:train:" + strconv.Itoa(trainID)
+}
+
+func scheduleStationTag(name string) string {
+	return scheduleCachePrefix + "tag:station:" + strings.ToLower(strings.TrimSpace(name))
+}
+
+func scheduleDirtyKey(id int) string {
+	return scheduleCachePrefix + "dirty:" + strconv.Itoa(id)
+}
+
+type disabledScheduleCache struct{}
+
+// NewDisabledScheduleCache dipakai saat cache jadwal dimatikan lewat config.
+func NewDisabledScheduleCache() ScheduleCache {
+	return disabledScheduleCache{}
+}
+
+func (disabledScheduleCache) InvalidateSchedules(...int) {}
+
+func (disabledScheduleCache) InvalidateSeats(...int) {}
+
+func (disabledScheduleCache) InvalidateTrain(int) {}
+
+func (disabledScheduleCache) InvalidateStation(string) {}
+
+func (disabledScheduleCache) Stats() ScheduleCacheStats {
+	return ScheduleCacheStats{Operations: map[string]ScheduleCacheCounter{}}
+}
@@ -0,0 +1,116 @@
+package repository
+
+import (
+	"tiketsepur/models"
+	"tiketsepur/utils"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type CrewAssignmentRepository interface {
+	Create(assignment *models.CrewAssignment, tx *sqlx.Tx) error
+	FindByID(id int) (*models.CrewAssignment, error)
+	FindBySchedule(scheduleID int) ([]models.CrewAssignment, error)
+	FindUpcomingByStaff(staffID int, after time.Time) ([]models.CrewAssignment, error)
+	FindByStaffInRange(staffID int, from, to time.Time, tx *sqlx.Tx) ([]models.CrewAssignment, error)
+	IsAssigned(scheduleID, staffID int, duty string) (bool, error)
+	Delete(id int) error
+}
+
+// Penugasan selalu dibaca bersama staf dan jadwalnya supaya daftar kru dan
+// daftar tugas bisa langsung ditampilkan.
+const crewAssignmentSelect = `SELECT ca.id, ca.schedule_id, ca.staff_id, ca.duty, ca.created_by, ca.created_at,
+			  st.name AS staff_name, st.employee_number, t.train_code, t.train_name,
+			  s.departure_station, s.arrival_station, s.departure_time, s.arrival_time, s.platform,
+			  s.status AS schedule_status, ` + scheduleTimezoneColumns + `
+			  FROM crew_assignments ca
+			  JOIN staff st ON st.id = ca.staff_id
+			  JOIN schedules s ON s.id = ca.schedule_id
+			  JOIN trains t ON t.id = s.train_id` + scheduleStationJoins
+
+type crewAssignmentRepository struct {
+	db *sqlx.DB
+}
+
+func NewCrewAssignmentRepository(db *sqlx.DB) CrewAssignmentRepository {
+	return &crewAssignmentRepository{db: db}
+}
+
+func (r *crewAssignmentRepository) Create(assignment *models.CrewAssignment, tx *sqlx.Tx) error {
+	query := `INSERT INTO crew_assignments (schedule_id, staff_id, duty, created_by, created_at)
+			  VALUES ($1, $2, $3, $4, NOW()) RETURNING id, created_at`
+	return tx.QueryRow(query, assignment.ScheduleID, assignment.StaffID, assignment.Duty, assignment.CreatedBy).
+		Scan(&assignment.ID, &assignment.CreatedAt)
+}
+
+func (r *crewAssignmentRepository) FindByID(id int) (*models.CrewAssignment, error) {
+	var assignment models.CrewAssignment
+	if err := r.db.Get(&assignment, crewAssignmentSelect+` WHERE ca.id = $1`, id); err != nil {
+		return nil, err
+	}
+	localizeAssignment(&assignment)
+	return &assignment, nil
+}
+
+// FindBySchedule mengembalikan kru satu jadwal, masinis lebih dulu.
+func (r *crewAssignmentRepository) FindBySchedule(scheduleID int) ([]models.CrewAssignment, error) {
+	assignments := []models.CrewAssignment{}
+	query := crewAssignmentSelect + ` WHERE ca.schedule_id = $1
+			  ORDER BY ca.duty = 'driver' DESC, st.name, ca.id`
+	if err := r.db.Select(&assignments, query, scheduleID); err != nil {
+		return nil, err
+	}
+	localizeAssignments(assignments)
+	return assignments, nil
+}
+
+// FindUpcomingByStaff mengembalikan tugas staf yang belum selesai pada
+// after, tanpa jadwal yang dibatalkan.
+func (r *crewAssignmentRepository) FindUpcomingByStaff(staffID int, after time.Time) ([]models.CrewAssignment, error) {
+	assignments := []models.CrewAssignment{}
+	query := crewAssignmentSelect + ` WHERE ca.staff_id = $1 AND s.arrival_time > $2 AND s.status != 'cancelled'
+			  ORDER BY s.departure_time, ca.id`
+	if err := r.db.Select(&assignments, query, staffID, after); err != nil {
+		return nil, err
+	}
+	localizeAssignments(assignments)
+	return assignments, nil
+}
+
+// FindByStaffInRange mengembalikan tugas staf di jadwal yang belum dibatalkan
+// dan beririsan dengan from sampai to.
+func (r *crewAssignmentRepository) FindByStaffInRange(staffID int, from, to time.Time, tx *sqlx.Tx) ([]models.CrewAssignment, error) {
+	assignments := []models.CrewAssignment{}
+	query := crewAssignmentSelect + ` WHERE ca.staff_id = $1 AND s.status != 'cancelled'
+			  AND s.departure_time < $3 AND s.arrival_time > $2
+			  ORDER BY s.departure_time, ca.id`
+	if err := tx.Select(&assignments, query, staffID, from, to); err != nil {
+		return nil, err
+	}
+	localizeAssignments(assignments)
+	return assignments, nil
+}
+
+func (r *crewAssignmentRepository) IsAssigned(scheduleID, staffID int, duty string) (bool, error) {
+	var assigned bool
+	query := `SELECT EXISTS (SELECT 1 FROM crew_assignments WHERE schedule_id = $1 AND staff_id = $2 AND duty = $3)`
+	err := r.db.Get(&assigned, query, scheduleID, staffID, duty)
+	return assigned, err
+}
+
+func (r *crewAssignmentRepository) Delete(id int) error {
+	_, err := r.db.Exec(`DELETE FROM crew_assignments WHERE id = $1`, id)
+	return err
+}
+
+func localizeAssignment(assignment *models.CrewAssignment) {
+	assignment.DepartureTime = assignment.DepartureTime.In(utils.Location(assignment.DepartureTimezone))
+	assignment.ArrivalTime = assignment.ArrivalTime.In(utils.Location(assignment.ArrivalTimezone))
+}
+
+func localizeAssignments(assignments []models.CrewAssignment) {
+	for i := range assignments {
+		localizeAssignment(&assignments[i])
+	}
+}
@@ -0,0 +1,53 @@
+package repository
+
+import (
+	"tiketsepur/models"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type JWTSigningKeyRepository interface {
+	Create(key *models.JWTSigningKey, tx *sqlx.Tx) error
+	FindUsable() ([]models.JWTSigningKey, error)
+	RetireActive(algorithm string, expiresAt time.Time, tx *sqlx.Tx) error
+	UpdatePrivateKey(kid, privateKey string) error
+}
+
+type jwtSigningKeyRepository struct {
+	db *sqlx.DB
+}
+
+func NewJWTSigningKeyRepository(db *sqlx.DB) JWTSigningKeyRepository {
+	return &jwtSigningKeyRepository{db: db}
+}
+
+func (r *jwtSigningKeyRepository) Create(key *models.JWTSigningKey, tx *sqlx.Tx) error {
+	query := `INSERT INTO jwt_signing_keys (kid, algorithm, private_key, created_at)
+			  VALUES ($1, $2, $3, NOW()) RETURNING created_at`
+	return tx.QueryRow(query, key.KID, key.Algorithm, key.PrivateKey).Scan(&key.CreatedAt)
+}
+
+// FindUsable mengembalikan key yang masih aktif atau yang sudah pensiun
+// tetapi masih dibutuhkan untuk memverifikasi token lama, terbaru lebih dulu.
+func (r *jwtSigningKeyRepository) FindUsable() ([]models.JWTSigningKey, error) {
+	var keys []models.JWTSigningKey
+	query := `SELECT * FROM jwt_signing_keys
+			  WHERE expires_at IS NULL OR expires_at > NOW()
+			  ORDER BY created_at DESC`
+	err := r.db.Select(&keys, query)
+	return keys, err
+}
+
+func (r *jwtSigningKeyRepository) RetireActive(algorithm string, expiresAt time.Time, tx *sqlx.Tx) error {
+	query := `UPDATE jwt_signing_keys SET retired_at = NOW(), expires_at = $1
+			  WHERE retired_at IS NULL AND algorithm = $2`
+	_, err := tx.Exec(query, expiresAt, algorithm)
+	return err
+}
+
+func (r *jwtSigningKeyRepository) UpdatePrivateKey(kid, privateKey string) error {
+	query := `UPDATE jwt_signing_keys SET private_key = $1 WHERE kid = $2`
+	_, err := r.db.Exec(query, privateKey, kid)
+	return err
+}
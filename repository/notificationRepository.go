@@ -0,0 +1,93 @@
+package repository
+
+import (
+	"tiketsepur/models"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type NotificationRepository interface {
+	FindPreference(userID int, notificationType string) (*models.NotificationPreference, error)
+	FindPreferencesByUser(userID int) ([]models.NotificationPreference, error)
+	UpsertPreference(pref *models.NotificationPreference) error
+	CreateDelivery(delivery *models.NotificationDelivery) error
+	HasSentDelivery(messageID, channel string) (bool, error)
+	FindDeliveriesByUser(userID int, qs QuerySpec) ([]models.NotificationDelivery, Page, error)
+}
+
+type notificationRepository struct {
+	db *sqlx.DB
+}
+
+func NewNotificationRepository(db *sqlx.DB) NotificationRepository {
+	return &notificationRepository{db: db}
+}
+
+func (r *notificationRepository) FindPreference(userID int, notificationType string) (*models.NotificationPreference, error) {
+	var pref models.NotificationPreference
+	query := `SELECT * FROM notification_preferences WHERE user_id = $1 AND notification_type = $2`
+	err := r.db.Get(&pref, query, userID, notificationType)
+	if err != nil {
+		return nil, err
+	}
+	return &pref, nil
+}
+
+func (r *notificationRepository) FindPreferencesByUser(userID int) ([]models.NotificationPreference, error) {
+	var prefs []models.NotificationPreference
+	query := `SELECT * FROM notification_preferences WHERE user_id = $1 ORDER BY notification_type`
+	err := r.db.Select(&prefs, query, userID)
+	return prefs, err
+}
+
+func (r *notificationRepository) UpsertPreference(pref *models.NotificationPreference) error {
+	query := `INSERT INTO notification_preferences (user_id, notification_type, channels, webhook_url, created_at, modified_at)
+			  VALUES ($1, $2, $3, $4, NOW(), NOW())
+			  ON CONFLICT (user_id, notification_type)
+			  DO UPDATE SET channels = EXCLUDED.channels, webhook_url = EXCLUDED.webhook_url, modified_at = NOW()
+			  RETURNING id, created_at, modified_at`
+	return r.db.QueryRow(query, pref.UserID, pref.NotificationType, pref.Channels,
+		pref.WebhookURL).Scan(&pref.ID, &pref.CreatedAt, &pref.ModifiedAt)
+}
+
+func (r *notificationRepository) CreateDelivery(delivery *models.NotificationDelivery) error {
+	query := `INSERT INTO notification_deliveries (message_id, user_id, notification_type, channel, recipient,
+			  booking_code, status, error, created_at, sent_at)
+			  VALUES ($1, $2, $3, $4, $5, $6, $7, $8, NOW(), $9) RETURNING id, created_at`
+	return r.db.QueryRow(query, delivery.MessageID, delivery.UserID, delivery.NotificationType, delivery.Channel,
+		delivery.Recipient, delivery.BookingCode, delivery.Status, delivery.Error,
+		delivery.SentAt).Scan(&delivery.ID, &delivery.CreatedAt)
+}
+
+func (r *notificationRepository) HasSentDelivery(messageID, channel string) (bool, error) {
+	var exists bool
+	query := `SELECT EXISTS (SELECT 1 FROM notification_deliveries
+			  WHERE message_id = $1 AND channel = $2 AND status = 'sent')`
+	err := r.db.Get(&exists, query, messageID, channel)
+	return exists, err
+}
+
+var deliveryListSpec = listSpec{
+	sorts: map[string]sortField{
+		"created_at": {Column: "created_at", Field: "created_at", Cast: "timestamp"},
+	},
+	defaultSort: "created_at",
+	defaultDesc: true,
+	idColumn:    "id",
+}
+
+// FindDeliveriesByUser mendukung filter status pengiriman dan rentang tanggal.
+func (r *notificationRepository) FindDeliveriesByUser(userID int, qs QuerySpec) ([]models.NotificationDelivery, Page, error) {
+	var deliveries []models.NotificationDelivery
+	from := `FROM notification_deliveries`
+
+	filters := &listQuery{}
+	filters.add("user_id = ?", userID)
+	if qs.Status != "" {
+		filters.add("status = ?", qs.Status)
+	}
+	filters.dateRange("created_at", qs)
+
+	page, err := selectPage(r.db, &deliveries, deliveryListSpec, qs, `SELECT * `+from, from, filters)
+	return deliveries, page, err
+}
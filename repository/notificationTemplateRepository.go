@@ -0,0 +1,91 @@
+package repository
+
+import (
+	"tiketsepur/models"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type NotificationTemplateRepository interface {
+	Create(tpl *models.NotificationTemplate, tx *sqlx.Tx) error
+	FindByID(id int) (*models.NotificationTemplate, error)
+	FindActive(notificationType, language string) (*models.NotificationTemplate, error)
+	FindAll(notificationType, language string) ([]models.NotificationTemplate, error)
+	Activate(tpl *models.NotificationTemplate, tx *sqlx.Tx) error
+}
+
+type notificationTemplateRepository struct {
+	db *sqlx.DB
+}
+
+func NewNotificationTemplateRepository(db *sqlx.DB) NotificationTemplateRepository {
+	return &notificationTemplateRepository{db: db}
+}
+
+// Create menyimpan template sebagai versi berikutnya untuk tipe dan bahasa
+// yang sama. Advisory lock per tipe dan bahasa mencegah dua request
+// mendapatkan nomor versi yang sama.
+func (r *notificationTemplateRepository) Create(tpl *models.NotificationTemplate, tx *sqlx.Tx) error {
+	lockKey := "notification_template:" + tpl.NotificationType + ":" + tpl.Language
+	if _, err := tx.Exec(`SELECT pg_advisory_xact_lock(hashtext($1))`, lockKey); err != nil {
+		return err
+	}
+
+	var version int
+	versionQuery := `SELECT COALESCE(MAX(version), 0) FROM notification_templates
+					 WHERE notification_type = $1 AND language = $2`
+	if err := tx.Get(&version, versionQuery, tpl.NotificationType, tpl.Language); err != nil {
+		return err
+	}
+	tpl.Version = version + 1
+
+	query := `INSERT INTO notification_templates (notification_type, language, version, subject,
+			  body_text, body_html, body_short, is_active, created_by, created_at)
+			  VALUES ($1, $2, $3, $4, $5, $6, $7, FALSE, $8, NOW()) RETURNING id, created_at`
+	return tx.QueryRow(query, tpl.NotificationType, tpl.Language, tpl.Version, tpl.Subject,
+		tpl.BodyText, tpl.BodyHTML, tpl.BodyShort, tpl.CreatedBy).Scan(&tpl.ID, &tpl.CreatedAt)
+}
+
+func (r *notificationTemplateRepository) FindByID(id int) (*models.NotificationTemplate, error) {
+	var tpl models.NotificationTemplate
+	query := `SELECT * FROM notification_templates WHERE id = $1`
+	err := r.db.Get(&tpl, query, id)
+	if err != nil {
+		return nil, err
+	}
+	return &tpl, nil
+}
+
+func (r *notificationTemplateRepository) FindActive(notificationType, language string) (*models.NotificationTemplate, error) {
+	var tpl models.NotificationTemplate
+	query := `SELECT * FROM notification_templates WHERE notification_type = $1 AND language = $2 AND is_active`
+	err := r.db.Get(&tpl, query, notificationType, language)
+	if err != nil {
+		return nil, err
+	}
+	return &tpl, nil
+}
+
+func (r *notificationTemplateRepository) FindAll(notificationType, language string) ([]models.NotificationTemplate, error) {
+	var templates []models.NotificationTemplate
+	query := `SELECT * FROM notification_templates
+			  WHERE ($1 = '' OR notification_type = $1) AND ($2 = '' OR language = $2)
+			  ORDER BY notification_type, language, version DESC`
+	err := r.db.Select(&templates, query, notificationType, language)
+	return templates, err
+}
+
+func (r *notificationTemplateRepository) Activate(tpl *models.NotificationTemplate, tx *sqlx.Tx) error {
+	deactivate := `UPDATE notification_templates SET is_active = FALSE
+				   WHERE notification_type = $1 AND language = $2 AND is_active`
+	if _, err := tx.Exec(deactivate, tpl.NotificationType, tpl.Language); err != nil {
+		return err
+	}
+
+	activate := `UPDATE notification_templates SET is_active = TRUE WHERE id = $1`
+	if _, err := tx.Exec(activate, tpl.ID); err != nil {
+		return err
+	}
+	tpl.IsActive = true
+	return nil
+}
@@ -0,0 +1,76 @@
+package repository
+
+import (
+	"sort"
+	"tiketsepur/models"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type OutboxRepository interface {
+	Create(msg *models.OutboxMessage, tx *sqlx.Tx) error
+	ClaimPending(limit int, leaseUntil time.Time, tx *sqlx.Tx) ([]models.OutboxMessage, error)
+	MarkSent(id int64, tx *sqlx.Tx) error
+	MarkRetry(id int64, nextAttemptAt time.Time, lastError string, tx *sqlx.Tx) error
+	DeleteSentBefore(before time.Time) (int64, error)
+}
+
+type outboxRepository struct {
+	db *sqlx.DB
+}
+
+func NewOutboxRepository(db *sqlx.DB) OutboxRepository {
+	return &outboxRepository{db: db}
+}
+
+func (r *outboxRepository) Create(msg *models.OutboxMessage, tx *sqlx.Tx) error {
+	query := `INSERT INTO outbox (aggregate_type, aggregate_id, event_type, payload, status, next_attempt_at, created_at)
+			  VALUES ($1, $2, $3, $4, 'pending', NOW(), NOW()) RETURNING id, status, next_attempt_at, created_at`
+	return tx.QueryRow(query, msg.AggregateType, msg.AggregateID, msg.EventType,
+		msg.Payload).Scan(&msg.ID, &msg.Status, &msg.NextAttemptAt, &msg.CreatedAt)
+}
+
+// ClaimPending mengambil baris yang sudah waktunya dikirim dan memundurkan
+// next_attempt_at-nya ke leaseUntil. Setelah transaksi commit, relay lain tidak
+// mengambil baris yang sama sampai lease habis, jadi kunci baris tidak perlu
+// ditahan selama publish. SKIP LOCKED membuat beberapa relay bisa klaim
+// bersamaan.
+func (r *outboxRepository) ClaimPending(limit int, leaseUntil time.Time, tx *sqlx.Tx) ([]models.OutboxMessage, error) {
+	var messages []models.OutboxMessage
+	query := `UPDATE outbox SET next_attempt_at = $2
+			  WHERE id IN (
+				  SELECT id FROM outbox
+				  WHERE status = 'pending' AND next_attempt_at <= NOW()
+				  ORDER BY id
+				  LIMIT $1
+				  FOR UPDATE SKIP LOCKED
+			  )
+			  RETURNING *`
+	if err := tx.Select(&messages, query, limit, leaseUntil); err != nil {
+		return nil, err
+	}
+	sort.Slice(messages, func(i, j int) bool { return messages[i].ID < messages[j].ID })
+	return messages, nil
+}
+
+func (r *outboxRepository) MarkSent(id int64, tx *sqlx.Tx) error {
+	query := `UPDATE outbox SET status = 'sent', attempts = attempts + 1, last_error = NULL, sent_at = NOW() WHERE id = $1`
+	_, err := tx.Exec(query, id)
+	return err
+}
+
+func (r *outboxRepository) MarkRetry(id int64, nextAttemptAt time.Time, lastError string, tx *sqlx.Tx) error {
+	query := `UPDATE outbox SET attempts = attempts + 1, last_error = $1, next_attempt_at = $2 WHERE id = $3`
+	_, err := tx.Exec(query, lastError, nextAttemptAt, id)
+	return err
+}
+
+func (r *outboxRepository) DeleteSentBefore(before time.Time) (int64, error) {
+	query := `DELETE FROM outbox WHERE status = 'sent' AND sent_at < $1`
+	result, err := r.db.Exec(query, before)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
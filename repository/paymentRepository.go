@@ -14,6 +14,8 @@ type PaymentRepository interface {
 	UpdateStatus(id int, status string) error
 	UpdateStatusTx(id int, status string, tx *sqlx.Tx) error
 	UpdateStatusTxByTicketID(ticketID int, status string, tx *sqlx.Tx) error
+	RefundByTicketID(ticketID int, tx *sqlx.Tx) (*models.Payment, error)
+	CancelPendingByTicketID(ticketID int, tx *sqlx.Tx) error
 }
 
 type paymentRepository struct {
@@ -70,15 +72,41 @@ func (r *paymentRepository) UpdateStatus(id int, status string) error {
 }
 
 func (r *paymentRepository) UpdateStatusTx(id int, status string, tx *sqlx.Tx) error {
-    query := `UPDATE payments 
+	query := `UPDATE payments 
               SET payment_status = $1, paid_at = NOW(), modified_at = NOW() 
               WHERE id = $2`
-    _, err := tx.Exec(query, status, id)
-    return err
+	_, err := tx.Exec(query, status, id)
+	return err
 }
 
 func (r *paymentRepository) UpdateStatusTxByTicketID(ticketID int, status string, tx *sqlx.Tx) error {
 	query := `UPDATE payments SET payment_status = $1, modified_at = NOW() WHERE ticket_id = $2`
 	_, err := tx.Exec(query, status, ticketID)
 	return err
-}
\ No newline at end of file
+}
+
+// RefundByTicketID mengembalikan penuh payment yang sudah sukses dan
+// membatalkan payment yang belum dibayar.
+func (r *paymentRepository) RefundByTicketID(ticketID int, tx *sqlx.Tx) (*models.Payment, error) {
+	var payment models.Payment
+	query := `UPDATE payments SET
+			  payment_status = CASE WHEN payment_status = 'success' THEN 'refunded' ELSE 'cancelled' END,
+			  refunded_at = CASE WHEN payment_status = 'success' THEN NOW() ELSE refunded_at END,
+			  modified_at = NOW()
+			  WHERE ticket_id = $1 AND payment_status IN ('success', 'pending')
+			  RETURNING *`
+	err := tx.Get(&payment, query, ticketID)
+	if err != nil {
+		return nil, err
+	}
+	return &payment, nil
+}
+
+// CancelPendingByTicketID membatalkan payment yang belum dibayar. Payment yang
+// sudah sukses tidak disentuh.
+func (r *paymentRepository) CancelPendingByTicketID(ticketID int, tx *sqlx.Tx) error {
+	query := `UPDATE payments SET payment_status = 'cancelled', modified_at = NOW()
+			  WHERE ticket_id = $1 AND payment_status = 'pending'`
+	_, err := tx.Exec(query, ticketID)
+	return err
+}
@@ -0,0 +1,243 @@
+package repository
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+const (
+	DefaultPageLimit = 20
+	MaxPageLimit     = 100
+)
+
+var ErrInvalidQuerySpec = errors.New("parameter query tidak valid")
+
+// QuerySpec adalah parameter list yang dipakai bersama semua repository.
+// Jika Cursor diisi, pagination memakai keyset dan Offset diabaikan. Filter
+// yang tidak didukung sebuah repository diabaikan.
+type QuerySpec struct {
+	Limit  int
+	Offset int
+	Cursor string
+	Sort   string
+	Desc   *bool
+
+	Status     string
+	Role       string
+	TrainType  string
+	TrainID    int
+	UserID     int
+	ScheduleID int
+	From       *time.Time
+	To         *time.Time
+	Search     string
+
+	Qualification string
+}
+
+// Page adalah metadata pagination yang dikembalikan bersama hasil list.
+type Page struct {
+	Total      int    `json:"total"`
+	Limit      int    `json:"limit"`
+	Offset     int    `json:"offset"`
+	HasMore    bool   `json:"has_more"`
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
+// sortField memetakan nama sort di API ke kolom sql. Field adalah tag db di
+// struct hasil yang nilainya dipakai untuk cursor, dan Cast adalah tipe
+// postgres nilai cursor tersebut.
+type sortField struct {
+	Column string
+	Field  string
+	Cast   string
+}
+
+// listSpec adalah whitelist sort untuk satu repository.
+type listSpec struct {
+	sorts       map[string]sortField
+	defaultSort string
+	defaultDesc bool
+	idColumn    string
+}
+
+type cursorValue struct {
+	Sort  string `json:"s"`
+	Value string `json:"v"`
+	ID    int    `json:"id"`
+}
+
+// listQuery mengumpulkan kondisi WHERE dengan placeholder ? yang diubah ke
+// format postgres lewat Rebind.
+type listQuery struct {
+	where []string
+	args  []interface{}
+}
+
+func (q *listQuery) add(condition string, args ...interface{}) {
+	q.where = append(q.where, condition)
+	q.args = append(q.args, args...)
+}
+
+func (q *listQuery) clause() string {
+	if len(q.where) == 0 {
+		return ""
+	}
+	return " WHERE " + strings.Join(q.where, " AND ")
+}
+
+// selectPage menjalankan query count dan query halaman. base adalah SELECT
+// tanpa WHERE/ORDER BY dan from adalah bagian FROM ... JOIN ... yang sama
+// untuk query count.
+func selectPage(db *sqlx.DB, dest interface{}, spec listSpec, qs QuerySpec, base, from string, filters *listQuery) (Page, error) {
+	page := Page{Limit: qs.Limit, Offset: qs.Offset}
+	if page.Limit <= 0 {
+		page.Limit = DefaultPageLimit
+	}
+	if page.Limit > MaxPageLimit {
+		page.Limit = MaxPageLimit
+	}
+
+	sortName := qs.Sort
+	if sortName == "" {
+		sortName = spec.defaultSort
+	}
+	sort, ok := spec.sorts[sortName]
+	if !ok {
+		return page, fmt.Errorf("%w: sort %q tidak didukung", ErrInvalidQuerySpec, qs.Sort)
+	}
+	desc := spec.defaultDesc
+	if qs.Desc != nil {
+		desc = *qs.Desc
+	}
+
+	var cursor cursorValue
+	if qs.Cursor != "" {
+		var err error
+		cursor, err = decodeCursor(qs.Cursor)
+		if err != nil || cursor.Sort != sortName {
+			return page, fmt.Errorf("%w: cursor tidak valid", ErrInvalidQuerySpec)
+		}
+	}
+
+	countQuery := "SELECT COUNT(*) " + from + filters.clause()
+	if err := db.Get(&page.Total, db.Rebind(countQuery), filters.args...); err != nil {
+		return page, err
+	}
+
+	pageQuery := listQuery{where: append([]string(nil), filters.where...), args: append([]interface{}(nil), filters.args...)}
+	if qs.Cursor != "" {
+		op := ">"
+		if desc {
+			op = "<"
+		}
+		pageQuery.add(fmt.Sprintf("(%s, %s) %s (?::%s, ?)", sort.Column, spec.idColumn, op, sort.Cast), cursor.Value, cursor.ID)
+		page.Offset = 0
+	}
+
+	direction := "ASC"
+	if desc {
+		direction = "DESC"
+	}
+	query := fmt.Sprintf("%s%s ORDER BY %s %s, %s %s LIMIT ?", base, pageQuery.clause(), sort.Column, direction, spec.idColumn, direction)
+	args := append(pageQuery.args, page.Limit+1)
+	if qs.Cursor == "" {
+		query += " OFFSET ?"
+		args = append(args, page.Offset)
+	}
+
+	if err := db.Select(dest, db.Rebind(query), args...); err != nil {
+		return page, err
+	}
+
+	err := trimPage(dest, &page, sortName, sort)
+	return page, err
+}
+
+// trimPage membuang baris ekstra dari hasil query LIMIT page.Limit+1. Baris
+// ekstra itu hanya penanda bahwa masih ada halaman berikutnya, dan cursor
+// halaman berikutnya dibuat dari baris terakhir yang dikembalikan.
+func trimPage(dest interface{}, page *Page, sortName string, sort sortField) error {
+	rows := reflect.ValueOf(dest).Elem()
+	if rows.Len() > page.Limit {
+		rows.Set(rows.Slice(0, page.Limit))
+		page.HasMore = true
+
+		cursor, err := encodeCursor(sortName, sort.Field, rows.Index(page.Limit-1))
+		if err != nil {
+			return err
+		}
+		page.NextCursor = cursor
+	}
+	if rows.IsNil() {
+		rows.Set(reflect.MakeSlice(rows.Type(), 0, 0))
+	}
+	return nil
+}
+
+func encodeCursor(sortName, field string, row reflect.Value) (string, error) {
+	value, ok := fieldByDBTag(row, field)
+	if !ok {
+		return "", fmt.Errorf("field %s tidak ditemukan untuk cursor", field)
+	}
+	id, ok := fieldByDBTag(row, "id")
+	if !ok {
+		return "", errors.New("field id tidak ditemukan untuk cursor")
+	}
+
+	cursor := cursorValue{Sort: sortName, ID: int(id.Int())}
+	switch v := value.Interface().(type) {
+	case time.Time:
+		cursor.Value = v.Format(time.RFC3339Nano)
+	default:
+		cursor.Value = fmt.Sprint(v)
+	}
+
+	body, err := json.Marshal(cursor)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(body), nil
+}
+
+func decodeCursor(raw string) (cursorValue, error) {
+	var cursor cursorValue
+	body, err := base64.RawURLEncoding.DecodeString(raw)
+	if err != nil {
+		return cursor, err
+	}
+	err = json.Unmarshal(body, &cursor)
+	return cursor, err
+}
+
+func fieldByDBTag(row reflect.Value, tag string) (reflect.Value, bool) {
+	t := row.Type()
+	for i := 0; i < t.NumField(); i++ {
+		if t.Field(i).Tag.Get("db") == tag {
+			return row.Field(i), true
+		}
+	}
+	return reflect.Value{}, false
+}
+
+// dateRange menerapkan filter From (inklusif) dan To (eksklusif) pada kolom.
+func (q *listQuery) dateRange(column string, qs QuerySpec) {
+	if qs.From != nil {
+		q.add(column+" >= ?", *qs.From)
+	}
+	if qs.To != nil {
+		q.add(column+" < ?", *qs.To)
+	}
+}
+
+func likePattern(search string) string {
+	replacer := strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+	return "%" + replacer.Replace(search) + "%"
+}
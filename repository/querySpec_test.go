@@ -0,0 +1,149 @@
+package repository
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type cursorRow struct {
+	ID            int       `db:"id"`
+	Name          string    `db:"name"`
+	DepartureTime time.Time `db:"departure_time"`
+}
+
+var testSorts = map[string]sortField{
+	"name":           {Column: "name", Field: "name", Cast: "text"},
+	"departure_time": {Column: "s.departure_time", Field: "departure_time", Cast: "timestamptz"},
+}
+
+func TestCursorRoundTripKeepsTimezoneOffset(t *testing.T) {
+	wita := time.FixedZone("WITA", 8*3600)
+	departure := time.Date(2026, 11, 3, 8, 15, 30, 123000000, wita)
+	row := cursorRow{ID: 42, DepartureTime: departure}
+
+	encoded, err := encodeCursor("departure_time", "departure_time", reflect.ValueOf(row))
+	if err != nil {
+		t.Fatal(err)
+	}
+	cursor, err := decodeCursor(encoded)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if cursor.Sort != "departure_time" || cursor.ID != 42 {
+		t.Fatalf("cursor = %+v", cursor)
+	}
+	// nilai dibandingkan dengan kolom timestamptz, jadi offset harus ikut
+	parsed, err := time.Parse(time.RFC3339Nano, cursor.Value)
+	if err != nil {
+		t.Fatalf("nilai cursor bukan RFC3339: %v", err)
+	}
+	if !parsed.Equal(departure) {
+		t.Fatalf("waktu cursor = %v, want %v", parsed, departure)
+	}
+}
+
+func TestCursorRoundTripText(t *testing.T) {
+	encoded, err := encodeCursor("name", "name", reflect.ValueOf(cursorRow{ID: 7, Name: "Gambir"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	cursor, err := decodeCursor(encoded)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cursor != (cursorValue{Sort: "name", Value: "Gambir", ID: 7}) {
+		t.Fatalf("cursor = %+v", cursor)
+	}
+}
+
+func TestEncodeCursorUnknownField(t *testing.T) {
+	if _, err := encodeCursor("price", "price", reflect.ValueOf(cursorRow{ID: 1})); err == nil {
+		t.Fatal("field yang tidak ada di struct seharusnya error")
+	}
+}
+
+func TestDecodeCursorRejectsGarbage(t *testing.T) {
+	for _, raw := range []string{"bukan base64!", "e30", "bm90LWpzb24"} {
+		cursor, err := decodeCursor(raw)
+		if err == nil && cursor.Sort != "" {
+			t.Errorf("decodeCursor(%q) = %+v", raw, cursor)
+		}
+	}
+}
+
+func TestTrimPageWithExtraRow(t *testing.T) {
+	rows := []cursorRow{{ID: 1, Name: "A"}, {ID: 2, Name: "B"}, {ID: 3, Name: "C"}}
+	page := Page{Limit: 2}
+
+	if err := trimPage(&rows, &page, "name", testSorts["name"]); err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 2 {
+		t.Fatalf("len(rows) = %d, baris ekstra seharusnya dibuang", len(rows))
+	}
+	if !page.HasMore || page.NextCursor == "" {
+		t.Fatalf("page = %+v, seharusnya masih ada halaman berikutnya", page)
+	}
+
+	// cursor halaman berikutnya menunjuk baris terakhir yang dikembalikan,
+	// bukan baris ekstra
+	cursor, err := decodeCursor(page.NextCursor)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cursor.ID != 2 || cursor.Value != "B" {
+		t.Fatalf("cursor = %+v, want baris id 2", cursor)
+	}
+}
+
+func TestTrimPageExactlyLimit(t *testing.T) {
+	rows := []cursorRow{{ID: 1, Name: "A"}, {ID: 2, Name: "B"}}
+	page := Page{Limit: 2}
+
+	if err := trimPage(&rows, &page, "name", testSorts["name"]); err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 2 || page.HasMore || page.NextCursor != "" {
+		t.Fatalf("rows = %d, page = %+v, seharusnya halaman terakhir", len(rows), page)
+	}
+}
+
+func TestTrimPageEmptyResultIsNotNil(t *testing.T) {
+	var rows []cursorRow
+	page := Page{Limit: 20}
+
+	if err := trimPage(&rows, &page, "name", testSorts["name"]); err != nil {
+		t.Fatal(err)
+	}
+	if rows == nil {
+		t.Fatal("hasil kosong harus slice kosong supaya di-encode sebagai [] bukan null")
+	}
+	if page.HasMore {
+		t.Fatal("hasil kosong tidak punya halaman berikutnya")
+	}
+}
+
+func TestSelectPageRejectsInvalidSortAndCursor(t *testing.T) {
+	spec := listSpec{sorts: testSorts, defaultSort: "name", idColumn: "id"}
+	nameCursor, err := encodeCursor("name", "name", reflect.ValueOf(cursorRow{ID: 1, Name: "A"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, qs := range []QuerySpec{
+		{Sort: "password"},
+		{Cursor: "bukan-cursor"},
+		// cursor dari sort lain tidak boleh dipakai
+		{Sort: "departure_time", Cursor: nameCursor},
+	} {
+		var rows []cursorRow
+		// validasi terjadi sebelum query, jadi db nil tidak pernah dipakai
+		_, err := selectPage(nil, &rows, spec, qs, "SELECT *", "FROM t", &listQuery{})
+		if !errors.Is(err, ErrInvalidQuerySpec) {
+			t.Errorf("selectPage(%+v) error = %v, want ErrInvalidQuerySpec", qs, err)
+		}
+	}
+}
@@ -0,0 +1,134 @@
+package repository
+
+import (
+	"errors"
+	"tiketsepur/models"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+type RoleRepository interface {
+	Create(role *models.Role, tx *sqlx.Tx) error
+	FindByID(id int) (*models.Role, error)
+	FindByName(name string) (*models.Role, error)
+	FindAll(qs QuerySpec) ([]models.Role, Page, error)
+	Delete(id int) error
+	CountUsers(name string) (int, error)
+	FindAllPermissions() ([]models.Permission, error)
+	FindPermissionsByRole(name string) ([]string, error)
+	ReplacePermissions(roleID int, codes []string, tx *sqlx.Tx) error
+}
+
+type roleRepository struct {
+	db *sqlx.DB
+}
+
+func NewRoleRepository(db *sqlx.DB) RoleRepository {
+	return &roleRepository{db: db}
+}
+
+func (r *roleRepository) Create(role *models.Role, tx *sqlx.Tx) error {
+	query := `INSERT INTO roles (name, description, created_at, modified_at)
+			  VALUES ($1, $2, NOW(), NOW()) RETURNING id, created_at, modified_at`
+	return tx.QueryRow(query, role.Name, role.Description).Scan(&role.ID, &role.CreatedAt, &role.ModifiedAt)
+}
+
+func (r *roleRepository) FindByID(id int) (*models.Role, error) {
+	var role models.Role
+	query := `SELECT * FROM roles WHERE id = $1`
+	err := r.db.Get(&role, query, id)
+	if err != nil {
+		return nil, err
+	}
+	return &role, nil
+}
+
+func (r *roleRepository) FindByName(name string) (*models.Role, error) {
+	var role models.Role
+	query := `SELECT * FROM roles WHERE name = $1`
+	err := r.db.Get(&role, query, name)
+	if err != nil {
+		return nil, err
+	}
+	return &role, nil
+}
+
+var roleListSpec = listSpec{
+	sorts: map[string]sortField{
+		"name":       {Column: "name", Field: "name", Cast: "text"},
+		"created_at": {Column: "created_at", Field: "created_at", Cast: "timestamp"},
+		"id":         {Column: "id", Field: "id", Cast: "int"},
+	},
+	defaultSort: "name",
+	idColumn:    "id",
+}
+
+// FindAll mendukung pencarian nama role.
+func (r *roleRepository) FindAll(qs QuerySpec) ([]models.Role, Page, error) {
+	var roles []models.Role
+	from := `FROM roles`
+
+	filters := &listQuery{}
+	if qs.Search != "" {
+		filters.add("name ILIKE ?", likePattern(qs.Search))
+	}
+
+	page, err := selectPage(r.db, &roles, roleListSpec, qs, `SELECT * `+from, from, filters)
+	return roles, page, err
+}
+
+func (r *roleRepository) Delete(id int) error {
+	query := `DELETE FROM roles WHERE id = $1`
+	_, err := r.db.Exec(query, id)
+	return err
+}
+
+func (r *roleRepository) CountUsers(name string) (int, error) {
+	var count int
+	query := `SELECT COUNT(*) FROM users WHERE role = $1`
+	err := r.db.Get(&count, query, name)
+	return count, err
+}
+
+func (r *roleRepository) FindAllPermissions() ([]models.Permission, error) {
+	var permissions []models.Permission
+	query := `SELECT * FROM permissions ORDER BY code ASC`
+	err := r.db.Select(&permissions, query)
+	return permissions, err
+}
+
+func (r *roleRepository) FindPermissionsByRole(name string) ([]string, error) {
+	permissions := []string{}
+	query := `SELECT p.code FROM permissions p
+			  JOIN role_permissions rp ON rp.permission_id = p.id
+			  JOIN roles r ON rp.role_id = r.id
+			  WHERE r.name = $1
+			  ORDER BY p.code ASC`
+	err := r.db.Select(&permissions, query, name)
+	return permissions, err
+}
+
+func (r *roleRepository) ReplacePermissions(roleID int, codes []string, tx *sqlx.Tx) error {
+	if _, err := tx.Exec(`DELETE FROM role_permissions WHERE role_id = $1`, roleID); err != nil {
+		return err
+	}
+
+	if len(codes) == 0 {
+		return nil
+	}
+
+	query := `INSERT INTO role_permissions (role_id, permission_id)
+			  SELECT $1, id FROM permissions WHERE code = ANY($2)`
+	result, err := tx.Exec(query, roleID, pq.Array(codes))
+	if err != nil {
+		return err
+	}
+	rows, _ := result.RowsAffected()
+	if int(rows) != len(codes) {
+		return ErrUnknownPermission
+	}
+	return nil
+}
+
+var ErrUnknownPermission = errors.New("permission tidak dikenal")
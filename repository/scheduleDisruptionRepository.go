@@ -0,0 +1,36 @@
+package repository
+
+import (
+	"tiketsepur/models"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type ScheduleDisruptionRepository interface {
+	Create(disruption *models.ScheduleDisruption, tx *sqlx.Tx) error
+	FindBySchedule(scheduleID int) ([]models.ScheduleDisruption, error)
+}
+
+type scheduleDisruptionRepository struct {
+	db *sqlx.DB
+}
+
+func NewScheduleDisruptionRepository(db *sqlx.DB) ScheduleDisruptionRepository {
+	return &scheduleDisruptionRepository{db: db}
+}
+
+func (r *scheduleDisruptionRepository) Create(disruption *models.ScheduleDisruption, tx *sqlx.Tx) error {
+	query := `INSERT INTO schedule_disruptions (schedule_id, disruption_type, delay_minutes, old_platform,
+			  new_platform, reason, affected_tickets, created_by, created_at)
+			  VALUES ($1, $2, $3, $4, $5, $6, $7, $8, NOW()) RETURNING id, created_at`
+	return tx.QueryRow(query, disruption.ScheduleID, disruption.DisruptionType, disruption.DelayMinutes,
+		disruption.OldPlatform, disruption.NewPlatform, disruption.Reason, disruption.AffectedTickets,
+		disruption.CreatedBy).Scan(&disruption.ID, &disruption.CreatedAt)
+}
+
+func (r *scheduleDisruptionRepository) FindBySchedule(scheduleID int) ([]models.ScheduleDisruption, error) {
+	disruptions := []models.ScheduleDisruption{}
+	query := `SELECT * FROM schedule_disruptions WHERE schedule_id = $1 ORDER BY created_at DESC, id DESC`
+	err := r.db.Select(&disruptions, query, scheduleID)
+	return disruptions, err
+}
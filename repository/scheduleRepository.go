@@ -2,22 +2,51 @@ package repository
 
 import (
 	"errors"
+	"fmt"
 	"tiketsepur/models"
+	"tiketsepur/utils"
+	"time"
 
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 )
 
 type ScheduleRepository interface {
-	Create(schedule *models.Schedule) error
+	Create(schedule *models.Schedule, tx *sqlx.Tx) error
 	FindByID(id int) (*models.Schedule, error)
-	FindAll() ([]models.Schedule, error)
-	Search(departure, arrival, date string) ([]models.Schedule, error)
-	Update(id int, schedule *models.Schedule) error
-	Delete(id int) error
+	FindByIDForUpdate(id int, tx *sqlx.Tx) (*models.Schedule, error)
+	FindAll(qs QuerySpec) ([]models.Schedule, Page, error)
+	Search(criteria ScheduleSearch) ([]models.Schedule, error)
+	Update(id int, schedule *models.Schedule, tx *sqlx.Tx) error
+	Delete(id int, tx *sqlx.Tx) error
+	FindAlternatives(schedule *models.Schedule, limit int) ([]models.Schedule, error)
+	FareCalendar(departure, arrival, monthStart string) ([]models.FareCalendarDay, error)
+	UpdateOperationalStatus(schedule *models.Schedule, tx *sqlx.Tx) (bool, error)
 	DecrementSeat(id int, tx *sqlx.Tx) error
 	IncrementSeat(id int, tx *sqlx.Tx) error
+	FindOverlapping(trainID int, departure, arrival time.Time, excludeID int) ([]models.Schedule, error)
+	FindOverlappingInTx(trainID int, departure, arrival time.Time, excludeID int, tx *sqlx.Tx) ([]models.Schedule, error)
+	FindViolations() ([]models.ScheduleViolation, error)
+	FindExceedingCapacity(trainID, capacity int, tx *sqlx.Tx) ([]models.Schedule, error)
+	RelocalizeLegacyTimes(station, timezone string, tx *sqlx.Tx) ([]int, error)
+	ClearLegacyTimes(id int, tx *sqlx.Tx) error
 }
 
+// Zona waktu stasiun diambil lewat LEFT JOIN ke stations sehingga jadwal
+// dengan stasiun yang belum terdaftar tetap muncul memakai DefaultTimezone.
+const (
+	departureStationJoin = `
+			LEFT JOIN stations sd ON LOWER(sd.name) = LOWER(s.departure_station)`
+	departureTimezoneColumn = `COALESCE(sd.timezone, '` + utils.DefaultTimezone + `') AS departure_timezone`
+
+	scheduleStationJoins = departureStationJoin + `
+			LEFT JOIN stations sa ON LOWER(sa.name) = LOWER(s.arrival_station)`
+	scheduleTimezoneColumns = departureTimezoneColumn + `,
+			COALESCE(sa.timezone, '` + utils.DefaultTimezone + `') AS arrival_timezone`
+	// waktu berangkat sebagai jam dinding di stasiun keberangkatan
+	scheduleLocalDeparture = `(s.departure_time AT TIME ZONE COALESCE(sd.timezone, '` + utils.DefaultTimezone + `'))`
+)
+
 type scheduleRepository struct {
 	db *sqlx.DB
 }
@@ -26,89 +55,320 @@ func NewScheduleRepository(db *sqlx.DB) ScheduleRepository {
 	return &scheduleRepository{db: db}
 }
 
-func (r *scheduleRepository) Create(schedule *models.Schedule) error {
+func (r *scheduleRepository) Create(schedule *models.Schedule, tx *sqlx.Tx) error {
 	query := `INSERT INTO schedules (train_id, departure_station, arrival_station, 
-			  departure_time, arrival_time, price, available_seats, created_at) 
-			  VALUES ($1, $2, $3, $4, $5, $6, $7, NOW()) RETURNING id`
-	return r.db.QueryRow(query, schedule.TrainID, schedule.DepartureStation,
+			  departure_time, arrival_time, price, available_seats, platform, template_id, created_at) 
+			  VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, NOW()) RETURNING id, status`
+	return tx.QueryRow(query, schedule.TrainID, schedule.DepartureStation,
 		schedule.ArrivalStation, schedule.DepartureTime, schedule.ArrivalTime,
-		schedule.Price, schedule.AvailableSeats).Scan(&schedule.ID)
+		schedule.Price, schedule.AvailableSeats, schedule.Platform, schedule.TemplateID).Scan(&schedule.ID, &schedule.Status)
 }
 
 func (r *scheduleRepository) FindByID(id int) (*models.Schedule, error) {
 	var schedule models.Schedule
 	query := `SELECT s.id, s.train_id, s.departure_station, s.arrival_station, 
-				s.departure_time, s.arrival_time, s.price, s.available_seats, 
-				s.created_at, s.modified_at,
+				s.departure_time, s.arrival_time, s.price, s.available_seats, s.platform, s.status, s.delay_minutes, s.template_id,
+			` + scheduleTimezoneColumns + `,
+				(EXTRACT(EPOCH FROM (s.arrival_time - s.departure_time)) / 60)::int AS duration_minutes,
+			s.created_at, s.modified_at,
 				t.id as train_id, t.train_code, t.train_name, t.train_type
 				FROM schedules s
-				JOIN trains t ON s.train_id = t.id
+				JOIN trains t ON s.train_id = t.id` + scheduleStationJoins + `
 				WHERE s.id = $1`
 	err := r.db.Get(&schedule, query, id)
 	if err != nil {
 		return nil, err
 	}
+	localizeSchedule(&schedule)
 	return &schedule, nil
 }
 
-func (r *scheduleRepository) FindAll() ([]models.Schedule, error) {
+var scheduleListSpec = listSpec{
+	sorts: map[string]sortField{
+		"departure_time":  {Column: "s.departure_time", Field: "departure_time", Cast: "timestamptz"},
+		"price":           {Column: "s.price", Field: "price", Cast: "numeric"},
+		"available_seats": {Column: "s.available_seats", Field: "available_seats", Cast: "int"},
+		"created_at":      {Column: "s.created_at", Field: "created_at", Cast: "timestamp"},
+		"id":              {Column: "s.id", Field: "id", Cast: "int"},
+	},
+	defaultSort: "departure_time",
+	idColumn:    "s.id",
+}
+
+// FindByIDForUpdate mengunci baris jadwal sampai transaksi selesai sehingga
+// booking dan pembatalan yang berjalan bersamaan menunggu.
+func (r *scheduleRepository) FindByIDForUpdate(id int, tx *sqlx.Tx) (*models.Schedule, error) {
+	var schedule models.Schedule
+	query := `SELECT s.id, s.train_id, s.departure_station, s.arrival_station,
+			  s.departure_time, s.arrival_time, s.price, s.available_seats, s.platform, s.status, s.delay_minutes, s.template_id,
+			` + scheduleTimezoneColumns + `,
+			  (EXTRACT(EPOCH FROM (s.arrival_time - s.departure_time)) / 60)::int AS duration_minutes,
+			  s.created_at, s.modified_at, t.train_code, t.train_name, t.train_type
+			  FROM schedules s
+			  JOIN trains t ON s.train_id = t.id` + scheduleStationJoins + `
+			  WHERE s.id = $1
+			  FOR UPDATE OF s`
+	if err := tx.Get(&schedule, query, id); err != nil {
+		return nil, err
+	}
+	localizeSchedule(&schedule)
+	return &schedule, nil
+}
+
+// FindAll mendukung filter status, train_id, rentang tanggal keberangkatan,
+// dan pencarian stasiun. Rentang tanggal dibaca sebagai tanggal lokal di
+// stasiun keberangkatan.
+func (r *scheduleRepository) FindAll(qs QuerySpec) ([]models.Schedule, Page, error) {
 	var schedules []models.Schedule
+	from := `FROM schedules s
+			JOIN trains t ON s.train_id = t.id` + scheduleStationJoins
 	query := `SELECT s.id, s.train_id, s.departure_station, s.arrival_station, 
-			s.departure_time, s.arrival_time, s.price, s.available_seats, 
+			s.departure_time, s.arrival_time, s.price, s.available_seats, s.platform, s.status, s.delay_minutes, s.template_id,
+			` + scheduleTimezoneColumns + `,
+			(EXTRACT(EPOCH FROM (s.arrival_time - s.departure_time)) / 60)::int AS duration_minutes,
 			s.created_at, s.modified_at,
-			t.id as train_id, t.train_code, t.train_name, t.train_type
-			FROM schedules s
-			JOIN trains t ON s.train_id = t.id
-			ORDER BY s.departure_time ASC`
-	err := r.db.Select(&schedules, query)
-	return schedules, err
+			t.id as train_id, t.train_code, t.train_name, t.train_type ` + from
+
+	filters := &listQuery{}
+	if qs.Status != "" {
+		filters.add("s.status = ?", qs.Status)
+	}
+	if qs.TrainID != 0 {
+		filters.add("s.train_id = ?", qs.TrainID)
+	}
+	if qs.TrainType != "" {
+		filters.add("t.train_type = ?", qs.TrainType)
+	}
+	filters.dateRange(scheduleLocalDeparture, qs)
+	if qs.Search != "" {
+		pattern := likePattern(qs.Search)
+		filters.add("(s.departure_station ILIKE ? OR s.arrival_station ILIKE ?)", pattern, pattern)
+	}
+
+	page, err := selectPage(r.db, &schedules, scheduleListSpec, qs, query, from, filters)
+	localizeSchedules(schedules)
+	return schedules, page, err
 }
 
-func (r *scheduleRepository) Search(departure, arrival, date string) ([]models.Schedule, error) {
-	var schedules []models.Schedule
-	var err error
+// ScheduleSearch adalah kriteria pencarian jadwal publik. Date dan jendela jam
+// berangkat memakai format YYYY-MM-DD dan HH:MM.
+type ScheduleSearch struct {
+	DepartureStation string
+	ArrivalStation   string
+	Date             string
+	DepartureAfter   string
+	DepartureBefore  string
+	TrainTypes       []string
+	MaxPrice         *float64
+	MinSeats         int
+	Sort             string
+	Desc             bool
+}
+
+var scheduleSearchSorts = map[string]string{
+	"departure_time": "s.departure_time",
+	"duration":       "(s.arrival_time - s.departure_time)",
+	"price":          "s.price",
+}
+
+func (r *scheduleRepository) Search(criteria ScheduleSearch) ([]models.Schedule, error) {
+	schedules := []models.Schedule{}
 
-	baseQuery := `SELECT s.id, s.train_id, s.departure_station, s.arrival_station, 
-			s.departure_time, s.arrival_time, s.price, s.available_seats, 
+	minSeats := criteria.MinSeats
+	if minSeats < 1 {
+		minSeats = 1
+	}
+
+	filters := &listQuery{}
+	filters.add("s.departure_station ILIKE ?", likePattern(criteria.DepartureStation))
+	filters.add("s.arrival_station ILIKE ?", likePattern(criteria.ArrivalStation))
+	filters.add("s.available_seats >= ?", minSeats)
+	// predikat literal ini sama dengan predikat index parsial
+	// idx_schedules_searchable; planner tidak bisa membuktikannya dari
+	// available_seats >= $n sehingga tanpa kondisi ini index tidak terpakai
+	filters.add("s.available_seats > 0")
+	filters.add("s.status != 'cancelled'")
+
+	if criteria.Date != "" {
+		// tanggal dibaca di zona waktu stasiun keberangkatan; rentang kasar
+		// selebar satu hari di kedua sisi tetap memakai index departure_time
+		filters.add("s.departure_time >= ?::date - 1 AND s.departure_time < ?::date + 2", criteria.Date, criteria.Date)
+		filters.add(scheduleLocalDeparture+"::date = ?::date", criteria.Date)
+	}
+
+	after, before := criteria.DepartureAfter, criteria.DepartureBefore
+	switch {
+	case crossesMidnight(after, before):
+		// jendela melewati tengah malam, misalnya 22:00 sampai 02:00
+		filters.add("("+scheduleLocalDeparture+"::time >= ?::time OR "+scheduleLocalDeparture+"::time <= ?::time)", after, before)
+	default:
+		if after != "" {
+			filters.add(scheduleLocalDeparture+"::time >= ?::time", after)
+		}
+		if before != "" {
+			filters.add(scheduleLocalDeparture+"::time <= ?::time", before)
+		}
+	}
+
+	if len(criteria.TrainTypes) > 0 {
+		filters.add("t.train_type ILIKE ANY(?)", pq.Array(criteria.TrainTypes))
+	}
+	if criteria.MaxPrice != nil {
+		filters.add("s.price <= ?", *criteria.MaxPrice)
+	}
+
+	sortColumn, ok := scheduleSearchSorts[criteria.Sort]
+	if !ok {
+		sortColumn = scheduleSearchSorts["departure_time"]
+	}
+	direction := "ASC"
+	if criteria.Desc {
+		direction = "DESC"
+	}
+
+	query := `SELECT s.id, s.train_id, s.departure_station, s.arrival_station, 
+			s.departure_time, s.arrival_time, s.price, s.available_seats, s.platform, s.status, s.delay_minutes, s.template_id,
+			` + scheduleTimezoneColumns + `,
+			(EXTRACT(EPOCH FROM (s.arrival_time - s.departure_time)) / 60)::int AS duration_minutes,
 			s.created_at, s.modified_at,
 			t.id as train_id, t.train_code, t.train_name, t.train_type
 			FROM schedules s
-			JOIN trains t ON s.train_id = t.id
-			WHERE s.departure_station ILIKE $1 
-			AND s.arrival_station ILIKE $2 
-			AND s.available_seats > 0`
-
-	if date != "" {
-		baseQuery += " AND DATE(s.departure_time) = $3 ORDER BY s.departure_time ASC"
-		err = r.db.Select(&schedules, baseQuery, "%"+departure+"%", "%"+arrival+"%", date)
-	} else {
-		baseQuery += " ORDER BY s.departure_time ASC"
-		err = r.db.Select(&schedules, baseQuery, "%"+departure+"%", "%"+arrival+"%")
-	}
+			JOIN trains t ON s.train_id = t.id` + scheduleStationJoins + filters.clause() +
+		fmt.Sprintf(" ORDER BY %s %s, s.departure_time ASC, s.id ASC", sortColumn, direction)
 
+	err := r.db.Select(&schedules, r.db.Rebind(query), filters.args...)
+	localizeSchedules(schedules)
 	return schedules, err
 }
 
+// crossesMidnight bernilai true jika jendela jam keberangkatan melewati tengah
+// malam. Jam dibandingkan sebagai waktu karena binding menerima "9:00" yang
+// secara string lebih besar dari "10:00".
+func crossesMidnight(after, before string) bool {
+	if after == "" || before == "" {
+		return false
+	}
+	afterTime, err := time.Parse("15:04", after)
+	if err != nil {
+		return false
+	}
+	beforeTime, err := time.Parse("15:04", before)
+	if err != nil {
+		return false
+	}
+	return afterTime.After(beforeTime)
+}
 
-func (r *scheduleRepository) Update(id int, schedule *models.Schedule) error {
+func (r *scheduleRepository) Update(id int, schedule *models.Schedule, tx *sqlx.Tx) error {
 	query := `UPDATE schedules SET train_id = $1, departure_station = $2, 
 			  arrival_station = $3, departure_time = $4, arrival_time = $5, 
-			  price = $6, available_seats = $7, modified_at = NOW() WHERE id = $8`
-	_, err := r.db.Exec(query, schedule.TrainID, schedule.DepartureStation,
+			  price = $6, available_seats = $7, platform = $8, modified_at = NOW() WHERE id = $9`
+	_, err := tx.Exec(query, schedule.TrainID, schedule.DepartureStation,
 		schedule.ArrivalStation, schedule.DepartureTime, schedule.ArrivalTime,
-		schedule.Price, schedule.AvailableSeats, id)
+		schedule.Price, schedule.AvailableSeats, schedule.Platform, id)
+	return err
+}
+
+// RelocalizeLegacyTimes membaca ulang jam lokal jadwal dari sebelum
+// migrasi zona waktu di zona waktu baru stasiun. Hanya sisi jadwal yang
+// memakai stasiun tersebut yang berubah; id jadwal yang berubah dikembalikan.
+func (r *scheduleRepository) RelocalizeLegacyTimes(station, timezone string, tx *sqlx.Tx) ([]int, error) {
+	ids := []int{}
+	query := `UPDATE schedules s SET
+				departure_time = CASE WHEN LOWER(s.departure_station) = LOWER($1)
+					THEN l.departure_local AT TIME ZONE $2 ELSE s.departure_time END,
+				arrival_time = CASE WHEN LOWER(s.arrival_station) = LOWER($1)
+					THEN l.arrival_local AT TIME ZONE $2 ELSE s.arrival_time END,
+				modified_at = NOW()
+			  FROM schedule_legacy_times l
+			  WHERE l.schedule_id = s.id
+				AND (LOWER(s.departure_station) = LOWER($1) OR LOWER(s.arrival_station) = LOWER($1))
+			  RETURNING s.id`
+	err := tx.Select(&ids, query, station, timezone)
+	return ids, err
+}
+
+// ClearLegacyTimes dipanggil saat waktu atau stasiun jadwal diubah lewat API
+// sehingga perubahan zona waktu stasiun tidak lagi menimpanya.
+func (r *scheduleRepository) ClearLegacyTimes(id int, tx *sqlx.Tx) error {
+	_, err := tx.Exec(`DELETE FROM schedule_legacy_times WHERE schedule_id = $1`, id)
 	return err
 }
 
-func (r *scheduleRepository) Delete(id int) error {
+func (r *scheduleRepository) Delete(id int, tx *sqlx.Tx) error {
 	query := `DELETE FROM schedules WHERE id = $1`
-	_, err := r.db.Exec(query, id)
+	_, err := tx.Exec(query, id)
 	return err
 }
 
+// FindAlternatives mencari jadwal lain dengan rute yang sama yang masih bisa
+// dipesan, diurutkan dari yang waktu berangkatnya paling dekat dengan jadwal asal.
+func (r *scheduleRepository) FindAlternatives(schedule *models.Schedule, limit int) ([]models.Schedule, error) {
+	schedules := []models.Schedule{}
+	query := `SELECT s.id, s.train_id, s.departure_station, s.arrival_station, 
+			s.departure_time, s.arrival_time, s.price, s.available_seats, s.platform, s.status, s.delay_minutes, s.template_id,
+			` + scheduleTimezoneColumns + `,
+			(EXTRACT(EPOCH FROM (s.arrival_time - s.departure_time)) / 60)::int AS duration_minutes,
+			s.created_at, s.modified_at,
+			t.id as train_id, t.train_code, t.train_name, t.train_type
+			FROM schedules s
+			JOIN trains t ON s.train_id = t.id` + scheduleStationJoins + `
+			WHERE s.id != $1
+			AND s.departure_station = $2
+			AND s.arrival_station = $3
+			AND s.status != 'cancelled'
+			AND s.available_seats > 0
+			AND s.departure_time > NOW()
+			ORDER BY ABS(EXTRACT(EPOCH FROM (s.departure_time - $4::timestamptz))) ASC
+			LIMIT $5`
+	err := r.db.Select(&schedules, query, schedule.ID, schedule.DepartureStation,
+		schedule.ArrivalStation, schedule.DepartureTime, limit)
+	localizeSchedules(schedules)
+	return schedules, err
+}
+
+// FareCalendar menghitung tarif termurah yang masih punya kursi per hari untuk
+// satu rute dalam sebulan. Nama stasiun dicocokkan tanpa membedakan huruf
+// besar kecil dan hari dihitung di zona waktu stasiun keberangkatan; hari
+// tanpa keberangkatan tidak dikembalikan.
+func (r *scheduleRepository) FareCalendar(departure, arrival, monthStart string) ([]models.FareCalendarDay, error) {
+	days := []models.FareCalendarDay{}
+	query := `SELECT TO_CHAR(` + scheduleLocalDeparture + `::date, 'YYYY-MM-DD') AS date,
+			  MIN(s.price) FILTER (WHERE s.available_seats > 0) AS lowest_fare,
+			  BOOL_OR(s.available_seats > 0) AS seats_available,
+			  COUNT(*) AS departures
+			  FROM schedules s` + departureStationJoin + `
+			  WHERE LOWER(s.departure_station) = LOWER($1)
+			  AND LOWER(s.arrival_station) = LOWER($2)
+			  AND s.status != 'cancelled'
+			  AND s.departure_time >= $3::date - 1
+			  AND s.departure_time < $3::date + INTERVAL '1 month' + INTERVAL '1 day'
+			  AND ` + scheduleLocalDeparture + `::date >= $3::date
+			  AND ` + scheduleLocalDeparture + `::date < $3::date + INTERVAL '1 month'
+			  AND s.departure_time > NOW()
+			  GROUP BY 1
+			  ORDER BY 1`
+	err := r.db.Select(&days, query, departure, arrival, monthStart)
+	return days, err
+}
+
+// UpdateOperationalStatus menyimpan status, keterlambatan, dan peron jadwal.
+// Jadwal yang sudah dibatalkan tidak bisa diubah lagi; false dikembalikan jika
+// tidak ada baris yang berubah.
+func (r *scheduleRepository) UpdateOperationalStatus(schedule *models.Schedule, tx *sqlx.Tx) (bool, error) {
+	query := `UPDATE schedules SET status = $1, delay_minutes = $2, platform = $3, modified_at = NOW()
+			  WHERE id = $4 AND status != 'cancelled'`
+	result, err := tx.Exec(query, schedule.Status, schedule.DelayMinutes, schedule.Platform, schedule.ID)
+	if err != nil {
+		return false, err
+	}
+	rows, err := result.RowsAffected()
+	return rows > 0, err
+}
+
 func (r *scheduleRepository) DecrementSeat(id int, tx *sqlx.Tx) error {
 	query := `UPDATE schedules SET available_seats = available_seats - 1, 
-			  modified_at = NOW() WHERE id = $1 AND available_seats > 0`
+			  modified_at = NOW() WHERE id = $1 AND available_seats > 0 AND status != 'cancelled'`
 	result, err := tx.Exec(query, id)
 	if err != nil {
 		return err
@@ -127,4 +387,91 @@ func (r *scheduleRepository) IncrementSeat(id int, tx *sqlx.Tx) error {
 	return err
 }
 
-var ErrNoSeatsAvailable = errors.New("kursi tidak tersedia")
\ No newline at end of file
+// localizeSchedule mengubah waktu berangkat ke zona waktu stasiun
+// keberangkatan dan waktu tiba ke zona waktu stasiun tujuan.
+func localizeSchedule(schedule *models.Schedule) {
+	schedule.DepartureTime = schedule.DepartureTime.In(utils.Location(schedule.DepartureTimezone))
+	schedule.ArrivalTime = schedule.ArrivalTime.In(utils.Location(schedule.ArrivalTimezone))
+}
+
+func localizeSchedules(schedules []models.Schedule) {
+	for i := range schedules {
+		localizeSchedule(&schedules[i])
+	}
+}
+
+var ErrNoSeatsAvailable = errors.New("kursi tidak tersedia")
+
+const overlappingSchedulesQuery = `SELECT id, train_id, departure_station, arrival_station, departure_time, arrival_time
+			  FROM schedules
+			  WHERE train_id = $1 AND id != $2 AND status != 'cancelled'
+			  AND departure_time < $4 AND arrival_time > $3
+			  ORDER BY departure_time`
+
+// FindOverlapping mencari jadwal lain kereta yang sama yang rentang
+// berangkat-tibanya beririsan. Jadwal yang dibatalkan tidak dihitung.
+func (r *scheduleRepository) FindOverlapping(trainID int, departure, arrival time.Time, excludeID int) ([]models.Schedule, error) {
+	schedules := []models.Schedule{}
+	err := r.db.Select(&schedules, overlappingSchedulesQuery, trainID, excludeID, departure, arrival)
+	return schedules, err
+}
+
+// FindOverlappingInTx sama dengan FindOverlapping di dalam transaksi yang
+// sudah mengunci baris kereta.
+func (r *scheduleRepository) FindOverlappingInTx(trainID int, departure, arrival time.Time, excludeID int, tx *sqlx.Tx) ([]models.Schedule, error) {
+	schedules := []models.Schedule{}
+	err := tx.Select(&schedules, overlappingSchedulesQuery, trainID, excludeID, departure, arrival)
+	return schedules, err
+}
+
+// FindViolations mengembalikan semua jadwal tersimpan yang melanggar aturan
+// konsistensi. Untuk train_overlap setiap pasangan muncul sekali, dari jadwal
+// dengan id terkecil.
+func (r *scheduleRepository) FindViolations() ([]models.ScheduleViolation, error) {
+	violations := []models.ScheduleViolation{}
+	query := `SELECT id AS schedule_id, 'arrival_before_departure' AS rule, 'arrival_time' AS field,
+			  NULL::int AS conflicting_schedule_id
+			  FROM schedules WHERE arrival_time <= departure_time
+			  UNION ALL
+			  SELECT id, 'same_station', 'arrival_station', NULL
+			  FROM schedules WHERE LOWER(TRIM(departure_station)) = LOWER(TRIM(arrival_station))
+			  UNION ALL
+			  SELECT s.id, 'seats_exceed_capacity', 'available_seats', NULL
+			  FROM schedules s JOIN trains t ON s.train_id = t.id
+			  WHERE s.available_seats + (SELECT COUNT(*) FROM tickets tk
+			  WHERE tk.schedule_id = s.id AND tk.status IN ('pending', 'confirmed')) > t.total_seats
+			  UNION ALL
+			  SELECT a.id, 'train_overlap', 'departure_time', b.id
+			  FROM schedules a JOIN schedules b ON a.train_id = b.train_id AND a.id < b.id
+			  WHERE a.status != 'cancelled' AND b.status != 'cancelled'
+			  AND a.departure_time < b.arrival_time AND b.departure_time < a.arrival_time
+			  UNION ALL
+			  SELECT s.id, 'station_not_found', 'departure_station', NULL
+			  FROM schedules s WHERE NOT EXISTS (SELECT 1 FROM stations st WHERE LOWER(st.name) = LOWER(s.departure_station))
+			  UNION ALL
+			  SELECT s.id, 'station_not_found', 'arrival_station', NULL
+			  FROM schedules s WHERE NOT EXISTS (SELECT 1 FROM stations st WHERE LOWER(st.name) = LOWER(s.arrival_station))
+			  UNION ALL
+			  SELECT s.id, 'train_unavailable', 'train_id', NULL
+			  FROM schedules s WHERE s.status != 'cancelled' AND EXISTS (
+			  SELECT 1 FROM train_unavailabilities u
+			  WHERE u.train_id = s.train_id AND u.starts_at < s.arrival_time AND u.ends_at > s.departure_time)
+			  ORDER BY schedule_id, rule`
+	err := r.db.Select(&violations, query)
+	return violations, err
+}
+
+// FindExceedingCapacity mencari jadwal kereta yang belum selesai yang kursi
+// tersedia ditambah tiket aktifnya melebihi capacity, dipakai sebelum
+// kapasitas kereta dikurangi.
+func (r *scheduleRepository) FindExceedingCapacity(trainID, capacity int, tx *sqlx.Tx) ([]models.Schedule, error) {
+	schedules := []models.Schedule{}
+	query := `SELECT s.id, s.train_id, s.departure_station, s.arrival_station, s.departure_time, s.arrival_time, s.available_seats
+			  FROM schedules s
+			  WHERE s.train_id = $1 AND s.status != 'cancelled' AND s.arrival_time > NOW()
+			  AND s.available_seats + (SELECT COUNT(*) FROM tickets tk
+			  WHERE tk.schedule_id = s.id AND tk.status IN ('pending', 'confirmed')) > $2
+			  ORDER BY s.departure_time`
+	err := tx.Select(&schedules, query, trainID, capacity)
+	return schedules, err
+}
@@ -0,0 +1,25 @@
+package repository
+
+import "testing"
+
+func TestCrossesMidnight(t *testing.T) {
+	cases := []struct {
+		after, before string
+		want          bool
+	}{
+		{"22:00", "02:00", true},
+		{"23:59", "00:00", true},
+		// secara string "9:00" > "10:00", tetapi jendelanya tidak melewati tengah malam
+		{"9:00", "10:00", false},
+		{"10:00", "9:00", true},
+		{"08:00", "17:00", false},
+		{"08:00", "08:00", false},
+		{"22:00", "", false},
+		{"", "02:00", false},
+	}
+	for _, c := range cases {
+		if got := crossesMidnight(c.after, c.before); got != c.want {
+			t.Errorf("crossesMidnight(%q, %q) = %v, want %v", c.after, c.before, got, c.want)
+		}
+	}
+}
@@ -0,0 +1,125 @@
+package repository
+
+import (
+	"tiketsepur/models"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type ScheduleTemplateRepository interface {
+	Create(template *models.ScheduleTemplate) error
+	FindByID(id int) (*models.ScheduleTemplate, error)
+	FindAll(qs QuerySpec) ([]models.ScheduleTemplate, Page, error)
+	Update(template *models.ScheduleTemplate) error
+	Delete(id int) error
+	FindExistingDepartures(template *models.ScheduleTemplate, from, to time.Time) ([]ExistingDeparture, error)
+}
+
+// ExistingDeparture adalah jadwal yang sudah ada di rentang generate, baik
+// hasil template yang sama maupun jadwal lain kereta yang sama.
+type ExistingDeparture struct {
+	ScheduleID    int       `db:"id"`
+	TemplateID    *int      `db:"template_id"`
+	DepartureTime time.Time `db:"departure_time"`
+	ArrivalTime   time.Time `db:"arrival_time"`
+	Status        string    `db:"status"`
+}
+
+type scheduleTemplateRepository struct {
+	db *sqlx.DB
+}
+
+func NewScheduleTemplateRepository(db *sqlx.DB) ScheduleTemplateRepository {
+	return &scheduleTemplateRepository{db: db}
+}
+
+const scheduleTemplateColumns = `id, train_id, departure_station, arrival_station,
+			  TO_CHAR(departure_time, 'HH24:MI') AS departure_time,
+			  TO_CHAR(arrival_time, 'HH24:MI') AS arrival_time,
+			  arrival_day_offset, days_of_week,
+			  TO_CHAR(valid_from, 'YYYY-MM-DD') AS valid_from,
+			  TO_CHAR(valid_to, 'YYYY-MM-DD') AS valid_to,
+			  price, platform, is_active, created_by, created_at, modified_at`
+
+func (r *scheduleTemplateRepository) Create(template *models.ScheduleTemplate) error {
+	query := `INSERT INTO schedule_templates (train_id, departure_station, arrival_station, departure_time,
+			  arrival_time, arrival_day_offset, days_of_week, valid_from, valid_to, price, platform,
+			  is_active, created_by, created_at, modified_at)
+			  VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, NOW(), NOW())
+			  RETURNING id, created_at, modified_at`
+	return r.db.QueryRow(query, template.TrainID, template.DepartureStation, template.ArrivalStation,
+		template.DepartureTime, template.ArrivalTime, template.ArrivalDayOffset, template.DaysOfWeek,
+		template.ValidFrom, template.ValidTo, template.Price, template.Platform, template.IsActive,
+		template.CreatedBy).Scan(&template.ID, &template.CreatedAt, &template.ModifiedAt)
+}
+
+func (r *scheduleTemplateRepository) FindByID(id int) (*models.ScheduleTemplate, error) {
+	var template models.ScheduleTemplate
+	query := `SELECT ` + scheduleTemplateColumns + ` FROM schedule_templates WHERE id = $1`
+	if err := r.db.Get(&template, query, id); err != nil {
+		return nil, err
+	}
+	return &template, nil
+}
+
+var scheduleTemplateListSpec = listSpec{
+	sorts: map[string]sortField{
+		"id":         {Column: "id", Field: "id", Cast: "int"},
+		"created_at": {Column: "created_at", Field: "created_at", Cast: "timestamp"},
+	},
+	defaultSort: "id",
+	idColumn:    "id",
+}
+
+// FindAll mendukung filter train_id, status (active atau inactive), dan
+// pencarian stasiun keberangkatan atau tujuan.
+func (r *scheduleTemplateRepository) FindAll(qs QuerySpec) ([]models.ScheduleTemplate, Page, error) {
+	var templates []models.ScheduleTemplate
+	from := `FROM schedule_templates`
+
+	filters := &listQuery{}
+	if qs.TrainID != 0 {
+		filters.add("train_id = ?", qs.TrainID)
+	}
+	switch qs.Status {
+	case "active":
+		filters.add("is_active")
+	case "inactive":
+		filters.add("NOT is_active")
+	}
+	if qs.Search != "" {
+		pattern := likePattern(qs.Search)
+		filters.add("(departure_station ILIKE ? OR arrival_station ILIKE ?)", pattern, pattern)
+	}
+
+	page, err := selectPage(r.db, &templates, scheduleTemplateListSpec, qs, `SELECT `+scheduleTemplateColumns+` `+from, from, filters)
+	return templates, page, err
+}
+
+func (r *scheduleTemplateRepository) Update(template *models.ScheduleTemplate) error {
+	query := `UPDATE schedule_templates SET train_id = $1, departure_station = $2, arrival_station = $3,
+			  departure_time = $4, arrival_time = $5, arrival_day_offset = $6, days_of_week = $7,
+			  valid_from = $8, valid_to = $9, price = $10, platform = $11, is_active = $12, modified_at = NOW()
+			  WHERE id = $13 RETURNING modified_at`
+	return r.db.QueryRow(query, template.TrainID, template.DepartureStation, template.ArrivalStation,
+		template.DepartureTime, template.ArrivalTime, template.ArrivalDayOffset, template.DaysOfWeek,
+		template.ValidFrom, template.ValidTo, template.Price, template.Platform, template.IsActive,
+		template.ID).Scan(&template.ModifiedAt)
+}
+
+// Delete hanya menghapus template; jadwal yang sudah dibuat tetap ada dengan
+// template_id kosong.
+func (r *scheduleTemplateRepository) Delete(id int) error {
+	_, err := r.db.Exec(`DELETE FROM schedule_templates WHERE id = $1`, id)
+	return err
+}
+
+func (r *scheduleTemplateRepository) FindExistingDepartures(template *models.ScheduleTemplate, from, to time.Time) ([]ExistingDeparture, error) {
+	departures := []ExistingDeparture{}
+	query := `SELECT id, template_id, departure_time, arrival_time, status FROM schedules
+			  WHERE departure_time < $2 AND arrival_time > $1
+			  AND (template_id = $3 OR train_id = $4)`
+	err := r.db.Select(&departures, query, from, to, template.ID, template.TrainID)
+	return departures, err
+}
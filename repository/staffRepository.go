@@ -0,0 +1,164 @@
+package repository
+
+import (
+	"tiketsepur/models"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+type StaffRepository interface {
+	Create(staff *models.Staff, tx *sqlx.Tx) error
+	FindByID(id int) (*models.Staff, error)
+	FindByIDForUpdate(id int, tx *sqlx.Tx) (*models.Staff, error)
+	FindByUserID(userID int) (*models.Staff, error)
+	FindByEmployeeNumber(employeeNumber string) (*models.Staff, error)
+	FindAll(qs QuerySpec) ([]models.Staff, Page, error)
+	Update(staff *models.Staff, tx *sqlx.Tx) error
+	ReplaceQualifications(staffID int, qualifications []models.StaffQualification, tx *sqlx.Tx) error
+}
+
+type staffRepository struct {
+	db *sqlx.DB
+}
+
+func NewStaffRepository(db *sqlx.DB) StaffRepository {
+	return &staffRepository{db: db}
+}
+
+func (r *staffRepository) Create(staff *models.Staff, tx *sqlx.Tx) error {
+	query := `INSERT INTO staff (user_id, employee_number, name, phone, is_active, created_at, modified_at)
+			  VALUES ($1, $2, $3, $4, $5, NOW(), NOW()) RETURNING id, created_at, modified_at`
+	return tx.QueryRow(query, staff.UserID, staff.EmployeeNumber, staff.Name, staff.Phone, staff.IsActive).
+		Scan(&staff.ID, &staff.CreatedAt, &staff.ModifiedAt)
+}
+
+func (r *staffRepository) FindByID(id int) (*models.Staff, error) {
+	var staff models.Staff
+	if err := r.db.Get(&staff, `SELECT * FROM staff WHERE id = $1`, id); err != nil {
+		return nil, err
+	}
+	if err := r.withQualifications(r.db, []*models.Staff{&staff}); err != nil {
+		return nil, err
+	}
+	return &staff, nil
+}
+
+// FindByIDForUpdate mengunci baris staf sehingga penugasan staf yang sama ke
+// jadwal lain menunggu sampai pemeriksaan bentrok dan waktu istirahat selesai.
+func (r *staffRepository) FindByIDForUpdate(id int, tx *sqlx.Tx) (*models.Staff, error) {
+	var staff models.Staff
+	if err := tx.Get(&staff, `SELECT * FROM staff WHERE id = $1 FOR UPDATE`, id); err != nil {
+		return nil, err
+	}
+	if err := r.withQualifications(tx, []*models.Staff{&staff}); err != nil {
+		return nil, err
+	}
+	return &staff, nil
+}
+
+func (r *staffRepository) FindByUserID(userID int) (*models.Staff, error) {
+	var staff models.Staff
+	if err := r.db.Get(&staff, `SELECT * FROM staff WHERE user_id = $1`, userID); err != nil {
+		return nil, err
+	}
+	if err := r.withQualifications(r.db, []*models.Staff{&staff}); err != nil {
+		return nil, err
+	}
+	return &staff, nil
+}
+
+func (r *staffRepository) FindByEmployeeNumber(employeeNumber string) (*models.Staff, error) {
+	var staff models.Staff
+	if err := r.db.Get(&staff, `SELECT * FROM staff WHERE LOWER(employee_number) = LOWER($1)`, employeeNumber); err != nil {
+		return nil, err
+	}
+	return &staff, nil
+}
+
+var staffListSpec = listSpec{
+	sorts: map[string]sortField{
+		"name":            {Column: "name", Field: "name", Cast: "text"},
+		"employee_number": {Column: "employee_number", Field: "employee_number", Cast: "text"},
+		"id":              {Column: "id", Field: "id", Cast: "int"},
+	},
+	defaultSort: "name",
+	idColumn:    "id",
+}
+
+// FindAll mendukung filter kualifikasi, status (active atau inactive), dan
+// pencarian nama atau nomor pegawai.
+func (r *staffRepository) FindAll(qs QuerySpec) ([]models.Staff, Page, error) {
+	var staff []models.Staff
+	from := `FROM staff`
+
+	filters := &listQuery{}
+	if qs.Qualification != "" {
+		filters.add("id IN (SELECT staff_id FROM staff_qualifications WHERE qualification = ?)", qs.Qualification)
+	}
+	switch qs.Status {
+	case "active":
+		filters.add("is_active")
+	case "inactive":
+		filters.add("NOT is_active")
+	}
+	if qs.Search != "" {
+		pattern := likePattern(qs.Search)
+		filters.add("(name ILIKE ? OR employee_number ILIKE ?)", pattern, pattern)
+	}
+
+	page, err := selectPage(r.db, &staff, staffListSpec, qs, `SELECT * `+from, from, filters)
+	if err != nil {
+		return nil, page, err
+	}
+
+	members := make([]*models.Staff, len(staff))
+	for i := range staff {
+		members[i] = &staff[i]
+	}
+	if err := r.withQualifications(r.db, members); err != nil {
+		return nil, page, err
+	}
+	return staff, page, nil
+}
+
+func (r *staffRepository) Update(staff *models.Staff, tx *sqlx.Tx) error {
+	query := `UPDATE staff SET user_id = $1, name = $2, phone = $3, is_active = $4, modified_at = NOW()
+			  WHERE id = $5 RETURNING modified_at`
+	return tx.QueryRow(query, staff.UserID, staff.Name, staff.Phone, staff.IsActive, staff.ID).Scan(&staff.ModifiedAt)
+}
+
+func (r *staffRepository) ReplaceQualifications(staffID int, qualifications []models.StaffQualification, tx *sqlx.Tx) error {
+	if _, err := tx.Exec(`DELETE FROM staff_qualifications WHERE staff_id = $1`, staffID); err != nil {
+		return err
+	}
+	for _, qualification := range qualifications {
+		query := `INSERT INTO staff_qualifications (staff_id, qualification, valid_until) VALUES ($1, $2, $3)`
+		if _, err := tx.Exec(query, staffID, qualification.Qualification, qualification.ValidUntil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// withQualifications mengisi kualifikasi beberapa staf dengan satu query.
+func (r *staffRepository) withQualifications(q sqlx.Queryer, staff []*models.Staff) error {
+	ids := make([]int64, len(staff))
+	byID := make(map[int]*models.Staff, len(staff))
+	for i, member := range staff {
+		ids[i] = int64(member.ID)
+		member.Qualifications = []models.StaffQualification{}
+		byID[member.ID] = member
+	}
+
+	qualifications := []models.StaffQualification{}
+	query := `SELECT * FROM staff_qualifications WHERE staff_id = ANY($1) ORDER BY staff_id, qualification`
+	if err := sqlx.Select(q, &qualifications, query, pq.Array(ids)); err != nil {
+		return err
+	}
+	for _, qualification := range qualifications {
+		member := byID[qualification.StaffID]
+		member.Qualifications = append(member.Qualifications, qualification)
+	}
+	return nil
+}
@@ -0,0 +1,106 @@
+package repository
+
+import (
+	"strings"
+	"tiketsepur/models"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type StationRepository interface {
+	Create(station *models.Station) error
+	FindByID(id int) (*models.Station, error)
+	FindByName(name string) (*models.Station, error)
+	FindAll(qs QuerySpec) ([]models.Station, Page, error)
+	FindTimezones() (map[string]string, error)
+	UpdateTimezone(id int, timezone string, tx *sqlx.Tx) error
+	Delete(id int) error
+	IsUsed(name string) (bool, error)
+}
+
+type stationRepository struct {
+	db *sqlx.DB
+}
+
+func NewStationRepository(db *sqlx.DB) StationRepository {
+	return &stationRepository{db: db}
+}
+
+func (r *stationRepository) Create(station *models.Station) error {
+	query := `INSERT INTO stations (name, timezone) VALUES ($1, $2)
+			  RETURNING id, created_at, modified_at`
+	return r.db.QueryRow(query, station.Name, station.Timezone).Scan(&station.ID, &station.CreatedAt, &station.ModifiedAt)
+}
+
+func (r *stationRepository) FindByID(id int) (*models.Station, error) {
+	var station models.Station
+	if err := r.db.Get(&station, `SELECT * FROM stations WHERE id = $1`, id); err != nil {
+		return nil, err
+	}
+	return &station, nil
+}
+
+func (r *stationRepository) FindByName(name string) (*models.Station, error) {
+	var station models.Station
+	if err := r.db.Get(&station, `SELECT * FROM stations WHERE LOWER(name) = LOWER($1)`, name); err != nil {
+		return nil, err
+	}
+	return &station, nil
+}
+
+var stationListSpec = listSpec{
+	sorts: map[string]sortField{
+		"name": {Column: "name", Field: "name", Cast: "text"},
+		"id":   {Column: "id", Field: "id", Cast: "int"},
+	},
+	defaultSort: "name",
+	idColumn:    "id",
+}
+
+// FindAll mendukung pencarian nama stasiun.
+func (r *stationRepository) FindAll(qs QuerySpec) ([]models.Station, Page, error) {
+	var stations []models.Station
+	from := `FROM stations`
+
+	filters := &listQuery{}
+	if qs.Search != "" {
+		filters.add("name ILIKE ?", likePattern(qs.Search))
+	}
+
+	page, err := selectPage(r.db, &stations, stationListSpec, qs, `SELECT * `+from, from, filters)
+	return stations, page, err
+}
+
+// FindTimezones mengembalikan zona waktu semua stasiun dengan key nama
+// stasiun huruf kecil.
+func (r *stationRepository) FindTimezones() (map[string]string, error) {
+	var stations []models.Station
+	if err := r.db.Select(&stations, `SELECT * FROM stations`); err != nil {
+		return nil, err
+	}
+	timezones := make(map[string]string, len(stations))
+	for _, station := range stations {
+		timezones[strings.ToLower(station.Name)] = station.Timezone
+	}
+	return timezones, nil
+}
+
+func (r *stationRepository) UpdateTimezone(id int, timezone string, tx *sqlx.Tx) error {
+	_, err := tx.Exec(`UPDATE stations SET timezone = $1, modified_at = NOW() WHERE id = $2`, timezone, id)
+	return err
+}
+
+func (r *stationRepository) Delete(id int) error {
+	_, err := r.db.Exec(`DELETE FROM stations WHERE id = $1`, id)
+	return err
+}
+
+// IsUsed bernilai true jika masih ada jadwal atau template jadwal yang
+// berangkat dari atau tiba di stasiun tersebut.
+func (r *stationRepository) IsUsed(name string) (bool, error) {
+	var used bool
+	query := `SELECT EXISTS (SELECT 1 FROM schedules WHERE LOWER(departure_station) = LOWER($1) OR LOWER(arrival_station) = LOWER($1))
+			  OR EXISTS (SELECT 1 FROM schedule_templates WHERE LOWER(departure_station) = LOWER($1) OR LOWER(arrival_station) = LOWER($1))`
+	err := r.db.Get(&used, query, name)
+	return used, err
+}
@@ -0,0 +1,63 @@
+package repository
+
+import (
+	"tiketsepur/models"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type TicketReminderRepository interface {
+	FindDue(offsetMinutes, nextOffsetMinutes, limit int, tx *sqlx.Tx) ([]models.TicketWithDetails, error)
+	Create(ticketID, offsetMinutes int, tx *sqlx.Tx) (bool, error)
+}
+
+type ticketReminderRepository struct {
+	db *sqlx.DB
+}
+
+func NewTicketReminderRepository(db *sqlx.DB) TicketReminderRepository {
+	return &ticketReminderRepository{db: db}
+}
+
+// FindDue mengunci tiket confirmed yang sudah masuk jendela pengingat untuk
+// offset tersebut, yaitu antara offsetMinutes dan nextOffsetMinutes sebelum
+// keberangkatan, dan belum pernah diingatkan untuk offset itu. Tiket yang
+// dipesan setelah waktu pengingat lewat tidak ikut diingatkan.
+func (r *ticketReminderRepository) FindDue(offsetMinutes, nextOffsetMinutes, limit int, tx *sqlx.Tx) ([]models.TicketWithDetails, error) {
+	var tickets []models.TicketWithDetails
+	query := `SELECT t.id, COALESCE(t.user_id, 0) AS user_id, t.schedule_id, t.seat_number,
+			  t.passenger_name, t.passenger_id_number, t.status,
+			  t.booking_code, t.total_price, t.coach, t.created_at, t.modified_at,
+			  s.departure_station, s.arrival_station, s.departure_time, s.platform, ` + departureTimezoneColumn + `,
+			  tr.train_name, tr.train_code, tr.train_type FROM tickets t
+			  JOIN schedules s ON t.schedule_id = s.id` + departureStationJoin + `
+			  JOIN trains tr ON s.train_id = tr.id
+			  WHERE t.status = 'confirmed'
+			  AND s.departure_time - $1 * INTERVAL '1 minute' <= NOW()
+			  AND s.departure_time - $2 * INTERVAL '1 minute' > NOW()
+			  AND t.created_at < s.departure_time - $1 * INTERVAL '1 minute'
+			  AND NOT EXISTS (
+				  SELECT 1 FROM ticket_reminders rm
+				  WHERE rm.ticket_id = t.id AND rm.offset_minutes = $1
+			  )
+			  ORDER BY s.departure_time
+			  LIMIT $3
+			  FOR UPDATE OF t SKIP LOCKED`
+	err := tx.Select(&tickets, query, offsetMinutes, nextOffsetMinutes, limit)
+	localizeTickets(tickets)
+	return tickets, err
+}
+
+// Create mencatat bahwa pengingat sudah dijadwalkan. Mengembalikan false jika
+// pengingat untuk tiket dan offset yang sama sudah ada.
+func (r *ticketReminderRepository) Create(ticketID, offsetMinutes int, tx *sqlx.Tx) (bool, error) {
+	query := `INSERT INTO ticket_reminders (ticket_id, offset_minutes, created_at)
+			  VALUES ($1, $2, NOW())
+			  ON CONFLICT (ticket_id, offset_minutes) DO NOTHING`
+	result, err := tx.Exec(query, ticketID, offsetMinutes)
+	if err != nil {
+		return false, err
+	}
+	rows, err := result.RowsAffected()
+	return rows > 0, err
+}
@@ -2,6 +2,8 @@ package repository
 
 import (
 	"tiketsepur/models"
+	"tiketsepur/utils"
+	"time"
 
 	"github.com/jmoiron/sqlx"
 )
@@ -10,9 +12,12 @@ type TicketRepository interface {
 	Create(ticket *models.Ticket, tx *sqlx.Tx) error
 	FindByID(id int) (*models.TicketWithDetails, error)
 	FindByBookingCode(code string) (*models.TicketWithDetails, error)
-	FindByUserID(userID int) ([]models.TicketWithDetails, error)
-	FindAll() ([]models.TicketWithDetails, error)
+	FindAll(qs QuerySpec) ([]models.TicketWithDetails, Page, error)
 	UpdateStatus(id int, status string, tx *sqlx.Tx) error
+	TransitionStatus(id int, from, to string, tx *sqlx.Tx) (bool, error)
+	FindExpiredPending(before time.Time, limit int, tx *sqlx.Tx) ([]models.TicketWithDetails, error)
+	FindActiveBySchedule(scheduleID int, tx *sqlx.Tx) ([]models.TicketWithDetails, error)
+	CountBySchedule(scheduleID int, tx *sqlx.Tx) (*TicketCounts, error)
 	CheckSeatAvailability(scheduleID int, seatNumber string) (bool, error)
 }
 
@@ -26,20 +31,20 @@ func NewTicketRepository(db *sqlx.DB) TicketRepository {
 
 func (r *ticketRepository) Create(ticket *models.Ticket, tx *sqlx.Tx) error {
 	query := `INSERT INTO tickets (user_id, schedule_id, seat_number, passenger_name, 
-			  passenger_id_number, status, booking_code, total_price, created_at, modified_at) 
-			  VALUES ($1, $2, $3, $4, $5, $6, $7, $8, NOW(), NOW()) RETURNING id, created_at, modified_at`
+			  passenger_id_number, status, booking_code, total_price, coach, created_at, modified_at) 
+			  VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, NOW(), NOW()) RETURNING id, created_at, modified_at`
 	return tx.QueryRow(query, ticket.UserID, ticket.ScheduleID, ticket.SeatNumber,
 		ticket.PassengerName, ticket.PassengerIDNumber, ticket.Status,
-		ticket.BookingCode, ticket.TotalPrice).Scan(&ticket.ID, &ticket.CreatedAt, &ticket.ModifiedAt)
+		ticket.BookingCode, ticket.TotalPrice, ticket.Coach).Scan(&ticket.ID, &ticket.CreatedAt, &ticket.ModifiedAt)
 }
 
 func (r *ticketRepository) FindByID(id int) (*models.TicketWithDetails, error) {
 	var ticket models.TicketWithDetails
 	query := `SELECT t.id, t.user_id, t.schedule_id, t.seat_number, 
     		  t.passenger_name, t.passenger_id_number, t.status, 
-    		  t.booking_code, t.total_price, t.created_at, t.modified_at, s.departure_station, s.arrival_station, s.departure_time,
+    		  t.booking_code, t.total_price, t.coach, t.created_at, t.modified_at, s.departure_station, s.arrival_station, s.departure_time, s.platform, ` + departureTimezoneColumn + `,
 			  tr.train_name, tr.train_code, tr.train_type, p.payment_code, p.payment_status FROM tickets t
-			  JOIN schedules s ON t.schedule_id = s.id
+			  JOIN schedules s ON t.schedule_id = s.id` + departureStationJoin + `
 			  JOIN trains tr ON s.train_id = tr.id
 			  LEFT JOIN payments p ON p.ticket_id = t.id
 			  WHERE t.id = $1`
@@ -47,6 +52,7 @@ func (r *ticketRepository) FindByID(id int) (*models.TicketWithDetails, error) {
 	if err != nil {
 		return nil, err
 	}
+	localizeTicket(&ticket)
 	return &ticket, nil
 }
 
@@ -54,9 +60,9 @@ func (r *ticketRepository) FindByBookingCode(code string) (*models.TicketWithDet
 	var ticket models.TicketWithDetails
 	query := `SELECT t.id, t.user_id, t.schedule_id, t.seat_number, 
     		  t.passenger_name, t.passenger_id_number, t.status, 
-    		  t.booking_code, t.total_price, t.created_at, t.modified_at, s.departure_station, s.arrival_station, s.departure_time,
+    		  t.booking_code, t.total_price, t.coach, t.created_at, t.modified_at, s.departure_station, s.arrival_station, s.departure_time, s.platform, ` + departureTimezoneColumn + `,
 			  tr.train_name, tr.train_code, tr.train_type, p.payment_code, p.payment_status FROM tickets t
-			  JOIN schedules s ON t.schedule_id = s.id
+			  JOIN schedules s ON t.schedule_id = s.id` + departureStationJoin + `
 			  JOIN trains tr ON s.train_id = tr.id
 			  LEFT JOIN payments p ON p.ticket_id = t.id
 			  WHERE t.booking_code = $1`
@@ -64,44 +70,57 @@ func (r *ticketRepository) FindByBookingCode(code string) (*models.TicketWithDet
 	if err != nil {
 		return nil, err
 	}
+	localizeTicket(&ticket)
 	return &ticket, nil
 }
 
-func (r *ticketRepository) FindByUserID(userID int) ([]models.TicketWithDetails, error) {
-	var tickets []models.TicketWithDetails
-	query := `SELECT t.id, t.user_id, t.schedule_id, t.seat_number, 
-			t.passenger_name, t.passenger_id_number, t.status, 
-			t.booking_code, t.total_price, t.created_at, t.modified_at,
-			s.departure_station AS departure_station,
-			s.arrival_station AS arrival_station,
-			s.departure_time AS departure_time,
-			tr.train_name AS train_name,
-			tr.train_code AS train_code,
-			tr.train_type AS train_type,
-			p.payment_code AS payment_code,
-			p.payment_status AS payment_status
-			FROM tickets t
-			JOIN schedules s ON t.schedule_id = s.id
-			JOIN trains tr ON s.train_id = tr.id
-			LEFT JOIN payments p ON p.ticket_id = t.id
-			WHERE t.user_id = $1
-			ORDER BY t.created_at DESC`
-	err := r.db.Select(&tickets, query, userID)
-	return tickets, err
+var ticketListSpec = listSpec{
+	sorts: map[string]sortField{
+		"created_at":     {Column: "t.created_at", Field: "created_at", Cast: "timestamp"},
+		"departure_time": {Column: "s.departure_time", Field: "departure_time", Cast: "timestamptz"},
+		"total_price":    {Column: "t.total_price", Field: "total_price", Cast: "numeric"},
+		"id":             {Column: "t.id", Field: "id", Cast: "int"},
+	},
+	defaultSort: "created_at",
+	defaultDesc: true,
+	idColumn:    "t.id",
 }
 
-func (r *ticketRepository) FindAll() ([]models.TicketWithDetails, error) {
+// FindAll mendukung filter status, user_id, schedule_id, train_id, rentang
+// tanggal pemesanan, dan pencarian kode booking atau nama penumpang.
+func (r *ticketRepository) FindAll(qs QuerySpec) ([]models.TicketWithDetails, Page, error) {
 	var tickets []models.TicketWithDetails
-	query := `SELECT t.id, t.user_id, t.schedule_id, t.seat_number, 
-    		  t.passenger_name, t.passenger_id_number, t.status, 
-    		  t.booking_code, t.total_price, t.created_at, t.modified_at, s.departure_station, s.arrival_station, s.departure_time,
-			  tr.train_name, tr.train_code, tr.train_type,p.payment_code, p.payment_status FROM tickets t
-			  JOIN schedules s ON t.schedule_id = s.id
+	from := `FROM tickets t
+			  JOIN schedules s ON t.schedule_id = s.id` + departureStationJoin + `
 			  JOIN trains tr ON s.train_id = tr.id
-			  LEFT JOIN payments p ON p.ticket_id = t.id
-			  ORDER BY t.created_at DESC`
-	err := r.db.Select(&tickets, query)
-	return tickets, err
+			  LEFT JOIN payments p ON p.ticket_id = t.id`
+	query := `SELECT t.id, COALESCE(t.user_id, 0) AS user_id, t.schedule_id, t.seat_number, 
+    		  t.passenger_name, t.passenger_id_number, t.status, 
+    		  t.booking_code, t.total_price, t.coach, t.created_at, t.modified_at, s.departure_station, s.arrival_station, s.departure_time, s.platform, ` + departureTimezoneColumn + `,
+			  tr.train_name, tr.train_code, tr.train_type, p.payment_code, p.payment_status ` + from
+
+	filters := &listQuery{}
+	if qs.Status != "" {
+		filters.add("t.status = ?", qs.Status)
+	}
+	if qs.UserID != 0 {
+		filters.add("t.user_id = ?", qs.UserID)
+	}
+	if qs.ScheduleID != 0 {
+		filters.add("t.schedule_id = ?", qs.ScheduleID)
+	}
+	if qs.TrainID != 0 {
+		filters.add("s.train_id = ?", qs.TrainID)
+	}
+	filters.dateRange("t.created_at", qs)
+	if qs.Search != "" {
+		pattern := likePattern(qs.Search)
+		filters.add("(t.booking_code ILIKE ? OR t.passenger_name ILIKE ?)", pattern, pattern)
+	}
+
+	page, err := selectPage(r.db, &tickets, ticketListSpec, qs, query, from, filters)
+	localizeTickets(tickets)
+	return tickets, page, err
 }
 
 func (r *ticketRepository) UpdateStatus(id int, status string, tx *sqlx.Tx) error {
@@ -110,10 +129,90 @@ func (r *ticketRepository) UpdateStatus(id int, status string, tx *sqlx.Tx) erro
 	return err
 }
 
+// TransitionStatus hanya mengubah status jika status saat ini masih from,
+// sehingga dua proses yang berebut tiket yang sama tidak saling menimpa.
+func (r *ticketRepository) TransitionStatus(id int, from, to string, tx *sqlx.Tx) (bool, error) {
+	query := `UPDATE tickets SET status = $1, modified_at = NOW() WHERE id = $2 AND status = $3`
+	result, err := tx.Exec(query, to, id, from)
+	if err != nil {
+		return false, err
+	}
+	rows, err := result.RowsAffected()
+	return rows > 0, err
+}
+
+// FindExpiredPending mengunci tiket pending yang dibuat sebelum batas waktu
+// pembayaran. Tiket yang sedang dikunci proses lain dilewati.
+func (r *ticketRepository) FindExpiredPending(before time.Time, limit int, tx *sqlx.Tx) ([]models.TicketWithDetails, error) {
+	var tickets []models.TicketWithDetails
+	query := `SELECT t.id, COALESCE(t.user_id, 0) AS user_id, t.schedule_id, t.seat_number,
+			  t.passenger_name, t.passenger_id_number, t.status,
+			  t.booking_code, t.total_price, t.coach, t.created_at, t.modified_at, s.departure_station, s.arrival_station, s.departure_time, s.platform, ` + departureTimezoneColumn + `,
+			  tr.train_name, tr.train_code, tr.train_type, p.payment_code, p.payment_status FROM tickets t
+			  JOIN schedules s ON t.schedule_id = s.id` + departureStationJoin + `
+			  JOIN trains tr ON s.train_id = tr.id
+			  LEFT JOIN payments p ON p.ticket_id = t.id
+			  WHERE t.status = 'pending' AND t.created_at < $1
+			  ORDER BY t.created_at
+			  LIMIT $2
+			  FOR UPDATE OF t SKIP LOCKED`
+	err := tx.Select(&tickets, query, before, limit)
+	localizeTickets(tickets)
+	return tickets, err
+}
+
+// FindActiveBySchedule mengunci semua tiket pending dan confirmed pada jadwal
+// tersebut. Berbeda dengan sweep, baris yang sedang dikunci proses lain
+// ditunggu karena setiap pemegang tiket harus ikut diproses.
+func (r *ticketRepository) FindActiveBySchedule(scheduleID int, tx *sqlx.Tx) ([]models.TicketWithDetails, error) {
+	var tickets []models.TicketWithDetails
+	query := `SELECT t.id, COALESCE(t.user_id, 0) AS user_id, t.schedule_id, t.seat_number,
+			  t.passenger_name, t.passenger_id_number, t.status,
+			  t.booking_code, t.total_price, t.coach, t.created_at, t.modified_at, s.departure_station, s.arrival_station, s.departure_time, s.platform, ` + departureTimezoneColumn + `,
+			  tr.train_name, tr.train_code, tr.train_type, p.payment_code, p.payment_status FROM tickets t
+			  JOIN schedules s ON t.schedule_id = s.id` + departureStationJoin + `
+			  JOIN trains tr ON s.train_id = tr.id
+			  LEFT JOIN payments p ON p.ticket_id = t.id
+			  WHERE t.schedule_id = $1 AND t.status IN ('pending', 'confirmed')
+			  ORDER BY t.id
+			  FOR UPDATE OF t`
+	err := tx.Select(&tickets, query, scheduleID)
+	localizeTickets(tickets)
+	return tickets, err
+}
+
 func (r *ticketRepository) CheckSeatAvailability(scheduleID int, seatNumber string) (bool, error) {
 	var count int
 	query := `SELECT COUNT(*) FROM tickets WHERE schedule_id = $1 AND seat_number = $2 
-			  AND status != 'cancelled'`
+			  AND status NOT IN ('cancelled', 'expired')`
 	err := r.db.Get(&count, query, scheduleID, seatNumber)
 	return count == 0, err
-}
\ No newline at end of file
+}
+
+// localizeTicket mengubah waktu berangkat ke zona waktu stasiun keberangkatan.
+func localizeTicket(ticket *models.TicketWithDetails) {
+	ticket.DepartureTime = ticket.DepartureTime.In(utils.Location(ticket.DepartureTimezone))
+}
+
+func localizeTickets(tickets []models.TicketWithDetails) {
+	for i := range tickets {
+		localizeTicket(&tickets[i])
+	}
+}
+
+// TicketCounts adalah jumlah tiket sebuah jadwal. Active menghitung tiket
+// pending dan confirmed yang memakai kursi.
+type TicketCounts struct {
+	Active int `db:"active"`
+	Total  int `db:"total"`
+}
+
+func (r *ticketRepository) CountBySchedule(scheduleID int, tx *sqlx.Tx) (*TicketCounts, error) {
+	var counts TicketCounts
+	query := `SELECT COUNT(*) FILTER (WHERE status IN ('pending', 'confirmed')) AS active, COUNT(*) AS total
+			  FROM tickets WHERE schedule_id = $1`
+	if err := tx.Get(&counts, query, scheduleID); err != nil {
+		return nil, err
+	}
+	return &counts, nil
+}
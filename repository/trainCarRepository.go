@@ -0,0 +1,45 @@
+package repository
+
+import (
+	"tiketsepur/models"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type TrainCarRepository interface {
+	FindByTrain(trainID int) ([]models.TrainCar, error)
+	Replace(trainID int, cars []models.TrainCar, tx *sqlx.Tx) error
+}
+
+type trainCarRepository struct {
+	db *sqlx.DB
+}
+
+func NewTrainCarRepository(db *sqlx.DB) TrainCarRepository {
+	return &trainCarRepository{db: db}
+}
+
+func (r *trainCarRepository) FindByTrain(trainID int) ([]models.TrainCar, error) {
+	cars := []models.TrainCar{}
+	query := `SELECT * FROM train_cars WHERE train_id = $1 ORDER BY position`
+	err := r.db.Select(&cars, query, trainID)
+	return cars, err
+}
+
+// Replace menghapus rangkaian lama lalu menyimpan cars sesuai urutannya.
+func (r *trainCarRepository) Replace(trainID int, cars []models.TrainCar, tx *sqlx.Tx) error {
+	if _, err := tx.Exec(`DELETE FROM train_cars WHERE train_id = $1`, trainID); err != nil {
+		return err
+	}
+	query := `INSERT INTO train_cars (train_id, position, car_code, car_type, car_class, seats, created_at)
+			  VALUES ($1, $2, $3, $4, $5, $6, NOW()) RETURNING id, created_at`
+	for i := range cars {
+		car := &cars[i]
+		car.TrainID = trainID
+		if err := tx.QueryRow(query, trainID, car.Position, car.CarCode, car.CarType, car.CarClass, car.Seats).
+			Scan(&car.ID, &car.CreatedAt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
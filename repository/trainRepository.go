@@ -2,6 +2,7 @@ package repository
 
 import (
 	"tiketsepur/models"
+	"time"
 
 	"github.com/jmoiron/sqlx"
 )
@@ -9,9 +10,12 @@ import (
 type TrainRepository interface {
 	Create(train *models.Train) error
 	FindByID(id int) (*models.Train, error)
-	FindAll() ([]models.Train, error)
+	FindAll(qs QuerySpec) ([]models.Train, Page, error)
 	Update(id int, train *models.Train) error
 	Delete(id int) error
+	FindByIDForUpdate(id int, tx *sqlx.Tx) (*models.Train, error)
+	UpdateTotalSeats(id, totalSeats int, tx *sqlx.Tx) error
+	Utilization(from, to time.Time) ([]models.TrainUtilization, error)
 }
 
 type trainRepository struct {
@@ -38,11 +42,36 @@ func (r *trainRepository) FindByID(id int) (*models.Train, error) {
 	return &train, nil
 }
 
-func (r *trainRepository) FindAll() ([]models.Train, error) {
+var trainListSpec = listSpec{
+	sorts: map[string]sortField{
+		"created_at":  {Column: "created_at", Field: "created_at", Cast: "timestamp"},
+		"train_code":  {Column: "train_code", Field: "train_code", Cast: "text"},
+		"train_name":  {Column: "train_name", Field: "train_name", Cast: "text"},
+		"total_seats": {Column: "total_seats", Field: "total_seats", Cast: "int"},
+		"id":          {Column: "id", Field: "id", Cast: "int"},
+	},
+	defaultSort: "created_at",
+	defaultDesc: true,
+	idColumn:    "id",
+}
+
+// FindAll mendukung filter tipe kereta dan pencarian kode atau nama kereta.
+func (r *trainRepository) FindAll(qs QuerySpec) ([]models.Train, Page, error) {
 	var trains []models.Train
-	query := `SELECT * FROM trains ORDER BY created_at DESC`
-	err := r.db.Select(&trains, query)
-	return trains, err
+	from := `FROM trains`
+
+	filters := &listQuery{}
+	if qs.TrainType != "" {
+		filters.add("train_type = ?", qs.TrainType)
+	}
+	filters.dateRange("created_at", qs)
+	if qs.Search != "" {
+		pattern := likePattern(qs.Search)
+		filters.add("(train_code ILIKE ? OR train_name ILIKE ?)", pattern, pattern)
+	}
+
+	page, err := selectPage(r.db, &trains, trainListSpec, qs, `SELECT * `+from, from, filters)
+	return trains, page, err
 }
 
 func (r *trainRepository) Update(id int, train *models.Train) error {
@@ -56,4 +85,35 @@ func (r *trainRepository) Delete(id int) error {
 	query := `DELETE FROM trains WHERE id = $1`
 	_, err := r.db.Exec(query, id)
 	return err
-}
\ No newline at end of file
+}
+
+func (r *trainRepository) FindByIDForUpdate(id int, tx *sqlx.Tx) (*models.Train, error) {
+	var train models.Train
+	if err := tx.Get(&train, `SELECT * FROM trains WHERE id = $1 FOR UPDATE`, id); err != nil {
+		return nil, err
+	}
+	return &train, nil
+}
+
+func (r *trainRepository) UpdateTotalSeats(id, totalSeats int, tx *sqlx.Tx) error {
+	query := `UPDATE trains SET total_seats = $1, modified_at = NOW() WHERE id = $2`
+	_, err := tx.Exec(query, totalSeats, id)
+	return err
+}
+
+// Utilization menghitung jumlah jadwal dan menit perjalanan setiap kereta
+// yang jatuh di rentang from sampai to. Jadwal yang melewati batas rentang
+// hanya dihitung bagian di dalamnya; jadwal yang dibatalkan tidak dihitung.
+func (r *trainRepository) Utilization(from, to time.Time) ([]models.TrainUtilization, error) {
+	utilization := []models.TrainUtilization{}
+	query := `SELECT t.id AS train_id, t.train_code, t.train_name, t.total_seats,
+			  COUNT(s.id) AS schedules,
+			  COALESCE(SUM(EXTRACT(EPOCH FROM (LEAST(s.arrival_time, $2) - GREATEST(s.departure_time, $1))) / 60), 0)::int AS scheduled_minutes
+			  FROM trains t
+			  LEFT JOIN schedules s ON s.train_id = t.id AND s.status != 'cancelled'
+			  AND s.departure_time < $2 AND s.arrival_time > $1
+			  GROUP BY t.id
+			  ORDER BY t.train_code, t.id`
+	err := r.db.Select(&utilization, query, from, to)
+	return utilization, err
+}
@@ -0,0 +1,65 @@
+package repository
+
+import (
+	"tiketsepur/models"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type TrainUnavailabilityRepository interface {
+	Create(unavailability *models.TrainUnavailability, tx *sqlx.Tx) error
+	FindByID(id int) (*models.TrainUnavailability, error)
+	FindByTrain(trainID int, from, to time.Time) ([]models.TrainUnavailability, error)
+	FindInRange(from, to time.Time) ([]models.TrainUnavailability, error)
+	Delete(id int) error
+}
+
+type trainUnavailabilityRepository struct {
+	db *sqlx.DB
+}
+
+func NewTrainUnavailabilityRepository(db *sqlx.DB) TrainUnavailabilityRepository {
+	return &trainUnavailabilityRepository{db: db}
+}
+
+func (r *trainUnavailabilityRepository) Create(unavailability *models.TrainUnavailability, tx *sqlx.Tx) error {
+	query := `INSERT INTO train_unavailabilities (train_id, reason, starts_at, ends_at, notes, created_by, created_at)
+			  VALUES ($1, $2, $3, $4, $5, $6, NOW()) RETURNING id, created_at`
+	return tx.QueryRow(query, unavailability.TrainID, unavailability.Reason, unavailability.StartsAt,
+		unavailability.EndsAt, unavailability.Notes, unavailability.CreatedBy).Scan(&unavailability.ID, &unavailability.CreatedAt)
+}
+
+func (r *trainUnavailabilityRepository) FindByID(id int) (*models.TrainUnavailability, error) {
+	var unavailability models.TrainUnavailability
+	if err := r.db.Get(&unavailability, `SELECT * FROM train_unavailabilities WHERE id = $1`, id); err != nil {
+		return nil, err
+	}
+	return &unavailability, nil
+}
+
+// FindByTrain mengembalikan rentang ketidaktersediaan kereta yang beririsan
+// dengan from sampai to.
+func (r *trainUnavailabilityRepository) FindByTrain(trainID int, from, to time.Time) ([]models.TrainUnavailability, error) {
+	unavailabilities := []models.TrainUnavailability{}
+	query := `SELECT * FROM train_unavailabilities
+			  WHERE train_id = $1 AND starts_at < $3 AND ends_at > $2
+			  ORDER BY starts_at, id`
+	err := r.db.Select(&unavailabilities, query, trainID, from, to)
+	return unavailabilities, err
+}
+
+// FindInRange sama dengan FindByTrain untuk semua kereta.
+func (r *trainUnavailabilityRepository) FindInRange(from, to time.Time) ([]models.TrainUnavailability, error) {
+	unavailabilities := []models.TrainUnavailability{}
+	query := `SELECT * FROM train_unavailabilities
+			  WHERE starts_at < $2 AND ends_at > $1
+			  ORDER BY train_id, starts_at, id`
+	err := r.db.Select(&unavailabilities, query, from, to)
+	return unavailabilities, err
+}
+
+func (r *trainUnavailabilityRepository) Delete(id int) error {
+	_, err := r.db.Exec(`DELETE FROM train_unavailabilities WHERE id = $1`, id)
+	return err
+}
@@ -0,0 +1,37 @@
+package repository
+
+import (
+	"tiketsepur/models"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type UserIdentityRepository interface {
+	Create(identity *models.UserIdentity) error
+	FindByProviderSubject(provider, subject string) (*models.UserIdentity, error)
+}
+
+type userIdentityRepository struct {
+	db *sqlx.DB
+}
+
+func NewUserIdentityRepository(db *sqlx.DB) UserIdentityRepository {
+	return &userIdentityRepository{db: db}
+}
+
+func (r *userIdentityRepository) Create(identity *models.UserIdentity) error {
+	query := `INSERT INTO user_identities (user_id, provider, subject, email, created_at)
+			  VALUES ($1, $2, $3, $4, NOW()) RETURNING id, created_at`
+	return r.db.QueryRow(query, identity.UserID, identity.Provider, identity.Subject,
+		identity.Email).Scan(&identity.ID, &identity.CreatedAt)
+}
+
+func (r *userIdentityRepository) FindByProviderSubject(provider, subject string) (*models.UserIdentity, error) {
+	var identity models.UserIdentity
+	query := `SELECT * FROM user_identities WHERE provider = $1 AND subject = $2`
+	err := r.db.Get(&identity, query, provider, subject)
+	if err != nil {
+		return nil, err
+	}
+	return &identity, nil
+}
@@ -10,7 +10,7 @@ type UserRepository interface {
 	Create(user *models.User) error
 	FindByID(id int) (*models.User, error)
 	FindByEmail(email string) (*models.User, error)
-	FindAll() ([]models.User, error)
+	FindAll(qs QuerySpec) ([]models.User, Page, error)
 	Update(id int, user *models.User) error
 	Delete(id int) error
 }
@@ -24,9 +24,9 @@ func NewUserRepository(db *sqlx.DB) UserRepository {
 }
 
 func (r *userRepository) Create(user *models.User) error {
-	query := `INSERT INTO users (email, password, full_name, phone, role, created_at) 
-			  VALUES ($1, $2, $3, $4, $5, NOW()) RETURNING id`
-	return r.db.QueryRow(query, user.Email, user.Password, user.FullName, user.Phone, user.Role).Scan(&user.ID)
+	query := `INSERT INTO users (email, password, full_name, phone, role, language, created_at) 
+			  VALUES ($1, $2, $3, $4, $5, COALESCE(NULLIF($6, ''), 'id'), NOW()) RETURNING id, language`
+	return r.db.QueryRow(query, user.Email, user.Password, user.FullName, user.Phone, user.Role, user.Language).Scan(&user.ID, &user.Language)
 }
 
 func (r *userRepository) FindByID(id int) (*models.User, error) {
@@ -49,16 +49,41 @@ func (r *userRepository) FindByEmail(email string) (*models.User, error) {
 	return &user, nil
 }
 
-func (r *userRepository) FindAll() ([]models.User, error) {
+var userListSpec = listSpec{
+	sorts: map[string]sortField{
+		"created_at": {Column: "created_at", Field: "created_at", Cast: "timestamp"},
+		"email":      {Column: "email", Field: "email", Cast: "text"},
+		"full_name":  {Column: "full_name", Field: "full_name", Cast: "text"},
+		"id":         {Column: "id", Field: "id", Cast: "int"},
+	},
+	defaultSort: "created_at",
+	defaultDesc: true,
+	idColumn:    "id",
+}
+
+// FindAll mendukung filter role, rentang tanggal daftar, dan pencarian email
+// atau nama.
+func (r *userRepository) FindAll(qs QuerySpec) ([]models.User, Page, error) {
 	var users []models.User
-	query := `SELECT * FROM users ORDER BY created_at DESC`
-	err := r.db.Select(&users, query)
-	return users, err
+	from := `FROM users`
+
+	filters := &listQuery{}
+	if qs.Role != "" {
+		filters.add("role = ?", qs.Role)
+	}
+	filters.dateRange("created_at", qs)
+	if qs.Search != "" {
+		pattern := likePattern(qs.Search)
+		filters.add("(email ILIKE ? OR full_name ILIKE ?)", pattern, pattern)
+	}
+
+	page, err := selectPage(r.db, &users, userListSpec, qs, `SELECT * `+from, from, filters)
+	return users, page, err
 }
 
 func (r *userRepository) Update(id int, user *models.User) error {
-	query := `UPDATE users SET email = $1, full_name = $2, phone = $3, role = $4, modified_at = NOW() WHERE id = $5`
-	_, err := r.db.Exec(query, user.Email, user.FullName, user.Phone, user.Role, id)
+	query := `UPDATE users SET email = $1, full_name = $2, phone = $3, role = $4, language = $5, modified_at = NOW() WHERE id = $6`
+	_, err := r.db.Exec(query, user.Email, user.FullName, user.Phone, user.Role, user.Language, id)
 	return err
 }
 
@@ -1,13 +1,10 @@
 package routes
 
 import (
-	"log"
-	config "tiketsepur/configs"
+	"tiketsepur/app"
 	"tiketsepur/controllers"
 	"tiketsepur/database/connection"
 	"tiketsepur/middleware"
-	"tiketsepur/repository"
-	"tiketsepur/service"
 
 	swaggerFiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
@@ -15,24 +12,22 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
-func StartServer() *gin.Engine{
-	cfg, err := config.LoadConfig()
-	if err != nil {
-		log.Fatal("gagal melakukan konfigurasi: ", err)
-	}
-	
-	userRepo := repository.NewUserRepository(connection.DB)
-	trainRepo := repository.NewTrainRepository(connection.DB)
-	scheduleRepo := repository.NewScheduleRepository(connection.DB)
-	ticketRepo := repository.NewTicketRepository(connection.DB)
-	paymentRepo := repository.NewPaymentRepository(connection.DB)
-
-	authService := service.NewAuthService(userRepo, connection.Redis, cfg)
-	userService := service.NewUserService(userRepo)
-	trainService := service.NewTrainService(trainRepo)
-	scheduleService := service.NewScheduleService(scheduleRepo, trainRepo)
-	ticketService := service.NewTicketService(connection.DB, ticketRepo, scheduleRepo, userRepo, paymentRepo, connection.Redis, connection.RabbitMQ)
-	paymentService := service.NewPaymentService(connection.DB, paymentRepo, ticketRepo, scheduleRepo, userRepo, connection.RabbitMQ)
+// StartServer hanya menyusun routing. Konsumen dan job latar belakang
+// dijalankan terpisah lewat Container.RunWorkers.
+func StartServer(container *app.Container) *gin.Engine {
+	cfg := container.Config
+
+	rbacService := container.RBACService
+	apiKeyService := container.APIKeyService
+	authService := container.AuthService
+	oidcService := container.OIDCService
+	userService := container.UserService
+	trainService := container.TrainService
+	scheduleService := container.ScheduleService
+	ticketService := container.TicketService
+	paymentService := container.PaymentService
+	notificationTemplateService := container.NotificationTemplateService
+	notificationService := container.NotificationService
 
 	authControllers := controllers.NewAuthControllers(authService, userService)
 	userControllers := controllers.NewUserControllers(userService)
@@ -40,6 +35,22 @@ func StartServer() *gin.Engine{
 	scheduleControllers := controllers.NewScheduleControllers(scheduleService)
 	ticketControllers := controllers.NewTicketControllers(ticketService)
 	paymentControllers := controllers.NewPaymentHandler(paymentService)
+	roleControllers := controllers.NewRoleControllers(rbacService)
+	apiKeyControllers := controllers.NewAPIKeyControllers(apiKeyService)
+	oidcControllers := controllers.NewOIDCControllers(oidcService)
+	notificationControllers := controllers.NewNotificationControllers(notificationService)
+	notificationTemplateControllers := controllers.NewNotificationTemplateControllers(notificationTemplateService)
+	scheduleDisruptionControllers := controllers.NewScheduleDisruptionControllers(container.ScheduleDisruptionService)
+	liveControllers := controllers.NewLiveControllers(container.LiveScheduleService)
+	fareCalendarControllers := controllers.NewFareCalendarControllers(container.FareCalendarService)
+	cacheControllers := controllers.NewCacheControllers(container.ScheduleCache)
+	scheduleTemplateControllers := controllers.NewScheduleTemplateControllers(container.ScheduleTemplateService)
+	stationControllers := controllers.NewStationControllers(container.StationService)
+	trainAvailabilityControllers := controllers.NewTrainAvailabilityControllers(container.TrainAvailabilityService)
+	staffControllers := controllers.NewStaffControllers(container.StaffService)
+	crewControllers := controllers.NewCrewControllers(container.CrewService, container.BoardingService)
+	eventControllers := controllers.NewEventControllers()
+	healthControllers := controllers.NewHealthControllers(connection.DB, connection.Redis, connection.RabbitMQ)
 
 	if cfg.Server.Mode == "release" {
 		gin.SetMode(gin.ReleaseMode)
@@ -52,44 +63,53 @@ func StartServer() *gin.Engine{
 	r.Use(func(c *gin.Context) {
 		c.Writer.Header().Set("Access-Control-Allow-Origin", "*")
 		c.Writer.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-		c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
-		
+		c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, X-API-Key")
+
 		if c.Request.Method == "OPTIONS" {
 			c.AbortWithStatus(204)
 			return
 		}
-		
+
 		c.Next()
 	})
-	
-	r.GET("/health", func(c *gin.Context) {
-		c.JSON(200, gin.H{
-			"status": "healthy",
-			"service": "train-ticket-api",
-		})
-	})
-	
+
+	r.GET("/.well-known/jwks.json", authControllers.JWKS)
+
+	r.GET("/health", healthControllers.Health)
+
 	api := r.Group("/api")
 	{
 		public := api.Group("/public")
 		{
 			public.GET("schedules", scheduleControllers.GetAll)
 			public.GET("/search", scheduleControllers.Search)
+			public.GET("/live/schedules", liveControllers.Schedules)
+			public.GET("/fare-calendar", fareCalendarControllers.Get)
+			public.GET("/stations", stationControllers.GetAll)
 			public.GET("/:id", scheduleControllers.GetByID)
 		}
+		events := api.Group("/events")
+		{
+			events.GET("/schemas", eventControllers.GetSchemas)
+			events.GET("/schemas/:name", eventControllers.GetSchema)
+		}
 		auth := api.Group("/auth")
 		{
 			auth.POST("/register", authControllers.Register)
-			auth.POST("/register-admin", authControllers.RegisterAdmin)
 			auth.POST("/login", authControllers.Login)
+			auth.GET("/oidc/:provider/login", oidcControllers.Login)
+			auth.GET("/oidc/:provider/callback", oidcControllers.Callback)
 		}
 
 		authenticated := api.Group("")
-		authenticated.Use(middleware.JWTAuthMiddleware(authService))
+		authenticated.Use(middleware.JWTAuthMiddleware(authService, apiKeyService))
 		{
 
 			authenticated.POST("/auth/logout", authControllers.Logout)
+			authenticated.POST("/auth/register-admin", middleware.RequirePermission(rbacService, "roles:manage"), authControllers.RegisterAdmin)
 			authenticated.GET("/auth/me", authControllers.Me)
+			authenticated.PUT("/auth/me/language", authControllers.UpdateLanguage)
+			authenticated.POST("/auth/unlock", middleware.RequirePermission(rbacService, "users:manage"), authControllers.Unlock)
 
 			tickets := authenticated.Group("/tickets")
 			{
@@ -97,6 +117,16 @@ func StartServer() *gin.Engine{
 				tickets.GET("/my-tickets", ticketControllers.GetMyTickets)
 				tickets.GET("/:id", ticketControllers.GetByID)
 				tickets.PUT("/:id/cancel", ticketControllers.Cancel)
+				tickets.GET("/:id/alternatives", ticketControllers.GetAlternatives)
+			}
+
+			notifications := authenticated.Group("/notifications")
+			{
+				notifications.GET("/preferences", notificationControllers.GetPreferences)
+				notifications.PUT("/preferences", notificationControllers.UpdatePreference)
+				notifications.GET("/deliveries", notificationControllers.GetDeliveries)
+				notifications.GET("/dead-letters", middleware.RequirePermission(rbacService, "notifications:manage"), notificationControllers.GetDeadLetters)
+				notifications.POST("/dead-letters/replay", middleware.RequirePermission(rbacService, "notifications:manage"), notificationControllers.ReplayDeadLetters)
 			}
 
 			payments := authenticated.Group("/payments")
@@ -105,42 +135,124 @@ func StartServer() *gin.Engine{
 				payments.GET("/status/:paymentCode", paymentControllers.GetPaymentStatus)
 			}
 
-			admin := authenticated.Group("")
-			admin.Use(middleware.AdminOnly())
+			users := authenticated.Group("/users")
+			users.Use(middleware.RequirePermission(rbacService, "users:manage"))
+			{
+				users.POST("", userControllers.Create)
+				users.GET("", userControllers.GetAll)
+				users.GET("/:id", userControllers.GetByID)
+				users.PUT("/:id", userControllers.Update)
+				users.DELETE("/:id", userControllers.Delete)
+			}
+
+			trains := authenticated.Group("/trains")
+			trains.Use(middleware.RequirePermission(rbacService, "trains:manage"))
+			{
+				trains.POST("", trainControllers.Create)
+				trains.GET("", trainControllers.GetAll)
+				trains.GET("/utilization", trainAvailabilityControllers.Utilization)
+				trains.GET("/:id", trainControllers.GetByID)
+				trains.PUT("/:id", trainControllers.Update)
+				trains.DELETE("/:id", trainControllers.Delete)
+				trains.GET("/:id/composition", trainControllers.GetComposition)
+				trains.PUT("/:id/composition", trainControllers.ReplaceComposition)
+				trains.POST("/:id/unavailability", trainAvailabilityControllers.Create)
+				trains.GET("/:id/unavailability", trainAvailabilityControllers.GetByTrain)
+				trains.DELETE("/:id/unavailability/:unavailabilityId", trainAvailabilityControllers.Delete)
+			}
+
+			adminSchedules := authenticated.Group("/schedules")
+			adminSchedules.Use(middleware.RequirePermission(rbacService, "schedules:manage"))
+			{
+				adminSchedules.POST("", scheduleControllers.Create)
+				adminSchedules.GET("/violations", scheduleControllers.GetViolations)
+				adminSchedules.PUT("/:id", scheduleControllers.Update)
+				adminSchedules.DELETE("/:id", scheduleControllers.Delete)
+				adminSchedules.POST("/:id/recompute-seats", scheduleControllers.RecomputeSeats)
+				adminSchedules.POST("/:id/disruptions", scheduleDisruptionControllers.Create)
+				adminSchedules.GET("/:id/disruptions", scheduleDisruptionControllers.GetBySchedule)
+			}
+
+			scheduleTemplates := authenticated.Group("/schedule-templates")
+			scheduleTemplates.Use(middleware.RequirePermission(rbacService, "schedules:manage"))
+			{
+				scheduleTemplates.POST("", scheduleTemplateControllers.Create)
+				scheduleTemplates.GET("", scheduleTemplateControllers.GetAll)
+				scheduleTemplates.GET("/:id", scheduleTemplateControllers.GetByID)
+				scheduleTemplates.PUT("/:id", scheduleTemplateControllers.Update)
+				scheduleTemplates.DELETE("/:id", scheduleTemplateControllers.Delete)
+				scheduleTemplates.POST("/:id/generate", scheduleTemplateControllers.Generate)
+			}
+
+			scheduleCrew := authenticated.Group("/schedules/:id/crew")
+			scheduleCrew.Use(middleware.RequirePermission(rbacService, "crew:manage"))
+			{
+				scheduleCrew.POST("", crewControllers.Assign)
+				scheduleCrew.GET("", crewControllers.GetBySchedule)
+				scheduleCrew.DELETE("/:assignmentId", crewControllers.Unassign)
+			}
+
+			staff := authenticated.Group("/staff")
+			staff.Use(middleware.RequirePermission(rbacService, "crew:manage"))
+			{
+				staff.POST("", staffControllers.Create)
+				staff.GET("", staffControllers.GetAll)
+				staff.GET("/:id", staffControllers.GetByID)
+				staff.PUT("/:id", staffControllers.Update)
+				staff.PUT("/:id/qualifications", staffControllers.ReplaceQualifications)
+				staff.GET("/:id/duties", staffControllers.GetDuties)
+			}
+
+			authenticated.GET("/crew/my-duties", staffControllers.GetMyDuties)
+			authenticated.POST("/boarding/scan", middleware.RequirePermission(rbacService, "tickets:board"), crewControllers.Board)
+
+			stations := authenticated.Group("/stations")
+			stations.Use(middleware.RequirePermission(rbacService, "schedules:manage"))
+			{
+				stations.POST("", stationControllers.Create)
+				stations.PUT("/:id", stationControllers.Update)
+				stations.DELETE("/:id", stationControllers.Delete)
+			}
+
+			adminTickets := authenticated.Group("/tickets")
+			adminTickets.Use(middleware.RequirePermission(rbacService, "tickets:read"))
 			{
-				users := admin.Group("/users")
-				{
-					users.POST("", userControllers.Create)
-					users.GET("", userControllers.GetAll)
-					users.GET("/:id", userControllers.GetByID)
-					users.PUT("/:id", userControllers.Update)
-					users.DELETE("/:id", userControllers.Delete)
-				}
-
-				trains := admin.Group("/trains")
-				{
-					trains.POST("", trainControllers.Create)
-					trains.GET("", trainControllers.GetAll)
-					trains.GET("/:id", trainControllers.GetByID)
-					trains.PUT("/:id", trainControllers.Update)
-					trains.DELETE("/:id", trainControllers.Delete)
-				}
-
-				adminSchedules := admin.Group("/schedules")
-				{
-					adminSchedules.POST("", scheduleControllers.Create)
-					adminSchedules.PUT("/:id", scheduleControllers.Update)
-					adminSchedules.DELETE("/:id", scheduleControllers.Delete)
-				}
-
-				adminTickets := admin.Group("/tickets")
-				{
-					adminTickets.GET("/all", ticketControllers.GetAll)
-				}
+				adminTickets.GET("/all", ticketControllers.GetAll)
 			}
+
+			roles := authenticated.Group("/roles")
+			roles.Use(middleware.RequirePermission(rbacService, "roles:manage"))
+			{
+				roles.POST("", roleControllers.Create)
+				roles.GET("", roleControllers.GetAll)
+				roles.GET("/:id", roleControllers.GetByID)
+				roles.DELETE("/:id", roleControllers.Delete)
+				roles.PUT("/:id/permissions", roleControllers.SetPermissions)
+			}
+
+			apiKeys := authenticated.Group("/api-keys")
+			apiKeys.Use(middleware.RequirePermission(rbacService, "api_keys:manage"))
+			{
+				apiKeys.POST("", apiKeyControllers.Create)
+				apiKeys.GET("", apiKeyControllers.GetAll)
+				apiKeys.DELETE("/:id", apiKeyControllers.Revoke)
+			}
+
+			notificationTemplates := authenticated.Group("/notification-templates")
+			notificationTemplates.Use(middleware.RequirePermission(rbacService, "notifications:manage"))
+			{
+				notificationTemplates.GET("", notificationTemplateControllers.GetAll)
+				notificationTemplates.POST("", notificationTemplateControllers.Create)
+				notificationTemplates.PUT("/:id/activate", notificationTemplateControllers.Activate)
+				notificationTemplates.POST("/preview", notificationTemplateControllers.Preview)
+			}
+
+			authenticated.GET("/cache/schedules", middleware.RequirePermission(rbacService, "schedules:manage"), cacheControllers.ScheduleStats)
+			authenticated.GET("/permissions", middleware.RequirePermission(rbacService, "roles:manage"), roleControllers.GetPermissions)
+			authenticated.PUT("/users/:id/role", middleware.RequirePermission(rbacService, "roles:manage"), roleControllers.AssignUser)
 		}
 	}
-	
+
 	return r
 
-}
\ No newline at end of file
+}
@@ -0,0 +1,205 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"tiketsepur/dto"
+	"tiketsepur/models"
+	"tiketsepur/repository"
+	"tiketsepur/utils"
+	"time"
+)
+
+const (
+	apiKeyPrefix           = "tks"
+	defaultAPIKeyRateLimit = 60
+)
+
+var ErrAPIKeyInvalid = errors.New("api key tidak valid")
+
+type APIKeyService interface {
+	Create(ctx context.Context, createdBy int, req dto.CreateAPIKeyRequest) (*dto.CreateAPIKeyResponse, error)
+	GetAll(query dto.ListQuery) ([]models.APIKey, repository.Page, error)
+	Revoke(id int) error
+	Authenticate(ctx context.Context, rawKey string) (*models.APIKey, *models.User, error)
+	Allow(ctx context.Context, key *models.APIKey) (bool, error)
+}
+
+type apiKeyService struct {
+	apiKeyRepo  repository.APIKeyRepository
+	userRepo    repository.UserRepository
+	rbacService RBACService
+	redis       *utils.RedisClient
+}
+
+func NewAPIKeyService(apiKeyRepo repository.APIKeyRepository, userRepo repository.UserRepository, rbacService RBACService, redis *utils.RedisClient) APIKeyService {
+	return &apiKeyService{
+		apiKeyRepo:  apiKeyRepo,
+		userRepo:    userRepo,
+		rbacService: rbacService,
+		redis:       redis,
+	}
+}
+
+func (s *apiKeyService) Create(ctx context.Context, createdBy int, req dto.CreateAPIKeyRequest) (*dto.CreateAPIKeyResponse, error) {
+	ownerID := req.UserID
+	if ownerID == 0 {
+		ownerID = createdBy
+	}
+
+	owner, err := s.userRepo.FindByID(ownerID)
+	if err != nil {
+		return nil, errors.New("user pemilik api key tidak ditemukan")
+	}
+
+	if req.ExpiresAt != nil && !req.ExpiresAt.After(time.Now()) {
+		return nil, errors.New("expires_at harus di masa depan")
+	}
+
+	// scope api key tidak boleh melebihi permission role pemiliknya
+	ownerPermissions, err := s.rbacService.GetPermissions(ctx, owner.Role)
+	if err != nil {
+		return nil, err
+	}
+	allowed := make(map[string]bool, len(ownerPermissions))
+	for _, p := range ownerPermissions {
+		allowed[p] = true
+	}
+	permissions := uniqueStrings(req.Permissions)
+	for _, p := range permissions {
+		if !allowed[p] {
+			return nil, fmt.Errorf("permission %s tidak dimiliki oleh role %s", p, owner.Role)
+		}
+	}
+
+	rateLimit := req.RateLimit
+	if rateLimit == 0 {
+		rateLimit = defaultAPIKeyRateLimit
+	}
+
+	prefix, err := randomHex(4)
+	if err != nil {
+		return nil, err
+	}
+	secret, err := randomHex(24)
+	if err != nil {
+		return nil, err
+	}
+	rawKey := fmt.Sprintf("%s_%s_%s", apiKeyPrefix, prefix, secret)
+
+	key := &models.APIKey{
+		Name:        req.Name,
+		KeyPrefix:   prefix,
+		KeyHash:     hashAPIKey(rawKey),
+		UserID:      owner.ID,
+		Permissions: permissions,
+		RateLimit:   rateLimit,
+		ExpiresAt:   req.ExpiresAt,
+		CreatedBy:   &createdBy,
+	}
+
+	if err := s.apiKeyRepo.Create(key); err != nil {
+		return nil, err
+	}
+
+	return &dto.CreateAPIKeyResponse{
+		Key:    rawKey,
+		APIKey: key,
+	}, nil
+}
+
+func (s *apiKeyService) GetAll(query dto.ListQuery) ([]models.APIKey, repository.Page, error) {
+	spec, err := querySpec(query)
+	if err != nil {
+		return nil, repository.Page{}, err
+	}
+	return s.apiKeyRepo.FindAll(spec)
+}
+
+func (s *apiKeyService) Revoke(id int) error {
+	key, err := s.apiKeyRepo.FindByID(id)
+	if err != nil {
+		return errors.New("api key tidak ditemukan")
+	}
+
+	if key.RevokedAt != nil {
+		return errors.New("api key sudah dicabut")
+	}
+
+	return s.apiKeyRepo.Revoke(id)
+}
+
+func (s *apiKeyService) Authenticate(ctx context.Context, rawKey string) (*models.APIKey, *models.User, error) {
+	parts := strings.Split(rawKey, "_")
+	if len(parts) != 3 || parts[0] != apiKeyPrefix {
+		return nil, nil, ErrAPIKeyInvalid
+	}
+
+	key, err := s.apiKeyRepo.FindByPrefix(parts[1])
+	if err != nil {
+		return nil, nil, ErrAPIKeyInvalid
+	}
+
+	if subtle.ConstantTimeCompare([]byte(key.KeyHash), []byte(hashAPIKey(rawKey))) != 1 {
+		return nil, nil, ErrAPIKeyInvalid
+	}
+
+	if key.RevokedAt != nil {
+		return nil, nil, errors.New("api key sudah dicabut")
+	}
+
+	if key.ExpiresAt != nil && key.ExpiresAt.Before(time.Now()) {
+		return nil, nil, errors.New("api key sudah expired")
+	}
+
+	user, err := s.userRepo.FindByID(key.UserID)
+	if err != nil {
+		return nil, nil, ErrAPIKeyInvalid
+	}
+
+	go func() {
+		if err := s.apiKeyRepo.TouchLastUsed(key.ID); err != nil {
+			log.Printf("gagal memperbarui last_used_at api key %d: %v", key.ID, err)
+		}
+	}()
+
+	return key, user, nil
+}
+
+// Allow menerapkan rate limit fixed window per menit untuk setiap api key.
+func (s *apiKeyService) Allow(ctx context.Context, key *models.APIKey) (bool, error) {
+	window := time.Now().Unix() / 60
+	counterKey := fmt.Sprintf("ratelimit:apikey:%d:%d", key.ID, window)
+
+	count, err := s.redis.Incr(ctx, counterKey)
+	if err != nil {
+		return false, err
+	}
+	if count == 1 {
+		if err := s.redis.Expire(ctx, counterKey, time.Minute); err != nil {
+			return false, err
+		}
+	}
+
+	return count <= int64(key.RateLimit), nil
+}
+
+func hashAPIKey(rawKey string) string {
+	sum := sha256.Sum256([]byte(rawKey))
+	return hex.EncodeToString(sum[:])
+}
+
+func randomHex(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -3,33 +3,45 @@ package service
 import (
 	"context"
 	"errors"
+	"log"
+	"strconv"
 	config "tiketsepur/configs"
 	"tiketsepur/dto"
 	"tiketsepur/models"
 	"tiketsepur/repository"
 	"tiketsepur/utils"
+	"time"
 
 	"golang.org/x/crypto/bcrypt"
 )
 
 type AuthService interface {
 	Register(req dto.RegisterRequest) (*models.User, error)
-	Login(ctx context.Context, req dto.LoginRequest) (*dto.LoginResponse, error)
+	Login(ctx context.Context, req dto.LoginRequest, clientIP string) (*dto.LoginResponse, error)
 	Logout(ctx context.Context, token string) error
 	ValidateToken(ctx context.Context, token string) (*utils.Claims, error)
+	Unlock(ctx context.Context, req dto.UnlockLoginRequest) error
+	CreateSession(ctx context.Context, user *models.User) (*dto.LoginResponse, error)
+	JWKS() utils.JWKS
 }
 
 type authService struct {
-	userRepo repository.UserRepository
-	redis    *utils.RedisClient
-	config   *config.Config
+	userRepo   repository.UserRepository
+	redis      *utils.RedisClient
+	rabbitmq   *utils.RabbitMQ
+	config     *config.Config
+	jwtKeys    *utils.JWTKeySet
+	loginGuard *loginGuard
 }
 
-func NewAuthService(userRepo repository.UserRepository, redis *utils.RedisClient, cfg *config.Config) AuthService {
+func NewAuthService(userRepo repository.UserRepository, redis *utils.RedisClient, rabbitmq *utils.RabbitMQ, cfg *config.Config, jwtKeys *utils.JWTKeySet) AuthService {
 	return &authService{
-		userRepo: userRepo,
-		redis:    redis,
-		config:   cfg,
+		userRepo:   userRepo,
+		redis:      redis,
+		rabbitmq:   rabbitmq,
+		config:     cfg,
+		jwtKeys:    jwtKeys,
+		loginGuard: newLoginGuard(redis, cfg.LoginProtection),
 	}
 }
 
@@ -50,6 +62,7 @@ func (s *authService) Register(req dto.RegisterRequest) (*models.User, error) {
 		FullName: req.FullName,
 		Phone:    req.Phone,
 		Role:     "user",
+		Language: req.Language,
 	}
 
 	if err := s.userRepo.Create(user); err != nil {
@@ -59,17 +72,33 @@ func (s *authService) Register(req dto.RegisterRequest) (*models.User, error) {
 	return user, nil
 }
 
-func (s *authService) Login(ctx context.Context, req dto.LoginRequest) (*dto.LoginResponse, error) {
+func (s *authService) Login(ctx context.Context, req dto.LoginRequest, clientIP string) (*dto.LoginResponse, error) {
+	if err := s.loginGuard.check(ctx, req.Email, clientIP); err != nil {
+		return nil, err
+	}
+
 	user, err := s.userRepo.FindByEmail(req.Email)
 	if err != nil {
+		s.handleLoginFailure(ctx, nil, req.Email, clientIP)
 		return nil, errors.New("credentials tidak valid ")
 	}
 
 	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(req.Password)); err != nil {
+		s.handleLoginFailure(ctx, user, req.Email, clientIP)
 		return nil, errors.New("credentials tidak valid ")
 	}
 
-	token, err := utils.GenerateJWT(user.ID, user.Email, user.Role, s.config.JWT.Secret, s.config.JWT.Exp)
+	if err := s.loginGuard.reset(ctx, req.Email); err != nil {
+		log.Printf("gagal mereset percobaan login %s: %v", req.Email, err)
+	}
+
+	return s.CreateSession(ctx, user)
+}
+
+// CreateSession menerbitkan JWT dan menyimpan sesinya di redis. Dipakai oleh
+// login password maupun login OIDC.
+func (s *authService) CreateSession(ctx context.Context, user *models.User) (*dto.LoginResponse, error) {
+	token, err := utils.GenerateJWT(user.ID, user.Email, user.Role, s.jwtKeys, s.config.JWT.Exp)
 	if err != nil {
 		return nil, err
 	}
@@ -107,10 +136,52 @@ func (s *authService) ValidateToken(ctx context.Context, token string) (*utils.C
 		return nil, errors.New("token tidak ditemukan atau expired")
 	}
 
-	claims, err := utils.ValidateJWT(token, s.config.JWT.Secret)
+	claims, err := utils.ValidateJWT(token, s.jwtKeys)
 	if err != nil {
 		return nil, err
 	}
 
+	if role, err := s.redis.Get(ctx, "user:role:"+strconv.Itoa(claims.UserID)); err == nil && role != "" {
+		claims.Role = role
+	}
+
 	return claims, nil
-}
\ No newline at end of file
+}
+
+func (s *authService) JWKS() utils.JWKS {
+	return s.jwtKeys.JWKS()
+}
+
+func (s *authService) Unlock(ctx context.Context, req dto.UnlockLoginRequest) error {
+	if req.Email == "" && req.IP == "" {
+		return errors.New("email atau ip harus diisi")
+	}
+	return s.loginGuard.unlock(ctx, req.Email, req.IP)
+}
+
+func (s *authService) handleLoginFailure(ctx context.Context, user *models.User, email, clientIP string) {
+	lockout, err := s.loginGuard.recordFailure(ctx, email, clientIP)
+	if err != nil {
+		log.Printf("gagal mencatat percobaan login %s: %v", email, err)
+		return
+	}
+
+	if lockout == 0 || user == nil {
+		return
+	}
+
+	lockedUntil := time.Now().Add(lockout)
+	go func() {
+		event, err := utils.NewDomainEvent(utils.EventUserLockedOut, 1, "user", strconv.Itoa(user.ID), utils.UserLockedOutEvent{
+			UserID:      user.ID,
+			LockedUntil: lockedUntil.UTC(),
+		})
+		if err != nil {
+			log.Printf("gagal membuat event penguncian akun: %v", err)
+			return
+		}
+		if err := s.rabbitmq.PublishEvent(context.Background(), event); err != nil {
+			log.Printf("gagal mengirim notifikasi penguncian akun: %v", err)
+		}
+	}()
+}
@@ -0,0 +1,129 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	config "tiketsepur/configs"
+	"tiketsepur/dto"
+	"tiketsepur/models"
+	"tiketsepur/repository"
+	"tiketsepur/utils"
+	"time"
+)
+
+// scan tiket dibuka 2 jam sebelum keberangkatan jika
+// crew.boarding_opens_before tidak diisi
+const defaultBoardingOpensBefore = 2 * time.Hour
+
+// BoardingService mencatat scan tiket oleh kondektur. Scan hanya diterima
+// dari staf yang ditugaskan sebagai kondektur di jadwal tiket tersebut, mulai
+// crew.boarding_opens_before sebelum keberangkatan sampai kereta tiba,
+// termasuk keterlambatannya.
+type BoardingService interface {
+	Scan(userID int, req dto.BoardingScanRequest) (*dto.BoardingScanResponse, error)
+}
+
+type boardingService struct {
+	boardingRepo repository.BoardingScanRepository
+	ticketRepo   repository.TicketRepository
+	staffRepo    repository.StaffRepository
+	crewRepo     repository.CrewAssignmentRepository
+	scheduleRepo repository.ScheduleRepository
+	opensBefore  time.Duration
+}
+
+func NewBoardingService(boardingRepo repository.BoardingScanRepository, ticketRepo repository.TicketRepository, staffRepo repository.StaffRepository, crewRepo repository.CrewAssignmentRepository, scheduleRepo repository.ScheduleRepository, cfg *config.Config) BoardingService {
+	opensBefore := cfg.Crew.BoardingOpensBefore
+	if opensBefore <= 0 {
+		opensBefore = defaultBoardingOpensBefore
+	}
+	return &boardingService{
+		boardingRepo: boardingRepo,
+		ticketRepo:   ticketRepo,
+		staffRepo:    staffRepo,
+		crewRepo:     crewRepo,
+		scheduleRepo: scheduleRepo,
+		opensBefore:  opensBefore,
+	}
+}
+
+func (s *boardingService) Scan(userID int, req dto.BoardingScanRequest) (*dto.BoardingScanResponse, error) {
+	staff, err := s.staffRepo.FindByUserID(userID)
+	if err != nil {
+		return nil, errors.New("akun ini tidak terhubung dengan data staf")
+	}
+	if !staff.IsActive {
+		return nil, errors.New("staf tidak aktif")
+	}
+
+	ticket, err := s.ticketRepo.FindByBookingCode(strings.TrimSpace(req.BookingCode))
+	if err != nil {
+		return nil, errors.New("tiket tidak ditemukan")
+	}
+	assigned, err := s.crewRepo.IsAssigned(ticket.ScheduleID, staff.ID, models.DutyConductor)
+	if err != nil {
+		return nil, err
+	}
+	if !assigned {
+		return nil, errors.New("kamu tidak bertugas sebagai kondektur di jadwal tiket ini")
+	}
+	if ticket.Status != "confirmed" {
+		return nil, fmt.Errorf("tiket berstatus %s tidak bisa boarding", ticket.Status)
+	}
+
+	schedule, err := s.scheduleRepo.FindByID(ticket.ScheduleID)
+	if err != nil {
+		return nil, errors.New("jadwal tiket tidak ditemukan")
+	}
+	if err := boardingWindow(schedule, s.opensBefore, time.Now()); err != nil {
+		return nil, err
+	}
+
+	scan := &models.BoardingScan{TicketID: ticket.ID, ScheduleID: ticket.ScheduleID, StaffID: &staff.ID}
+	created, err := s.boardingRepo.Create(scan)
+	if err != nil {
+		return nil, err
+	}
+	loc := utils.Location(ticket.DepartureTimezone)
+	if !created {
+		existing, err := s.boardingRepo.FindByTicket(ticket.ID)
+		if err != nil {
+			return nil, err
+		}
+		return nil, fmt.Errorf("tiket sudah discan pada %s", utils.FormatLocalTime(existing.ScannedAt.In(loc)))
+	}
+
+	scan.ScannedAt = scan.ScannedAt.In(loc)
+
+	return &dto.BoardingScanResponse{
+		Scan:              *scan,
+		BookingCode:       ticket.BookingCode,
+		PassengerName:     ticket.PassengerName,
+		PassengerIDNumber: ticket.PassengerIDNumber,
+		Coach:             ticket.Coach,
+		SeatNumber:        ticket.SeatNumber,
+		TrainName:         ticket.TrainName,
+		DepartureStation:  ticket.DepartureStation,
+		ArrivalStation:    ticket.ArrivalStation,
+	}, nil
+}
+
+// boardingWindow menolak scan sebelum boarding dibuka, setelah kereta tiba
+// (ditambah keterlambatannya), atau untuk jadwal yang dibatalkan.
+func boardingWindow(schedule *models.Schedule, opensBefore time.Duration, now time.Time) error {
+	if schedule.Status == models.ScheduleStatusCancelled {
+		return errors.New("jadwal tiket sudah dibatalkan")
+	}
+
+	loc := utils.Location(schedule.DepartureTimezone)
+	opens := schedule.DepartureTime.Add(-opensBefore)
+	if now.Before(opens) {
+		return fmt.Errorf("boarding baru dibuka pada %s", utils.FormatLocalTime(opens.In(loc)))
+	}
+	closes := schedule.ArrivalTime.Add(time.Duration(schedule.DelayMinutes) * time.Minute)
+	if now.After(closes) {
+		return errors.New("kereta sudah tiba, tiket tidak bisa discan lagi")
+	}
+	return nil
+}
@@ -0,0 +1,45 @@
+package service
+
+import (
+	"testing"
+	"tiketsepur/models"
+	"time"
+)
+
+func TestBoardingWindow(t *testing.T) {
+	schedule := &models.Schedule{
+		DepartureTime:     wibAt(10, 8, 0),
+		ArrivalTime:       wibAt(10, 12, 0),
+		DepartureTimezone: "Asia/Jakarta",
+		Status:            models.ScheduleStatusScheduled,
+	}
+
+	cases := []struct {
+		name string
+		now  time.Time
+		ok   bool
+	}{
+		{"terlalu awal", wibAt(10, 5, 59), false},
+		{"boarding dibuka", wibAt(10, 6, 0), true},
+		{"dalam perjalanan", wibAt(10, 10, 0), true},
+		{"tepat tiba", wibAt(10, 12, 0), true},
+		{"sudah tiba", wibAt(10, 12, 1), false},
+	}
+	for _, tc := range cases {
+		if err := boardingWindow(schedule, 2*time.Hour, tc.now); (err == nil) != tc.ok {
+			t.Errorf("%s: boardingWindow error = %v, want ok = %v", tc.name, err, tc.ok)
+		}
+	}
+
+	delayed := *schedule
+	delayed.DelayMinutes = 30
+	if err := boardingWindow(&delayed, 2*time.Hour, wibAt(10, 12, 20)); err != nil {
+		t.Errorf("kereta terlambat 30 menit seharusnya masih bisa discan: %v", err)
+	}
+
+	cancelled := *schedule
+	cancelled.Status = models.ScheduleStatusCancelled
+	if err := boardingWindow(&cancelled, 2*time.Hour, wibAt(10, 7, 0)); err == nil {
+		t.Error("tiket jadwal yang dibatalkan seharusnya tidak bisa discan")
+	}
+}
@@ -0,0 +1,151 @@
+package service
+
+import (
+	"context"
+	"log"
+	config "tiketsepur/configs"
+	"tiketsepur/repository"
+	"tiketsepur/utils"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+const bookingExpiryBatchSize = 100
+
+type BookingExpiryService interface {
+	ExpireOnce(ctx context.Context) (int, error)
+	Run(ctx context.Context)
+}
+
+type bookingExpiryService struct {
+	db            *sqlx.DB
+	ticketRepo    repository.TicketRepository
+	paymentRepo   repository.PaymentRepository
+	scheduleRepo  repository.ScheduleRepository
+	scheduleCache repository.ScheduleCache
+	outboxService OutboxService
+	liveSchedules LiveScheduleService
+	fareCalendar  FareCalendarService
+	config        config.BookingConfig
+}
+
+func NewBookingExpiryService(
+	db *sqlx.DB,
+	ticketRepo repository.TicketRepository,
+	paymentRepo repository.PaymentRepository,
+	scheduleRepo repository.ScheduleRepository,
+	scheduleCache repository.ScheduleCache,
+	outboxService OutboxService,
+	liveSchedules LiveScheduleService,
+	fareCalendar FareCalendarService,
+	cfg *config.Config,
+) BookingExpiryService {
+	bookingCfg := cfg.Booking
+	if bookingCfg.PaymentTimeout <= 0 {
+		bookingCfg.PaymentTimeout = 30 * time.Minute
+	}
+	if bookingCfg.ExpirySweepInterval <= 0 {
+		bookingCfg.ExpirySweepInterval = time.Minute
+	}
+
+	return &bookingExpiryService{
+		db:            db,
+		ticketRepo:    ticketRepo,
+		paymentRepo:   paymentRepo,
+		scheduleRepo:  scheduleRepo,
+		scheduleCache: scheduleCache,
+		outboxService: outboxService,
+		liveSchedules: liveSchedules,
+		fareCalendar:  fareCalendar,
+		config:        bookingCfg,
+	}
+}
+
+// ExpireOnce membatalkan satu batch tiket pending yang melewati batas waktu
+// pembayaran: status tiket dan payment menjadi expired, kursi dikembalikan,
+// dan event ticket.expired dimasukkan ke outbox dalam transaksi yang sama.
+func (s *bookingExpiryService) ExpireOnce(ctx context.Context) (int, error) {
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	tickets, err := s.ticketRepo.FindExpiredPending(time.Now().Add(-s.config.PaymentTimeout), bookingExpiryBatchSize, tx)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, ticket := range tickets {
+		if err := s.ticketRepo.UpdateStatus(ticket.ID, "expired", tx); err != nil {
+			return 0, err
+		}
+		if err := s.paymentRepo.UpdateStatusTxByTicketID(ticket.ID, "expired", tx); err != nil {
+			return 0, err
+		}
+		if err := s.scheduleRepo.IncrementSeat(ticket.ScheduleID, tx); err != nil {
+			return 0, err
+		}
+
+		expired := utils.TicketExpiredEvent{
+			TicketID:         ticket.ID,
+			BookingCode:      ticket.BookingCode,
+			UserID:           ticket.UserID,
+			ScheduleID:       ticket.ScheduleID,
+			TrainName:        ticket.TrainName,
+			DepartureStation: ticket.DepartureStation,
+			ArrivalStation:   ticket.ArrivalStation,
+			SeatNumber:       ticket.SeatNumber,
+			TotalPrice:       ticket.TotalPrice,
+		}
+		if err := enqueueEvent(s.outboxService, tx, utils.EventTicketExpired, "ticket", ticket.BookingCode, expired); err != nil {
+			return 0, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+
+	scheduleIDs := make(map[int]struct{})
+	for _, ticket := range tickets {
+		if _, ok := scheduleIDs[ticket.ScheduleID]; ok {
+			continue
+		}
+		scheduleIDs[ticket.ScheduleID] = struct{}{}
+		s.scheduleCache.InvalidateSchedules(ticket.ScheduleID)
+		s.liveSchedules.Publish(ctx, ticket.ScheduleID)
+		s.fareCalendar.Invalidate(ctx, ticket.DepartureStation, ticket.ArrivalStation, ticket.DepartureTime)
+	}
+	return len(tickets), nil
+}
+
+// Run menjalankan sweep sampai ctx selesai. Sweep aman dijalankan di beberapa
+// worker sekaligus karena tiket yang sedang diproses worker lain dilewati.
+func (s *bookingExpiryService) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.config.ExpirySweepInterval)
+	defer ticker.Stop()
+
+	for {
+		for {
+			expired, err := s.ExpireOnce(ctx)
+			if err != nil {
+				log.Printf("gagal menjalankan sweep tiket kedaluwarsa: %v", err)
+				break
+			}
+			if expired > 0 {
+				log.Printf("%d tiket kedaluwarsa dibatalkan", expired)
+			}
+			if expired < bookingExpiryBatchSize {
+				break
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
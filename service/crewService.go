@@ -0,0 +1,227 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	config "tiketsepur/configs"
+	"tiketsepur/dto"
+	"tiketsepur/models"
+	"tiketsepur/repository"
+	"tiketsepur/utils"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// waktu istirahat minimum antara dua tugas jika crew.min_rest tidak diisi
+const defaultCrewMinRest = 10 * time.Hour
+
+// CrewService menugaskan masinis dan kondektur ke jadwal. Setiap jadwal
+// punya paling banyak satu masinis, staf harus aktif dan punya kualifikasi
+// yang berlaku di tanggal keberangkatan, tugasnya tidak boleh beririsan, dan
+// jeda antara dua tugas minimal crew.min_rest.
+type CrewService interface {
+	Assign(scheduleID int, req dto.CreateCrewAssignmentRequest, createdBy int) (*models.CrewAssignment, error)
+	GetBySchedule(scheduleID int) ([]models.CrewAssignment, error)
+	Unassign(scheduleID, assignmentID int) error
+	CheckSchedule(schedule *models.Schedule, tx *sqlx.Tx) error
+}
+
+type crewService struct {
+	db           *sqlx.DB
+	crewRepo     repository.CrewAssignmentRepository
+	staffRepo    repository.StaffRepository
+	scheduleRepo repository.ScheduleRepository
+	minRest      time.Duration
+}
+
+func NewCrewService(db *sqlx.DB, crewRepo repository.CrewAssignmentRepository, staffRepo repository.StaffRepository, scheduleRepo repository.ScheduleRepository, cfg *config.Config) CrewService {
+	minRest := cfg.Crew.MinRest
+	if minRest <= 0 {
+		minRest = defaultCrewMinRest
+	}
+	return &crewService{
+		db:           db,
+		crewRepo:     crewRepo,
+		staffRepo:    staffRepo,
+		scheduleRepo: scheduleRepo,
+		minRest:      minRest,
+	}
+}
+
+// Assign mengunci jadwal lalu staf, sehingga dua penugasan ke jadwal yang
+// sama atau untuk staf yang sama tidak bisa lolos pemeriksaan bersamaan.
+func (s *crewService) Assign(scheduleID int, req dto.CreateCrewAssignmentRequest, createdBy int) (*models.CrewAssignment, error) {
+	tx, err := s.db.Beginx()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	schedule, err := s.scheduleRepo.FindByIDForUpdate(scheduleID, tx)
+	if err != nil {
+		return nil, errors.New("jadwal tidak ditemukan")
+	}
+	if schedule.Status == models.ScheduleStatusCancelled {
+		return nil, errors.New("jadwal sudah dibatalkan")
+	}
+	if !schedule.DepartureTime.After(time.Now()) {
+		return nil, errors.New("jadwal sudah berangkat")
+	}
+
+	staff, err := s.staffRepo.FindByIDForUpdate(req.StaffID, tx)
+	if err != nil {
+		return nil, errors.New("staf tidak ditemukan")
+	}
+
+	result := &utils.ValidationError{}
+	if !staff.IsActive {
+		result.Add("staff_id", models.ViolationStaffInactive, "staf tidak aktif")
+	}
+	if !qualifiedFor(staff, req.Duty, schedule.DepartureTime) {
+		result.Add("duty", models.ViolationNotQualified, fmt.Sprintf(
+			"staf tidak punya kualifikasi %s yang berlaku pada %s", req.Duty, schedule.DepartureTime.Format("2006-01-02")))
+	}
+
+	crew, err := s.crewRepo.FindBySchedule(scheduleID)
+	if err != nil {
+		return nil, err
+	}
+	addCrewConflicts(result, crew, staff.ID, req.Duty)
+
+	nearby, err := s.crewRepo.FindByStaffInRange(staff.ID, schedule.DepartureTime.Add(-s.minRest), schedule.ArrivalTime.Add(s.minRest), tx)
+	if err != nil {
+		return nil, err
+	}
+	addDutyConflicts(result, "staff_id", "staf", schedule, nearby, s.minRest)
+	if err := result.Err(); err != nil {
+		return nil, err
+	}
+
+	assignment := &models.CrewAssignment{
+		ScheduleID: scheduleID,
+		StaffID:    staff.ID,
+		Duty:       req.Duty,
+		CreatedBy:  &createdBy,
+	}
+	if err := s.crewRepo.Create(assignment, tx); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return s.crewRepo.FindByID(assignment.ID)
+}
+
+func (s *crewService) GetBySchedule(scheduleID int) ([]models.CrewAssignment, error) {
+	if _, err := s.scheduleRepo.FindByID(scheduleID); err != nil {
+		return nil, errors.New("jadwal tidak ditemukan")
+	}
+	return s.crewRepo.FindBySchedule(scheduleID)
+}
+
+// Unassign menolak menghapus tugas jadwal yang sudah berangkat supaya
+// riwayat kru perjalanan tetap tersimpan.
+func (s *crewService) Unassign(scheduleID, assignmentID int) error {
+	assignment, err := s.crewRepo.FindByID(assignmentID)
+	if err != nil || assignment.ScheduleID != scheduleID {
+		return errors.New("penugasan kru tidak ditemukan")
+	}
+	if !assignment.DepartureTime.After(time.Now()) {
+		return errors.New("tugas jadwal yang sudah berangkat tidak bisa dihapus")
+	}
+	return s.crewRepo.Delete(assignmentID)
+}
+
+// CheckSchedule memeriksa ulang kru jadwal yang waktunya diubah, di dalam
+// transaksi yang sudah mengunci jadwal tersebut. Kualifikasi setiap kru harus
+// masih berlaku di tanggal keberangkatan baru, dan tugasnya tidak boleh
+// beririsan atau terlalu dekat dengan tugas lain staf itu. Staf dikunci
+// berurutan id supaya tidak balapan dengan Assign untuk staf yang sama.
+func (s *crewService) CheckSchedule(schedule *models.Schedule, tx *sqlx.Tx) error {
+	crew, err := s.crewRepo.FindBySchedule(schedule.ID)
+	if err != nil {
+		return err
+	}
+	sort.Slice(crew, func(i, j int) bool { return crew[i].StaffID < crew[j].StaffID })
+
+	result := &utils.ValidationError{}
+	for _, member := range crew {
+		staff, err := s.staffRepo.FindByIDForUpdate(member.StaffID, tx)
+		if err != nil {
+			return err
+		}
+		if !qualifiedFor(staff, member.Duty, schedule.DepartureTime) {
+			result.Add("departure_time", models.ViolationNotQualified, fmt.Sprintf(
+				"%s tidak punya kualifikasi %s yang berlaku pada %s", staff.Name, member.Duty, schedule.DepartureTime.Format("2006-01-02")))
+		}
+
+		nearby, err := s.crewRepo.FindByStaffInRange(staff.ID, schedule.DepartureTime.Add(-s.minRest), schedule.ArrivalTime.Add(s.minRest), tx)
+		if err != nil {
+			return err
+		}
+		addDutyConflicts(result, "departure_time", staff.Name, schedule, nearby, s.minRest)
+	}
+	return result.Err()
+}
+
+// addCrewConflicts memeriksa kru jadwal yang sudah ada: staf yang sama tidak
+// boleh ditugaskan dua kali dan jadwal hanya punya satu masinis.
+func addCrewConflicts(result *utils.ValidationError, crew []models.CrewAssignment, staffID int, duty string) {
+	for _, member := range crew {
+		if member.StaffID == staffID {
+			result.Add("staff_id", models.ViolationStaffAlreadyAssigned, "staf sudah ditugaskan di jadwal ini")
+		} else if duty == models.DutyDriver && member.Duty == models.DutyDriver {
+			result.Add("duty", models.ViolationDriverAlreadyAssigned, fmt.Sprintf("jadwal sudah punya masinis %s", member.StaffName))
+		}
+	}
+}
+
+// addDutyConflicts memeriksa tugas lain staf terhadap jadwal: tugas yang
+// beririsan melanggar crew_overlap, dan tugas yang jedanya kurang dari
+// minRest melanggar crew_rest_too_short. who adalah subjek pesan, misalnya
+// "staf" atau nama staf.
+func addDutyConflicts(result *utils.ValidationError, field, who string, schedule *models.Schedule, duties []models.CrewAssignment, minRest time.Duration) {
+	for _, duty := range duties {
+		if duty.ScheduleID == schedule.ID {
+			continue
+		}
+		if duty.DepartureTime.Before(schedule.ArrivalTime) && duty.ArrivalTime.After(schedule.DepartureTime) {
+			result.Add(field, models.ViolationCrewOverlap, fmt.Sprintf("%s sudah bertugas di jadwal %d (%s - %s)",
+				who, duty.ScheduleID, utils.FormatLocalTime(duty.DepartureTime), utils.FormatLocalTime(duty.ArrivalTime)))
+			continue
+		}
+
+		gap := schedule.DepartureTime.Sub(duty.ArrivalTime)
+		if duty.DepartureTime.After(schedule.DepartureTime) {
+			gap = duty.DepartureTime.Sub(schedule.ArrivalTime)
+		}
+		if gap < minRest {
+			result.Add(field, models.ViolationCrewRestTooShort, fmt.Sprintf("jeda %s dengan tugas di jadwal %d kurang dari %s",
+				who, duty.ScheduleID, formatRest(minRest)))
+		}
+	}
+}
+
+// qualifiedFor memeriksa kualifikasi di tanggal lokal keberangkatan;
+// sertifikasi masih berlaku sampai akhir hari valid_until.
+func qualifiedFor(staff *models.Staff, duty string, departure time.Time) bool {
+	date := departure.Format("2006-01-02")
+	for _, qualification := range staff.Qualifications {
+		if qualification.Qualification != duty {
+			continue
+		}
+		if qualification.ValidUntil == nil || date <= qualification.ValidUntil.Format("2006-01-02") {
+			return true
+		}
+	}
+	return false
+}
+
+func formatRest(d time.Duration) string {
+	if d%time.Hour == 0 {
+		return fmt.Sprintf("%d jam", int(d.Hours()))
+	}
+	return fmt.Sprintf("%d menit", int(d.Minutes()))
+}
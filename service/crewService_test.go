@@ -0,0 +1,147 @@
+package service
+
+import (
+	"testing"
+	"tiketsepur/models"
+	"tiketsepur/utils"
+	"time"
+)
+
+func testDuty(scheduleID int, departure, arrival time.Time) models.CrewAssignment {
+	return models.CrewAssignment{ScheduleID: scheduleID, DepartureTime: departure, ArrivalTime: arrival}
+}
+
+func fieldCodes(result *utils.ValidationError) []string {
+	codes := make([]string, len(result.Fields))
+	for i, field := range result.Fields {
+		codes[i] = field.Field + ":" + field.Code
+	}
+	return codes
+}
+
+func TestAddDutyConflicts(t *testing.T) {
+	schedule := &models.Schedule{ID: 1, DepartureTime: wibAt(10, 8, 0), ArrivalTime: wibAt(10, 12, 0)}
+	minRest := 10 * time.Hour
+
+	cases := []struct {
+		name string
+		duty models.CrewAssignment
+		want string
+	}{
+		{"jadwal itu sendiri", testDuty(1, wibAt(10, 8, 0), wibAt(10, 12, 0)), ""},
+		{"beririsan", testDuty(2, wibAt(10, 11, 0), wibAt(10, 15, 0)), "staff_id:" + models.ViolationCrewOverlap},
+		{"menutupi seluruh jadwal", testDuty(2, wibAt(10, 6, 0), wibAt(10, 14, 0)), "staff_id:" + models.ViolationCrewOverlap},
+		{"jeda sebelum kurang", testDuty(2, wibAt(9, 20, 0), wibAt(9, 23, 0)), "staff_id:" + models.ViolationCrewRestTooShort},
+		{"jeda sesudah kurang", testDuty(2, wibAt(10, 13, 0), wibAt(10, 16, 0)), "staff_id:" + models.ViolationCrewRestTooShort},
+		{"jeda tepat min_rest sebelum", testDuty(2, wibAt(9, 18, 0), wibAt(9, 22, 0)), ""},
+		{"jeda tepat min_rest sesudah", testDuty(2, wibAt(10, 22, 0), wibAt(11, 2, 0)), ""},
+		{"bersambung tanpa jeda", testDuty(2, wibAt(10, 12, 0), wibAt(10, 14, 0)), "staff_id:" + models.ViolationCrewRestTooShort},
+	}
+	for _, tc := range cases {
+		result := &utils.ValidationError{}
+		addDutyConflicts(result, "staff_id", "staf", schedule, []models.CrewAssignment{tc.duty}, minRest)
+
+		codes := fieldCodes(result)
+		if tc.want == "" && len(codes) != 0 {
+			t.Errorf("%s: pelanggaran = %v, want tidak ada", tc.name, codes)
+		}
+		if tc.want != "" && (len(codes) != 1 || codes[0] != tc.want) {
+			t.Errorf("%s: pelanggaran = %v, want %s", tc.name, codes, tc.want)
+		}
+	}
+}
+
+func TestAddDutyConflictsUsesFieldAndName(t *testing.T) {
+	schedule := &models.Schedule{ID: 1, DepartureTime: wibAt(10, 8, 0), ArrivalTime: wibAt(10, 12, 0)}
+
+	// saat jadwal diubah waktunya, pelanggaran dilaporkan di departure_time
+	// dengan nama staf yang bermasalah
+	result := &utils.ValidationError{}
+	addDutyConflicts(result, "departure_time", "Budi", schedule, []models.CrewAssignment{testDuty(7, wibAt(10, 10, 0), wibAt(10, 14, 0))}, time.Hour)
+	if len(result.Fields) != 1 || result.Fields[0].Field != "departure_time" {
+		t.Fatalf("fields = %+v", result.Fields)
+	}
+	if want := "Budi sudah bertugas di jadwal 7"; result.Fields[0].Message[:len(want)] != want {
+		t.Errorf("message = %q", result.Fields[0].Message)
+	}
+}
+
+func TestAddCrewConflicts(t *testing.T) {
+	crew := []models.CrewAssignment{
+		{StaffID: 1, Duty: models.DutyDriver, StaffName: "Budi"},
+		{StaffID: 2, Duty: models.DutyConductor, StaffName: "Sari"},
+	}
+
+	cases := []struct {
+		name    string
+		staffID int
+		duty    string
+		want    []string
+	}{
+		{"masinis kedua", 3, models.DutyDriver, []string{"duty:" + models.ViolationDriverAlreadyAssigned}},
+		{"kondektur tambahan", 3, models.DutyConductor, nil},
+		{"staf yang sama", 2, models.DutyConductor, []string{"staff_id:" + models.ViolationStaffAlreadyAssigned}},
+		{"masinis yang sama", 1, models.DutyDriver, []string{"staff_id:" + models.ViolationStaffAlreadyAssigned}},
+	}
+	for _, tc := range cases {
+		result := &utils.ValidationError{}
+		addCrewConflicts(result, crew, tc.staffID, tc.duty)
+
+		codes := fieldCodes(result)
+		if len(codes) != len(tc.want) {
+			t.Errorf("%s: pelanggaran = %v, want %v", tc.name, codes, tc.want)
+			continue
+		}
+		for i := range codes {
+			if codes[i] != tc.want[i] {
+				t.Errorf("%s: pelanggaran = %v, want %v", tc.name, codes, tc.want)
+			}
+		}
+	}
+}
+
+func TestQualifiedFor(t *testing.T) {
+	validUntil := time.Date(2026, 11, 10, 0, 0, 0, 0, time.UTC)
+	staff := &models.Staff{Qualifications: []models.StaffQualification{
+		{Qualification: models.DutyDriver, ValidUntil: &validUntil},
+		{Qualification: models.DutyConductor},
+	}}
+
+	cases := []struct {
+		name      string
+		duty      string
+		departure time.Time
+		want      bool
+	}{
+		{"sebelum kedaluwarsa", models.DutyDriver, wibAt(9, 8, 0), true},
+		// sertifikasi berlaku sampai akhir hari valid_until
+		{"hari terakhir", models.DutyDriver, wibAt(10, 23, 30), true},
+		{"sudah kedaluwarsa", models.DutyDriver, wibAt(11, 0, 30), false},
+		// tanggal dibaca di zona stasiun keberangkatan: di UTC masih tanggal 10
+		{"lewat tengah malam WITA", models.DutyDriver, time.Date(2026, 11, 11, 0, 30, 0, 0, testWITA), false},
+		{"tanpa valid_until", models.DutyConductor, time.Date(2030, 1, 1, 8, 0, 0, 0, testWIB), true},
+		{"kualifikasi lain", "mechanic", wibAt(9, 8, 0), false},
+	}
+	for _, tc := range cases {
+		if got := qualifiedFor(staff, tc.duty, tc.departure); got != tc.want {
+			t.Errorf("%s: qualifiedFor = %v, want %v", tc.name, got, tc.want)
+		}
+	}
+
+	if qualifiedFor(&models.Staff{}, models.DutyDriver, time.Now()) {
+		t.Error("staf tanpa kualifikasi seharusnya tidak memenuhi syarat")
+	}
+}
+
+func TestFormatRest(t *testing.T) {
+	cases := map[time.Duration]string{
+		10 * time.Hour:   "10 jam",
+		90 * time.Minute: "90 menit",
+		45 * time.Minute: "45 menit",
+	}
+	for d, want := range cases {
+		if got := formatRest(d); got != want {
+			t.Errorf("formatRest(%s) = %q, want %q", d, got, want)
+		}
+	}
+}
@@ -0,0 +1,112 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	config "tiketsepur/configs"
+	"tiketsepur/dto"
+	"tiketsepur/models"
+	"tiketsepur/repository"
+	"tiketsepur/utils"
+	"time"
+)
+
+type FareCalendarService interface {
+	Get(ctx context.Context, req dto.FareCalendarRequest) (*dto.FareCalendarResponse, error)
+	Invalidate(ctx context.Context, departureStation, arrivalStation string, departureTime time.Time)
+}
+
+type fareCalendarService struct {
+	scheduleRepo repository.ScheduleRepository
+	stations     StationService
+	redis        *utils.RedisClient
+	ttl          time.Duration
+}
+
+func NewFareCalendarService(scheduleRepo repository.ScheduleRepository, stations StationService, redis *utils.RedisClient, cfg *config.Config) FareCalendarService {
+	ttl := cfg.Cache.FareCalendarTTL
+	if ttl <= 0 {
+		ttl = 15 * time.Minute
+	}
+	return &fareCalendarService{
+		scheduleRepo: scheduleRepo,
+		stations:     stations,
+		redis:        redis,
+		ttl:          ttl,
+	}
+}
+
+// Get mengembalikan satu entri untuk setiap hari di bulan tersebut menurut
+// tanggal lokal stasiun keberangkatan. Hasil disimpan di redis per rute dan
+// bulan, lalu dihapus lewat Invalidate setiap kali jadwal atau jumlah kursi di
+// rute itu berubah. TTL hanya jaring pengaman jika invalidasi gagal atau zona
+// waktu stasiun diganti.
+func (s *fareCalendarService) Get(ctx context.Context, req dto.FareCalendarRequest) (*dto.FareCalendarResponse, error) {
+	month, err := time.Parse("2006-01", req.Month)
+	if err != nil {
+		return nil, errors.New("month harus berformat YYYY-MM")
+	}
+	from := strings.TrimSpace(req.From)
+	to := strings.TrimSpace(req.To)
+	if strings.EqualFold(from, to) {
+		return nil, errors.New("stasiun asal dan tujuan tidak boleh sama")
+	}
+
+	key := fareCalendarKey(from, to, month)
+	if cached, err := s.redis.Get(ctx, key); err == nil {
+		var response dto.FareCalendarResponse
+		if err := json.Unmarshal([]byte(cached), &response); err == nil {
+			return &response, nil
+		}
+	}
+
+	rows, err := s.scheduleRepo.FareCalendar(from, to, month.Format("2006-01-02"))
+	if err != nil {
+		return nil, err
+	}
+
+	byDate := make(map[string]models.FareCalendarDay, len(rows))
+	for _, row := range rows {
+		byDate[row.Date] = row
+	}
+
+	response := &dto.FareCalendarResponse{From: from, To: to, Month: req.Month}
+	for day := month; day.Month() == month.Month(); day = day.AddDate(0, 0, 1) {
+		date := day.Format("2006-01-02")
+		entry, ok := byDate[date]
+		if !ok {
+			entry = models.FareCalendarDay{Date: date}
+		}
+		response.Days = append(response.Days, entry)
+	}
+
+	if body, err := json.Marshal(response); err == nil {
+		if err := s.redis.Set(ctx, key, body, s.ttl); err != nil {
+			log.Printf("gagal menyimpan cache fare calendar %s: %v", key, err)
+		}
+	}
+
+	return response, nil
+}
+
+// Invalidate menghapus cache rute dan bulan yang memuat keberangkatan
+// tersebut, dihitung di zona waktu stasiun keberangkatan. Kegagalan hanya
+// dicatat; cache akan kedaluwarsa lewat TTL.
+func (s *fareCalendarService) Invalidate(ctx context.Context, departureStation, arrivalStation string, departureTime time.Time) {
+	local := departureTime.In(s.stations.Location(departureStation))
+	key := fareCalendarKey(departureStation, arrivalStation, local)
+	if err := s.redis.Delete(ctx, key); err != nil {
+		log.Printf("gagal menghapus cache fare calendar %s: %v", key, err)
+	}
+}
+
+func fareCalendarKey(departureStation, arrivalStation string, month time.Time) string {
+	return fmt.Sprintf("fare_calendar:%s:%s:%s",
+		strings.ToLower(strings.TrimSpace(departureStation)),
+		strings.ToLower(strings.TrimSpace(arrivalStation)),
+		month.Format("2006-01"))
+}
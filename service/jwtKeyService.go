@@ -0,0 +1,239 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	config "tiketsepur/configs"
+	"tiketsepur/models"
+	"tiketsepur/repository"
+	"tiketsepur/utils"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+const (
+	jwtKeyReloadInterval = time.Minute
+	jwtKeyRotateLockTTL  = time.Minute
+	jwtKeyInitRetryDelay = 2 * time.Second
+)
+
+type JWTKeyService interface {
+	Init(ctx context.Context) error
+	Load() error
+	RotateIfDue(ctx context.Context) error
+	Run(ctx context.Context)
+}
+
+type jwtKeyService struct {
+	db      *sqlx.DB
+	keyRepo repository.JWTSigningKeyRepository
+	redis   *utils.RedisClient
+	config  *config.Config
+	keys    *utils.JWTKeySet
+}
+
+func NewJWTKeyService(db *sqlx.DB, keyRepo repository.JWTSigningKeyRepository, redis *utils.RedisClient, cfg *config.Config, keys *utils.JWTKeySet) JWTKeyService {
+	s := &jwtKeyService{
+		db:      db,
+		keyRepo: keyRepo,
+		redis:   redis,
+		config:  cfg,
+		keys:    keys,
+	}
+	keys.SetRefreshFunc(s.Load)
+	return s
+}
+
+func (s *jwtKeyService) asymmetric() bool {
+	return s.config.JWT.Algorithm == "RS256" || s.config.JWT.Algorithm == "EdDSA"
+}
+
+// Init dipanggil saat startup. Jika instance lain sedang memegang lock
+// rotasi, key aktif belum ada di database sehingga Load gagal; Init mencoba
+// lagi sampai lock rotasi kedaluwarsa, bukan jatuh ke HS256.
+func (s *jwtKeyService) Init(ctx context.Context) error {
+	deadline := time.Now().Add(jwtKeyRotateLockTTL + jwtKeyInitRetryDelay)
+	for {
+		err := s.RotateIfDue(ctx)
+		if err == nil {
+			err = s.Load()
+		}
+		if err == nil || time.Now().After(deadline) {
+			return err
+		}
+
+		log.Printf("jwt signing key belum siap, mencoba lagi: %v", err)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(jwtKeyInitRetryDelay):
+		}
+	}
+}
+
+// Load membaca ulang key dari database ke dalam key set. Key terbaru yang
+// belum pensiun dengan algoritma yang dikonfigurasi menjadi key penanda tangan.
+// Untuk RS256/EdDSA, Load gagal tanpa mengubah key set jika key aktif tidak ada.
+func (s *jwtKeyService) Load() error {
+	rows, err := s.keyRepo.FindUsable()
+	if err != nil {
+		return err
+	}
+
+	var keyCipher *utils.KeyCipher
+	if len(rows) > 0 || s.asymmetric() {
+		if keyCipher, err = utils.NewKeyCipher(s.config.JWT.KeyEncryptionKey); err != nil {
+			return err
+		}
+	}
+
+	var active *utils.SigningKey
+	keys := make([]*utils.SigningKey, 0, len(rows))
+	for _, row := range rows {
+		privateKeyPEM, err := s.privateKeyPEM(keyCipher, row)
+		if err != nil {
+			log.Printf("gagal membaca jwt signing key %s: %v", row.KID, err)
+			continue
+		}
+		key, err := utils.ParseSigningKey(row.KID, row.Algorithm, privateKeyPEM)
+		if err != nil {
+			log.Printf("gagal membaca jwt signing key %s: %v", row.KID, err)
+			continue
+		}
+		keys = append(keys, key)
+		if active == nil && row.RetiredAt == nil && row.Algorithm == s.config.JWT.Algorithm {
+			active = key
+		}
+	}
+
+	if !s.asymmetric() {
+		active = nil
+	} else if active == nil {
+		return fmt.Errorf("belum ada jwt signing key %s yang aktif", s.config.JWT.Algorithm)
+	}
+	s.keys.SetKeys(active, keys)
+	return nil
+}
+
+// privateKeyPEM mendekripsi private key dari database. Baris lama yang masih
+// berupa PEM polos dienkripsi ulang di tempat.
+func (s *jwtKeyService) privateKeyPEM(keyCipher *utils.KeyCipher, row models.JWTSigningKey) (string, error) {
+	if utils.IsEncryptedKey(row.PrivateKey) {
+		return keyCipher.Decrypt(row.KID, row.PrivateKey)
+	}
+
+	encrypted, err := keyCipher.Encrypt(row.KID, row.PrivateKey)
+	if err != nil {
+		return "", err
+	}
+	if err := s.keyRepo.UpdatePrivateKey(row.KID, encrypted); err != nil {
+		log.Printf("gagal mengenkripsi ulang jwt signing key %s: %v", row.KID, err)
+	}
+	return row.PrivateKey, nil
+}
+
+func (s *jwtKeyService) RotateIfDue(ctx context.Context) error {
+	if !s.asymmetric() {
+		return nil
+	}
+
+	rows, err := s.keyRepo.FindUsable()
+	if err != nil {
+		return err
+	}
+	for _, row := range rows {
+		if row.RetiredAt == nil && row.Algorithm == s.config.JWT.Algorithm &&
+			(s.config.JWT.RotationInterval <= 0 || time.Since(row.CreatedAt) < s.config.JWT.RotationInterval) {
+			return nil
+		}
+	}
+
+	// hanya satu instance yang boleh merotasi key pada satu waktu
+	locked, err := s.redis.SetNX(ctx, "lock:jwt:rotate", "1", jwtKeyRotateLockTTL)
+	if err != nil {
+		return err
+	}
+	if !locked {
+		return nil
+	}
+	defer s.redis.Delete(ctx, "lock:jwt:rotate")
+
+	if err := s.rotate(); err != nil {
+		return err
+	}
+	return s.Load()
+}
+
+func (s *jwtKeyService) rotate() error {
+	keyCipher, err := utils.NewKeyCipher(s.config.JWT.KeyEncryptionKey)
+	if err != nil {
+		return err
+	}
+
+	kid, err := utils.RandomURLString(12)
+	if err != nil {
+		return err
+	}
+
+	key, err := utils.GenerateSigningKey(kid, s.config.JWT.Algorithm)
+	if err != nil {
+		return err
+	}
+	privateKeyPEM, err := key.PrivateKeyPEM()
+	if err != nil {
+		return err
+	}
+	encryptedKey, err := keyCipher.Encrypt(key.KID, privateKeyPEM)
+	if err != nil {
+		return err
+	}
+
+	tx, err := s.db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// key lama tetap dipublikasikan sampai token terakhir yang ditandatanganinya expired
+	if err := s.keyRepo.RetireActive(key.Algorithm, time.Now().Add(s.config.JWT.Exp), tx); err != nil {
+		return err
+	}
+
+	if err := s.keyRepo.Create(&models.JWTSigningKey{
+		KID:        key.KID,
+		Algorithm:  key.Algorithm,
+		PrivateKey: encryptedKey,
+	}, tx); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	log.Printf("jwt signing key dirotasi, kid baru %s", key.KID)
+	return nil
+}
+
+// Run memuat ulang key secara berkala supaya semua instance melihat hasil
+// rotasi, dan merotasi key ketika sudah melewati RotationInterval.
+func (s *jwtKeyService) Run(ctx context.Context) {
+	ticker := time.NewTicker(jwtKeyReloadInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := s.RotateIfDue(ctx); err != nil {
+				log.Printf("gagal merotasi jwt signing key: %v", err)
+			}
+			if err := s.Load(); err != nil {
+				log.Printf("gagal memuat jwt signing key: %v", err)
+			}
+		}
+	}
+}
@@ -0,0 +1,40 @@
+package service
+
+import (
+	"fmt"
+	"tiketsepur/dto"
+	"tiketsepur/repository"
+	"time"
+)
+
+// querySpec mengubah query string list menjadi QuerySpec repository. Tanggal
+// to bersifat inklusif sehingga dikirim ke repository sebagai awal hari
+// berikutnya.
+func querySpec(q dto.ListQuery) (repository.QuerySpec, error) {
+	spec := repository.QuerySpec{
+		Limit:      q.Limit,
+		Offset:     q.Offset,
+		Cursor:     q.Cursor,
+		Sort:       q.Sort,
+		Status:     q.Status,
+		Role:       q.Role,
+		TrainType:  q.TrainType,
+		TrainID:    q.TrainID,
+		UserID:     q.UserID,
+		ScheduleID: q.ScheduleID,
+		From:       q.From,
+		Search:     q.Q,
+	}
+	if q.Order != "" {
+		desc := q.Order == "desc"
+		spec.Desc = &desc
+	}
+	if q.To != nil {
+		to := q.To.Add(24 * time.Hour)
+		spec.To = &to
+	}
+	if spec.From != nil && spec.To != nil && !spec.From.Before(*spec.To) {
+		return spec, fmt.Errorf("%w: from tidak boleh setelah to", repository.ErrInvalidQuerySpec)
+	}
+	return spec, nil
+}
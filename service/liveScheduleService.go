@@ -0,0 +1,211 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"sync"
+	"tiketsepur/models"
+	"tiketsepur/repository"
+	"tiketsepur/utils"
+	"time"
+)
+
+// kapasitas buffer per subscriber; update yang menumpuk di client lambat
+// dibuang dari yang paling lama karena setiap update berisi kondisi lengkap
+const liveSubscriberBuffer = 16
+
+var ErrLiveUpdatesClosed = errors.New("live update sedang dihentikan")
+
+type LiveScheduleService interface {
+	Publish(ctx context.Context, scheduleID int)
+	PublishDeleted(ctx context.Context, scheduleID int)
+	Snapshot(scheduleID int) (*utils.ScheduleUpdate, error)
+	Subscribe(scheduleIDs []int) (<-chan utils.ScheduleUpdate, func(), error)
+	Run(ctx context.Context) error
+}
+
+type liveSubscriber struct {
+	updates chan utils.ScheduleUpdate
+}
+
+type liveScheduleService struct {
+	scheduleRepo repository.ScheduleRepository
+	redis        *utils.RedisClient
+
+	mu          sync.Mutex
+	subscribers map[int]map[*liveSubscriber]struct{}
+	closed      bool
+}
+
+func NewLiveScheduleService(scheduleRepo repository.ScheduleRepository, redis *utils.RedisClient) LiveScheduleService {
+	return &liveScheduleService{
+		scheduleRepo: scheduleRepo,
+		redis:        redis,
+		subscribers:  make(map[int]map[*liveSubscriber]struct{}),
+	}
+}
+
+// Publish membaca kondisi terbaru jadwal lalu menyiarkannya ke semua instance
+// lewat redis. Dipanggil setelah commit; kegagalan hanya dicatat karena update
+// berikutnya tetap membawa kondisi lengkap.
+func (s *liveScheduleService) Publish(ctx context.Context, scheduleID int) {
+	update, err := s.Snapshot(scheduleID)
+	if err != nil {
+		log.Printf("gagal membaca jadwal %d untuk live update: %v", scheduleID, err)
+		return
+	}
+	update.Event = utils.ScheduleUpdateChanged
+	s.publish(ctx, update)
+}
+
+func (s *liveScheduleService) PublishDeleted(ctx context.Context, scheduleID int) {
+	s.publish(ctx, &utils.ScheduleUpdate{
+		ScheduleID: scheduleID,
+		Event:      utils.ScheduleUpdateDeleted,
+		UpdatedAt:  time.Now(),
+	})
+}
+
+func (s *liveScheduleService) publish(ctx context.Context, update *utils.ScheduleUpdate) {
+	body, err := json.Marshal(update)
+	if err != nil {
+		log.Printf("gagal encode live update jadwal %d: %v", update.ScheduleID, err)
+		return
+	}
+	if err := s.redis.Publish(ctx, utils.ScheduleUpdatesChannel(update.ScheduleID), body); err != nil {
+		log.Printf("gagal publish live update jadwal %d: %v", update.ScheduleID, err)
+	}
+}
+
+func (s *liveScheduleService) Snapshot(scheduleID int) (*utils.ScheduleUpdate, error) {
+	schedule, err := s.scheduleRepo.FindByID(scheduleID)
+	if err != nil {
+		return nil, err
+	}
+	return scheduleUpdateFrom(schedule), nil
+}
+
+func scheduleUpdateFrom(schedule *models.Schedule) *utils.ScheduleUpdate {
+	updatedAt := schedule.ModifiedAt
+	if updatedAt.IsZero() {
+		updatedAt = schedule.CreatedAt
+	}
+	return &utils.ScheduleUpdate{
+		ScheduleID:     schedule.ID,
+		Event:          utils.ScheduleUpdateSnapshot,
+		AvailableSeats: schedule.AvailableSeats,
+		Status:         schedule.Status,
+		DelayMinutes:   schedule.DelayMinutes,
+		Platform:       schedule.Platform,
+		DepartureTime:  schedule.DepartureTime,
+		ArrivalTime:    schedule.ArrivalTime,
+		Price:          schedule.Price,
+		UpdatedAt:      updatedAt,
+	}
+}
+
+// Subscribe mendaftarkan subscriber lokal untuk jadwal tertentu. Channel
+// ditutup saat Run berhenti; fungsi yang dikembalikan wajib dipanggil saat
+// client memutus koneksi.
+func (s *liveScheduleService) Subscribe(scheduleIDs []int) (<-chan utils.ScheduleUpdate, func(), error) {
+	sub := &liveSubscriber{updates: make(chan utils.ScheduleUpdate, liveSubscriberBuffer)}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return nil, nil, ErrLiveUpdatesClosed
+	}
+	for _, id := range scheduleIDs {
+		if s.subscribers[id] == nil {
+			s.subscribers[id] = make(map[*liveSubscriber]struct{})
+		}
+		s.subscribers[id][sub] = struct{}{}
+	}
+
+	var once sync.Once
+	unsubscribe := func() {
+		once.Do(func() {
+			s.mu.Lock()
+			defer s.mu.Unlock()
+			for _, id := range scheduleIDs {
+				delete(s.subscribers[id], sub)
+				if len(s.subscribers[id]) == 0 {
+					delete(s.subscribers, id)
+				}
+			}
+		})
+	}
+	return sub.updates, unsubscribe, nil
+}
+
+// Run berlangganan semua channel jadwal di redis dan meneruskan update ke
+// subscriber lokal sampai ctx selesai. Setiap instance api menjalankan satu Run.
+func (s *liveScheduleService) Run(ctx context.Context) error {
+	pubsub := s.redis.PSubscribe(ctx, utils.ScheduleUpdatesChannelPrefix+"*")
+	defer pubsub.Close()
+	defer s.close()
+
+	messages := pubsub.Channel()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case msg, ok := <-messages:
+			if !ok {
+				return errors.New("subscription redis tertutup")
+			}
+			scheduleID, ok := utils.ScheduleIDFromChannel(msg.Channel)
+			if !ok {
+				continue
+			}
+			var update utils.ScheduleUpdate
+			if err := json.Unmarshal([]byte(msg.Payload), &update); err != nil {
+				log.Printf("live update jadwal %d tidak valid: %v", scheduleID, err)
+				continue
+			}
+			s.broadcast(scheduleID, update)
+		}
+	}
+}
+
+func (s *liveScheduleService) broadcast(scheduleID int, update utils.ScheduleUpdate) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for sub := range s.subscribers[scheduleID] {
+		select {
+		case sub.updates <- update:
+			continue
+		default:
+		}
+		// buffer penuh: buang update terlama supaya yang terbaru tetap masuk
+		select {
+		case <-sub.updates:
+		default:
+		}
+		select {
+		case sub.updates <- update:
+		default:
+		}
+	}
+}
+
+// close menutup semua subscriber supaya koneksi sse selesai saat shutdown.
+func (s *liveScheduleService) close() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.closed = true
+	closed := make(map[*liveSubscriber]struct{})
+	for _, subs := range s.subscribers {
+		for sub := range subs {
+			if _, ok := closed[sub]; !ok {
+				close(sub.updates)
+				closed[sub] = struct{}{}
+			}
+		}
+	}
+	s.subscribers = make(map[int]map[*liveSubscriber]struct{})
+}
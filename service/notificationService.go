@@ -0,0 +1,317 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	config "tiketsepur/configs"
+	"tiketsepur/dto"
+	"tiketsepur/models"
+	"tiketsepur/repository"
+	"tiketsepur/utils"
+	"time"
+)
+
+const (
+	deliveryStatusSent    = "sent"
+	deliveryStatusFailed  = "failed"
+	deliveryStatusSkipped = "skipped"
+
+	notificationSendTimeout = 30 * time.Second
+	deadLetterDefaultBatch  = 20
+	deadLetterMaxBatch      = 500
+	webhookResolveTimeout   = 5 * time.Second
+)
+
+type NotificationService interface {
+	Handle(ctx context.Context, messageID string, msg utils.NotificationMessage) error
+	GetPreferences(userID int) ([]models.NotificationPreference, error)
+	UpdatePreference(userID int, req dto.UpdateNotificationPreferenceRequest) (*models.NotificationPreference, error)
+	GetDeliveries(userID int, query dto.ListQuery) ([]models.NotificationDelivery, repository.Page, error)
+	GetDeadLetters(limit int) ([]utils.DeadLetter, error)
+	ReplayDeadLetters(ctx context.Context, req dto.ReplayDeadLettersRequest) ([]string, error)
+}
+
+type notificationService struct {
+	notificationRepo repository.NotificationRepository
+	userRepo         repository.UserRepository
+	templateService  NotificationTemplateService
+	notifiers        map[string]utils.Notifier
+	rabbitmq         *utils.RabbitMQ
+	config           *config.Config
+}
+
+func NewNotificationService(
+	notificationRepo repository.NotificationRepository,
+	userRepo repository.UserRepository,
+	templateService NotificationTemplateService,
+	notifiers []utils.Notifier,
+	rabbitmq *utils.RabbitMQ,
+	cfg *config.Config,
+) NotificationService {
+	byChannel := make(map[string]utils.Notifier, len(notifiers))
+	for _, n := range notifiers {
+		byChannel[n.Channel()] = n
+	}
+
+	return &notificationService{
+		notificationRepo: notificationRepo,
+		userRepo:         userRepo,
+		templateService:  templateService,
+		notifiers:        byChannel,
+		rabbitmq:         rabbitmq,
+		config:           cfg,
+	}
+}
+
+// NewNotifiers memilih adapter untuk setiap channel berdasarkan konfigurasi.
+// Email tanpa host SMTP dan SMS dengan provider "fake" hanya ditulis ke log.
+func NewNotifiers(cfg *config.Config) []utils.Notifier {
+	var notifiers []utils.Notifier
+
+	smtpCfg := cfg.Notification.SMTP
+	if smtpCfg.Host != "" {
+		notifiers = append(notifiers, utils.NewSMTPNotifier(smtpCfg.Host, smtpCfg.Port,
+			smtpCfg.Username, smtpCfg.Password, smtpCfg.From, smtpCfg.Timeout))
+	} else {
+		notifiers = append(notifiers, utils.NewLogNotifier(utils.ChannelEmail))
+	}
+
+	smsCfg := cfg.Notification.SMS
+	if smsCfg.Provider == "http" && smsCfg.URL != "" {
+		notifiers = append(notifiers, utils.NewHTTPSMSNotifier(smsCfg.URL, smsCfg.APIKey, smsCfg.Sender, smsCfg.Timeout))
+	} else {
+		notifiers = append(notifiers, utils.NewFakeSMSNotifier())
+	}
+
+	notifiers = append(notifiers, utils.NewWebhookNotifier(cfg.Notification.Webhook.Secret, cfg.Notification.Webhook.Timeout))
+	return notifiers
+}
+
+// Handle mengirim satu notifikasi ke semua channel yang dipilih user untuk
+// tipe tersebut, atau channel default dari konfigurasi jika user belum
+// mengatur preferensi. Setiap percobaan pengiriman dicatat statusnya, dan
+// channel yang sudah sukses untuk messageID yang sama tidak dikirim ulang
+// ketika pesan diproses lagi karena retry atau duplikasi dari outbox.
+func (s *notificationService) Handle(ctx context.Context, messageID string, msg utils.NotificationMessage) error {
+	ctx, cancel := context.WithTimeout(ctx, notificationSendTimeout)
+	defer cancel()
+
+	user := s.findRecipient(msg)
+
+	channels, webhookURL, err := s.resolveChannels(user, msg.Type)
+	if err != nil {
+		return err
+	}
+	if len(channels) == 0 {
+		return nil
+	}
+
+	language := utils.DefaultLanguage
+	if user != nil {
+		language = user.Language
+	}
+	content, err := s.templateService.Render(msg.Type, language, msg)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, channel := range channels {
+		if messageID != "" {
+			sent, err := s.notificationRepo.HasSentDelivery(messageID, channel)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", channel, err))
+				continue
+			}
+			if sent {
+				continue
+			}
+		}
+
+		out := utils.OutboundMessage{
+			Type:    msg.Type,
+			Subject: content.Subject,
+			Body:    content.Text,
+			Payload: msg,
+		}
+		switch channel {
+		case utils.ChannelEmail:
+			// event tidak membawa email, alamat diambil dari data user terbaru
+			out.Recipient = msg.Email
+			if user != nil {
+				out.Recipient = user.Email
+			}
+			out.HTML = content.HTML
+		case utils.ChannelSMS:
+			out.Body = content.Short
+			if user != nil {
+				out.Recipient = user.Phone
+			}
+		case utils.ChannelWebhook:
+			out.Recipient = webhookURL
+		}
+
+		if err := s.deliver(ctx, messageID, user, channel, out, msg.BookingCode); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", channel, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func (s *notificationService) findRecipient(msg utils.NotificationMessage) *models.User {
+	if msg.UserID != 0 {
+		if user, err := s.userRepo.FindByID(msg.UserID); err == nil {
+			return user
+		}
+	}
+	// pesan lama di antrian belum membawa user_id
+	if msg.Email != "" {
+		if user, err := s.userRepo.FindByEmail(msg.Email); err == nil {
+			return user
+		}
+	}
+	return nil
+}
+
+func (s *notificationService) resolveChannels(user *models.User, notificationType string) ([]string, string, error) {
+	if user != nil {
+		pref, err := s.notificationRepo.FindPreference(user.ID, notificationType)
+		if err == nil {
+			webhookURL := ""
+			if pref.WebhookURL != nil {
+				webhookURL = *pref.WebhookURL
+			}
+			return pref.Channels, webhookURL, nil
+		}
+		if !errors.Is(err, sql.ErrNoRows) {
+			return nil, "", err
+		}
+	}
+
+	return s.config.Notification.Channels[notificationType], "", nil
+}
+
+func (s *notificationService) deliver(ctx context.Context, messageID string, user *models.User, channel string, out utils.OutboundMessage, bookingCode string) error {
+	delivery := &models.NotificationDelivery{
+		NotificationType: out.Type,
+		Channel:          channel,
+		Recipient:        out.Recipient,
+	}
+	if messageID != "" {
+		delivery.MessageID = &messageID
+	}
+	if user != nil {
+		delivery.UserID = &user.ID
+	}
+	if bookingCode != "" {
+		delivery.BookingCode = &bookingCode
+	}
+
+	var sendErr error
+	notifier, ok := s.notifiers[channel]
+	switch {
+	case !ok:
+		delivery.Status = deliveryStatusSkipped
+		delivery.Error = stringPtr("channel tidak dikenal")
+	case out.Recipient == "":
+		delivery.Status = deliveryStatusSkipped
+		delivery.Error = stringPtr("tujuan pengiriman belum diatur")
+	default:
+		sendErr = notifier.Send(ctx, out)
+		if sendErr != nil {
+			delivery.Status = deliveryStatusFailed
+			delivery.Error = stringPtr(sendErr.Error())
+		} else {
+			now := time.Now()
+			delivery.Status = deliveryStatusSent
+			delivery.SentAt = &now
+		}
+	}
+
+	if err := s.notificationRepo.CreateDelivery(delivery); err != nil {
+		log.Printf("gagal mencatat status pengiriman notifikasi %s: %v", out.Type, err)
+	}
+	return sendErr
+}
+
+func (s *notificationService) GetPreferences(userID int) ([]models.NotificationPreference, error) {
+	return s.notificationRepo.FindPreferencesByUser(userID)
+}
+
+func (s *notificationService) UpdatePreference(userID int, req dto.UpdateNotificationPreferenceRequest) (*models.NotificationPreference, error) {
+	channels := uniqueStrings(req.Channels)
+
+	var webhookURL *string
+	if req.WebhookURL != nil && *req.WebhookURL != "" {
+		ctx, cancel := context.WithTimeout(context.Background(), webhookResolveTimeout)
+		err := utils.ValidateWebhookURL(ctx, *req.WebhookURL)
+		cancel()
+		if err != nil {
+			return nil, err
+		}
+		webhookURL = req.WebhookURL
+	}
+
+	for _, channel := range channels {
+		if channel != utils.ChannelWebhook {
+			continue
+		}
+		if s.config.Notification.Webhook.Secret == "" {
+			return nil, errors.New("channel webhook belum tersedia")
+		}
+		if webhookURL == nil {
+			return nil, errors.New("webhook_url wajib diisi untuk channel webhook")
+		}
+	}
+
+	pref := &models.NotificationPreference{
+		UserID:           userID,
+		NotificationType: req.NotificationType,
+		Channels:         channels,
+		WebhookURL:       webhookURL,
+	}
+	if err := s.notificationRepo.UpsertPreference(pref); err != nil {
+		return nil, err
+	}
+	return pref, nil
+}
+
+func (s *notificationService) GetDeliveries(userID int, query dto.ListQuery) ([]models.NotificationDelivery, repository.Page, error) {
+	spec, err := querySpec(query)
+	if err != nil {
+		return nil, repository.Page{}, err
+	}
+	return s.notificationRepo.FindDeliveriesByUser(userID, spec)
+}
+
+func (s *notificationService) GetDeadLetters(limit int) ([]utils.DeadLetter, error) {
+	if limit <= 0 || limit > deadLetterMaxBatch {
+		limit = deadLetterDefaultBatch
+	}
+	return s.rabbitmq.PeekDeadLetters(limit)
+}
+
+func (s *notificationService) ReplayDeadLetters(ctx context.Context, req dto.ReplayDeadLettersRequest) ([]string, error) {
+	limit := req.Limit
+	if limit <= 0 {
+		limit = deadLetterDefaultBatch
+	}
+	if len(req.MessageIDs) > 0 && limit < len(req.MessageIDs) {
+		limit = len(req.MessageIDs)
+	}
+
+	replayed, err := s.rabbitmq.ReplayDeadLetters(ctx, req.MessageIDs, limit)
+	if err != nil {
+		return replayed, err
+	}
+	log.Printf("%d notifikasi dari dead-letter queue dikirim ulang", len(replayed))
+	return replayed, nil
+}
+
+func stringPtr(s string) *string {
+	return &s
+}
@@ -0,0 +1,208 @@
+package service
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"tiketsepur/dto"
+	"tiketsepur/models"
+	"tiketsepur/repository"
+	"tiketsepur/utils"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type NotificationTemplateService interface {
+	Render(notificationType, language string, msg utils.NotificationMessage) (*utils.RenderedNotification, error)
+	GetAll(notificationType, language string) ([]models.NotificationTemplate, error)
+	Create(createdBy int, req dto.CreateNotificationTemplateRequest) (*models.NotificationTemplate, error)
+	Activate(id int) (*models.NotificationTemplate, error)
+	Preview(req dto.PreviewNotificationTemplateRequest) (*utils.RenderedNotification, error)
+}
+
+type notificationTemplateService struct {
+	db           *sqlx.DB
+	templateRepo repository.NotificationTemplateRepository
+}
+
+func NewNotificationTemplateService(db *sqlx.DB, templateRepo repository.NotificationTemplateRepository) NotificationTemplateService {
+	return &notificationTemplateService{
+		db:           db,
+		templateRepo: templateRepo,
+	}
+}
+
+// Render memakai template aktif di database untuk tipe dan bahasa tersebut,
+// lalu template bawaan. Jika bahasa user tidak tersedia, dipakai bahasa default.
+func (s *notificationTemplateService) Render(notificationType, language string, msg utils.NotificationMessage) (*utils.RenderedNotification, error) {
+	if !utils.IsNotificationLanguage(language) {
+		language = utils.DefaultLanguage
+	}
+
+	tpl, err := s.resolve(notificationType, language)
+	if err != nil && language != utils.DefaultLanguage {
+		tpl, err = s.resolve(notificationType, utils.DefaultLanguage)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return tpl.Render(msg)
+}
+
+func (s *notificationTemplateService) resolve(notificationType, language string) (*utils.NotificationTemplate, error) {
+	stored, err := s.templateRepo.FindActive(notificationType, language)
+	if err == nil {
+		return toNotificationTemplate(stored), nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return nil, err
+	}
+	return utils.DefaultNotificationTemplate(notificationType, language)
+}
+
+func (s *notificationTemplateService) GetAll(notificationType, language string) ([]models.NotificationTemplate, error) {
+	return s.templateRepo.FindAll(notificationType, language)
+}
+
+func (s *notificationTemplateService) Create(createdBy int, req dto.CreateNotificationTemplateRequest) (*models.NotificationTemplate, error) {
+	stored := &models.NotificationTemplate{
+		NotificationType: req.NotificationType,
+		Language:         req.Language,
+		Subject:          req.Subject,
+		BodyText:         req.BodyText,
+		BodyHTML:         req.BodyHTML,
+		BodyShort:        req.BodyShort,
+		CreatedBy:        &createdBy,
+	}
+
+	// template yang tidak bisa dirender dengan data contoh ditolak sebelum disimpan
+	if _, err := toNotificationTemplate(stored).Render(sampleNotificationMessage(req.NotificationType)); err != nil {
+		return nil, err
+	}
+
+	tx, err := s.db.Beginx()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	if err := s.templateRepo.Create(stored, tx); err != nil {
+		return nil, err
+	}
+	if req.Activate {
+		if err := s.templateRepo.Activate(stored, tx); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return stored, nil
+}
+
+func (s *notificationTemplateService) Activate(id int) (*models.NotificationTemplate, error) {
+	stored, err := s.templateRepo.FindByID(id)
+	if err != nil {
+		return nil, errors.New("template tidak ditemukan")
+	}
+
+	tx, err := s.db.Beginx()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	if err := s.templateRepo.Activate(stored, tx); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return stored, nil
+}
+
+// Preview merender template tanpa mengirim apa pun. Bagian template yang
+// dikirim di request menimpa template tersimpan, sehingga draft bisa dicoba
+// sebelum disimpan sebagai versi baru.
+func (s *notificationTemplateService) Preview(req dto.PreviewNotificationTemplateRequest) (*utils.RenderedNotification, error) {
+	var tpl *utils.NotificationTemplate
+	if req.TemplateID != nil {
+		stored, err := s.templateRepo.FindByID(*req.TemplateID)
+		if err != nil {
+			return nil, errors.New("template tidak ditemukan")
+		}
+		tpl = toNotificationTemplate(stored)
+	} else {
+		resolved, err := s.resolve(req.NotificationType, req.Language)
+		if err != nil {
+			return nil, err
+		}
+		tpl = resolved
+	}
+
+	if req.Subject != nil {
+		tpl.Subject = *req.Subject
+	}
+	if req.BodyText != nil {
+		tpl.Text = *req.BodyText
+	}
+	if req.BodyHTML != nil {
+		tpl.HTML = *req.BodyHTML
+	}
+	if req.BodyShort != nil {
+		tpl.Short = *req.BodyShort
+	}
+
+	data := sampleNotificationMessage(req.NotificationType)
+	if len(req.Data) > 0 {
+		if err := json.Unmarshal(req.Data, &data); err != nil {
+			return nil, fmt.Errorf("data preview tidak valid: %w", err)
+		}
+	}
+
+	return tpl.Render(data)
+}
+
+func toNotificationTemplate(stored *models.NotificationTemplate) *utils.NotificationTemplate {
+	return &utils.NotificationTemplate{
+		Subject: stored.Subject,
+		Text:    stored.BodyText,
+		HTML:    stored.BodyHTML,
+		Short:   stored.BodyShort,
+	}
+}
+
+func sampleNotificationMessage(notificationType string) utils.NotificationMessage {
+	departure := time.Now().Add(48*time.Hour).Format("2006-01-02 15:04") + " WIB"
+	return utils.NotificationMessage{
+		Type:          notificationType,
+		Email:         "penumpang@example.com",
+		BookingCode:   "TRNAB12CD34",
+		TrainName:     "Argo Bromo Anggrek",
+		Departure:     "Gambir",
+		Arrival:       "Surabaya Pasarturi",
+		SeatNumber:    "EKS-1 5A",
+		TotalPrice:    550000,
+		PaymentCode:   "PAYXY98ZW76VU54",
+		PaymentMethod: "bank_transfer",
+		DepartureTime: departure,
+		LockedUntil:   time.Now().Add(15 * time.Minute).Format("2006-01-02 15:04"),
+		PassengerName: "Budi Santoso",
+		Platform:      "3",
+		Coach:         "EKS-1",
+
+		DisruptionType:         "cancellation",
+		DelayMinutes:           45,
+		EstimatedDepartureTime: time.Now().Add(48*time.Hour+45*time.Minute).Format("2006-01-02 15:04") + " WIB",
+		Reason:                 "gangguan persinyalan",
+		RefundAmount:           550000,
+		Alternatives: []utils.NotificationAlternative{
+			{TrainName: "Sembrani", DepartureTime: time.Now().Add(50*time.Hour).Format("2006-01-02 15:04") + " WIB", Price: 520000},
+		},
+	}
+}
@@ -0,0 +1,202 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	config "tiketsepur/configs"
+	"tiketsepur/dto"
+	"tiketsepur/models"
+	"tiketsepur/repository"
+	"tiketsepur/utils"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+type OIDCService interface {
+	LoginURL(ctx context.Context, provider string) (string, error)
+	Callback(ctx context.Context, provider, code, state string) (*dto.LoginResponse, error)
+}
+
+type oidcState struct {
+	Provider     string `json:"provider"`
+	CodeVerifier string `json:"code_verifier"`
+	Nonce        string `json:"nonce"`
+}
+
+// oidcStateStore adalah bagian RedisClient yang dipakai untuk menyimpan state
+// login sampai callback.
+type oidcStateStore interface {
+	Set(ctx context.Context, key string, value interface{}, expiration time.Duration) error
+	Get(ctx context.Context, key string) (string, error)
+	Delete(ctx context.Context, keys ...string) error
+}
+
+type oidcService struct {
+	providers    map[string]*utils.OIDCProvider
+	userRepo     repository.UserRepository
+	identityRepo repository.UserIdentityRepository
+	authService  AuthService
+	redis        oidcStateStore
+	config       *config.Config
+}
+
+func NewOIDCService(
+	userRepo repository.UserRepository,
+	identityRepo repository.UserIdentityRepository,
+	authService AuthService,
+	redis oidcStateStore,
+	cfg *config.Config,
+) OIDCService {
+	providers := make(map[string]*utils.OIDCProvider)
+	for name, p := range cfg.OIDC.Providers {
+		if p.ClientID == "" || p.Issuer == "" {
+			continue
+		}
+		providers[name] = utils.NewOIDCProvider(name, p.Issuer, p.ClientID, p.ClientSecret, p.RedirectURL, p.Scopes)
+	}
+
+	return &oidcService{
+		providers:    providers,
+		userRepo:     userRepo,
+		identityRepo: identityRepo,
+		authService:  authService,
+		redis:        redis,
+		config:       cfg,
+	}
+}
+
+func (s *oidcService) LoginURL(ctx context.Context, provider string) (string, error) {
+	p, ok := s.providers[provider]
+	if !ok {
+		return "", errors.New("provider login tidak dikenal")
+	}
+
+	state, err := utils.RandomURLString(24)
+	if err != nil {
+		return "", err
+	}
+	nonce, err := utils.RandomURLString(24)
+	if err != nil {
+		return "", err
+	}
+	verifier, challenge, err := utils.GeneratePKCE()
+	if err != nil {
+		return "", err
+	}
+
+	data, err := json.Marshal(oidcState{Provider: provider, CodeVerifier: verifier, Nonce: nonce})
+	if err != nil {
+		return "", err
+	}
+	if err := s.redis.Set(ctx, "oidc:state:"+state, data, s.config.OIDC.StateExpiry); err != nil {
+		return "", err
+	}
+
+	return p.AuthCodeURL(ctx, state, nonce, challenge)
+}
+
+func (s *oidcService) Callback(ctx context.Context, provider, code, state string) (*dto.LoginResponse, error) {
+	p, ok := s.providers[provider]
+	if !ok {
+		return nil, errors.New("provider login tidak dikenal")
+	}
+
+	stateKey := "oidc:state:" + state
+	raw, err := s.redis.Get(ctx, stateKey)
+	if err != nil {
+		return nil, errors.New("state login tidak valid atau expired")
+	}
+	// state hanya boleh dipakai sekali
+	if err := s.redis.Delete(ctx, stateKey); err != nil {
+		return nil, err
+	}
+
+	var saved oidcState
+	if err := json.Unmarshal([]byte(raw), &saved); err != nil || saved.Provider != provider {
+		return nil, errors.New("state login tidak valid atau expired")
+	}
+
+	token, err := p.Exchange(ctx, code, saved.CodeVerifier)
+	if err != nil {
+		return nil, fmt.Errorf("gagal menukar authorization code: %w", err)
+	}
+
+	claims, err := p.VerifyIDToken(ctx, token.IDToken, saved.Nonce)
+	if err != nil {
+		return nil, fmt.Errorf("id_token tidak valid: %w", err)
+	}
+
+	user, err := s.resolveUser(provider, claims)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.authService.CreateSession(ctx, user)
+}
+
+// resolveUser mencari user dari identitas yang sudah terhubung, lalu dari
+// email yang sudah diverifikasi provider, dan terakhir membuat user baru.
+func (s *oidcService) resolveUser(provider string, claims *utils.OIDCIDTokenClaims) (*models.User, error) {
+	identity, err := s.identityRepo.FindByProviderSubject(provider, claims.Subject)
+	if err == nil {
+		return s.userRepo.FindByID(identity.UserID)
+	}
+
+	if claims.Email == "" || !claims.IsEmailVerified() {
+		return nil, errors.New("email akun belum diverifikasi oleh provider")
+	}
+
+	user, err := s.userRepo.FindByEmail(claims.Email)
+	if err != nil {
+		user, err = s.createUser(claims)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	email := claims.Email
+	if err := s.identityRepo.Create(&models.UserIdentity{
+		UserID:   user.ID,
+		Provider: provider,
+		Subject:  claims.Subject,
+		Email:    &email,
+	}); err != nil {
+		return nil, err
+	}
+	log.Printf("akun %s terhubung dengan provider %s", user.Email, provider)
+
+	return user, nil
+}
+
+func (s *oidcService) createUser(claims *utils.OIDCIDTokenClaims) (*models.User, error) {
+	// user OIDC tidak punya password, jadi disimpan hash dari nilai acak
+	// supaya login password tidak pernah berhasil
+	randomPassword, err := utils.RandomURLString(32)
+	if err != nil {
+		return nil, err
+	}
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(randomPassword), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, err
+	}
+
+	fullName := claims.Name
+	if fullName == "" {
+		fullName = claims.Email
+	}
+
+	user := &models.User{
+		Email:    claims.Email,
+		Password: string(hashedPassword),
+		FullName: fullName,
+		Role:     "user",
+	}
+	if err := s.userRepo.Create(user); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
@@ -0,0 +1,391 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	config "tiketsepur/configs"
+	"tiketsepur/dto"
+	"tiketsepur/models"
+	"tiketsepur/repository"
+	"tiketsepur/utils"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+const (
+	fakeClientID     = "tiketsepur"
+	fakeClientSecret = "rahasia"
+	fakeRedirectURL  = "http://localhost:8080/api/auth/oidc/fake/callback"
+)
+
+// fakeOIDCProvider adalah provider OpenID Connect minimal: discovery, JWKS,
+// dan token endpoint yang memeriksa PKCE lalu menandatangani id_token RS256.
+type fakeOIDCProvider struct {
+	server *httptest.Server
+	key    *rsa.PrivateKey
+	issuer string
+
+	mu    sync.Mutex
+	codes map[string]fakeAuthCode
+}
+
+// fakeAuthCode adalah hasil halaman login provider untuk satu code.
+type fakeAuthCode struct {
+	challenge     string
+	nonce         string
+	subject       string
+	email         string
+	emailVerified bool
+}
+
+func newFakeOIDCProvider(t *testing.T) *fakeOIDCProvider {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := &fakeOIDCProvider{key: key, codes: make(map[string]fakeAuthCode)}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(utils.OIDCDiscovery{
+			Issuer:                p.issuer,
+			AuthorizationEndpoint: p.server.URL + "/authorize",
+			TokenEndpoint:         p.server.URL + "/token",
+			JWKSURI:               p.server.URL + "/jwks",
+		})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		jwk, err := utils.NewJWK("fake-key", "RS256", &p.key.PublicKey)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		json.NewEncoder(w).Encode(utils.JWKS{Keys: []utils.JWK{jwk}})
+	})
+	mux.HandleFunc("/token", p.token)
+
+	p.server = httptest.NewServer(mux)
+	p.issuer = p.server.URL
+	t.Cleanup(p.server.Close)
+	return p
+}
+
+// authorize mensimulasikan user yang login di provider dan mengembalikan
+// authorization code untuk callback.
+func (p *fakeOIDCProvider) authorize(challenge, nonce, subject, email string, emailVerified bool) string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	code := "code-" + subject + "-" + nonce
+	p.codes[code] = fakeAuthCode{challenge: challenge, nonce: nonce, subject: subject, email: email, emailVerified: emailVerified}
+	return code
+}
+
+func (p *fakeOIDCProvider) token(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if r.Form.Get("client_id") != fakeClientID || r.Form.Get("client_secret") != fakeClientSecret ||
+		r.Form.Get("redirect_uri") != fakeRedirectURL {
+		http.Error(w, "invalid_client", http.StatusUnauthorized)
+		return
+	}
+
+	p.mu.Lock()
+	code, ok := p.codes[r.Form.Get("code")]
+	delete(p.codes, r.Form.Get("code"))
+	p.mu.Unlock()
+	if !ok {
+		http.Error(w, "invalid_grant", http.StatusBadRequest)
+		return
+	}
+
+	sum := sha256.Sum256([]byte(r.Form.Get("code_verifier")))
+	if base64.RawURLEncoding.EncodeToString(sum[:]) != code.challenge {
+		http.Error(w, "invalid_grant", http.StatusBadRequest)
+		return
+	}
+
+	idToken := jwt.NewWithClaims(jwt.SigningMethodRS256, utils.OIDCIDTokenClaims{
+		Email:         code.email,
+		EmailVerified: code.emailVerified,
+		Name:          "Penumpang " + code.subject,
+		Nonce:         code.nonce,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    p.issuer,
+			Subject:   code.subject,
+			Audience:  jwt.ClaimStrings{fakeClientID},
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Minute)),
+		},
+	})
+	idToken.Header["kid"] = "fake-key"
+	signed, err := idToken.SignedString(p.key)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	json.NewEncoder(w).Encode(utils.OIDCTokenResponse{AccessToken: "access", IDToken: signed, TokenType: "Bearer"})
+}
+
+type memoryStateStore struct {
+	mu     sync.Mutex
+	values map[string]string
+}
+
+func (m *memoryStateStore) Set(ctx context.Context, key string, value interface{}, expiration time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.values[key] = string(value.([]byte))
+	return nil
+}
+
+func (m *memoryStateStore) Get(ctx context.Context, key string) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	value, ok := m.values[key]
+	if !ok {
+		return "", errors.New("redis: nil")
+	}
+	return value, nil
+}
+
+func (m *memoryStateStore) Delete(ctx context.Context, keys ...string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, key := range keys {
+		delete(m.values, key)
+	}
+	return nil
+}
+
+type memoryUserRepo struct {
+	repository.UserRepository
+	users []*models.User
+}
+
+func (r *memoryUserRepo) Create(user *models.User) error {
+	user.ID = len(r.users) + 1
+	r.users = append(r.users, user)
+	return nil
+}
+
+func (r *memoryUserRepo) FindByID(id int) (*models.User, error) {
+	for _, user := range r.users {
+		if user.ID == id {
+			return user, nil
+		}
+	}
+	return nil, errors.New("user tidak ditemukan")
+}
+
+func (r *memoryUserRepo) FindByEmail(email string) (*models.User, error) {
+	for _, user := range r.users {
+		if user.Email == email {
+			return user, nil
+		}
+	}
+	return nil, errors.New("user tidak ditemukan")
+}
+
+type memoryIdentityRepo struct {
+	identities []models.UserIdentity
+}
+
+func (r *memoryIdentityRepo) Create(identity *models.UserIdentity) error {
+	identity.ID = len(r.identities) + 1
+	r.identities = append(r.identities, *identity)
+	return nil
+}
+
+func (r *memoryIdentityRepo) FindByProviderSubject(provider, subject string) (*models.UserIdentity, error) {
+	for i := range r.identities {
+		if r.identities[i].Provider == provider && r.identities[i].Subject == subject {
+			return &r.identities[i], nil
+		}
+	}
+	return nil, errors.New("identitas tidak ditemukan")
+}
+
+type sessionAuthService struct {
+	AuthService
+}
+
+func (s sessionAuthService) CreateSession(ctx context.Context, user *models.User) (*dto.LoginResponse, error) {
+	return &dto.LoginResponse{Token: "sesi-" + user.Email, User: user}, nil
+}
+
+type oidcTestEnv struct {
+	provider   *fakeOIDCProvider
+	service    OIDCService
+	users      *memoryUserRepo
+	identities *memoryIdentityRepo
+}
+
+func newOIDCTestEnv(t *testing.T) *oidcTestEnv {
+	provider := newFakeOIDCProvider(t)
+	env := &oidcTestEnv{
+		provider:   provider,
+		users:      &memoryUserRepo{},
+		identities: &memoryIdentityRepo{},
+	}
+	cfg := &config.Config{OIDC: config.OIDCConfig{
+		StateExpiry: time.Minute,
+		Providers: map[string]config.OIDCProviderConfig{
+			"fake": {Issuer: provider.issuer, ClientID: fakeClientID, ClientSecret: fakeClientSecret, RedirectURL: fakeRedirectURL},
+		},
+	}}
+	env.service = NewOIDCService(env.users, env.identities, sessionAuthService{}, &memoryStateStore{values: map[string]string{}}, cfg)
+	return env
+}
+
+// login menjalankan LoginURL dan mengembalikan state, nonce, dan
+// code_challenge yang dikirim ke provider.
+func (e *oidcTestEnv) login(t *testing.T) (state, nonce, challenge string) {
+	t.Helper()
+	loginURL, err := e.service.LoginURL(context.Background(), "fake")
+	if err != nil {
+		t.Fatalf("LoginURL: %v", err)
+	}
+	parsed, err := url.Parse(loginURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(loginURL, e.provider.server.URL+"/authorize?") {
+		t.Fatalf("login URL %s tidak mengarah ke authorization endpoint", loginURL)
+	}
+	query := parsed.Query()
+	if query.Get("code_challenge_method") != "S256" || query.Get("client_id") != fakeClientID {
+		t.Fatalf("parameter login tidak lengkap: %s", loginURL)
+	}
+	return query.Get("state"), query.Get("nonce"), query.Get("code_challenge")
+}
+
+func TestOIDCCallbackCreatesUserAndLinksIdentity(t *testing.T) {
+	env := newOIDCTestEnv(t)
+
+	state, nonce, challenge := env.login(t)
+	code := env.provider.authorize(challenge, nonce, "sub-1", "baru@example.com", true)
+	session, err := env.service.Callback(context.Background(), "fake", code, state)
+	if err != nil {
+		t.Fatalf("Callback: %v", err)
+	}
+	if session.Token != "sesi-baru@example.com" {
+		t.Fatalf("token sesi = %q", session.Token)
+	}
+	if len(env.users.users) != 1 || env.users.users[0].Role != "user" {
+		t.Fatalf("user baru tidak dibuat dengan role user: %+v", env.users.users)
+	}
+	if len(env.identities.identities) != 1 || env.identities.identities[0].Subject != "sub-1" {
+		t.Fatalf("identitas tidak terhubung: %+v", env.identities.identities)
+	}
+
+	// login berikutnya memakai identitas yang sudah terhubung, walaupun email
+	// di provider sudah berubah
+	state, nonce, challenge = env.login(t)
+	code = env.provider.authorize(challenge, nonce, "sub-1", "ganti@example.com", true)
+	session, err = env.service.Callback(context.Background(), "fake", code, state)
+	if err != nil {
+		t.Fatalf("Callback kedua: %v", err)
+	}
+	if session.Token != "sesi-baru@example.com" || len(env.users.users) != 1 || len(env.identities.identities) != 1 {
+		t.Fatalf("login kedua tidak memakai identitas yang sama: token %q, %d user, %d identitas",
+			session.Token, len(env.users.users), len(env.identities.identities))
+	}
+}
+
+func TestOIDCCallbackLinksExistingVerifiedEmail(t *testing.T) {
+	env := newOIDCTestEnv(t)
+	env.users.Create(&models.User{Email: "lama@example.com", Role: "user"})
+
+	state, nonce, challenge := env.login(t)
+	code := env.provider.authorize(challenge, nonce, "sub-2", "lama@example.com", true)
+	if _, err := env.service.Callback(context.Background(), "fake", code, state); err != nil {
+		t.Fatalf("Callback: %v", err)
+	}
+	if len(env.users.users) != 1 {
+		t.Fatalf("user baru dibuat padahal email sudah terdaftar")
+	}
+	if len(env.identities.identities) != 1 || env.identities.identities[0].UserID != env.users.users[0].ID {
+		t.Fatalf("identitas tidak terhubung ke user lama: %+v", env.identities.identities)
+	}
+}
+
+func TestOIDCCallbackRejectsUnverifiedEmail(t *testing.T) {
+	env := newOIDCTestEnv(t)
+	env.users.Create(&models.User{Email: "lama@example.com", Role: "user"})
+
+	state, nonce, challenge := env.login(t)
+	code := env.provider.authorize(challenge, nonce, "sub-3", "lama@example.com", false)
+	if _, err := env.service.Callback(context.Background(), "fake", code, state); err == nil {
+		t.Fatal("email yang belum diverifikasi tidak boleh dihubungkan ke akun lama")
+	}
+	if len(env.identities.identities) != 0 {
+		t.Fatalf("identitas tetap dibuat: %+v", env.identities.identities)
+	}
+}
+
+func TestOIDCCallbackStateIsSingleUse(t *testing.T) {
+	env := newOIDCTestEnv(t)
+
+	state, nonce, challenge := env.login(t)
+	code := env.provider.authorize(challenge, nonce, "sub-4", "sekali@example.com", true)
+	if _, err := env.service.Callback(context.Background(), "fake", code, state); err != nil {
+		t.Fatalf("Callback: %v", err)
+	}
+
+	code = env.provider.authorize(challenge, nonce, "sub-4", "sekali@example.com", true)
+	if _, err := env.service.Callback(context.Background(), "fake", code, state); err == nil {
+		t.Fatal("state yang sudah dipakai diterima lagi")
+	}
+	if _, err := env.service.Callback(context.Background(), "fake", code, "state-palsu"); err == nil {
+		t.Fatal("state yang tidak pernah dibuat diterima")
+	}
+}
+
+func TestOIDCCallbackRejectsNonceMismatch(t *testing.T) {
+	env := newOIDCTestEnv(t)
+
+	state, _, challenge := env.login(t)
+	code := env.provider.authorize(challenge, "nonce-lain", "sub-5", "nonce@example.com", true)
+	if _, err := env.service.Callback(context.Background(), "fake", code, state); err == nil {
+		t.Fatal("id_token dengan nonce berbeda diterima")
+	}
+	if len(env.users.users) != 0 {
+		t.Fatal("user dibuat dari id_token yang tidak valid")
+	}
+}
+
+func TestOIDCCallbackRejectsWrongCodeVerifier(t *testing.T) {
+	env := newOIDCTestEnv(t)
+
+	state, nonce, _ := env.login(t)
+	// code diterbitkan untuk challenge lain sehingga code_verifier milik
+	// state ini tidak cocok
+	code := env.provider.authorize("challenge-lain", nonce, "sub-6", "pkce@example.com", true)
+	if _, err := env.service.Callback(context.Background(), "fake", code, state); err == nil {
+		t.Fatal("code_verifier yang tidak cocok diterima")
+	}
+}
+
+func TestOIDCDiscoveryRejectsIssuerMismatch(t *testing.T) {
+	env := newOIDCTestEnv(t)
+	env.provider.issuer = "https://issuer-lain.example.com"
+
+	if _, err := env.service.LoginURL(context.Background(), "fake"); err == nil {
+		t.Fatal("discovery dengan issuer berbeda diterima")
+	}
+}
@@ -0,0 +1,209 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	config "tiketsepur/configs"
+	"tiketsepur/models"
+	"tiketsepur/repository"
+	"tiketsepur/utils"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+const outboxCleanupInterval = time.Hour
+
+type OutboxService interface {
+	Enqueue(tx *sqlx.Tx, event utils.DomainEvent) error
+	RelayOnce(ctx context.Context) (int, error)
+	Run(ctx context.Context)
+}
+
+type outboxService struct {
+	db         *sqlx.DB
+	outboxRepo repository.OutboxRepository
+	rabbitmq   *utils.RabbitMQ
+	config     config.OutboxConfig
+}
+
+func NewOutboxService(db *sqlx.DB, outboxRepo repository.OutboxRepository, rabbitmq *utils.RabbitMQ, cfg *config.Config) OutboxService {
+	outboxCfg := cfg.Outbox
+	if outboxCfg.PollInterval <= 0 {
+		outboxCfg.PollInterval = 2 * time.Second
+	}
+	if outboxCfg.BatchSize <= 0 {
+		outboxCfg.BatchSize = 50
+	}
+	if outboxCfg.RetryBase <= 0 {
+		outboxCfg.RetryBase = 5 * time.Second
+	}
+	if outboxCfg.RetryMax < outboxCfg.RetryBase {
+		outboxCfg.RetryMax = outboxCfg.RetryBase
+	}
+	if outboxCfg.ClaimLease <= 0 {
+		outboxCfg.ClaimLease = 5 * time.Minute
+	}
+
+	return &outboxService{
+		db:         db,
+		outboxRepo: outboxRepo,
+		rabbitmq:   rabbitmq,
+		config:     outboxCfg,
+	}
+}
+
+// Enqueue menyimpan event ke tabel outbox di dalam transaksi yang sama
+// dengan perubahan datanya. Event hanya akan dipublikasikan jika transaksi commit.
+func (s *outboxService) Enqueue(tx *sqlx.Tx, event utils.DomainEvent) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	return s.outboxRepo.Create(&models.OutboxMessage{
+		AggregateType: event.AggregateType,
+		AggregateID:   event.AggregateID,
+		EventType:     event.RoutingKey(),
+		Payload:       payload,
+	}, tx)
+}
+
+// enqueueEvent membungkus data menjadi DomainEvent versi 1 lalu menyimpannya
+// ke outbox.
+func enqueueEvent(outbox OutboxService, tx *sqlx.Tx, eventType, aggregateType, aggregateID string, data interface{}) error {
+	event, err := utils.NewDomainEvent(eventType, 1, aggregateType, aggregateID, data)
+	if err != nil {
+		return err
+	}
+	return outbox.Enqueue(tx, event)
+}
+
+// RelayOnce mempublikasikan satu batch pesan pending ke RabbitMQ. Pesan yang
+// gagal dijadwalkan ulang dengan backoff eksponensial dan tidak pernah dibuang,
+// sehingga setiap pesan terkirim minimal satu kali. Klaim dan pencatatan hasil
+// memakai dua transaksi pendek; publish berjalan di antaranya tanpa menahan
+// kunci baris atau koneksi database selama menunggu konfirmasi broker.
+func (s *outboxService) RelayOnce(ctx context.Context) (int, error) {
+	leaseUntil := time.Now().Add(s.config.ClaimLease)
+	messages, err := s.claim(ctx, leaseUntil)
+	if err != nil || len(messages) == 0 {
+		return 0, err
+	}
+
+	published := make([]error, 0, len(messages))
+	for _, msg := range messages {
+		// setelah lease habis relay lain boleh mengklaim baris yang sama,
+		// jadi sisa batch ditinggalkan untuk klaim berikutnya
+		if ctx.Err() != nil || !time.Now().Before(leaseUntil) {
+			break
+		}
+		published = append(published, s.publish(ctx, msg))
+	}
+
+	tx, err := s.db.BeginTxx(context.Background(), nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	sent := 0
+	for i, publishErr := range published {
+		msg := messages[i]
+		if publishErr != nil {
+			nextAttempt := time.Now().Add(s.backoff(msg.Attempts))
+			if err := s.outboxRepo.MarkRetry(msg.ID, nextAttempt, publishErr.Error(), tx); err != nil {
+				return 0, err
+			}
+			log.Printf("gagal mengirim pesan outbox %d, dicoba lagi pada %s: %v", msg.ID, nextAttempt.Format(time.RFC3339), publishErr)
+			continue
+		}
+
+		if err := s.outboxRepo.MarkSent(msg.ID, tx); err != nil {
+			return 0, err
+		}
+		sent++
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+	return sent, nil
+}
+
+func (s *outboxService) claim(ctx context.Context, leaseUntil time.Time) ([]models.OutboxMessage, error) {
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	messages, err := s.outboxRepo.ClaimPending(s.config.BatchSize, leaseUntil, tx)
+	if err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return messages, nil
+}
+
+// publish mengirim event ke topic exchange. Baris yang dibuat sebelum event
+// stream dipakai masih berisi NotificationMessage dan dikirim langsung ke
+// queue notifikasi seperti sebelumnya.
+func (s *outboxService) publish(ctx context.Context, msg models.OutboxMessage) error {
+	var event utils.DomainEvent
+	if err := json.Unmarshal(msg.Payload, &event); err == nil && event.Version > 0 {
+		return s.rabbitmq.PublishEventJSON(ctx, event.RoutingKey(), msg.Payload, event.ID)
+	}
+	return s.rabbitmq.PublishJSON(ctx, msg.Payload, fmt.Sprintf("outbox-%d", msg.ID))
+}
+
+func (s *outboxService) backoff(attempts int) time.Duration {
+	delay := s.config.RetryBase
+	for i := 0; i < attempts && delay < s.config.RetryMax; i++ {
+		delay *= 2
+	}
+	if delay > s.config.RetryMax {
+		delay = s.config.RetryMax
+	}
+	return delay
+}
+
+// Run menjalankan relay sampai ctx selesai. Selama masih ada batch penuh,
+// relay langsung lanjut tanpa menunggu interval berikutnya.
+func (s *outboxService) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.config.PollInterval)
+	defer ticker.Stop()
+
+	lastCleanup := time.Now()
+	for {
+		for {
+			sent, err := s.RelayOnce(ctx)
+			if err != nil {
+				log.Printf("gagal menjalankan relay outbox: %v", err)
+				break
+			}
+			if sent < s.config.BatchSize {
+				break
+			}
+		}
+
+		if s.config.Retention > 0 && time.Since(lastCleanup) >= outboxCleanupInterval {
+			lastCleanup = time.Now()
+			if n, err := s.outboxRepo.DeleteSentBefore(time.Now().Add(-s.config.Retention)); err != nil {
+				log.Printf("gagal membersihkan outbox: %v", err)
+			} else if n > 0 {
+				log.Printf("%d pesan outbox lama dihapus", n)
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -3,7 +3,6 @@ package service
 import (
 	"context"
 	"errors"
-	"log"
 	"tiketsepur/models"
 	"tiketsepur/repository"
 	"tiketsepur/utils"
@@ -21,8 +20,8 @@ type paymentService struct {
 	paymentRepo  repository.PaymentRepository
 	ticketRepo   repository.TicketRepository
 	scheduleRepo repository.ScheduleRepository
-	userRepo     repository.UserRepository
-	rabbitmq     *utils.RabbitMQ
+	userRepo      repository.UserRepository
+	outboxService OutboxService
 }
 
 func NewPaymentService(
@@ -31,15 +30,15 @@ func NewPaymentService(
 	ticketRepo repository.TicketRepository,
 	scheduleRepo repository.ScheduleRepository,
 	userRepo repository.UserRepository,
-	rabbitmq *utils.RabbitMQ,
+	outboxService OutboxService,
 ) PaymentService {
 	return &paymentService{
 		db:           db,
 		paymentRepo:  paymentRepo,
 		ticketRepo:   ticketRepo,
 		scheduleRepo: scheduleRepo,
-		userRepo:     userRepo,
-		rabbitmq:     rabbitmq,
+		userRepo:      userRepo,
+		outboxService: outboxService,
 	}
 }
 
@@ -53,6 +52,10 @@ func (s *paymentService) ConfirmPayment(ctx context.Context, paymentCode string)
 		return errors.New("payment sudah dikonfirmasi")
 	}
 
+	if payment.PaymentStatus == "expired" {
+		return errors.New("batas waktu pembayaran sudah habis")
+	}
+
 	ticket, err := s.ticketRepo.FindByID(payment.TicketID)
 	if err != nil {
 		return errors.New("tiket tidak ditemukan")
@@ -74,12 +77,22 @@ func (s *paymentService) ConfirmPayment(ctx context.Context, paymentCode string)
 	}
 	defer tx.Rollback()
 
+	// tiket dikunci lebih dulu, urutan yang sama dengan sweep tiket kedaluwarsa,
+	// supaya konfirmasi dan sweep yang berjalan bersamaan tidak deadlock
+	confirmed, err := s.ticketRepo.TransitionStatus(ticket.ID, "pending", "confirmed", tx)
+	if err != nil {
+		return err
+	}
+	if !confirmed {
+		return errors.New("tiket tidak lagi menunggu pembayaran")
+	}
+
 	now := time.Now()
 	if err := s.updatePaymentStatus(tx, payment.ID, "success", &now); err != nil {
 		return err
 	}
 
-	if err := s.ticketRepo.UpdateStatus(ticket.ID, "confirmed", tx); err != nil {
+	if err := s.enqueuePaymentConfirmed(tx, user, ticket, schedule, payment, now); err != nil {
 		return err
 	}
 
@@ -87,8 +100,6 @@ func (s *paymentService) ConfirmPayment(ctx context.Context, paymentCode string)
 		return err
 	}
 
-	go s.sendPaymentConfirmationNotification(user, ticket, schedule, payment)
-
 	return nil
 }
 
@@ -100,29 +111,29 @@ func (s *paymentService) updatePaymentStatus(tx *sqlx.Tx, paymentID int, status
 	return err
 }
 
-func (s *paymentService) sendPaymentConfirmationNotification(
+func (s *paymentService) enqueuePaymentConfirmed(
+	tx *sqlx.Tx,
 	user *models.User,
 	ticket *models.TicketWithDetails,
 	schedule *models.Schedule,
 	payment *models.Payment,
-) {
-	notification := utils.NotificationMessage{
-		Type:          "payment",
-		Email:         user.Email,
-		BookingCode:   ticket.BookingCode,
-		TrainName:     schedule.TrainName,
-		Departure:     schedule.DepartureStation,
-		Arrival:       schedule.ArrivalStation,
-		SeatNumber:    ticket.SeatNumber,
-		TotalPrice:    payment.PaymentAmount,
-		PaymentCode:   payment.PaymentCode,
-		PaymentMethod: payment.PaymentMethod,
-		DepartureTime: schedule.DepartureTime.Format("2006-01-02 15:04"),
+	paidAt time.Time,
+) error {
+	confirmed := utils.PaymentConfirmedEvent{
+		PaymentCode:      payment.PaymentCode,
+		PaymentMethod:    payment.PaymentMethod,
+		Amount:           payment.PaymentAmount,
+		PaidAt:           paidAt.UTC(),
+		TicketID:         ticket.ID,
+		BookingCode:      ticket.BookingCode,
+		UserID:           user.ID,
+		ScheduleID:       schedule.ID,
+		TrainName:        schedule.TrainName,
+		DepartureStation: schedule.DepartureStation,
+		ArrivalStation:   schedule.ArrivalStation,
+		DepartureTime:    schedule.DepartureTime,
+		SeatNumber:       ticket.SeatNumber,
 	}
 
-	if err := s.rabbitmq.PublishNotification(notification); err != nil {
-		log.Printf("gagal mengirim notifikasi pembayaran: %v", err)
-	} else {
-		log.Printf("pemberitahuan konfirmasi pembayaran dikirim ke %s", user.Email)
-	}
+	return enqueueEvent(s.outboxService, tx, utils.EventPaymentConfirmed, "payment", payment.PaymentCode, confirmed)
 }
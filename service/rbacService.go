@@ -0,0 +1,278 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"sort"
+	"strconv"
+	config "tiketsepur/configs"
+	"tiketsepur/dto"
+	"tiketsepur/models"
+	"tiketsepur/repository"
+	"tiketsepur/utils"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+const permissionCacheExpiry = 10 * time.Minute
+
+// Role bawaan yang dipakai langsung oleh kode sehingga tidak boleh dihapus.
+var systemRoles = map[string]bool{"admin": true, "user": true}
+
+type RBACService interface {
+	GetPermissions(ctx context.Context, role string) ([]string, error)
+	HasPermission(ctx context.Context, role, permission string) (bool, error)
+	Authorize(ctx context.Context, role string, scopes []string, permission string) (bool, error)
+	CreateRole(ctx context.Context, req dto.CreateRoleRequest) (*models.Role, error)
+	GetRoles(query dto.ListQuery) ([]models.Role, repository.Page, error)
+	GetRoleByID(id int) (*models.Role, error)
+	DeleteRole(ctx context.Context, id int) error
+	GetAllPermissions() ([]models.Permission, error)
+	SetRolePermissions(ctx context.Context, id int, req dto.SetRolePermissionsRequest) (*models.Role, error)
+	AssignUserRole(ctx context.Context, userID int, req dto.AssignRoleRequest) (*models.User, error)
+}
+
+type rbacService struct {
+	db       *sqlx.DB
+	roleRepo repository.RoleRepository
+	userRepo repository.UserRepository
+	redis    *utils.RedisClient
+	config   *config.Config
+}
+
+func NewRBACService(db *sqlx.DB, roleRepo repository.RoleRepository, userRepo repository.UserRepository, redis *utils.RedisClient, cfg *config.Config) RBACService {
+	return &rbacService{
+		db:       db,
+		roleRepo: roleRepo,
+		userRepo: userRepo,
+		redis:    redis,
+		config:   cfg,
+	}
+}
+
+func (s *rbacService) GetPermissions(ctx context.Context, role string) ([]string, error) {
+	cacheKey := "rbac:role:" + role
+	if cached, err := s.redis.Get(ctx, cacheKey); err == nil {
+		var permissions []string
+		if err := json.Unmarshal([]byte(cached), &permissions); err == nil {
+			return permissions, nil
+		}
+	}
+
+	permissions, err := s.roleRepo.FindPermissionsByRole(role)
+	if err != nil {
+		return nil, err
+	}
+
+	if data, err := json.Marshal(permissions); err == nil {
+		if err := s.redis.Set(ctx, cacheKey, data, permissionCacheExpiry); err != nil {
+			log.Printf("gagal menyimpan cache permission role %s: %v", role, err)
+		}
+	}
+
+	return permissions, nil
+}
+
+func (s *rbacService) HasPermission(ctx context.Context, role, permission string) (bool, error) {
+	permissions, err := s.GetPermissions(ctx, role)
+	if err != nil {
+		return false, err
+	}
+
+	for _, p := range permissions {
+		if p == permission {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// Authorize sama dengan HasPermission, tetapi untuk request dengan api key
+// permission juga harus ada di scope key tersebut. Scopes nil berarti
+// request memakai sesi JWT sehingga hanya role yang diperiksa.
+func (s *rbacService) Authorize(ctx context.Context, role string, scopes []string, permission string) (bool, error) {
+	allowed, err := s.HasPermission(ctx, role, permission)
+	if err != nil || !allowed || scopes == nil {
+		return allowed, err
+	}
+	for _, scope := range scopes {
+		if scope == permission {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (s *rbacService) CreateRole(ctx context.Context, req dto.CreateRoleRequest) (*models.Role, error) {
+	existingRole, _ := s.roleRepo.FindByName(req.Name)
+	if existingRole != nil {
+		return nil, errors.New("role sudah ada")
+	}
+
+	role := &models.Role{Name: req.Name}
+	if req.Description != "" {
+		role.Description = &req.Description
+	}
+
+	tx, err := s.db.Beginx()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	if err := s.roleRepo.Create(role, tx); err != nil {
+		return nil, err
+	}
+
+	role.Permissions = uniqueStrings(req.Permissions)
+	if err := s.roleRepo.ReplacePermissions(role.ID, role.Permissions, tx); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	s.invalidate(ctx, role.Name)
+	return role, nil
+}
+
+func (s *rbacService) GetRoles(query dto.ListQuery) ([]models.Role, repository.Page, error) {
+	spec, err := querySpec(query)
+	if err != nil {
+		return nil, repository.Page{}, err
+	}
+
+	roles, page, err := s.roleRepo.FindAll(spec)
+	if err != nil {
+		return nil, page, err
+	}
+
+	for i := range roles {
+		roles[i].Permissions, err = s.roleRepo.FindPermissionsByRole(roles[i].Name)
+		if err != nil {
+			return nil, page, err
+		}
+	}
+	return roles, page, nil
+}
+
+func (s *rbacService) GetRoleByID(id int) (*models.Role, error) {
+	role, err := s.roleRepo.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	role.Permissions, err = s.roleRepo.FindPermissionsByRole(role.Name)
+	if err != nil {
+		return nil, err
+	}
+	return role, nil
+}
+
+func (s *rbacService) DeleteRole(ctx context.Context, id int) error {
+	role, err := s.roleRepo.FindByID(id)
+	if err != nil {
+		return errors.New("role tidak ditemukan")
+	}
+
+	if systemRoles[role.Name] {
+		return errors.New("role bawaan sistem tidak dapat dihapus")
+	}
+
+	count, err := s.roleRepo.CountUsers(role.Name)
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return errors.New("role masih digunakan oleh user")
+	}
+
+	if err := s.roleRepo.Delete(id); err != nil {
+		return err
+	}
+
+	s.invalidate(ctx, role.Name)
+	return nil
+}
+
+func (s *rbacService) GetAllPermissions() ([]models.Permission, error) {
+	return s.roleRepo.FindAllPermissions()
+}
+
+func (s *rbacService) SetRolePermissions(ctx context.Context, id int, req dto.SetRolePermissionsRequest) (*models.Role, error) {
+	role, err := s.roleRepo.FindByID(id)
+	if err != nil {
+		return nil, errors.New("role tidak ditemukan")
+	}
+
+	tx, err := s.db.Beginx()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	role.Permissions = uniqueStrings(req.Permissions)
+	if err := s.roleRepo.ReplacePermissions(role.ID, role.Permissions, tx); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	s.invalidate(ctx, role.Name)
+	return role, nil
+}
+
+func (s *rbacService) AssignUserRole(ctx context.Context, userID int, req dto.AssignRoleRequest) (*models.User, error) {
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return nil, errors.New("user tidak ditemukan")
+	}
+
+	if _, err := s.roleRepo.FindByName(req.Role); err != nil {
+		return nil, errors.New("role tidak ditemukan")
+	}
+
+	user.Role = req.Role
+	if err := s.userRepo.Update(userID, user); err != nil {
+		return nil, err
+	}
+
+	storeRoleOverride(ctx, s.redis, s.config, userID, user.Role)
+
+	return user, nil
+}
+
+func (s *rbacService) invalidate(ctx context.Context, role string) {
+	if err := s.redis.Delete(ctx, "rbac:role:"+role); err != nil {
+		log.Printf("gagal menghapus cache permission role %s: %v", role, err)
+	}
+}
+
+// Token yang sudah terbit masih membawa role lama, jadi role baru disimpan
+// di redis selama umur sesi dan dibaca ulang oleh authService.ValidateToken.
+func storeRoleOverride(ctx context.Context, redis *utils.RedisClient, cfg *config.Config, userID int, role string) {
+	roleKey := "user:role:" + strconv.Itoa(userID)
+	if err := redis.Set(ctx, roleKey, role, cfg.Redis.SessionExpiry); err != nil {
+		log.Printf("gagal menyimpan role baru user %d: %v", userID, err)
+	}
+}
+
+func uniqueStrings(values []string) []string {
+	seen := make(map[string]bool, len(values))
+	result := []string{}
+	for _, value := range values {
+		if value == "" || seen[value] {
+			continue
+		}
+		seen[value] = true
+		result = append(result, value)
+	}
+	sort.Strings(result)
+	return result
+}
@@ -0,0 +1,150 @@
+package service
+
+import (
+	"context"
+	"log"
+	"sort"
+	config "tiketsepur/configs"
+	"tiketsepur/repository"
+	"tiketsepur/utils"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+const reminderBatchSize = 100
+
+type ReminderService interface {
+	SendDueOnce(ctx context.Context) (int, error)
+	Run(ctx context.Context)
+}
+
+type reminderService struct {
+	db            *sqlx.DB
+	reminderRepo  repository.TicketReminderRepository
+	outboxService OutboxService
+	offsets       []time.Duration
+	interval      time.Duration
+}
+
+func NewReminderService(db *sqlx.DB, reminderRepo repository.TicketReminderRepository, outboxService OutboxService, cfg *config.Config) ReminderService {
+	offsets := append([]time.Duration(nil), cfg.Reminder.Offsets...)
+	if len(offsets) == 0 {
+		offsets = []time.Duration{24 * time.Hour, 2 * time.Hour}
+	}
+	// offset terbesar diproses lebih dulu; jendela setiap offset berakhir
+	// saat offset berikutnya mulai
+	sort.Slice(offsets, func(i, j int) bool { return offsets[i] > offsets[j] })
+
+	interval := cfg.Reminder.Interval
+	if interval <= 0 {
+		interval = time.Minute
+	}
+
+	return &reminderService{
+		db:            db,
+		reminderRepo:  reminderRepo,
+		outboxService: outboxService,
+		offsets:       offsets,
+		interval:      interval,
+	}
+}
+
+// SendDueOnce menjadwalkan pengingat yang sudah waktunya untuk setiap offset.
+// Jika worker sempat mati dan beberapa offset terlewat, hanya offset terdekat
+// dengan keberangkatan yang dikirim supaya penumpang tidak menerima pengingat
+// basi.
+func (s *reminderService) SendDueOnce(ctx context.Context) (int, error) {
+	total := 0
+	for i, offset := range s.offsets {
+		next := time.Duration(0)
+		if i+1 < len(s.offsets) {
+			next = s.offsets[i+1]
+		}
+
+		for {
+			sent, err := s.sendBatch(ctx, offset, next)
+			total += sent
+			if err != nil {
+				return total, err
+			}
+			if sent < reminderBatchSize {
+				break
+			}
+		}
+	}
+	return total, nil
+}
+
+// sendBatch mencatat pengingat dan memasukkan event ke outbox dalam satu
+// transaksi, sehingga restart di tengah jalan tidak pernah mengirim dua kali.
+func (s *reminderService) sendBatch(ctx context.Context, offset, next time.Duration) (int, error) {
+	offsetMinutes := int(offset / time.Minute)
+
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	tickets, err := s.reminderRepo.FindDue(offsetMinutes, int(next/time.Minute), reminderBatchSize, tx)
+	if err != nil {
+		return 0, err
+	}
+
+	sent := 0
+	for _, ticket := range tickets {
+		created, err := s.reminderRepo.Create(ticket.ID, offsetMinutes, tx)
+		if err != nil {
+			return 0, err
+		}
+		if !created {
+			continue
+		}
+
+		reminder := utils.TicketDepartureReminderEvent{
+			TicketID:         ticket.ID,
+			BookingCode:      ticket.BookingCode,
+			UserID:           ticket.UserID,
+			ScheduleID:       ticket.ScheduleID,
+			TrainName:        ticket.TrainName,
+			DepartureStation: ticket.DepartureStation,
+			ArrivalStation:   ticket.ArrivalStation,
+			DepartureTime:    ticket.DepartureTime,
+			Platform:         ticket.Platform,
+			Coach:            ticket.Coach,
+			SeatNumber:       ticket.SeatNumber,
+			PassengerName:    ticket.PassengerName,
+			OffsetMinutes:    offsetMinutes,
+		}
+		if err := enqueueEvent(s.outboxService, tx, utils.EventTicketReminder, "ticket", ticket.BookingCode, reminder); err != nil {
+			return 0, err
+		}
+		sent++
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+	return sent, nil
+}
+
+func (s *reminderService) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		sent, err := s.SendDueOnce(ctx)
+		if err != nil {
+			log.Printf("gagal menjadwalkan pengingat keberangkatan: %v", err)
+		} else if sent > 0 {
+			log.Printf("%d pengingat keberangkatan dijadwalkan", sent)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,241 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strconv"
+	"tiketsepur/dto"
+	"tiketsepur/models"
+	"tiketsepur/repository"
+	"tiketsepur/utils"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// jumlah jadwal pengganti yang ditawarkan ke penumpang saat jadwal dibatalkan
+const disruptionAlternativeLimit = 3
+
+type ScheduleDisruptionService interface {
+	Create(scheduleID int, createdBy int, req dto.CreateScheduleDisruptionRequest) (*models.ScheduleDisruption, error)
+	GetBySchedule(scheduleID int) ([]models.ScheduleDisruption, error)
+}
+
+type scheduleDisruptionService struct {
+	db             *sqlx.DB
+	disruptionRepo repository.ScheduleDisruptionRepository
+	scheduleRepo   repository.ScheduleRepository
+	scheduleCache  repository.ScheduleCache
+	ticketRepo     repository.TicketRepository
+	paymentRepo    repository.PaymentRepository
+	outboxService  OutboxService
+	liveSchedules  LiveScheduleService
+	fareCalendar   FareCalendarService
+}
+
+func NewScheduleDisruptionService(
+	db *sqlx.DB,
+	disruptionRepo repository.ScheduleDisruptionRepository,
+	scheduleRepo repository.ScheduleRepository,
+	scheduleCache repository.ScheduleCache,
+	ticketRepo repository.TicketRepository,
+	paymentRepo repository.PaymentRepository,
+	outboxService OutboxService,
+	liveSchedules LiveScheduleService,
+	fareCalendar FareCalendarService,
+) ScheduleDisruptionService {
+	return &scheduleDisruptionService{
+		db:             db,
+		disruptionRepo: disruptionRepo,
+		scheduleRepo:   scheduleRepo,
+		scheduleCache:  scheduleCache,
+		ticketRepo:     ticketRepo,
+		paymentRepo:    paymentRepo,
+		outboxService:  outboxService,
+		liveSchedules:  liveSchedules,
+		fareCalendar:   fareCalendar,
+	}
+}
+
+// Create mencatat gangguan jadwal, memperbarui status jadwal, dan mengabari
+// setiap pemegang tiket aktif lewat outbox. Untuk pembatalan, semua tiket ikut
+// dibatalkan, pembayaran yang sudah sukses di-refund penuh, dan penumpang
+// ditawari jadwal pengganti. Semua langkah berjalan dalam satu transaksi.
+func (s *scheduleDisruptionService) Create(scheduleID int, createdBy int, req dto.CreateScheduleDisruptionRequest) (*models.ScheduleDisruption, error) {
+	tx, err := s.db.Beginx()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	// jadwal dikunci sebelum dibaca supaya dua gangguan yang dicatat bersamaan
+	// tidak saling menimpa delay, peron, atau status
+	schedule, err := s.scheduleRepo.FindByIDForUpdate(scheduleID, tx)
+	if err != nil {
+		return nil, errors.New("schedule tidak ditemukan")
+	}
+	if schedule.Status == models.ScheduleStatusCancelled {
+		return nil, errors.New("jadwal sudah dibatalkan")
+	}
+
+	disruption := &models.ScheduleDisruption{
+		ScheduleID:     scheduleID,
+		DisruptionType: req.DisruptionType,
+		OldPlatform:    schedule.Platform,
+		CreatedBy:      &createdBy,
+	}
+	if req.Reason != "" {
+		disruption.Reason = &req.Reason
+	}
+
+	var alternatives []utils.AlternativeSchedule
+
+	switch req.DisruptionType {
+	case models.DisruptionDelay:
+		if req.DelayMinutes == nil {
+			return nil, errors.New("delay_minutes wajib diisi untuk keterlambatan")
+		}
+		disruption.DelayMinutes = req.DelayMinutes
+		schedule.DelayMinutes = *req.DelayMinutes
+		// delay 0 berarti kereta kembali sesuai jadwal
+		schedule.Status = models.ScheduleStatusScheduled
+		if *req.DelayMinutes > 0 {
+			schedule.Status = models.ScheduleStatusDelayed
+		}
+	case models.DisruptionPlatformChange:
+		if req.Platform == nil {
+			return nil, errors.New("platform wajib diisi untuk perubahan peron")
+		}
+		if schedule.Platform != nil && *schedule.Platform == *req.Platform {
+			return nil, errors.New("peron baru sama dengan peron saat ini")
+		}
+		disruption.NewPlatform = req.Platform
+		schedule.Platform = req.Platform
+	case models.DisruptionCancellation:
+		if req.Reason == "" {
+			return nil, errors.New("reason wajib diisi untuk pembatalan")
+		}
+		schedule.Status = models.ScheduleStatusCancelled
+
+		candidates, err := s.scheduleRepo.FindAlternatives(schedule, disruptionAlternativeLimit)
+		if err != nil {
+			return nil, err
+		}
+		for _, alt := range candidates {
+			alternatives = append(alternatives, utils.AlternativeSchedule{
+				ScheduleID:     alt.ID,
+				TrainName:      alt.TrainName,
+				DepartureTime:  alt.DepartureTime,
+				ArrivalTime:    alt.ArrivalTime,
+				Price:          alt.Price,
+				AvailableSeats: alt.AvailableSeats,
+			})
+		}
+	default:
+		return nil, errors.New("tipe gangguan tidak dikenal")
+	}
+
+	updated, err := s.scheduleRepo.UpdateOperationalStatus(schedule, tx)
+	if err != nil {
+		return nil, err
+	}
+	if !updated {
+		return nil, errors.New("jadwal sudah dibatalkan")
+	}
+
+	tickets, err := s.ticketRepo.FindActiveBySchedule(scheduleID, tx)
+	if err != nil {
+		return nil, err
+	}
+
+	refunds := make(map[int]float64)
+	if req.DisruptionType == models.DisruptionCancellation {
+		for _, ticket := range tickets {
+			if err := s.ticketRepo.UpdateStatus(ticket.ID, "cancelled", tx); err != nil {
+				return nil, err
+			}
+			payment, err := s.paymentRepo.RefundByTicketID(ticket.ID, tx)
+			if errors.Is(err, sql.ErrNoRows) {
+				// tidak ada pembayaran aktif yang perlu dikembalikan
+				continue
+			}
+			if err != nil {
+				return nil, err
+			}
+			if payment.PaymentStatus == "refunded" {
+				refunds[ticket.ID] = payment.PaymentAmount
+			}
+		}
+	}
+
+	disruption.AffectedTickets = len(tickets)
+	if err := s.disruptionRepo.Create(disruption, tx); err != nil {
+		return nil, err
+	}
+
+	estimated := schedule.DepartureTime.Add(time.Duration(schedule.DelayMinutes) * time.Minute)
+
+	disrupted := utils.ScheduleDisruptedEvent{
+		DisruptionID:           disruption.ID,
+		ScheduleID:             scheduleID,
+		DisruptionType:         disruption.DisruptionType,
+		Status:                 schedule.Status,
+		DelayMinutes:           schedule.DelayMinutes,
+		DepartureTime:          schedule.DepartureTime,
+		EstimatedDepartureTime: estimated,
+		OldPlatform:            disruption.OldPlatform,
+		NewPlatform:            disruption.NewPlatform,
+		Reason:                 req.Reason,
+		AffectedTickets:        disruption.AffectedTickets,
+	}
+	if err := enqueueEvent(s.outboxService, tx, utils.EventScheduleDisrupted, "schedule", strconv.Itoa(scheduleID), disrupted); err != nil {
+		return nil, err
+	}
+
+	for _, ticket := range tickets {
+		notice := utils.TicketDisruptedEvent{
+			TicketID:               ticket.ID,
+			BookingCode:            ticket.BookingCode,
+			UserID:                 ticket.UserID,
+			ScheduleID:             scheduleID,
+			DisruptionID:           disruption.ID,
+			DisruptionType:         disruption.DisruptionType,
+			TrainName:              ticket.TrainName,
+			DepartureStation:       ticket.DepartureStation,
+			ArrivalStation:         ticket.ArrivalStation,
+			DepartureTime:          ticket.DepartureTime,
+			EstimatedDepartureTime: estimated,
+			DelayMinutes:           schedule.DelayMinutes,
+			Platform:               schedule.Platform,
+			Reason:                 req.Reason,
+			SeatNumber:             ticket.SeatNumber,
+			PassengerName:          ticket.PassengerName,
+			RefundAmount:           refunds[ticket.ID],
+			Alternatives:           alternatives,
+		}
+		if notice.Alternatives == nil {
+			notice.Alternatives = []utils.AlternativeSchedule{}
+		}
+		if err := enqueueEvent(s.outboxService, tx, utils.EventTicketDisrupted, "ticket", ticket.BookingCode, notice); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	s.scheduleCache.InvalidateSchedules(scheduleID)
+	s.liveSchedules.Publish(context.Background(), scheduleID)
+	s.fareCalendar.Invalidate(context.Background(), schedule.DepartureStation, schedule.ArrivalStation, schedule.DepartureTime)
+
+	return disruption, nil
+}
+
+func (s *scheduleDisruptionService) GetBySchedule(scheduleID int) ([]models.ScheduleDisruption, error) {
+	if _, err := s.scheduleRepo.FindByID(scheduleID); err != nil {
+		return nil, errors.New("schedule tidak ditemukan")
+	}
+	return s.disruptionRepo.FindBySchedule(scheduleID)
+}
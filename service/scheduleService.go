@@ -1,39 +1,82 @@
 package service
 
 import (
+	"context"
+	"database/sql"
 	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
 	"tiketsepur/dto"
 	"tiketsepur/models"
 	"tiketsepur/repository"
+	"tiketsepur/utils"
+
+	"github.com/jmoiron/sqlx"
 )
 
 type ScheduleService interface {
 	Create(req dto.CreateScheduleRequest) (*models.Schedule, error)
 	GetByID(id int) (*models.Schedule, error)
-	GetAll() ([]models.Schedule, error)
+	GetAll(query dto.ListQuery) ([]models.Schedule, repository.Page, error)
 	Search(req dto.SearchScheduleRequest) ([]models.Schedule, error)
 	Update(id int, req dto.UpdateScheduleRequest) (*models.Schedule, error)
-	Delete(id int) error
+	Delete(id int, deletedBy int) (*models.ScheduleDisruption, error)
+	RecomputeSeats(id int) (*models.Schedule, error)
+	GetViolations() ([]models.ScheduleViolation, error)
+}
+
+// alasan pembatalan yang dikirim ke penumpang saat jadwal bertiket dihapus
+const scheduleDeletedReason = "jadwal ditiadakan oleh operator"
+
+// field yang mengubah perjalanan penumpang sehingga tidak bisa diedit selama
+// masih ada tiket aktif
+var requiresReaccommodation = map[string]bool{
+	"train_id":          true,
+	"departure_station": true,
+	"arrival_station":   true,
+	"departure_time":    true,
+	"arrival_time":      true,
 }
 
 type scheduleService struct {
+	db           *sqlx.DB
 	scheduleRepo repository.ScheduleRepository
-	trainRepo    repository.TrainRepository
+	// publicRepo melayani GetByID, GetAll, dan Search lewat cache jadwal;
+	// logika bisnis selalu memakai scheduleRepo
+	publicRepo    repository.ScheduleRepository
+	scheduleCache repository.ScheduleCache
+	trainRepo     repository.TrainRepository
+	ticketRepo    repository.TicketRepository
+	disruptions   ScheduleDisruptionService
+	outboxService OutboxService
+	liveSchedules LiveScheduleService
+	fareCalendar  FareCalendarService
+	validator     ScheduleValidator
+	stations      StationService
+	crew          CrewService
 }
 
-func NewScheduleService(scheduleRepo repository.ScheduleRepository, trainRepo repository.TrainRepository) ScheduleService {
+func NewScheduleService(db *sqlx.DB, scheduleRepo, publicRepo repository.ScheduleRepository, scheduleCache repository.ScheduleCache, trainRepo repository.TrainRepository, ticketRepo repository.TicketRepository, disruptions ScheduleDisruptionService, outboxService OutboxService, liveSchedules LiveScheduleService, fareCalendar FareCalendarService, validator ScheduleValidator, stations StationService, crew CrewService) ScheduleService {
 	return &scheduleService{
-		scheduleRepo: scheduleRepo,
-		trainRepo:    trainRepo,
+		db:            db,
+		scheduleRepo:  scheduleRepo,
+		publicRepo:    publicRepo,
+		scheduleCache: scheduleCache,
+		trainRepo:     trainRepo,
+		ticketRepo:    ticketRepo,
+		disruptions:   disruptions,
+		outboxService: outboxService,
+		liveSchedules: liveSchedules,
+		fareCalendar:  fareCalendar,
+		validator:     validator,
+		stations:      stations,
+		crew:          crew,
 	}
 }
 
 func (s *scheduleService) Create(req dto.CreateScheduleRequest) (*models.Schedule, error) {
-	_, err := s.trainRepo.FindByID(req.TrainID)
-	if err != nil {
-		return nil, errors.New("kereta tidak ditemukan")
-	}
-
 	schedule := &models.Schedule{
 		TrainID:          req.TrainID,
 		DepartureStation: req.DepartureStation,
@@ -42,77 +85,393 @@ func (s *scheduleService) Create(req dto.CreateScheduleRequest) (*models.Schedul
 		ArrivalTime:      req.ArrivalTime,
 		Price:            req.Price,
 		AvailableSeats:   req.AvailableSeats,
+		Platform:         req.Platform,
 	}
 
-	if err := s.scheduleRepo.Create(schedule); err != nil {
+	tx, err := s.db.Beginx()
+	if err != nil {
 		return nil, err
 	}
+	defer tx.Rollback()
+
+	if err := lockTrains(s.trainRepo, tx, schedule.TrainID); err != nil {
+		return nil, err
+	}
+
+	s.stations.Localize(schedule)
+	if err := s.validator.Validate(schedule, 0); err != nil {
+		return nil, err
+	}
+
+	if err := s.scheduleRepo.Create(schedule, tx); err != nil {
+		return nil, err
+	}
+
+	if err := enqueueScheduleChanged(s.outboxService, tx, schedule, utils.ScheduleCreated, nil); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	s.scheduleCache.InvalidateSchedules(schedule.ID)
+	s.fareCalendar.Invalidate(context.Background(), schedule.DepartureStation, schedule.ArrivalStation, schedule.DepartureTime)
 
 	return schedule, nil
 }
 
 func (s *scheduleService) GetByID(id int) (*models.Schedule, error) {
-	return s.scheduleRepo.FindByID(id)
+	return s.publicRepo.FindByID(id)
 }
 
-func (s *scheduleService) GetAll() ([]models.Schedule, error) {
-	return s.scheduleRepo.FindAll()
+func (s *scheduleService) GetAll(query dto.ListQuery) ([]models.Schedule, repository.Page, error) {
+	spec, err := querySpec(query)
+	if err != nil {
+		return nil, repository.Page{}, err
+	}
+	return s.publicRepo.FindAll(spec)
 }
 
 func (s *scheduleService) Search(req dto.SearchScheduleRequest) ([]models.Schedule, error) {
-	return s.scheduleRepo.Search(req.DepartureStation, req.ArrivalStation, req.Date)
+	criteria := repository.ScheduleSearch{
+		DepartureStation: req.DepartureStation,
+		ArrivalStation:   req.ArrivalStation,
+		Date:             req.Date,
+		DepartureAfter:   req.DepartureAfter,
+		DepartureBefore:  req.DepartureBefore,
+		MaxPrice:         req.MaxPrice,
+		MinSeats:         req.MinSeats,
+		Sort:             req.Sort,
+		Desc:             req.Order == "desc",
+	}
+
+	// train_type bisa dikirim berulang atau dipisah koma
+	for _, value := range req.TrainTypes {
+		for _, trainType := range strings.Split(value, ",") {
+			if trainType = strings.TrimSpace(trainType); trainType != "" {
+				criteria.TrainTypes = append(criteria.TrainTypes, trainType)
+			}
+		}
+	}
+
+	return s.publicRepo.Search(criteria)
 }
 
+// Update mengunci baris jadwal selama perubahan sehingga booking yang
+// berjalan bersamaan tidak tertimpa. Selama masih ada tiket aktif, kereta,
+// rute, dan waktu tidak bisa diubah karena penumpang harus dipindahkan lewat
+// gangguan jadwal; harga, peron, dan kursi tersedia tetap bisa diubah.
 func (s *scheduleService) Update(id int, req dto.UpdateScheduleRequest) (*models.Schedule, error) {
-	schedule, err := s.scheduleRepo.FindByID(id)
+	tx, err := s.db.Beginx()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	schedule, err := s.scheduleRepo.FindByIDForUpdate(id, tx)
 	if err != nil {
 		return nil, errors.New("schedule not found")
 	}
+	previous := *schedule
+
+	tickets, err := s.ticketRepo.CountBySchedule(id, tx)
+	if err != nil {
+		return nil, err
+	}
+
+	var changed []string
 
 	if req.TrainID != nil {
 		_, err := s.trainRepo.FindByID(*req.TrainID)
 		if err != nil {
 			return nil, errors.New("train not found")
 		}
+		if schedule.TrainID != *req.TrainID {
+			changed = append(changed, "train_id")
+		}
 		schedule.TrainID = *req.TrainID
 	}
 
 	if req.DepartureStation != nil {
+		if schedule.DepartureStation != *req.DepartureStation {
+			changed = append(changed, "departure_station")
+		}
 		schedule.DepartureStation = *req.DepartureStation
 	}
 
 	if req.ArrivalStation != nil {
+		if schedule.ArrivalStation != *req.ArrivalStation {
+			changed = append(changed, "arrival_station")
+		}
 		schedule.ArrivalStation = *req.ArrivalStation
 	}
 
 	if req.DepartureTime != nil {
+		if !schedule.DepartureTime.Equal(*req.DepartureTime) {
+			changed = append(changed, "departure_time")
+		}
 		schedule.DepartureTime = *req.DepartureTime
 	}
 
 	if req.ArrivalTime != nil {
+		if !schedule.ArrivalTime.Equal(*req.ArrivalTime) {
+			changed = append(changed, "arrival_time")
+		}
 		schedule.ArrivalTime = *req.ArrivalTime
 	}
 
 	if req.Price != nil {
+		if schedule.Price != *req.Price {
+			changed = append(changed, "price")
+		}
 		schedule.Price = *req.Price
 	}
 
 	if req.AvailableSeats != nil {
+		if schedule.AvailableSeats != *req.AvailableSeats {
+			changed = append(changed, "available_seats")
+		}
 		schedule.AvailableSeats = *req.AvailableSeats
 	}
 
-	if err := s.scheduleRepo.Update(id, schedule); err != nil {
+	if req.Platform != nil {
+		if schedule.Platform == nil || *schedule.Platform != *req.Platform {
+			changed = append(changed, "platform")
+		}
+		schedule.Platform = req.Platform
+	}
+
+	if tickets.Active > 0 {
+		blocked := &utils.ValidationError{}
+		for _, field := range changed {
+			if requiresReaccommodation[field] {
+				blocked.Add(field, models.ViolationRequiresReaccommodation, fmt.Sprintf(
+					"%s tidak bisa diubah karena jadwal memiliki %d tiket aktif; gunakan gangguan jadwal untuk memindahkan penumpang",
+					field, tickets.Active))
+			}
+		}
+		if err := blocked.Err(); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := lockTrains(s.trainRepo, tx, previous.TrainID, schedule.TrainID); err != nil {
+		return nil, err
+	}
+
+	// ganti kereta berarti kapasitas berubah, jadi kursi tersedia dihitung
+	// ulang kecuali admin mengisinya sendiri
+	if schedule.TrainID != previous.TrainID && req.AvailableSeats == nil {
+		train, err := s.trainRepo.FindByID(schedule.TrainID)
+		if err != nil {
+			return nil, errors.New("train not found")
+		}
+		schedule.AvailableSeats = seatsLeft(train.TotalSeats, tickets.Active)
+		if schedule.AvailableSeats != previous.AvailableSeats {
+			changed = append(changed, "available_seats")
+		}
+	}
+
+	s.stations.Localize(schedule)
+	if err := s.validator.Validate(schedule, tickets.Active); err != nil {
+		return nil, err
+	}
+
+	// kru yang sudah ditugaskan harus tetap memenuhi aturan di waktu baru
+	retimed := !schedule.DepartureTime.Equal(previous.DepartureTime) || !schedule.ArrivalTime.Equal(previous.ArrivalTime)
+	if retimed && schedule.Status != models.ScheduleStatusCancelled {
+		if err := s.crew.CheckSchedule(schedule, tx); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.scheduleRepo.Update(id, schedule, tx); err != nil {
+		return nil, err
+	}
+
+	// waktu yang diisi lewat API sudah membawa zona waktu, jadi tidak boleh
+	// lagi dibaca ulang saat zona waktu stasiun berubah
+	for _, field := range changed {
+		if field != "train_id" && requiresReaccommodation[field] {
+			if err := s.scheduleRepo.ClearLegacyTimes(id, tx); err != nil {
+				return nil, err
+			}
+			break
+		}
+	}
+
+	// update tanpa perubahan nilai tidak perlu diumumkan
+	if len(changed) > 0 {
+		if err := enqueueScheduleChanged(s.outboxService, tx, schedule, utils.ScheduleUpdated, changed); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
 		return nil, err
 	}
 
+	if len(changed) > 0 {
+		s.scheduleCache.InvalidateSchedules(id)
+		s.liveSchedules.Publish(context.Background(), id)
+		// rute atau tanggal bisa berubah, jadi cache lama dan baru sama-sama dihapus
+		s.fareCalendar.Invalidate(context.Background(), previous.DepartureStation, previous.ArrivalStation, previous.DepartureTime)
+		s.fareCalendar.Invalidate(context.Background(), schedule.DepartureStation, schedule.ArrivalStation, schedule.DepartureTime)
+	}
+
 	return schedule, nil
 }
 
-func (s *scheduleService) Delete(id int) error {
-	_, err := s.scheduleRepo.FindByID(id)
+// RecomputeSeats menghitung ulang available_seats dari kapasitas kereta
+// dikurangi tiket aktif, untuk memperbaiki inventori yang tidak sesuai.
+func (s *scheduleService) RecomputeSeats(id int) (*models.Schedule, error) {
+	tx, err := s.db.Beginx()
 	if err != nil {
-		return errors.New("schedule tidak ditemukan")
+		return nil, err
 	}
+	defer tx.Rollback()
 
-	return s.scheduleRepo.Delete(id)
-}
\ No newline at end of file
+	schedule, err := s.scheduleRepo.FindByIDForUpdate(id, tx)
+	if err != nil {
+		return nil, errors.New("schedule tidak ditemukan")
+	}
+	train, err := s.trainRepo.FindByID(schedule.TrainID)
+	if err != nil {
+		return nil, errors.New("kereta tidak ditemukan")
+	}
+	tickets, err := s.ticketRepo.CountBySchedule(id, tx)
+	if err != nil {
+		return nil, err
+	}
+
+	if tickets.Active > train.TotalSeats {
+		result := &utils.ValidationError{}
+		result.Add("available_seats", models.ViolationSeatsExceedCapacity, fmt.Sprintf(
+			"%d tiket aktif melebihi kapasitas kereta (%d kursi)", tickets.Active, train.TotalSeats))
+		return nil, result
+	}
+
+	seats := seatsLeft(train.TotalSeats, tickets.Active)
+	if seats == schedule.AvailableSeats {
+		return schedule, nil
+	}
+	schedule.AvailableSeats = seats
+
+	if err := s.scheduleRepo.Update(id, schedule, tx); err != nil {
+		return nil, err
+	}
+	if err := enqueueScheduleChanged(s.outboxService, tx, schedule, utils.ScheduleUpdated, []string{"available_seats"}); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	s.scheduleCache.InvalidateSchedules(id)
+	s.liveSchedules.Publish(context.Background(), id)
+	s.fareCalendar.Invalidate(context.Background(), schedule.DepartureStation, schedule.ArrivalStation, schedule.DepartureTime)
+	return schedule, nil
+}
+
+// Delete menghapus jadwal yang belum pernah punya tiket. Jika sudah ada
+// tiket, jadwal dibatalkan lewat gangguan jadwal supaya tiket dan
+// pembayarannya tetap tersimpan, tiket aktif di-refund, dan penumpang diberi
+// notifikasi; gangguan yang tercatat dikembalikan.
+func (s *scheduleService) Delete(id int, deletedBy int) (*models.ScheduleDisruption, error) {
+	tx, err := s.db.Beginx()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	schedule, err := s.scheduleRepo.FindByIDForUpdate(id, tx)
+	if err != nil {
+		return nil, errors.New("schedule tidak ditemukan")
+	}
+
+	tickets, err := s.ticketRepo.CountBySchedule(id, tx)
+	if err != nil {
+		return nil, err
+	}
+	if tickets.Total > 0 {
+		// lepaskan kunci dulu; pembatalan berjalan di transaksinya sendiri dan
+		// ikut memproses tiket yang dibuat di antaranya
+		tx.Rollback()
+		return s.disruptions.Create(id, deletedBy, dto.CreateScheduleDisruptionRequest{
+			DisruptionType: models.DisruptionCancellation,
+			Reason:         scheduleDeletedReason,
+		})
+	}
+
+	if err := s.scheduleRepo.Delete(id, tx); err != nil {
+		return nil, err
+	}
+
+	if err := enqueueScheduleChanged(s.outboxService, tx, schedule, utils.ScheduleDeleted, nil); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	s.scheduleCache.InvalidateSchedules(id)
+	s.liveSchedules.PublishDeleted(context.Background(), id)
+	s.fareCalendar.Invalidate(context.Background(), schedule.DepartureStation, schedule.ArrivalStation, schedule.DepartureTime)
+	return nil, nil
+}
+
+func seatsLeft(totalSeats, activeTickets int) int {
+	if activeTickets >= totalSeats {
+		return 0
+	}
+	return totalSeats - activeTickets
+}
+
+// lockTrains mengunci baris kereta dengan urutan id yang tetap sebelum jadwal
+// divalidasi. Pemeriksaan bentrok dan masa tidak tersedia dibaca di luar tx,
+// jadi tanpa kunci ini dua transaksi bisa sama-sama lolos validasi lalu
+// menyimpan jadwal yang beririsan. Kereta yang tidak ada dilewati supaya
+// validator melaporkannya sebagai field error.
+func lockTrains(trainRepo repository.TrainRepository, tx *sqlx.Tx, ids ...int) error {
+	sort.Ints(ids)
+	for i, id := range ids {
+		if i > 0 && ids[i-1] == id {
+			continue
+		}
+		if _, err := trainRepo.FindByIDForUpdate(id, tx); err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetViolations melaporkan jadwal tersimpan yang melanggar aturan konsistensi,
+// misalnya data lama yang dibuat sebelum validasi diterapkan.
+func (s *scheduleService) GetViolations() ([]models.ScheduleViolation, error) {
+	return s.validator.Violations()
+}
+
+func enqueueScheduleChanged(outboxService OutboxService, tx *sqlx.Tx, schedule *models.Schedule, change string, changedFields []string) error {
+	if changedFields == nil {
+		changedFields = []string{}
+	}
+
+	changed := utils.ScheduleChangedEvent{
+		ScheduleID:       schedule.ID,
+		Change:           change,
+		ChangedFields:    changedFields,
+		TrainID:          schedule.TrainID,
+		DepartureStation: schedule.DepartureStation,
+		ArrivalStation:   schedule.ArrivalStation,
+		DepartureTime:    schedule.DepartureTime,
+		ArrivalTime:      schedule.ArrivalTime,
+		Price:            schedule.Price,
+		AvailableSeats:   schedule.AvailableSeats,
+		Platform:         schedule.Platform,
+	}
+
+	return enqueueEvent(outboxService, tx, utils.EventScheduleChanged, "schedule", strconv.Itoa(schedule.ID), changed)
+}
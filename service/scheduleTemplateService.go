@@ -0,0 +1,468 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"tiketsepur/dto"
+	"tiketsepur/models"
+	"tiketsepur/repository"
+	"tiketsepur/utils"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+// batas rentang satu kali generate, cukup untuk jadwal satu tahun penuh
+const maxGenerateDays = 366
+
+type ScheduleTemplateService interface {
+	Create(req dto.CreateScheduleTemplateRequest, createdBy int) (*models.ScheduleTemplate, error)
+	GetByID(id int) (*models.ScheduleTemplate, error)
+	GetAll(query dto.ListQuery) ([]models.ScheduleTemplate, repository.Page, error)
+	Update(id int, req dto.UpdateScheduleTemplateRequest) (*models.ScheduleTemplate, error)
+	Delete(id int) error
+	Generate(id int, req dto.GenerateSchedulesRequest) (*dto.GenerateSchedulesResponse, error)
+}
+
+type scheduleTemplateService struct {
+	db                 *sqlx.DB
+	templateRepo       repository.ScheduleTemplateRepository
+	scheduleRepo       repository.ScheduleRepository
+	scheduleCache      repository.ScheduleCache
+	trainRepo          repository.TrainRepository
+	outboxService      OutboxService
+	fareCalendar       FareCalendarService
+	stations           StationService
+	stationRepo        repository.StationRepository
+	unavailabilityRepo repository.TrainUnavailabilityRepository
+}
+
+func NewScheduleTemplateService(
+	db *sqlx.DB,
+	templateRepo repository.ScheduleTemplateRepository,
+	scheduleRepo repository.ScheduleRepository,
+	scheduleCache repository.ScheduleCache,
+	trainRepo repository.TrainRepository,
+	outboxService OutboxService,
+	fareCalendar FareCalendarService,
+	stations StationService,
+	stationRepo repository.StationRepository,
+	unavailabilityRepo repository.TrainUnavailabilityRepository,
+) ScheduleTemplateService {
+	return &scheduleTemplateService{
+		db:                 db,
+		templateRepo:       templateRepo,
+		scheduleRepo:       scheduleRepo,
+		scheduleCache:      scheduleCache,
+		trainRepo:          trainRepo,
+		outboxService:      outboxService,
+		fareCalendar:       fareCalendar,
+		stations:           stations,
+		stationRepo:        stationRepo,
+		unavailabilityRepo: unavailabilityRepo,
+	}
+}
+
+func (s *scheduleTemplateService) Create(req dto.CreateScheduleTemplateRequest, createdBy int) (*models.ScheduleTemplate, error) {
+	template := &models.ScheduleTemplate{
+		TrainID:          req.TrainID,
+		DepartureStation: req.DepartureStation,
+		ArrivalStation:   req.ArrivalStation,
+		DepartureTime:    req.DepartureTime,
+		ArrivalTime:      req.ArrivalTime,
+		ArrivalDayOffset: req.ArrivalDayOffset,
+		DaysOfWeek:       uniqueDays(req.DaysOfWeek),
+		ValidFrom:        req.ValidFrom,
+		ValidTo:          req.ValidTo,
+		Price:            req.Price,
+		Platform:         req.Platform,
+		IsActive:         true,
+		CreatedBy:        &createdBy,
+	}
+	if err := s.validate(template); err != nil {
+		return nil, err
+	}
+
+	if err := s.templateRepo.Create(template); err != nil {
+		return nil, err
+	}
+	return template, nil
+}
+
+func (s *scheduleTemplateService) GetByID(id int) (*models.ScheduleTemplate, error) {
+	return s.templateRepo.FindByID(id)
+}
+
+func (s *scheduleTemplateService) GetAll(query dto.ListQuery) ([]models.ScheduleTemplate, repository.Page, error) {
+	spec, err := querySpec(query)
+	if err != nil {
+		return nil, repository.Page{}, err
+	}
+	return s.templateRepo.FindAll(spec)
+}
+
+// Update hanya memengaruhi jadwal yang di-generate setelahnya; jadwal yang
+// sudah dibuat tidak ikut berubah.
+func (s *scheduleTemplateService) Update(id int, req dto.UpdateScheduleTemplateRequest) (*models.ScheduleTemplate, error) {
+	template, err := s.templateRepo.FindByID(id)
+	if err != nil {
+		return nil, errors.New("template jadwal tidak ditemukan")
+	}
+
+	if req.TrainID != nil {
+		template.TrainID = *req.TrainID
+	}
+	if req.DepartureStation != nil {
+		template.DepartureStation = *req.DepartureStation
+	}
+	if req.ArrivalStation != nil {
+		template.ArrivalStation = *req.ArrivalStation
+	}
+	if req.DepartureTime != nil {
+		template.DepartureTime = *req.DepartureTime
+	}
+	if req.ArrivalTime != nil {
+		template.ArrivalTime = *req.ArrivalTime
+	}
+	if req.ArrivalDayOffset != nil {
+		template.ArrivalDayOffset = *req.ArrivalDayOffset
+	}
+	if req.DaysOfWeek != nil {
+		template.DaysOfWeek = uniqueDays(req.DaysOfWeek)
+	}
+	if req.ValidFrom != nil {
+		template.ValidFrom = *req.ValidFrom
+	}
+	if req.ValidTo != nil {
+		template.ValidTo = *req.ValidTo
+	}
+	if req.Price != nil {
+		template.Price = *req.Price
+	}
+	if req.Platform != nil {
+		template.Platform = req.Platform
+	}
+	if req.IsActive != nil {
+		template.IsActive = *req.IsActive
+	}
+
+	if err := s.validate(template); err != nil {
+		return nil, err
+	}
+	if err := s.templateRepo.Update(template); err != nil {
+		return nil, err
+	}
+	return template, nil
+}
+
+func (s *scheduleTemplateService) Delete(id int) error {
+	if _, err := s.templateRepo.FindByID(id); err != nil {
+		return errors.New("template jadwal tidak ditemukan")
+	}
+	return s.templateRepo.Delete(id)
+}
+
+// Generate membuat jadwal untuk setiap tanggal di rentang from sampai to
+// (inklusif) yang masuk masa berlaku dan hari operasi template. Jam berangkat
+// dibaca di zona waktu stasiun keberangkatan dan jam tiba di zona waktu
+// stasiun tujuan. Tanggal yang sudah punya jadwal dari template ini, jadwal
+// kereta yang sama di jam berangkat yang sama, atau jatuh di masa kereta tidak
+// tersedia, dilewati. Dengan DryRun tidak ada yang disimpan.
+func (s *scheduleTemplateService) Generate(id int, req dto.GenerateSchedulesRequest) (*dto.GenerateSchedulesResponse, error) {
+	template, err := s.templateRepo.FindByID(id)
+	if err != nil {
+		return nil, errors.New("template jadwal tidak ditemukan")
+	}
+	if !template.IsActive {
+		return nil, errors.New("template jadwal tidak aktif")
+	}
+
+	from, _ := time.Parse("2006-01-02", req.From)
+	to, _ := time.Parse("2006-01-02", req.To)
+	if to.Before(from) {
+		return nil, errors.New("to harus sama dengan atau setelah from")
+	}
+	if days := int(to.Sub(from).Hours()/24) + 1; days > maxGenerateDays {
+		return nil, fmt.Errorf("rentang generate maksimal %d hari", maxGenerateDays)
+	}
+
+	validFrom, _ := time.Parse("2006-01-02", template.ValidFrom)
+	validTo, _ := time.Parse("2006-01-02", template.ValidTo)
+	if from.Before(validFrom) {
+		from = validFrom
+	}
+	if to.After(validTo) {
+		to = validTo
+	}
+
+	response := &dto.GenerateSchedulesResponse{
+		TemplateID: template.ID,
+		DryRun:     req.DryRun,
+		Created:    []models.Schedule{},
+		Skipped:    []dto.SkippedScheduleDate{},
+	}
+	if to.Before(from) {
+		return response, nil
+	}
+
+	// generate sungguhan mengunci baris kereta sebelum membaca jadwal yang ada,
+	// sama seperti pembuatan jadwal manual, supaya jadwal yang disusun tidak
+	// bentrok dengan jadwal yang disimpan transaksi lain di saat yang sama
+	var tx *sqlx.Tx
+	if !req.DryRun {
+		tx, err = s.db.Beginx()
+		if err != nil {
+			return nil, err
+		}
+		defer tx.Rollback()
+
+		if err := lockTrains(s.trainRepo, tx, template.TrainID); err != nil {
+			return nil, err
+		}
+	}
+
+	train, err := s.trainRepo.FindByID(template.TrainID)
+	if err != nil {
+		return nil, errors.New("kereta tidak ditemukan")
+	}
+
+	departureLoc := s.stations.Location(template.DepartureStation)
+	arrivalLoc := s.stations.Location(template.ArrivalStation)
+
+	// from dan to adalah tanggal tanpa zona waktu; sehari ekstra di depan
+	// menutup selisih zona waktu stasiun terhadap UTC
+	existing, err := s.templateRepo.FindExistingDepartures(template, from.AddDate(0, 0, -1), to.AddDate(0, 0, 1+template.ArrivalDayOffset))
+	if err != nil {
+		return nil, err
+	}
+	unavailable, err := s.unavailabilityRepo.FindByTrain(template.TrainID, from.AddDate(0, 0, -1), to.AddDate(0, 0, 1+template.ArrivalDayOffset))
+	if err != nil {
+		return nil, err
+	}
+
+	scheduleGeneration{
+		template:     template,
+		train:        train,
+		from:         from,
+		to:           to,
+		departureLoc: departureLoc,
+		arrivalLoc:   arrivalLoc,
+		existing:     existing,
+		unavailable:  unavailable,
+		now:          time.Now(),
+	}.plan(response)
+
+	if req.DryRun || len(response.Created) == 0 {
+		return response, nil
+	}
+
+	for i := range response.Created {
+		schedule := &response.Created[i]
+		if err := s.scheduleRepo.Create(schedule, tx); err != nil {
+			return nil, err
+		}
+		if err := enqueueScheduleChanged(s.outboxService, tx, schedule, utils.ScheduleCreated, nil); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	if len(response.Created) > 0 {
+		s.scheduleCache.InvalidateSchedules()
+	}
+
+	months := make(map[string]struct{})
+	for _, schedule := range response.Created {
+		month := schedule.DepartureTime.Format("2006-01")
+		if _, ok := months[month]; ok {
+			continue
+		}
+		months[month] = struct{}{}
+		s.fareCalendar.Invalidate(context.Background(), schedule.DepartureStation, schedule.ArrivalStation, schedule.DepartureTime)
+	}
+
+	return response, nil
+}
+
+func (s *scheduleTemplateService) validate(template *models.ScheduleTemplate) error {
+	if _, err := s.trainRepo.FindByID(template.TrainID); err != nil {
+		return errors.New("kereta tidak ditemukan")
+	}
+	if sameStation(template.DepartureStation, template.ArrivalStation) {
+		return errors.New("stasiun asal dan tujuan tidak boleh sama")
+	}
+	if _, err := s.stationRepo.FindByName(template.DepartureStation); err != nil {
+		return errors.New("stasiun keberangkatan belum terdaftar")
+	}
+	if _, err := s.stationRepo.FindByName(template.ArrivalStation); err != nil {
+		return errors.New("stasiun tujuan belum terdaftar")
+	}
+	if len(template.DaysOfWeek) == 0 {
+		return errors.New("days_of_week wajib diisi")
+	}
+
+	validFrom, err := time.Parse("2006-01-02", template.ValidFrom)
+	if err != nil {
+		return errors.New("valid_from harus berformat YYYY-MM-DD")
+	}
+	validTo, err := time.Parse("2006-01-02", template.ValidTo)
+	if err != nil {
+		return errors.New("valid_to harus berformat YYYY-MM-DD")
+	}
+	if validTo.Before(validFrom) {
+		return errors.New("valid_to harus sama dengan atau setelah valid_from")
+	}
+
+	departure, err := time.Parse("15:04", template.DepartureTime)
+	if err != nil {
+		return errors.New("departure_time harus berformat HH:MM")
+	}
+	arrival, err := time.Parse("15:04", template.ArrivalTime)
+	if err != nil {
+		return errors.New("arrival_time harus berformat HH:MM")
+	}
+	// stasiun asal dan tujuan bisa beda zona waktu, jadi dibandingkan sebagai
+	// waktu absolut pada tanggal mulai berlaku
+	departureAt := atClock(validFrom, departure, s.stations.Location(template.DepartureStation))
+	arrivalAt := atClock(validFrom.AddDate(0, 0, template.ArrivalDayOffset), arrival, s.stations.Location(template.ArrivalStation))
+	if !arrivalAt.After(departureAt) {
+		return errors.New("waktu tiba harus setelah waktu berangkat")
+	}
+	return nil
+}
+
+// scheduleGeneration berisi semua masukan untuk menyusun jadwal dari template
+// sehingga penyusunannya tidak bergantung pada database.
+type scheduleGeneration struct {
+	template     *models.ScheduleTemplate
+	train        *models.Train
+	from, to     time.Time
+	departureLoc *time.Location
+	arrivalLoc   *time.Location
+	existing     []repository.ExistingDeparture
+	unavailable  []models.TrainUnavailability
+	now          time.Time
+}
+
+// plan mengisi Created dan Skipped response untuk setiap hari operasi
+// template antara from dan to.
+func (g scheduleGeneration) plan(response *dto.GenerateSchedulesResponse) {
+	template := g.template
+	departureClock, _ := time.Parse("15:04", template.DepartureTime)
+	arrivalClock, _ := time.Parse("15:04", template.ArrivalTime)
+
+	fromTemplate := make(map[string]int)
+	// key map memakai detik Unix karena time.Time dengan lokasi atau monotonic
+	// clock berbeda tidak sama sebagai key walaupun menunjuk waktu yang sama
+	fromTrain := make(map[int64]int)
+	var occupied []repository.ExistingDeparture
+	for _, departure := range g.existing {
+		if departure.TemplateID != nil && *departure.TemplateID == template.ID {
+			fromTemplate[departure.DepartureTime.In(g.departureLoc).Format("2006-01-02")] = departure.ScheduleID
+		}
+		fromTrain[departure.DepartureTime.Truncate(time.Minute).Unix()] = departure.ScheduleID
+		if departure.Status != models.ScheduleStatusCancelled {
+			occupied = append(occupied, departure)
+		}
+	}
+
+	operates := make(map[time.Weekday]bool, len(template.DaysOfWeek))
+	for _, day := range template.DaysOfWeek {
+		operates[time.Weekday(day%7)] = true
+	}
+
+	for day := g.from; !day.After(g.to); day = day.AddDate(0, 0, 1) {
+		if !operates[day.Weekday()] {
+			continue
+		}
+		date := day.Format("2006-01-02")
+		departure := atClock(day, departureClock, g.departureLoc)
+		arrival := atClock(day.AddDate(0, 0, template.ArrivalDayOffset), arrivalClock, g.arrivalLoc)
+
+		if scheduleID, ok := fromTemplate[date]; ok {
+			response.Skipped = append(response.Skipped, dto.SkippedScheduleDate{Date: date, Reason: "exists", ScheduleID: scheduleID})
+			continue
+		}
+		if scheduleID, ok := fromTrain[departure.Unix()]; ok {
+			response.Skipped = append(response.Skipped, dto.SkippedScheduleDate{Date: date, Reason: "exists", ScheduleID: scheduleID})
+			continue
+		}
+		if departure.Before(g.now) {
+			response.Skipped = append(response.Skipped, dto.SkippedScheduleDate{Date: date, Reason: "past"})
+			continue
+		}
+		if unavailableAt(g.unavailable, departure, arrival) {
+			response.Skipped = append(response.Skipped, dto.SkippedScheduleDate{Date: date, Reason: "unavailable"})
+			continue
+		}
+		if scheduleID, ok := overlapping(occupied, departure, arrival); ok {
+			response.Skipped = append(response.Skipped, dto.SkippedScheduleDate{Date: date, Reason: "overlap", ScheduleID: scheduleID})
+			continue
+		}
+
+		response.Created = append(response.Created, models.Schedule{
+			TrainID:           template.TrainID,
+			DepartureStation:  template.DepartureStation,
+			ArrivalStation:    template.ArrivalStation,
+			DepartureTime:     departure,
+			ArrivalTime:       arrival,
+			DepartureTimezone: g.departureLoc.String(),
+			ArrivalTimezone:   g.arrivalLoc.String(),
+			Price:             template.Price,
+			AvailableSeats:    g.train.TotalSeats,
+			Platform:          template.Platform,
+			Status:            models.ScheduleStatusScheduled,
+			DurationMinutes:   int(arrival.Sub(departure).Minutes()),
+			TemplateID:        &template.ID,
+			TrainCode:         g.train.TrainCode,
+			TrainName:         g.train.TrainName,
+			TrainType:         g.train.TrainType,
+		})
+		// perjalanan yang lebih dari sehari bisa bentrok dengan keberangkatan berikutnya
+		occupied = append(occupied, repository.ExistingDeparture{DepartureTime: departure, ArrivalTime: arrival})
+	}
+}
+
+// overlapping mencari jadwal yang rentang waktunya beririsan dengan
+// departure-arrival. ScheduleID nol berarti bentrok dengan jadwal lain di
+// hasil generate yang sama.
+func overlapping(occupied []repository.ExistingDeparture, departure, arrival time.Time) (int, bool) {
+	for _, other := range occupied {
+		if departure.Before(other.ArrivalTime) && other.DepartureTime.Before(arrival) {
+			return other.ScheduleID, true
+		}
+	}
+	return 0, false
+}
+
+func unavailableAt(windows []models.TrainUnavailability, departure, arrival time.Time) bool {
+	for _, window := range windows {
+		if departure.Before(window.EndsAt) && window.StartsAt.Before(arrival) {
+			return true
+		}
+	}
+	return false
+}
+
+// atClock menggabungkan tanggal day dengan jam dan menit clock di zona waktu loc.
+func atClock(day, clock time.Time, loc *time.Location) time.Time {
+	return time.Date(day.Year(), day.Month(), day.Day(), clock.Hour(), clock.Minute(), 0, 0, loc)
+}
+
+func uniqueDays(days []int64) pq.Int64Array {
+	seen := make(map[int64]struct{}, len(days))
+	unique := pq.Int64Array{}
+	for _, day := range days {
+		if _, ok := seen[day]; ok {
+			continue
+		}
+		seen[day] = struct{}{}
+		unique = append(unique, day)
+	}
+	return unique
+}
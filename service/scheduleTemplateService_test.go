@@ -0,0 +1,163 @@
+package service
+
+import (
+	"testing"
+	"tiketsepur/dto"
+	"tiketsepur/models"
+	"tiketsepur/repository"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+var (
+	testWIB  = time.FixedZone("Asia/Jakarta", 7*3600)
+	testWITA = time.FixedZone("Asia/Makassar", 8*3600)
+)
+
+func testTemplate(offset int, days ...int64) *models.ScheduleTemplate {
+	return &models.ScheduleTemplate{
+		ID:               5,
+		TrainID:          9,
+		DepartureStation: "Gambir",
+		ArrivalStation:   "Bandung",
+		DepartureTime:    "08:00",
+		ArrivalTime:      "12:00",
+		ArrivalDayOffset: offset,
+		DaysOfWeek:       pq.Int64Array(days),
+		Price:            150000,
+	}
+}
+
+// wibAt mengembalikan jam tertentu di bulan November 2026 dalam WIB.
+func wibAt(day, hour, minute int) time.Time {
+	return time.Date(2026, 11, day, hour, minute, 0, 0, testWIB)
+}
+
+func testDate(day int) time.Time {
+	return time.Date(2026, 11, day, 0, 0, 0, 0, time.UTC)
+}
+
+func TestScheduleGenerationSkipReasons(t *testing.T) {
+	templateID := 5
+	g := scheduleGeneration{
+		// Senin sampai Sabtu, Minggu 8 November tidak beroperasi
+		template:     testTemplate(0, 1, 2, 3, 4, 5, 6),
+		train:        &models.Train{TrainCode: "KA7", TrainName: "Argo Parahyangan", TrainType: "eksekutif", TotalSeats: 400},
+		from:         testDate(2),
+		to:           testDate(8),
+		departureLoc: testWIB,
+		arrivalLoc:   testWITA,
+		existing: []repository.ExistingDeparture{
+			{ScheduleID: 11, TemplateID: &templateID, DepartureTime: wibAt(3, 8, 0), ArrivalTime: time.Date(2026, 11, 3, 13, 0, 0, 0, testWITA)},
+			// jadwal manual di jam yang sama, dibaca dari database dalam UTC
+			{ScheduleID: 12, DepartureTime: time.Date(2026, 11, 4, 1, 0, 0, 0, time.UTC), ArrivalTime: time.Date(2026, 11, 4, 5, 0, 0, 0, time.UTC)},
+			{ScheduleID: 13, DepartureTime: wibAt(6, 10, 0), ArrivalTime: wibAt(6, 14, 0)},
+			// jadwal batal tidak menghalangi jadwal baru
+			{ScheduleID: 14, DepartureTime: wibAt(7, 9, 0), ArrivalTime: wibAt(7, 11, 0), Status: models.ScheduleStatusCancelled},
+		},
+		unavailable: []models.TrainUnavailability{
+			{StartsAt: wibAt(5, 0, 0), EndsAt: wibAt(6, 0, 0)},
+		},
+		now: wibAt(2, 9, 0),
+	}
+
+	response := &dto.GenerateSchedulesResponse{}
+	g.plan(response)
+
+	want := []dto.SkippedScheduleDate{
+		{Date: "2026-11-02", Reason: "past"},
+		{Date: "2026-11-03", Reason: "exists", ScheduleID: 11},
+		{Date: "2026-11-04", Reason: "exists", ScheduleID: 12},
+		{Date: "2026-11-05", Reason: "unavailable"},
+		{Date: "2026-11-06", Reason: "overlap", ScheduleID: 13},
+	}
+	if len(response.Skipped) != len(want) {
+		t.Fatalf("skipped = %+v, want %+v", response.Skipped, want)
+	}
+	for i := range want {
+		if response.Skipped[i] != want[i] {
+			t.Errorf("skipped[%d] = %+v, want %+v", i, response.Skipped[i], want[i])
+		}
+	}
+
+	if len(response.Created) != 1 {
+		t.Fatalf("created = %d jadwal, want 1 (Sabtu)", len(response.Created))
+	}
+	created := response.Created[0]
+	if !created.DepartureTime.Equal(wibAt(7, 8, 0)) {
+		t.Errorf("departure = %v", created.DepartureTime)
+	}
+	// jam tiba dibaca di zona stasiun tujuan: 12:00 WITA = 11:00 WIB
+	if !created.ArrivalTime.Equal(wibAt(7, 11, 0)) || created.DurationMinutes != 180 {
+		t.Errorf("arrival = %v, duration = %d", created.ArrivalTime, created.DurationMinutes)
+	}
+	if created.AvailableSeats != 400 || created.TrainCode != "KA7" || created.TemplateID == nil || *created.TemplateID != 5 {
+		t.Errorf("created = %+v", created)
+	}
+	if created.DepartureTimezone != "Asia/Jakarta" || created.ArrivalTimezone != "Asia/Makassar" {
+		t.Errorf("timezone = %s - %s", created.DepartureTimezone, created.ArrivalTimezone)
+	}
+}
+
+func TestScheduleGenerationOverlapWithinSameRun(t *testing.T) {
+	// perjalanan tiba keesokan harinya jam 12:00, jadi keberangkatan hari
+	// berikutnya jam 08:00 bentrok dengan jadwal yang baru saja disusun
+	g := scheduleGeneration{
+		template:     testTemplate(1, 1, 2, 3, 4, 5, 6, 7),
+		train:        &models.Train{TotalSeats: 400},
+		from:         testDate(2),
+		to:           testDate(4),
+		departureLoc: testWIB,
+		arrivalLoc:   testWIB,
+		now:          wibAt(1, 0, 0),
+	}
+
+	response := &dto.GenerateSchedulesResponse{}
+	g.plan(response)
+
+	if len(response.Created) != 2 {
+		t.Fatalf("created = %d jadwal, want 2", len(response.Created))
+	}
+	if len(response.Skipped) != 1 || response.Skipped[0] != (dto.SkippedScheduleDate{Date: "2026-11-03", Reason: "overlap"}) {
+		t.Fatalf("skipped = %+v, want overlap 2026-11-03 tanpa schedule_id", response.Skipped)
+	}
+}
+
+func TestOverlapping(t *testing.T) {
+	occupied := []repository.ExistingDeparture{{ScheduleID: 3, DepartureTime: wibAt(2, 8, 0), ArrivalTime: wibAt(2, 12, 0)}}
+
+	cases := []struct {
+		departure, arrival time.Time
+		want               bool
+	}{
+		{wibAt(2, 6, 0), wibAt(2, 8, 0), false},   // tiba tepat saat jadwal lain berangkat
+		{wibAt(2, 12, 0), wibAt(2, 14, 0), false}, // berangkat tepat saat jadwal lain tiba
+		{wibAt(2, 7, 0), wibAt(2, 9, 0), true},
+		{wibAt(2, 9, 0), wibAt(2, 10, 0), true},
+		{wibAt(2, 6, 0), wibAt(2, 14, 0), true},
+	}
+	for _, tc := range cases {
+		id, got := overlapping(occupied, tc.departure, tc.arrival)
+		if got != tc.want || (got && id != 3) {
+			t.Errorf("overlapping(%v, %v) = %d, %v, want %v", tc.departure.Hour(), tc.arrival.Hour(), id, got, tc.want)
+		}
+	}
+}
+
+func TestUnavailableAt(t *testing.T) {
+	windows := []models.TrainUnavailability{{StartsAt: wibAt(2, 10, 0), EndsAt: wibAt(2, 14, 0)}}
+
+	if unavailableAt(windows, wibAt(2, 6, 0), wibAt(2, 10, 0)) {
+		t.Error("perjalanan yang tiba saat perawatan dimulai seharusnya tetap tersedia")
+	}
+	if unavailableAt(windows, wibAt(2, 14, 0), wibAt(2, 16, 0)) {
+		t.Error("perjalanan yang berangkat saat perawatan selesai seharusnya tetap tersedia")
+	}
+	if !unavailableAt(windows, wibAt(2, 9, 0), wibAt(2, 11, 0)) || !unavailableAt(windows, wibAt(2, 11, 0), wibAt(2, 12, 0)) {
+		t.Error("perjalanan yang beririsan dengan perawatan seharusnya tidak tersedia")
+	}
+	if unavailableAt(nil, wibAt(2, 9, 0), wibAt(2, 11, 0)) {
+		t.Error("tanpa jendela perawatan kereta selalu tersedia")
+	}
+}
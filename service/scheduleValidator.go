@@ -0,0 +1,135 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+	"tiketsepur/models"
+	"tiketsepur/repository"
+	"tiketsepur/utils"
+	"time"
+)
+
+// ScheduleValidator memeriksa aturan konsistensi jadwal: waktu tiba setelah
+// berangkat, stasiun asal dan tujuan berbeda dan sudah terdaftar, kursi tidak
+// melebihi kapasitas kereta, kereta tidak sedang dalam perawatan atau tidak
+// beroperasi, dan satu kereta tidak dipakai dua jadwal yang waktunya
+// beririsan.
+type ScheduleValidator interface {
+	Validate(schedule *models.Schedule, soldSeats int) error
+	Violations() ([]models.ScheduleViolation, error)
+}
+
+type scheduleValidator struct {
+	scheduleRepo       repository.ScheduleRepository
+	trainRepo          repository.TrainRepository
+	stationRepo        repository.StationRepository
+	unavailabilityRepo repository.TrainUnavailabilityRepository
+}
+
+func NewScheduleValidator(scheduleRepo repository.ScheduleRepository, trainRepo repository.TrainRepository, stationRepo repository.StationRepository, unavailabilityRepo repository.TrainUnavailabilityRepository) ScheduleValidator {
+	return &scheduleValidator{scheduleRepo: scheduleRepo, trainRepo: trainRepo, stationRepo: stationRepo, unavailabilityRepo: unavailabilityRepo}
+}
+
+// Validate mengembalikan *utils.ValidationError berisi semua pelanggaran, atau
+// error lain jika pemeriksaan gagal dijalankan. schedule.ID diisi saat update
+// supaya jadwal itu sendiri tidak dihitung sebagai bentrok. soldSeats adalah
+// jumlah tiket aktif yang sudah memakai kursi di luar available_seats.
+func (v *scheduleValidator) Validate(schedule *models.Schedule, soldSeats int) error {
+	result := &utils.ValidationError{}
+
+	if !schedule.ArrivalTime.After(schedule.DepartureTime) {
+		result.Add("arrival_time", models.ViolationArrivalBeforeDeparture, violationMessage(models.ViolationArrivalBeforeDeparture, nil))
+	}
+	if sameStation(schedule.DepartureStation, schedule.ArrivalStation) {
+		result.Add("arrival_station", models.ViolationSameStation, violationMessage(models.ViolationSameStation, nil))
+	}
+	// zona waktu jadwal diambil dari stasiun, jadi stasiun harus terdaftar dulu
+	if _, err := v.stationRepo.FindByName(schedule.DepartureStation); err != nil {
+		result.Add("departure_station", models.ViolationStationNotFound, violationMessage(models.ViolationStationNotFound, nil))
+	}
+	if _, err := v.stationRepo.FindByName(schedule.ArrivalStation); err != nil {
+		result.Add("arrival_station", models.ViolationStationNotFound, violationMessage(models.ViolationStationNotFound, nil))
+	}
+
+	train, err := v.trainRepo.FindByID(schedule.TrainID)
+	if err != nil {
+		result.Add("train_id", models.ViolationTrainNotFound, violationMessage(models.ViolationTrainNotFound, nil))
+		return result.Err()
+	}
+	if schedule.AvailableSeats+soldSeats > train.TotalSeats {
+		message := fmt.Sprintf("available_seats melebihi kapasitas kereta (%d kursi)", train.TotalSeats)
+		if soldSeats > 0 {
+			message = fmt.Sprintf("available_seats ditambah %d tiket aktif melebihi kapasitas kereta (%d kursi)", soldSeats, train.TotalSeats)
+		}
+		result.Add("available_seats", models.ViolationSeatsExceedCapacity, message)
+	}
+
+	if schedule.ArrivalTime.After(schedule.DepartureTime) {
+		unavailable, err := v.unavailabilityRepo.FindByTrain(schedule.TrainID, schedule.DepartureTime, schedule.ArrivalTime)
+		if err != nil {
+			return err
+		}
+		if len(unavailable) > 0 {
+			window := unavailable[0]
+			result.Add("train_id", models.ViolationTrainUnavailable, unavailableMessage(window, utils.Location(schedule.DepartureTimezone)))
+		}
+
+		overlapping, err := v.scheduleRepo.FindOverlapping(schedule.TrainID, schedule.DepartureTime, schedule.ArrivalTime, schedule.ID)
+		if err != nil {
+			return err
+		}
+		if len(overlapping) > 0 {
+			conflict := overlapping[0]
+			result.Add("departure_time", models.ViolationTrainOverlap, violationMessage(models.ViolationTrainOverlap, &conflict.ID))
+		}
+	}
+
+	return result.Err()
+}
+
+func (v *scheduleValidator) Violations() ([]models.ScheduleViolation, error) {
+	violations, err := v.scheduleRepo.FindViolations()
+	if err != nil {
+		return nil, err
+	}
+	for i := range violations {
+		violations[i].Message = violationMessage(violations[i].Rule, violations[i].ConflictingScheduleID)
+	}
+	return violations, nil
+}
+
+func violationMessage(rule string, conflictingID *int) string {
+	switch rule {
+	case models.ViolationTrainNotFound:
+		return "kereta tidak ditemukan"
+	case models.ViolationArrivalBeforeDeparture:
+		return "waktu tiba harus setelah waktu berangkat"
+	case models.ViolationSameStation:
+		return "stasiun asal dan tujuan tidak boleh sama"
+	case models.ViolationStationNotFound:
+		return "stasiun belum terdaftar"
+	case models.ViolationTrainUnavailable:
+		return "kereta sedang dalam perawatan atau tidak beroperasi pada waktu jadwal"
+	case models.ViolationSeatsExceedCapacity:
+		return "available_seats ditambah tiket aktif melebihi kapasitas kereta"
+	case models.ViolationTrainOverlap:
+		if conflictingID != nil {
+			return fmt.Sprintf("kereta sudah dipakai jadwal %d pada waktu yang beririsan", *conflictingID)
+		}
+		return "kereta sudah dipakai jadwal lain pada waktu yang beririsan"
+	}
+	return rule
+}
+
+func unavailableMessage(window models.TrainUnavailability, loc *time.Location) string {
+	reason := "perawatan"
+	if window.Reason == models.UnavailabilityOutOfService {
+		reason = "tidak beroperasi"
+	}
+	return fmt.Sprintf("kereta %s dari %s sampai %s", reason,
+		utils.FormatLocalTime(window.StartsAt.In(loc)), utils.FormatLocalTime(window.EndsAt.In(loc)))
+}
+
+func sameStation(departure, arrival string) bool {
+	return strings.EqualFold(strings.TrimSpace(departure), strings.TrimSpace(arrival))
+}
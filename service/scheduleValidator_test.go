@@ -0,0 +1,161 @@
+package service
+
+import (
+	"database/sql"
+	"errors"
+	"strings"
+	"testing"
+	"tiketsepur/models"
+	"tiketsepur/repository"
+	"tiketsepur/utils"
+	"time"
+)
+
+type validatorStationRepo struct {
+	repository.StationRepository
+	names []string
+}
+
+func (r *validatorStationRepo) FindByName(name string) (*models.Station, error) {
+	for _, station := range r.names {
+		if strings.EqualFold(station, name) {
+			return &models.Station{Name: station, Timezone: "Asia/Jakarta"}, nil
+		}
+	}
+	return nil, sql.ErrNoRows
+}
+
+type validatorTrainRepo struct {
+	repository.TrainRepository
+	trains map[int]*models.Train
+}
+
+func (r *validatorTrainRepo) FindByID(id int) (*models.Train, error) {
+	if train, ok := r.trains[id]; ok {
+		return train, nil
+	}
+	return nil, sql.ErrNoRows
+}
+
+type validatorUnavailabilityRepo struct {
+	repository.TrainUnavailabilityRepository
+	windows []models.TrainUnavailability
+}
+
+func (r *validatorUnavailabilityRepo) FindByTrain(trainID int, from, to time.Time) ([]models.TrainUnavailability, error) {
+	found := []models.TrainUnavailability{}
+	for _, window := range r.windows {
+		if window.TrainID == trainID && window.StartsAt.Before(to) && window.EndsAt.After(from) {
+			found = append(found, window)
+		}
+	}
+	return found, nil
+}
+
+type validatorScheduleRepo struct {
+	repository.ScheduleRepository
+	schedules []models.Schedule
+}
+
+func (r *validatorScheduleRepo) FindOverlapping(trainID int, departure, arrival time.Time, excludeID int) ([]models.Schedule, error) {
+	found := []models.Schedule{}
+	for _, schedule := range r.schedules {
+		if schedule.TrainID == trainID && schedule.ID != excludeID &&
+			schedule.DepartureTime.Before(arrival) && schedule.ArrivalTime.After(departure) {
+			found = append(found, schedule)
+		}
+	}
+	return found, nil
+}
+
+func newTestValidator() ScheduleValidator {
+	return NewScheduleValidator(
+		&validatorScheduleRepo{schedules: []models.Schedule{{ID: 20, TrainID: 1, DepartureTime: wibAt(10, 8, 0), ArrivalTime: wibAt(10, 12, 0)}}},
+		&validatorTrainRepo{trains: map[int]*models.Train{1: {TotalSeats: 400}, 2: {TotalSeats: 200}}},
+		&validatorStationRepo{names: []string{"Gambir", "Bandung"}},
+		&validatorUnavailabilityRepo{windows: []models.TrainUnavailability{
+			{TrainID: 2, Reason: models.UnavailabilityMaintenance, StartsAt: wibAt(11, 0, 0), EndsAt: wibAt(12, 0, 0)},
+		}},
+	)
+}
+
+func TestScheduleValidatorFieldErrors(t *testing.T) {
+	valid := models.Schedule{
+		TrainID:           1,
+		DepartureStation:  "Gambir",
+		ArrivalStation:    "Bandung",
+		DepartureTime:     wibAt(10, 13, 0),
+		ArrivalTime:       wibAt(10, 16, 0),
+		DepartureTimezone: "Asia/Jakarta",
+		AvailableSeats:    400,
+	}
+
+	cases := []struct {
+		name      string
+		change    func(s *models.Schedule)
+		soldSeats int
+		want      []string
+	}{
+		{"valid", func(s *models.Schedule) {}, 0, nil},
+		{"tiba sebelum berangkat", func(s *models.Schedule) { s.ArrivalTime = s.DepartureTime }, 0,
+			[]string{"arrival_time:" + models.ViolationArrivalBeforeDeparture}},
+		{"stasiun sama", func(s *models.Schedule) { s.ArrivalStation = "GAMBIR" }, 0,
+			[]string{"arrival_station:" + models.ViolationSameStation}},
+		{"stasiun belum terdaftar", func(s *models.Schedule) { s.DepartureStation = "Surabaya" }, 0,
+			[]string{"departure_station:" + models.ViolationStationNotFound}},
+		{"kereta tidak ada", func(s *models.Schedule) { s.TrainID = 99 }, 0,
+			[]string{"train_id:" + models.ViolationTrainNotFound}},
+		{"kursi melebihi kapasitas", func(s *models.Schedule) { s.AvailableSeats = 401 }, 0,
+			[]string{"available_seats:" + models.ViolationSeatsExceedCapacity}},
+		{"kursi ditambah tiket aktif melebihi kapasitas", func(s *models.Schedule) { s.AvailableSeats = 390 }, 11,
+			[]string{"available_seats:" + models.ViolationSeatsExceedCapacity}},
+		{"bentrok jadwal lain", func(s *models.Schedule) { s.DepartureTime = wibAt(10, 11, 0) }, 0,
+			[]string{"departure_time:" + models.ViolationTrainOverlap}},
+		{"jadwal itu sendiri tidak dihitung bentrok", func(s *models.Schedule) { s.ID = 20; s.DepartureTime = wibAt(10, 11, 0) }, 0, nil},
+		{"kereta dalam perawatan", func(s *models.Schedule) {
+			s.TrainID = 2
+			s.AvailableSeats = 200
+			s.DepartureTime = wibAt(11, 10, 0)
+			s.ArrivalTime = wibAt(11, 14, 0)
+		}, 0,
+			[]string{"train_id:" + models.ViolationTrainUnavailable}},
+		{"beberapa pelanggaran sekaligus", func(s *models.Schedule) { s.ArrivalStation = "Gambir"; s.AvailableSeats = 500 }, 0,
+			[]string{"arrival_station:" + models.ViolationSameStation, "available_seats:" + models.ViolationSeatsExceedCapacity}},
+	}
+
+	validator := newTestValidator()
+	for _, tc := range cases {
+		schedule := valid
+		tc.change(&schedule)
+
+		err := validator.Validate(&schedule, tc.soldSeats)
+		if tc.want == nil {
+			if err != nil {
+				t.Errorf("%s: Validate = %v, want nil", tc.name, err)
+			}
+			continue
+		}
+
+		var result *utils.ValidationError
+		if !errors.As(err, &result) {
+			t.Errorf("%s: Validate = %v, want *utils.ValidationError", tc.name, err)
+			continue
+		}
+		codes := fieldCodes(result)
+		if strings.Join(codes, ",") != strings.Join(tc.want, ",") {
+			t.Errorf("%s: pelanggaran = %v, want %v", tc.name, codes, tc.want)
+		}
+	}
+}
+
+func TestScheduleValidatorOverlapMessageNamesSchedule(t *testing.T) {
+	schedule := &models.Schedule{TrainID: 1, DepartureStation: "Gambir", ArrivalStation: "Bandung", DepartureTime: wibAt(10, 9, 0), ArrivalTime: wibAt(10, 11, 0), AvailableSeats: 10}
+
+	var result *utils.ValidationError
+	if err := newTestValidator().Validate(schedule, 0); !errors.As(err, &result) {
+		t.Fatalf("Validate = %v", err)
+	}
+	if !strings.Contains(result.Fields[0].Message, "jadwal 20") {
+		t.Errorf("message = %q, seharusnya menyebut jadwal yang bentrok", result.Fields[0].Message)
+	}
+}
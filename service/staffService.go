@@ -0,0 +1,230 @@
+package service
+
+import (
+	"errors"
+	"strings"
+	"tiketsepur/dto"
+	"tiketsepur/models"
+	"tiketsepur/repository"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type StaffService interface {
+	Create(req dto.CreateStaffRequest) (*models.Staff, error)
+	GetAll(query dto.StaffQuery) ([]models.Staff, repository.Page, error)
+	GetByID(id int) (*models.Staff, error)
+	Update(id int, req dto.UpdateStaffRequest) (*models.Staff, error)
+	ReplaceQualifications(id int, req dto.ReplaceQualificationsRequest) (*models.Staff, error)
+	UpcomingDuties(id int) ([]models.CrewAssignment, error)
+	MyDuties(userID int) ([]models.CrewAssignment, error)
+}
+
+type staffService struct {
+	db        *sqlx.DB
+	staffRepo repository.StaffRepository
+	crewRepo  repository.CrewAssignmentRepository
+	userRepo  repository.UserRepository
+}
+
+func NewStaffService(db *sqlx.DB, staffRepo repository.StaffRepository, crewRepo repository.CrewAssignmentRepository, userRepo repository.UserRepository) StaffService {
+	return &staffService{
+		db:        db,
+		staffRepo: staffRepo,
+		crewRepo:  crewRepo,
+		userRepo:  userRepo,
+	}
+}
+
+func (s *staffService) Create(req dto.CreateStaffRequest) (*models.Staff, error) {
+	employeeNumber := strings.TrimSpace(req.EmployeeNumber)
+	if _, err := s.staffRepo.FindByEmployeeNumber(employeeNumber); err == nil {
+		return nil, errors.New("nomor pegawai sudah terdaftar")
+	}
+	if err := s.checkUser(req.UserID, 0); err != nil {
+		return nil, err
+	}
+	qualifications, err := parseQualifications(req.Qualifications)
+	if err != nil {
+		return nil, err
+	}
+
+	staff := &models.Staff{
+		UserID:         req.UserID,
+		EmployeeNumber: employeeNumber,
+		Name:           strings.TrimSpace(req.Name),
+		Phone:          req.Phone,
+		IsActive:       true,
+	}
+
+	tx, err := s.db.Beginx()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	if err := s.staffRepo.Create(staff, tx); err != nil {
+		return nil, err
+	}
+	if err := s.staffRepo.ReplaceQualifications(staff.ID, qualifications, tx); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	for i := range qualifications {
+		qualifications[i].StaffID = staff.ID
+	}
+	staff.Qualifications = qualifications
+	return staff, nil
+}
+
+func (s *staffService) GetAll(query dto.StaffQuery) ([]models.Staff, repository.Page, error) {
+	spec, err := querySpec(query.ListQuery)
+	if err != nil {
+		return nil, repository.Page{}, err
+	}
+	spec.Qualification = query.Qualification
+	return s.staffRepo.FindAll(spec)
+}
+
+func (s *staffService) GetByID(id int) (*models.Staff, error) {
+	staff, err := s.staffRepo.FindByID(id)
+	if err != nil {
+		return nil, errors.New("staf tidak ditemukan")
+	}
+	return staff, nil
+}
+
+// Update menolak menonaktifkan staf yang masih punya tugas mendatang; tugas
+// itu harus dipindahkan ke staf lain lebih dulu.
+func (s *staffService) Update(id int, req dto.UpdateStaffRequest) (*models.Staff, error) {
+	tx, err := s.db.Beginx()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	staff, err := s.staffRepo.FindByIDForUpdate(id, tx)
+	if err != nil {
+		return nil, errors.New("staf tidak ditemukan")
+	}
+
+	if req.UserID != nil {
+		if err := s.checkUser(req.UserID, id); err != nil {
+			return nil, err
+		}
+		staff.UserID = req.UserID
+	}
+	if req.Name != nil {
+		staff.Name = strings.TrimSpace(*req.Name)
+	}
+	if req.Phone != nil {
+		staff.Phone = req.Phone
+	}
+	if req.IsActive != nil {
+		if staff.IsActive && !*req.IsActive {
+			duties, err := s.crewRepo.FindUpcomingByStaff(id, time.Now())
+			if err != nil {
+				return nil, err
+			}
+			if len(duties) > 0 {
+				return nil, errors.New("staf masih punya tugas mendatang; pindahkan tugasnya lebih dulu")
+			}
+		}
+		staff.IsActive = *req.IsActive
+	}
+
+	if err := s.staffRepo.Update(staff, tx); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return staff, nil
+}
+
+// ReplaceQualifications hanya berlaku untuk penugasan baru; tugas yang sudah
+// ada tidak diperiksa ulang.
+func (s *staffService) ReplaceQualifications(id int, req dto.ReplaceQualificationsRequest) (*models.Staff, error) {
+	qualifications, err := parseQualifications(req.Qualifications)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := s.db.Beginx()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	staff, err := s.staffRepo.FindByIDForUpdate(id, tx)
+	if err != nil {
+		return nil, errors.New("staf tidak ditemukan")
+	}
+	if err := s.staffRepo.ReplaceQualifications(id, qualifications, tx); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	for i := range qualifications {
+		qualifications[i].StaffID = id
+	}
+	staff.Qualifications = qualifications
+	return staff, nil
+}
+
+func (s *staffService) UpcomingDuties(id int) ([]models.CrewAssignment, error) {
+	if _, err := s.staffRepo.FindByID(id); err != nil {
+		return nil, errors.New("staf tidak ditemukan")
+	}
+	return s.crewRepo.FindUpcomingByStaff(id, time.Now())
+}
+
+func (s *staffService) MyDuties(userID int) ([]models.CrewAssignment, error) {
+	staff, err := s.staffRepo.FindByUserID(userID)
+	if err != nil {
+		return nil, errors.New("akun ini tidak terhubung dengan data staf")
+	}
+	return s.crewRepo.FindUpcomingByStaff(staff.ID, time.Now())
+}
+
+// checkUser memastikan user ada dan belum terhubung dengan staf lain.
+func (s *staffService) checkUser(userID *int, staffID int) error {
+	if userID == nil {
+		return nil
+	}
+	if _, err := s.userRepo.FindByID(*userID); err != nil {
+		return errors.New("user tidak ditemukan")
+	}
+	if linked, err := s.staffRepo.FindByUserID(*userID); err == nil && linked.ID != staffID {
+		return errors.New("user sudah terhubung dengan staf lain")
+	}
+	return nil
+}
+
+func parseQualifications(requests []dto.StaffQualificationRequest) ([]models.StaffQualification, error) {
+	qualifications := make([]models.StaffQualification, 0, len(requests))
+	seen := make(map[string]bool, len(requests))
+	for _, req := range requests {
+		if seen[req.Qualification] {
+			return nil, errors.New("kualifikasi " + req.Qualification + " diisi lebih dari sekali")
+		}
+		seen[req.Qualification] = true
+
+		qualification := models.StaffQualification{Qualification: req.Qualification}
+		if req.ValidUntil != nil {
+			validUntil, err := time.Parse("2006-01-02", *req.ValidUntil)
+			if err != nil {
+				return nil, errors.New("valid_until harus berformat YYYY-MM-DD")
+			}
+			qualification.ValidUntil = &validUntil
+		}
+		qualifications = append(qualifications, qualification)
+	}
+	return qualifications, nil
+}
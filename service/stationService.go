@@ -0,0 +1,198 @@
+package service
+
+import (
+	"errors"
+	"log"
+	"strings"
+	"sync"
+	"tiketsepur/dto"
+	"tiketsepur/models"
+	"tiketsepur/repository"
+	"tiketsepur/utils"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type StationService interface {
+	Create(req dto.CreateStationRequest) (*models.Station, error)
+	GetAll(query dto.ListQuery) ([]models.Station, repository.Page, error)
+	Update(id int, req dto.UpdateStationRequest) (*models.Station, error)
+	Delete(id int) error
+	Location(name string) *time.Location
+	Localize(schedule *models.Schedule)
+}
+
+// stationTimezoneTTL membatasi umur cache zona waktu stasiun di memori
+// supaya perubahan dari instance lain tetap terbaca.
+const stationTimezoneTTL = time.Minute
+
+type stationService struct {
+	db            *sqlx.DB
+	stationRepo   repository.StationRepository
+	scheduleRepo  repository.ScheduleRepository
+	scheduleCache repository.ScheduleCache
+	outboxService OutboxService
+
+	mu        sync.RWMutex
+	timezones map[string]string
+	loadedAt  time.Time
+}
+
+func NewStationService(db *sqlx.DB, stationRepo repository.StationRepository, scheduleRepo repository.ScheduleRepository, scheduleCache repository.ScheduleCache, outboxService OutboxService) StationService {
+	return &stationService{db: db, stationRepo: stationRepo, scheduleRepo: scheduleRepo, scheduleCache: scheduleCache, outboxService: outboxService}
+}
+
+func (s *stationService) Create(req dto.CreateStationRequest) (*models.Station, error) {
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return nil, errors.New("nama stasiun wajib diisi")
+	}
+	if !validTimezone(req.Timezone) {
+		return nil, errInvalidTimezone
+	}
+	if _, err := s.stationRepo.FindByName(name); err == nil {
+		return nil, errors.New("stasiun sudah terdaftar")
+	}
+
+	station := &models.Station{Name: name, Timezone: req.Timezone}
+	if err := s.stationRepo.Create(station); err != nil {
+		return nil, err
+	}
+	s.invalidateTimezones()
+	return station, nil
+}
+
+func (s *stationService) GetAll(query dto.ListQuery) ([]models.Station, repository.Page, error) {
+	spec, err := querySpec(query)
+	if err != nil {
+		return nil, repository.Page{}, err
+	}
+	return s.stationRepo.FindAll(spec)
+}
+
+// Update mengganti zona waktu stasiun. Waktu jadwal yang tersimpan tidak
+// berubah, hanya jam lokal yang ditampilkan dan tanggal pencariannya, kecuali
+// jadwal dari sebelum migrasi zona waktu yang jam lokal aslinya dibaca ulang
+// di zona waktu baru. Jadwal yang bergeser diumumkan lewat outbox dan
+// bentroknya bisa dilihat di /schedules/violations. Kalender tarif rute
+// stasiun ini diperbarui lewat TTL cache.
+func (s *stationService) Update(id int, req dto.UpdateStationRequest) (*models.Station, error) {
+	station, err := s.stationRepo.FindByID(id)
+	if err != nil {
+		return nil, errors.New("stasiun tidak ditemukan")
+	}
+	if !validTimezone(req.Timezone) {
+		return nil, errInvalidTimezone
+	}
+
+	tx, err := s.db.Beginx()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	if err := s.stationRepo.UpdateTimezone(id, req.Timezone, tx); err != nil {
+		return nil, err
+	}
+
+	relocalized, err := s.scheduleRepo.RelocalizeLegacyTimes(station.Name, req.Timezone, tx)
+	if err != nil {
+		return nil, err
+	}
+	for _, scheduleID := range relocalized {
+		schedule, err := s.scheduleRepo.FindByIDForUpdate(scheduleID, tx)
+		if err != nil {
+			return nil, err
+		}
+		if err := enqueueScheduleChanged(s.outboxService, tx, schedule, utils.ScheduleUpdated, []string{"departure_time", "arrival_time"}); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	station.Timezone = req.Timezone
+	s.invalidateTimezones()
+	s.scheduleCache.InvalidateStation(station.Name)
+	return station, nil
+}
+
+func (s *stationService) Delete(id int) error {
+	station, err := s.stationRepo.FindByID(id)
+	if err != nil {
+		return errors.New("stasiun tidak ditemukan")
+	}
+	used, err := s.stationRepo.IsUsed(station.Name)
+	if err != nil {
+		return err
+	}
+	if used {
+		return errors.New("stasiun masih dipakai jadwal atau template jadwal")
+	}
+	if err := s.stationRepo.Delete(id); err != nil {
+		return err
+	}
+	s.invalidateTimezones()
+	return nil
+}
+
+// Location mengembalikan zona waktu stasiun, atau utils.DefaultTimezone jika
+// stasiun belum terdaftar. Zona waktu dibaca dari cache di memori karena
+// dipanggil untuk setiap jadwal yang dibuat atau di-generate.
+func (s *stationService) Location(name string) *time.Location {
+	timezones := s.timezoneMap()
+	return utils.Location(timezones[strings.ToLower(strings.TrimSpace(name))])
+}
+
+func (s *stationService) timezoneMap() map[string]string {
+	s.mu.RLock()
+	timezones, loadedAt := s.timezones, s.loadedAt
+	s.mu.RUnlock()
+	if timezones != nil && time.Since(loadedAt) < stationTimezoneTTL {
+		return timezones
+	}
+
+	loaded, err := s.stationRepo.FindTimezones()
+	if err != nil {
+		// map lama tetap dipakai daripada semua stasiun jatuh ke zona default
+		log.Printf("gagal memuat zona waktu stasiun: %v", err)
+		return timezones
+	}
+
+	s.mu.Lock()
+	s.timezones = loaded
+	s.loadedAt = time.Now()
+	s.mu.Unlock()
+	return loaded
+}
+
+func (s *stationService) invalidateTimezones() {
+	s.mu.Lock()
+	s.timezones = nil
+	s.mu.Unlock()
+}
+
+// Localize mengisi zona waktu jadwal yang dibuat atau diubah lewat request
+// sehingga response-nya sama dengan hasil baca dari repository.
+func (s *stationService) Localize(schedule *models.Schedule) {
+	departure := s.Location(schedule.DepartureStation)
+	arrival := s.Location(schedule.ArrivalStation)
+	schedule.DepartureTimezone = departure.String()
+	schedule.ArrivalTimezone = arrival.String()
+	schedule.DepartureTime = schedule.DepartureTime.In(departure)
+	schedule.ArrivalTime = schedule.ArrivalTime.In(arrival)
+}
+
+var errInvalidTimezone = errors.New("timezone harus berupa nama zona waktu IANA, misalnya Asia/Jakarta")
+
+// validTimezone menolak "Local" karena hasilnya bergantung pada server.
+func validTimezone(name string) bool {
+	if name == "" || name == "Local" {
+		return false
+	}
+	_, err := utils.LoadTimezone(name)
+	return err == nil
+}
@@ -0,0 +1,57 @@
+package service
+
+import (
+	"errors"
+	"testing"
+	"tiketsepur/repository"
+)
+
+type fakeStationRepo struct {
+	repository.StationRepository
+	timezones map[string]string
+	err       error
+	loads     int
+}
+
+func (r *fakeStationRepo) FindTimezones() (map[string]string, error) {
+	r.loads++
+	if r.err != nil {
+		return nil, r.err
+	}
+	return r.timezones, nil
+}
+
+func TestStationLocationIsCached(t *testing.T) {
+	repo := &fakeStationRepo{timezones: map[string]string{"makassar": "Asia/Makassar"}}
+	s := &stationService{stationRepo: repo}
+
+	if loc := s.Location(" Makassar "); loc.String() != "Asia/Makassar" {
+		t.Fatalf("Location = %s", loc)
+	}
+	if loc := s.Location("Gambir"); loc.String() != "Asia/Jakarta" {
+		t.Fatalf("stasiun yang belum terdaftar seharusnya memakai zona default, didapat %s", loc)
+	}
+	if repo.loads != 1 {
+		t.Fatalf("zona waktu dimuat %d kali, want 1", repo.loads)
+	}
+
+	repo.timezones = map[string]string{"makassar": "Asia/Jayapura"}
+	s.invalidateTimezones()
+	if loc := s.Location("Makassar"); loc.String() != "Asia/Jayapura" || repo.loads != 2 {
+		t.Fatalf("Location = %s setelah invalidasi, loads = %d", loc, repo.loads)
+	}
+}
+
+func TestStationLocationKeepsStaleMapOnError(t *testing.T) {
+	repo := &fakeStationRepo{timezones: map[string]string{"makassar": "Asia/Makassar"}}
+	s := &stationService{stationRepo: repo}
+	s.Location("Makassar")
+
+	// paksa cache kedaluwarsa lalu buat database gagal
+	s.loadedAt = s.loadedAt.Add(-2 * stationTimezoneTTL)
+	repo.err = errors.New("koneksi putus")
+
+	if loc := s.Location("Makassar"); loc.String() != "Asia/Makassar" {
+		t.Fatalf("Location = %s, map lama seharusnya tetap dipakai", loc)
+	}
+}
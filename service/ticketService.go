@@ -4,7 +4,6 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"log"
 	"math/rand"
 	"tiketsepur/dto"
 	"tiketsepur/models"
@@ -19,45 +18,58 @@ type TicketService interface {
 	Create(ctx context.Context, userID int, req dto.CreateTicketRequest) (*models.Ticket, error)
 	GetByID(id int) (*models.TicketWithDetails, error)
 	GetByBookingCode(code string) (*models.TicketWithDetails, error)
-	GetByUserID(userID int) ([]models.TicketWithDetails, error)
-	GetAll() ([]models.TicketWithDetails, error)
-	Cancel(ctx context.Context, id int, userID int, role string) error
+	GetByUserID(userID int, query dto.ListQuery) ([]models.TicketWithDetails, repository.Page, error)
+	GetAll(query dto.ListQuery) ([]models.TicketWithDetails, repository.Page, error)
+	Cancel(ctx context.Context, id int, userID int, role string, scopes []string) error
+	GetAlternatives(ctx context.Context, id int, userID int, role string, scopes []string) ([]models.Schedule, error)
 }
 
 type ticketService struct {
-	db           *sqlx.DB
-	ticketRepo   repository.TicketRepository
-	scheduleRepo repository.ScheduleRepository
-	paymentRepo  repository.PaymentRepository
-	userRepo     repository.UserRepository
-	redis        *utils.RedisClient
-	rabbitmq     *utils.RabbitMQ
+	db            *sqlx.DB
+	ticketRepo    repository.TicketRepository
+	scheduleRepo  repository.ScheduleRepository
+	scheduleCache repository.ScheduleCache
+	paymentRepo   repository.PaymentRepository
+	userRepo      repository.UserRepository
+	rbacService   RBACService
+	outboxService OutboxService
+	liveSchedules LiveScheduleService
+	fareCalendar  FareCalendarService
+	redis         *utils.RedisClient
 }
 
 func NewTicketService(
 	db *sqlx.DB,
 	ticketRepo repository.TicketRepository,
 	scheduleRepo repository.ScheduleRepository,
+	scheduleCache repository.ScheduleCache,
 	userRepo repository.UserRepository,
 	paymentRepo repository.PaymentRepository,
+	rbacService RBACService,
+	outboxService OutboxService,
+	liveSchedules LiveScheduleService,
+	fareCalendar FareCalendarService,
 	redis *utils.RedisClient,
-	rabbitmq *utils.RabbitMQ,
 ) TicketService {
 	return &ticketService{
-		db:           db,
-		ticketRepo:   ticketRepo,
-		scheduleRepo: scheduleRepo,
-		paymentRepo:  paymentRepo,
-		userRepo:     userRepo,
-		redis:        redis,
-		rabbitmq:     rabbitmq,
+		db:            db,
+		ticketRepo:    ticketRepo,
+		scheduleRepo:  scheduleRepo,
+		scheduleCache: scheduleCache,
+		paymentRepo:   paymentRepo,
+		userRepo:      userRepo,
+		rbacService:   rbacService,
+		outboxService: outboxService,
+		liveSchedules: liveSchedules,
+		fareCalendar:  fareCalendar,
+		redis:         redis,
 	}
 }
 
 func (s *ticketService) Create(ctx context.Context, userID int, req dto.CreateTicketRequest) (*models.Ticket, error) {
 	lockKey := fmt.Sprintf("lock:seat:%d:%s", req.ScheduleID, req.SeatNumber)
 	lockValue := fmt.Sprintf("%d-%d", userID, time.Now().Unix())
-	
+
 	locked, err := s.acquireLock(ctx, lockKey, lockValue, 10*time.Second)
 	if err != nil || !locked {
 		return nil, errors.New("kursi sudah dibeli oleh pengguna lain, silahkan coba lagi")
@@ -68,6 +80,9 @@ func (s *ticketService) Create(ctx context.Context, userID int, req dto.CreateTi
 	if err != nil {
 		return nil, errors.New("schedule tidak ditemukan")
 	}
+	if schedule.Status == models.ScheduleStatusCancelled {
+		return nil, errors.New("jadwal sudah dibatalkan")
+	}
 
 	available, err := s.ticketRepo.CheckSeatAvailability(req.ScheduleID, req.SeatNumber)
 	if err != nil {
@@ -98,6 +113,7 @@ func (s *ticketService) Create(ctx context.Context, userID int, req dto.CreateTi
 		Status:            "pending",
 		BookingCode:       bookingCode,
 		TotalPrice:        schedule.Price,
+		Coach:             req.Coach,
 	}
 
 	if err := s.ticketRepo.Create(ticket, tx); err != nil {
@@ -117,17 +133,18 @@ func (s *ticketService) Create(ctx context.Context, userID int, req dto.CreateTi
 		return nil, err
 	}
 
-	if err := tx.Commit(); err != nil {
+	if err := s.enqueueTicketBooked(tx, ticket, payment, schedule); err != nil {
 		return nil, err
 	}
 
-	user, err := s.userRepo.FindByID(ticket.UserID)
-	if err != nil {
-		log.Printf("Warning: tidak bisa mendapatkan user untuk notifikasi: %v", err)
-	} else {
-		go s.sendBookingNotification(user, ticket, schedule)
+	if err := tx.Commit(); err != nil {
+		return nil, err
 	}
 
+	s.scheduleCache.InvalidateSeats(req.ScheduleID)
+	s.liveSchedules.Publish(ctx, req.ScheduleID)
+	s.fareCalendar.Invalidate(ctx, schedule.DepartureStation, schedule.ArrivalStation, schedule.DepartureTime)
+
 	return ticket, nil
 }
 
@@ -139,31 +156,56 @@ func (s *ticketService) GetByBookingCode(code string) (*models.TicketWithDetails
 	return s.ticketRepo.FindByBookingCode(code)
 }
 
-func (s *ticketService) GetByUserID(userID int) ([]models.TicketWithDetails, error) {
-	return s.ticketRepo.FindByUserID(userID)
+// GetByUserID selalu membatasi list ke tiket milik user tersebut, apa pun
+// filter user_id yang dikirim.
+func (s *ticketService) GetByUserID(userID int, query dto.ListQuery) ([]models.TicketWithDetails, repository.Page, error) {
+	spec, err := querySpec(query)
+	if err != nil {
+		return nil, repository.Page{}, err
+	}
+	spec.UserID = userID
+	return s.ticketRepo.FindAll(spec)
 }
 
-func (s *ticketService) GetAll() ([]models.TicketWithDetails, error) {
-	return s.ticketRepo.FindAll()
+func (s *ticketService) GetAll(query dto.ListQuery) ([]models.TicketWithDetails, repository.Page, error) {
+	spec, err := querySpec(query)
+	if err != nil {
+		return nil, repository.Page{}, err
+	}
+	return s.ticketRepo.FindAll(spec)
 }
 
-func (s *ticketService) Cancel(ctx context.Context, id int, userID int, role string) error {
+// Cancel membatalkan tiket milik sendiri, atau tiket user lain jika role dan
+// scope api key pemanggil punya tickets:refund. Hanya tiket pending atau
+// confirmed yang bisa dibatalkan. Status diubah dengan TransitionStatus di
+// dalam transaksi, jadi jika sweeper expiry lebih dulu mengubah tiket, kursi
+// tidak dikembalikan dua kali.
+func (s *ticketService) Cancel(ctx context.Context, id int, userID int, role string, scopes []string) error {
 	ticket, err := s.ticketRepo.FindByID(id)
 	if err != nil {
 		return errors.New("tiket tidak ditemukan")
 	}
 
-	if role != "admin" && ticket.UserID != userID {
-		return errors.New("tidak ada wewenang untuk membatalkan tiket ini")
+	if ticket.UserID != userID {
+		allowed, err := s.rbacService.Authorize(ctx, role, scopes, "tickets:refund")
+		if err != nil {
+			return err
+		}
+		if !allowed {
+			return errors.New("tidak ada wewenang untuk membatalkan tiket ini")
+		}
 	}
 
 	if ticket.Status == "cancelled" {
 		return errors.New("tiket sudah dibatalkan")
 	}
+	if ticket.Status != "pending" && ticket.Status != "confirmed" {
+		return fmt.Errorf("tiket berstatus %s tidak bisa dibatalkan", ticket.Status)
+	}
 
 	lockKey := fmt.Sprintf("lock:cancel:%d", id)
 	lockValue := fmt.Sprintf("%d-%d", userID, time.Now().Unix())
-	
+
 	locked, err := s.acquireLock(ctx, lockKey, lockValue, 10*time.Second)
 	if err != nil || !locked {
 		return errors.New("pembatalan sedang diproses, silakan coba lagi")
@@ -176,7 +218,15 @@ func (s *ticketService) Cancel(ctx context.Context, id int, userID int, role str
 	}
 	defer tx.Rollback()
 
-	if err := s.ticketRepo.UpdateStatus(id, "cancelled", tx); err != nil {
+	changed, err := s.ticketRepo.TransitionStatus(id, ticket.Status, "cancelled", tx)
+	if err != nil {
+		return err
+	}
+	if !changed {
+		return errors.New("status tiket sudah berubah, silakan muat ulang tiket")
+	}
+
+	if err := s.paymentRepo.CancelPendingByTicketID(id, tx); err != nil {
 		return err
 	}
 
@@ -184,34 +234,61 @@ func (s *ticketService) Cancel(ctx context.Context, id int, userID int, role str
 		return err
 	}
 
+	cancelled := utils.TicketCancelledEvent{
+		TicketID:    ticket.ID,
+		BookingCode: ticket.BookingCode,
+		UserID:      ticket.UserID,
+		ScheduleID:  ticket.ScheduleID,
+		TrainName:   ticket.TrainName,
+		SeatNumber:  ticket.SeatNumber,
+		CancelledBy: userID,
+	}
+	if err := enqueueEvent(s.outboxService, tx, utils.EventTicketCancelled, "ticket", ticket.BookingCode, cancelled); err != nil {
+		return err
+	}
+
 	if err := tx.Commit(); err != nil {
 		return err
 	}
 
-	user, err := s.userRepo.FindByID(ticket.UserID)
-if err == nil {
-    go func() {
-        notification := utils.NotificationMessage{
-            Type:        "cancellation",
-            Email:       user.Email,
-            BookingCode: ticket.BookingCode,
-            TrainName:   ticket.TrainName,
-        }
-        if err := s.rabbitmq.PublishNotification(notification); err != nil {
-            log.Printf("gagal untuk mempublish pembatalan: %v", err)
-        }
-    }()
-}
+	// jadwal yang sebelumnya penuh bisa kembali muncul di hasil pencarian
+	s.scheduleCache.InvalidateSchedules(ticket.ScheduleID)
+	s.liveSchedules.Publish(ctx, ticket.ScheduleID)
+	s.fareCalendar.Invalidate(ctx, ticket.DepartureStation, ticket.ArrivalStation, ticket.DepartureTime)
 
 	return nil
 }
 
+// GetAlternatives menawarkan jadwal pengganti dengan rute yang sama untuk
+// pemesanan ulang setelah jadwal tiket dibatalkan.
+func (s *ticketService) GetAlternatives(ctx context.Context, id int, userID int, role string, scopes []string) ([]models.Schedule, error) {
+	ticket, err := s.ticketRepo.FindByID(id)
+	if err != nil {
+		return nil, errors.New("tiket tidak ditemukan")
+	}
 
+	if ticket.UserID != userID {
+		allowed, err := s.rbacService.Authorize(ctx, role, scopes, "tickets:read")
+		if err != nil {
+			return nil, err
+		}
+		if !allowed {
+			return nil, errors.New("tidak ada wewenang untuk melihat tiket ini")
+		}
+	}
+
+	schedule, err := s.scheduleRepo.FindByID(ticket.ScheduleID)
+	if err != nil {
+		return nil, errors.New("schedule tidak ditemukan")
+	}
+
+	return s.scheduleRepo.FindAlternatives(schedule, 10)
+}
 
 func (s *ticketService) acquireLock(ctx context.Context, key, value string, expiry time.Duration) (bool, error) {
 	result, err := s.redis.Get(ctx, key)
 	if err == nil && result != "" {
-		return false, nil 
+		return false, nil
 	}
 
 	err = s.redis.Set(ctx, key, value, expiry)
@@ -237,42 +314,42 @@ func (s *ticketService) releaseLock(ctx context.Context, key, value string) erro
 
 func (s *ticketService) generateBookingCode() string {
 	const charset = "ABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
-	
+
 	code := make([]byte, 8)
 	for i := range code {
 		code[i] = charset[rand.Intn(len(charset))]
 	}
-	
+
 	return "TRN" + string(code)
 }
 
 func (s *ticketService) generatePaymentCode() string {
 	const charset = "ABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
-	
+
 	code := make([]byte, 12)
 	for i := range code {
 		code[i] = charset[rand.Intn(len(charset))]
 	}
-	
+
 	return "PAY" + string(code)
 }
 
-func (s *ticketService) sendBookingNotification(user *models.User,ticket *models.Ticket, schedule *models.Schedule) {
-	notification := utils.NotificationMessage{
-		Type:        "booking",
-		Email:       user.Email,
-		BookingCode: ticket.BookingCode,
-		TrainName:   schedule.TrainName,
-		Departure:   schedule.DepartureStation,
-		Arrival:     schedule.ArrivalStation,
-		SeatNumber:  ticket.SeatNumber,
-		TotalPrice:  ticket.TotalPrice,
+func (s *ticketService) enqueueTicketBooked(tx *sqlx.Tx, ticket *models.Ticket, payment *models.Payment, schedule *models.Schedule) error {
+	booked := utils.TicketBookedEvent{
+		TicketID:         ticket.ID,
+		BookingCode:      ticket.BookingCode,
+		UserID:           ticket.UserID,
+		ScheduleID:       ticket.ScheduleID,
+		TrainName:        schedule.TrainName,
+		DepartureStation: schedule.DepartureStation,
+		ArrivalStation:   schedule.ArrivalStation,
+		DepartureTime:    schedule.DepartureTime,
+		SeatNumber:       ticket.SeatNumber,
+		PassengerName:    ticket.PassengerName,
+		TotalPrice:       ticket.TotalPrice,
+		PaymentCode:      payment.PaymentCode,
+		PaymentMethod:    payment.PaymentMethod,
 	}
 
-if err := s.rabbitmq.PublishNotification(notification); err != nil {
-	} else {
-		log.Printf("[BOOKING] Sending notification to %s", user.Email)
-		log.Printf("Booking Code: %s", ticket.BookingCode)
-		log.Printf("Train: %s", schedule.TrainName)
-	}
-}
\ No newline at end of file
+	return enqueueEvent(s.outboxService, tx, utils.EventTicketBooked, "ticket", ticket.BookingCode, booked)
+}
@@ -0,0 +1,193 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"tiketsepur/dto"
+	"tiketsepur/models"
+	"tiketsepur/repository"
+	"tiketsepur/utils"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// batas rentang laporan utilisasi, sama dengan batas generate template
+const maxUtilizationDays = 366
+
+type TrainAvailabilityService interface {
+	Create(trainID int, req dto.CreateTrainUnavailabilityRequest, createdBy int) (*dto.TrainUnavailabilityResponse, error)
+	GetByTrain(trainID int, query dto.TrainAvailabilityQuery) ([]models.TrainUnavailability, error)
+	Delete(trainID, id int) error
+	Utilization(req dto.TrainUtilizationRequest) (*dto.TrainUtilizationResponse, error)
+}
+
+type trainAvailabilityService struct {
+	db                 *sqlx.DB
+	unavailabilityRepo repository.TrainUnavailabilityRepository
+	trainRepo          repository.TrainRepository
+	scheduleRepo       repository.ScheduleRepository
+}
+
+func NewTrainAvailabilityService(db *sqlx.DB, unavailabilityRepo repository.TrainUnavailabilityRepository, trainRepo repository.TrainRepository, scheduleRepo repository.ScheduleRepository) TrainAvailabilityService {
+	return &trainAvailabilityService{
+		db:                 db,
+		unavailabilityRepo: unavailabilityRepo,
+		trainRepo:          trainRepo,
+		scheduleRepo:       scheduleRepo,
+	}
+}
+
+// Create mencatat rentang kereta tidak bisa dijadwalkan. Kerusakan mendadak
+// tetap harus bisa dicatat, jadi jadwal yang sudah ada di rentang itu tidak
+// menghalangi; jadwal tersebut dikembalikan sebagai affected_schedules dan
+// muncul di laporan pelanggaran jadwal. Baris kereta dikunci seperti saat
+// membuat jadwal, sehingga jadwal yang disimpan bersamaan tidak lolos dari
+// pemeriksaan ketidaktersediaan dan tidak hilang dari affected_schedules.
+func (s *trainAvailabilityService) Create(trainID int, req dto.CreateTrainUnavailabilityRequest, createdBy int) (*dto.TrainUnavailabilityResponse, error) {
+	if !req.EndsAt.After(req.StartsAt) {
+		return nil, errors.New("ends_at harus setelah starts_at")
+	}
+
+	tx, err := s.db.Beginx()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	if _, err := s.trainRepo.FindByIDForUpdate(trainID, tx); err != nil {
+		return nil, errors.New("kereta tidak ditemukan")
+	}
+
+	unavailability := &models.TrainUnavailability{
+		TrainID:   trainID,
+		Reason:    req.Reason,
+		StartsAt:  req.StartsAt,
+		EndsAt:    req.EndsAt,
+		Notes:     req.Notes,
+		CreatedBy: &createdBy,
+	}
+	if err := s.unavailabilityRepo.Create(unavailability, tx); err != nil {
+		return nil, err
+	}
+
+	affected, err := s.scheduleRepo.FindOverlappingInTx(trainID, req.StartsAt, req.EndsAt, 0, tx)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return &dto.TrainUnavailabilityResponse{Unavailability: *unavailability, AffectedSchedules: affected}, nil
+}
+
+// GetByTrain membaca from dan to sebagai tanggal di utils.DefaultTimezone.
+// Tanpa from, daftar dimulai dari sekarang sehingga hanya rentang yang belum
+// selesai yang muncul.
+func (s *trainAvailabilityService) GetByTrain(trainID int, query dto.TrainAvailabilityQuery) ([]models.TrainUnavailability, error) {
+	if _, err := s.trainRepo.FindByID(trainID); err != nil {
+		return nil, errors.New("kereta tidak ditemukan")
+	}
+
+	loc := utils.Location("")
+	from := time.Now()
+	to := time.Date(9999, 1, 1, 0, 0, 0, 0, time.UTC)
+	if query.From != "" {
+		from, _ = time.ParseInLocation("2006-01-02", query.From, loc)
+	}
+	if query.To != "" {
+		day, _ := time.ParseInLocation("2006-01-02", query.To, loc)
+		to = day.AddDate(0, 0, 1)
+	}
+	if !to.After(from) {
+		return nil, errors.New("to harus sama dengan atau setelah from")
+	}
+	return s.unavailabilityRepo.FindByTrain(trainID, from, to)
+}
+
+func (s *trainAvailabilityService) Delete(trainID, id int) error {
+	unavailability, err := s.unavailabilityRepo.FindByID(id)
+	if err != nil || unavailability.TrainID != trainID {
+		return errors.New("data ketidaktersediaan kereta tidak ditemukan")
+	}
+	return s.unavailabilityRepo.Delete(id)
+}
+
+// Utilization menghitung pemakaian setiap kereta dari tanggal from sampai to
+// (inklusif) di utils.DefaultTimezone. Menit tersedia adalah panjang rentang
+// dikurangi gabungan rentang ketidaktersediaan, dan utilisasi adalah menit
+// terjadwal dibagi menit tersedia.
+func (s *trainAvailabilityService) Utilization(req dto.TrainUtilizationRequest) (*dto.TrainUtilizationResponse, error) {
+	loc := utils.Location("")
+	from, _ := time.ParseInLocation("2006-01-02", req.From, loc)
+	to, _ := time.ParseInLocation("2006-01-02", req.To, loc)
+	if to.Before(from) {
+		return nil, errors.New("to harus sama dengan atau setelah from")
+	}
+	end := to.AddDate(0, 0, 1)
+	if days := int(end.Sub(from).Hours() / 24); days > maxUtilizationDays {
+		return nil, fmt.Errorf("rentang utilisasi maksimal %d hari", maxUtilizationDays)
+	}
+
+	trains, err := s.trainRepo.Utilization(from, end)
+	if err != nil {
+		return nil, err
+	}
+	windows, err := s.unavailabilityRepo.FindInRange(from, end)
+	if err != nil {
+		return nil, err
+	}
+	byTrain := make(map[int][]models.TrainUnavailability)
+	for _, window := range windows {
+		byTrain[window.TrainID] = append(byTrain[window.TrainID], window)
+	}
+
+	periodMinutes := int(end.Sub(from).Minutes())
+	for i := range trains {
+		train := &trains[i]
+		train.UnavailableMinutes = unavailableMinutes(byTrain[train.TrainID], from, end)
+		train.AvailableMinutes = periodMinutes - train.UnavailableMinutes
+		if train.AvailableMinutes > 0 {
+			percent := float64(train.ScheduledMinutes) / float64(train.AvailableMinutes) * 100
+			train.UtilizationPercent = math.Round(percent*10) / 10
+		}
+	}
+
+	return &dto.TrainUtilizationResponse{
+		From:     req.From,
+		To:       req.To,
+		Timezone: loc.String(),
+		Trains:   trains,
+	}, nil
+}
+
+// unavailableMinutes menjumlahkan rentang yang dipotong ke from-to. Rentang
+// yang saling beririsan digabung dulu supaya tidak terhitung dua kali.
+func unavailableMinutes(windows []models.TrainUnavailability, from, to time.Time) int {
+	sort.Slice(windows, func(i, j int) bool { return windows[i].StartsAt.Before(windows[j].StartsAt) })
+
+	var total time.Duration
+	var start, end time.Time
+	for i, window := range windows {
+		windowStart, windowEnd := window.StartsAt, window.EndsAt
+		if windowStart.Before(from) {
+			windowStart = from
+		}
+		if windowEnd.After(to) {
+			windowEnd = to
+		}
+		if i > 0 && !windowStart.After(end) {
+			if windowEnd.After(end) {
+				end = windowEnd
+			}
+			continue
+		}
+		total += end.Sub(start)
+		start, end = windowStart, windowEnd
+	}
+	total += end.Sub(start)
+	return int(total.Minutes())
+}
@@ -0,0 +1,35 @@
+package service
+
+import (
+	"testing"
+	"tiketsepur/models"
+)
+
+func TestUnavailableMinutes(t *testing.T) {
+	window := func(startDay, startHour, endDay, endHour int) models.TrainUnavailability {
+		return models.TrainUnavailability{StartsAt: wibAt(startDay, startHour, 0), EndsAt: wibAt(endDay, endHour, 0)}
+	}
+	from, to := wibAt(10, 0, 0), wibAt(12, 0, 0)
+
+	cases := []struct {
+		name    string
+		windows []models.TrainUnavailability
+		want    int
+	}{
+		{"tanpa rentang", nil, 0},
+		{"satu rentang", []models.TrainUnavailability{window(10, 8, 10, 12)}, 240},
+		{"dipotong ke from dan to", []models.TrainUnavailability{window(9, 20, 10, 2), window(11, 22, 12, 6)}, 240},
+		{"beririsan digabung", []models.TrainUnavailability{window(10, 8, 10, 12), window(10, 10, 10, 14)}, 360},
+		{"di dalam rentang lain", []models.TrainUnavailability{window(10, 8, 10, 20), window(10, 10, 10, 12)}, 720},
+		{"bersambung", []models.TrainUnavailability{window(10, 8, 10, 10), window(10, 10, 10, 12)}, 240},
+		{"terpisah", []models.TrainUnavailability{window(10, 8, 10, 10), window(11, 8, 11, 10)}, 240},
+		// urutan masukan tidak berpengaruh
+		{"tidak berurutan", []models.TrainUnavailability{window(11, 8, 11, 10), window(10, 8, 10, 12), window(10, 11, 10, 13)}, 420},
+		{"menutupi seluruh periode", []models.TrainUnavailability{window(1, 0, 30, 0)}, 2880},
+	}
+	for _, tc := range cases {
+		if got := unavailableMinutes(tc.windows, from, to); got != tc.want {
+			t.Errorf("%s: unavailableMinutes = %d, want %d", tc.name, got, tc.want)
+		}
+	}
+}
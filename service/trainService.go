@@ -2,25 +2,42 @@ package service
 
 import (
 	"errors"
+	"fmt"
+	"strings"
 	"tiketsepur/dto"
 	"tiketsepur/models"
 	"tiketsepur/repository"
+	"tiketsepur/utils"
+
+	"github.com/jmoiron/sqlx"
 )
 
 type TrainService interface {
 	Create(req dto.CreateTrainRequest) (*models.Train, error)
 	GetByID(id int) (*models.Train, error)
-	GetAll() ([]models.Train, error)
+	GetAll(query dto.ListQuery) ([]models.Train, repository.Page, error)
 	Update(id int, req dto.UpdateTrainRequest) (*models.Train, error)
 	Delete(id int) error
+	GetComposition(id int) ([]models.TrainCar, error)
+	ReplaceComposition(id int, req dto.ReplaceCompositionRequest) ([]models.TrainCar, error)
 }
 
 type trainService struct {
-	trainRepo repository.TrainRepository
+	db            *sqlx.DB
+	trainRepo     repository.TrainRepository
+	carRepo       repository.TrainCarRepository
+	scheduleRepo  repository.ScheduleRepository
+	scheduleCache repository.ScheduleCache
 }
 
-func NewTrainService(trainRepo repository.TrainRepository) TrainService {
-	return &trainService{trainRepo: trainRepo}
+func NewTrainService(db *sqlx.DB, trainRepo repository.TrainRepository, carRepo repository.TrainCarRepository, scheduleRepo repository.ScheduleRepository, scheduleCache repository.ScheduleCache) TrainService {
+	return &trainService{
+		db:            db,
+		trainRepo:     trainRepo,
+		carRepo:       carRepo,
+		scheduleRepo:  scheduleRepo,
+		scheduleCache: scheduleCache,
+	}
 }
 
 func (s *trainService) Create(req dto.CreateTrainRequest) (*models.Train, error) {
@@ -42,8 +59,12 @@ func (s *trainService) GetByID(id int) (*models.Train, error) {
 	return s.trainRepo.FindByID(id)
 }
 
-func (s *trainService) GetAll() ([]models.Train, error) {
-	return s.trainRepo.FindAll()
+func (s *trainService) GetAll(query dto.ListQuery) ([]models.Train, repository.Page, error) {
+	spec, err := querySpec(query)
+	if err != nil {
+		return nil, repository.Page{}, err
+	}
+	return s.trainRepo.FindAll(spec)
 }
 
 func (s *trainService) Update(id int, req dto.UpdateTrainRequest) (*models.Train, error) {
@@ -64,13 +85,21 @@ func (s *trainService) Update(id int, req dto.UpdateTrainRequest) (*models.Train
 		train.TrainType = *req.TrainType
 	}
 
-	if req.TotalSeats != nil {
+	if req.TotalSeats != nil && *req.TotalSeats != train.TotalSeats {
+		cars, err := s.carRepo.FindByTrain(id)
+		if err != nil {
+			return nil, err
+		}
+		if len(cars) > 0 {
+			return nil, errors.New("total_seats mengikuti komposisi rangkaian; ubah lewat komposisi kereta")
+		}
 		train.TotalSeats = *req.TotalSeats
 	}
 
 	if err := s.trainRepo.Update(id, train); err != nil {
 		return nil, err
 	}
+	s.scheduleCache.InvalidateTrain(id)
 
 	return train, nil
 }
@@ -81,5 +110,94 @@ func (s *trainService) Delete(id int) error {
 		return errors.New("kereta tidak ditemukan")
 	}
 
-	return s.trainRepo.Delete(id)
-}
\ No newline at end of file
+	if err := s.trainRepo.Delete(id); err != nil {
+		return err
+	}
+	s.scheduleCache.InvalidateTrain(id)
+	return nil
+}
+
+func (s *trainService) GetComposition(id int) ([]models.TrainCar, error) {
+	if _, err := s.trainRepo.FindByID(id); err != nil {
+		return nil, errors.New("kereta tidak ditemukan")
+	}
+	return s.carRepo.FindByTrain(id)
+}
+
+// ReplaceComposition mengganti seluruh rangkaian kereta dan menyamakan
+// total_seats dengan jumlah kursi kereta penumpang. Kapasitas tidak bisa
+// dikurangi di bawah kursi yang sudah dialokasikan jadwal yang belum selesai;
+// available_seats jadwal tersebut harus dikurangi lebih dulu.
+func (s *trainService) ReplaceComposition(id int, req dto.ReplaceCompositionRequest) ([]models.TrainCar, error) {
+	result := &utils.ValidationError{}
+	cars := make([]models.TrainCar, 0, len(req.Cars))
+	codes := make(map[string]bool, len(req.Cars))
+	totalSeats := 0
+	for i, car := range req.Cars {
+		field := fmt.Sprintf("cars[%d]", i)
+		code := strings.TrimSpace(car.CarCode)
+		if codes[strings.ToLower(code)] {
+			result.Add(field+".car_code", "duplicate_car_code", fmt.Sprintf("car_code %s dipakai lebih dari sekali", code))
+		}
+		codes[strings.ToLower(code)] = true
+
+		if car.CarType == models.CarTypePassenger && car.Seats < 1 {
+			result.Add(field+".seats", "invalid_seats", "kereta penumpang harus punya kursi")
+		}
+		if car.CarType != models.CarTypePassenger && car.Seats != 0 {
+			result.Add(field+".seats", "invalid_seats", "hanya kereta penumpang yang punya kursi")
+		}
+		totalSeats += car.Seats
+
+		cars = append(cars, models.TrainCar{
+			Position: i + 1,
+			CarCode:  code,
+			CarType:  car.CarType,
+			CarClass: car.CarClass,
+			Seats:    car.Seats,
+		})
+	}
+	if totalSeats == 0 {
+		result.Add("cars", "invalid_seats", "rangkaian harus punya minimal satu kereta penumpang")
+	}
+	if err := result.Err(); err != nil {
+		return nil, err
+	}
+
+	tx, err := s.db.Beginx()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	train, err := s.trainRepo.FindByIDForUpdate(id, tx)
+	if err != nil {
+		return nil, errors.New("kereta tidak ditemukan")
+	}
+
+	if totalSeats < train.TotalSeats {
+		exceeding, err := s.scheduleRepo.FindExceedingCapacity(id, totalSeats, tx)
+		if err != nil {
+			return nil, err
+		}
+		for _, schedule := range exceeding {
+			result.Add("cars", models.ViolationSeatsExceedCapacity, fmt.Sprintf(
+				"jadwal %d sudah memakai lebih dari %d kursi; kurangi available_seats jadwal itu lebih dulu", schedule.ID, totalSeats))
+		}
+		if err := result.Err(); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.carRepo.Replace(id, cars, tx); err != nil {
+		return nil, err
+	}
+	if err := s.trainRepo.UpdateTotalSeats(id, totalSeats, tx); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return cars, nil
+}
@@ -10,27 +10,39 @@ import (
 )
 
 type UserService interface {
-	Create(req dto.CreateUserRequest) (*models.User, error)
+	Create(req dto.CreateUserRequest, role string) (*models.User, error)
 	GetByID(id int) (*models.User, error)
-	GetAll() ([]models.User, error)
+	GetAll(query dto.ListQuery) ([]models.User, repository.Page, error)
 	Update(id int, req dto.UpdateUserRequest) (*models.User, error)
 	Delete(id int) error
+	UpdateLanguage(id int, language string) (*models.User, error)
 }
 
 type userService struct {
 	userRepo repository.UserRepository
+	roleRepo repository.RoleRepository
 }
 
-func NewUserService(userRepo repository.UserRepository) UserService {
-	return &userService{userRepo: userRepo}
+func NewUserService(userRepo repository.UserRepository, roleRepo repository.RoleRepository) UserService {
+	return &userService{
+		userRepo: userRepo,
+		roleRepo: roleRepo,
+	}
 }
 
-func (s *userService) Create(req dto.CreateUserRequest) (*models.User, error) {
+// Create membuat akun dengan role yang ditentukan pemanggil, bukan dari
+// request; role user lain hanya bisa diganti lewat PUT /users/:id/role yang
+// butuh permission roles:manage.
+func (s *userService) Create(req dto.CreateUserRequest, role string) (*models.User, error) {
 	existingUser, _ := s.userRepo.FindByEmail(req.Email)
 	if existingUser != nil {
 		return nil, errors.New("email sudah digunakan")
 	}
 
+	if _, err := s.roleRepo.FindByName(role); err != nil {
+		return nil, errors.New("role tidak ditemukan")
+	}
+
 	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
 	if err != nil {
 		return nil, err
@@ -41,7 +53,8 @@ func (s *userService) Create(req dto.CreateUserRequest) (*models.User, error) {
 		Password: string(hashedPassword),
 		FullName: req.FullName,
 		Phone:    req.Phone,
-		Role:     req.Role,
+		Role:     role,
+		Language: req.Language,
 	}
 
 	if err := s.userRepo.Create(user); err != nil {
@@ -55,8 +68,12 @@ func (s *userService) GetByID(id int) (*models.User, error) {
 	return s.userRepo.FindByID(id)
 }
 
-func (s *userService) GetAll() ([]models.User, error) {
-	return s.userRepo.FindAll()
+func (s *userService) GetAll(query dto.ListQuery) ([]models.User, repository.Page, error) {
+	spec, err := querySpec(query)
+	if err != nil {
+		return nil, repository.Page{}, err
+	}
+	return s.userRepo.FindAll(spec)
 }
 
 func (s *userService) Update(id int, req dto.UpdateUserRequest) (*models.User, error) {
@@ -83,8 +100,8 @@ func (s *userService) Update(id int, req dto.UpdateUserRequest) (*models.User, e
 		user.Phone = *req.Phone
 	}
 
-	if req.Role != nil {
-		user.Role = *req.Role
+	if req.Language != nil {
+		user.Language = *req.Language
 	}
 
 	if err := s.userRepo.Update(id, user); err != nil {
@@ -101,4 +118,18 @@ func (s *userService) Delete(id int) error {
 	}
 
 	return s.userRepo.Delete(id)
-}
\ No newline at end of file
+}
+
+func (s *userService) UpdateLanguage(id int, language string) (*models.User, error) {
+	user, err := s.userRepo.FindByID(id)
+	if err != nil {
+		return nil, errors.New("user tidak ditemukan")
+	}
+
+	user.Language = language
+	if err := s.userRepo.Update(id, user); err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
@@ -0,0 +1,414 @@
+package utils
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	EventTicketBooked      = "ticket.booked"
+	EventTicketCancelled   = "ticket.cancelled"
+	EventTicketExpired     = "ticket.expired"
+	EventTicketReminder    = "ticket.departure_reminder"
+	EventPaymentConfirmed  = "payment.confirmed"
+	EventScheduleChanged   = "schedule.changed"
+	EventScheduleDisrupted = "schedule.disrupted"
+	EventTicketDisrupted   = "ticket.disrupted"
+	EventUserLockedOut     = "user.locked_out"
+
+	DefaultEventsExchange = "tiketsepur.events"
+)
+
+// DomainEvent adalah envelope untuk setiap event yang dipublikasikan ke topic
+// exchange. Perubahan yang tidak kompatibel pada Data dilakukan dengan menaikkan
+// Version, sehingga consumer lama tetap menerima versi yang mereka bind.
+type DomainEvent struct {
+	ID            string          `json:"id"`
+	Type          string          `json:"type"`
+	Version       int             `json:"version"`
+	OccurredAt    time.Time       `json:"occurred_at"`
+	AggregateType string          `json:"aggregate_type"`
+	AggregateID   string          `json:"aggregate_id"`
+	Data          json.RawMessage `json:"data" swaggertype:"object"`
+}
+
+// RoutingKey berbentuk <type>.v<version>, misalnya ticket.booked.v1.
+func (e DomainEvent) RoutingKey() string {
+	return fmt.Sprintf("%s.v%d", e.Type, e.Version)
+}
+
+func NewDomainEvent(eventType string, version int, aggregateType, aggregateID string, data interface{}) (DomainEvent, error) {
+	id, err := RandomURLString(16)
+	if err != nil {
+		return DomainEvent{}, err
+	}
+	body, err := json.Marshal(data)
+	if err != nil {
+		return DomainEvent{}, err
+	}
+	return DomainEvent{
+		ID:            id,
+		Type:          eventType,
+		Version:       version,
+		OccurredAt:    time.Now().UTC(),
+		AggregateType: aggregateType,
+		AggregateID:   aggregateID,
+		Data:          body,
+	}, nil
+}
+
+type TicketBookedEvent struct {
+	TicketID         int       `json:"ticket_id"`
+	BookingCode      string    `json:"booking_code"`
+	UserID           int       `json:"user_id"`
+	ScheduleID       int       `json:"schedule_id"`
+	TrainName        string    `json:"train_name"`
+	DepartureStation string    `json:"departure_station"`
+	ArrivalStation   string    `json:"arrival_station"`
+	DepartureTime    time.Time `json:"departure_time"`
+	SeatNumber       string    `json:"seat_number"`
+	PassengerName    string    `json:"passenger_name"`
+	TotalPrice       float64   `json:"total_price"`
+	PaymentCode      string    `json:"payment_code"`
+	PaymentMethod    string    `json:"payment_method"`
+}
+
+type TicketCancelledEvent struct {
+	TicketID    int    `json:"ticket_id"`
+	BookingCode string `json:"booking_code"`
+	UserID      int    `json:"user_id"`
+	ScheduleID  int    `json:"schedule_id"`
+	TrainName   string `json:"train_name"`
+	SeatNumber  string `json:"seat_number"`
+	CancelledBy int    `json:"cancelled_by"`
+}
+
+type TicketExpiredEvent struct {
+	TicketID         int     `json:"ticket_id"`
+	BookingCode      string  `json:"booking_code"`
+	UserID           int     `json:"user_id"`
+	ScheduleID       int     `json:"schedule_id"`
+	TrainName        string  `json:"train_name"`
+	DepartureStation string  `json:"departure_station"`
+	ArrivalStation   string  `json:"arrival_station"`
+	SeatNumber       string  `json:"seat_number"`
+	TotalPrice       float64 `json:"total_price"`
+}
+
+// TicketDepartureReminderEvent dikirim sekali untuk setiap offset pengingat
+// sebelum keberangkatan tiket yang sudah dibayar.
+type TicketDepartureReminderEvent struct {
+	TicketID         int       `json:"ticket_id"`
+	BookingCode      string    `json:"booking_code"`
+	UserID           int       `json:"user_id"`
+	ScheduleID       int       `json:"schedule_id"`
+	TrainName        string    `json:"train_name"`
+	DepartureStation string    `json:"departure_station"`
+	ArrivalStation   string    `json:"arrival_station"`
+	DepartureTime    time.Time `json:"departure_time"`
+	Platform         *string   `json:"platform"`
+	Coach            *string   `json:"coach"`
+	SeatNumber       string    `json:"seat_number"`
+	PassengerName    string    `json:"passenger_name"`
+	OffsetMinutes    int       `json:"offset_minutes"`
+}
+
+type PaymentConfirmedEvent struct {
+	PaymentCode      string    `json:"payment_code"`
+	PaymentMethod    string    `json:"payment_method"`
+	Amount           float64   `json:"amount"`
+	PaidAt           time.Time `json:"paid_at"`
+	TicketID         int       `json:"ticket_id"`
+	BookingCode      string    `json:"booking_code"`
+	UserID           int       `json:"user_id"`
+	ScheduleID       int       `json:"schedule_id"`
+	TrainName        string    `json:"train_name"`
+	DepartureStation string    `json:"departure_station"`
+	ArrivalStation   string    `json:"arrival_station"`
+	DepartureTime    time.Time `json:"departure_time"`
+	SeatNumber       string    `json:"seat_number"`
+}
+
+const (
+	ScheduleCreated = "created"
+	ScheduleUpdated = "updated"
+	ScheduleDeleted = "deleted"
+)
+
+type ScheduleChangedEvent struct {
+	ScheduleID       int       `json:"schedule_id"`
+	Change           string    `json:"change"`
+	ChangedFields    []string  `json:"changed_fields"`
+	TrainID          int       `json:"train_id"`
+	DepartureStation string    `json:"departure_station"`
+	ArrivalStation   string    `json:"arrival_station"`
+	DepartureTime    time.Time `json:"departure_time"`
+	ArrivalTime      time.Time `json:"arrival_time"`
+	Price            float64   `json:"price"`
+	AvailableSeats   int       `json:"available_seats"`
+	Platform         *string   `json:"platform"`
+}
+
+// ScheduleDisruptedEvent diterbitkan sekali untuk setiap gangguan jadwal.
+type ScheduleDisruptedEvent struct {
+	DisruptionID           int       `json:"disruption_id"`
+	ScheduleID             int       `json:"schedule_id"`
+	DisruptionType         string    `json:"disruption_type"`
+	Status                 string    `json:"status"`
+	DelayMinutes           int       `json:"delay_minutes"`
+	DepartureTime          time.Time `json:"departure_time"`
+	EstimatedDepartureTime time.Time `json:"estimated_departure_time"`
+	OldPlatform            *string   `json:"old_platform"`
+	NewPlatform            *string   `json:"new_platform"`
+	Reason                 string    `json:"reason"`
+	AffectedTickets        int       `json:"affected_tickets"`
+}
+
+type AlternativeSchedule struct {
+	ScheduleID     int       `json:"schedule_id"`
+	TrainName      string    `json:"train_name"`
+	DepartureTime  time.Time `json:"departure_time"`
+	ArrivalTime    time.Time `json:"arrival_time"`
+	Price          float64   `json:"price"`
+	AvailableSeats int       `json:"available_seats"`
+}
+
+// TicketDisruptedEvent diterbitkan untuk setiap tiket aktif pada jadwal yang
+// terganggu. Untuk pembatalan, RefundAmount berisi nominal yang dikembalikan
+// dan Alternatives berisi jadwal pengganti yang bisa dipesan.
+type TicketDisruptedEvent struct {
+	TicketID               int                   `json:"ticket_id"`
+	BookingCode            string                `json:"booking_code"`
+	UserID                 int                   `json:"user_id"`
+	ScheduleID             int                   `json:"schedule_id"`
+	DisruptionID           int                   `json:"disruption_id"`
+	DisruptionType         string                `json:"disruption_type"`
+	TrainName              string                `json:"train_name"`
+	DepartureStation       string                `json:"departure_station"`
+	ArrivalStation         string                `json:"arrival_station"`
+	DepartureTime          time.Time             `json:"departure_time"`
+	EstimatedDepartureTime time.Time             `json:"estimated_departure_time"`
+	DelayMinutes           int                   `json:"delay_minutes"`
+	Platform               *string               `json:"platform"`
+	Reason                 string                `json:"reason"`
+	SeatNumber             string                `json:"seat_number"`
+	PassengerName          string                `json:"passenger_name"`
+	RefundAmount           float64               `json:"refund_amount"`
+	Alternatives           []AlternativeSchedule `json:"alternatives"`
+}
+
+type UserLockedOutEvent struct {
+	UserID      int       `json:"user_id"`
+	LockedUntil time.Time `json:"locked_until"`
+}
+
+// NotificationEventKeys adalah routing key yang di-bind ke queue notifikasi.
+var NotificationEventKeys = []string{
+	EventTicketBooked + ".v1",
+	EventTicketCancelled + ".v1",
+	EventTicketExpired + ".v1",
+	EventTicketReminder + ".v1",
+	EventTicketDisrupted + ".v1",
+	EventPaymentConfirmed + ".v1",
+	EventUserLockedOut + ".v1",
+}
+
+// Notification menerjemahkan event menjadi pesan notifikasi. ok bernilai false
+// untuk event yang tidak punya notifikasi untuk user.
+func (e DomainEvent) Notification() (msg NotificationMessage, ok bool, err error) {
+	const timeLayout = "2006-01-02 15:04"
+
+	switch e.RoutingKey() {
+	case EventTicketBooked + ".v1":
+		var data TicketBookedEvent
+		if err := json.Unmarshal(e.Data, &data); err != nil {
+			return msg, false, err
+		}
+		return NotificationMessage{
+			Type:          "booking",
+			UserID:        data.UserID,
+			BookingCode:   data.BookingCode,
+			TrainName:     data.TrainName,
+			Departure:     data.DepartureStation,
+			Arrival:       data.ArrivalStation,
+			SeatNumber:    data.SeatNumber,
+			TotalPrice:    data.TotalPrice,
+			PaymentCode:   data.PaymentCode,
+			PaymentMethod: data.PaymentMethod,
+			DepartureTime: FormatLocalTime(data.DepartureTime),
+		}, true, nil
+	case EventTicketCancelled + ".v1":
+		var data TicketCancelledEvent
+		if err := json.Unmarshal(e.Data, &data); err != nil {
+			return msg, false, err
+		}
+		return NotificationMessage{
+			Type:        "cancellation",
+			UserID:      data.UserID,
+			BookingCode: data.BookingCode,
+			TrainName:   data.TrainName,
+			SeatNumber:  data.SeatNumber,
+		}, true, nil
+	case EventTicketExpired + ".v1":
+		var data TicketExpiredEvent
+		if err := json.Unmarshal(e.Data, &data); err != nil {
+			return msg, false, err
+		}
+		return NotificationMessage{
+			Type:        "expiry",
+			UserID:      data.UserID,
+			BookingCode: data.BookingCode,
+			TrainName:   data.TrainName,
+			Departure:   data.DepartureStation,
+			Arrival:     data.ArrivalStation,
+			SeatNumber:  data.SeatNumber,
+			TotalPrice:  data.TotalPrice,
+		}, true, nil
+	case EventTicketReminder + ".v1":
+		var data TicketDepartureReminderEvent
+		if err := json.Unmarshal(e.Data, &data); err != nil {
+			return msg, false, err
+		}
+		msg := NotificationMessage{
+			Type:          "reminder",
+			UserID:        data.UserID,
+			BookingCode:   data.BookingCode,
+			TrainName:     data.TrainName,
+			Departure:     data.DepartureStation,
+			Arrival:       data.ArrivalStation,
+			SeatNumber:    data.SeatNumber,
+			PassengerName: data.PassengerName,
+			DepartureTime: FormatLocalTime(data.DepartureTime),
+		}
+		if data.Platform != nil {
+			msg.Platform = *data.Platform
+		}
+		if data.Coach != nil {
+			msg.Coach = *data.Coach
+		}
+		return msg, true, nil
+	case EventTicketDisrupted + ".v1":
+		var data TicketDisruptedEvent
+		if err := json.Unmarshal(e.Data, &data); err != nil {
+			return msg, false, err
+		}
+		msg := NotificationMessage{
+			Type:                   "disruption",
+			UserID:                 data.UserID,
+			BookingCode:            data.BookingCode,
+			TrainName:              data.TrainName,
+			Departure:              data.DepartureStation,
+			Arrival:                data.ArrivalStation,
+			SeatNumber:             data.SeatNumber,
+			PassengerName:          data.PassengerName,
+			DepartureTime:          FormatLocalTime(data.DepartureTime),
+			DisruptionType:         data.DisruptionType,
+			DelayMinutes:           data.DelayMinutes,
+			EstimatedDepartureTime: FormatLocalTime(data.EstimatedDepartureTime),
+			Reason:                 data.Reason,
+			RefundAmount:           data.RefundAmount,
+		}
+		if data.Platform != nil {
+			msg.Platform = *data.Platform
+		}
+		for _, alt := range data.Alternatives {
+			msg.Alternatives = append(msg.Alternatives, NotificationAlternative{
+				TrainName:     alt.TrainName,
+				DepartureTime: FormatLocalTime(alt.DepartureTime),
+				Price:         alt.Price,
+			})
+		}
+		return msg, true, nil
+	case EventPaymentConfirmed + ".v1":
+		var data PaymentConfirmedEvent
+		if err := json.Unmarshal(e.Data, &data); err != nil {
+			return msg, false, err
+		}
+		return NotificationMessage{
+			Type:          "payment",
+			UserID:        data.UserID,
+			BookingCode:   data.BookingCode,
+			TrainName:     data.TrainName,
+			Departure:     data.DepartureStation,
+			Arrival:       data.ArrivalStation,
+			SeatNumber:    data.SeatNumber,
+			TotalPrice:    data.Amount,
+			PaymentCode:   data.PaymentCode,
+			PaymentMethod: data.PaymentMethod,
+			DepartureTime: FormatLocalTime(data.DepartureTime),
+		}, true, nil
+	case EventUserLockedOut + ".v1":
+		var data UserLockedOutEvent
+		if err := json.Unmarshal(e.Data, &data); err != nil {
+			return msg, false, err
+		}
+		return NotificationMessage{
+			Type:        "lockout",
+			UserID:      data.UserID,
+			LockedUntil: data.LockedUntil.Local().Format(timeLayout),
+		}, true, nil
+	}
+	return msg, false, nil
+}
+
+// DecodeNotificationBody membaca body pesan dari queue notifikasi. Body bisa
+// berupa DomainEvent atau NotificationMessage lama yang masih tersisa di
+// antrian atau outbox sebelum event stream dipakai.
+func DecodeNotificationBody(body []byte) (NotificationMessage, bool, error) {
+	var probe struct {
+		Version int             `json:"version"`
+		Data    json.RawMessage `json:"data"`
+	}
+	if err := json.Unmarshal(body, &probe); err != nil {
+		return NotificationMessage{}, false, err
+	}
+
+	if probe.Version > 0 && len(probe.Data) > 0 {
+		var event DomainEvent
+		if err := json.Unmarshal(body, &event); err != nil {
+			return NotificationMessage{}, false, err
+		}
+		return event.Notification()
+	}
+
+	var msg NotificationMessage
+	if err := json.Unmarshal(body, &msg); err != nil {
+		return msg, false, err
+	}
+	return msg, true, nil
+}
+
+//go:embed event_schemas/*.json
+var eventSchemas embed.FS
+
+// EventSchemaNames mengembalikan nama semua JSON Schema event, misalnya
+// ticket.booked.v1, terurut.
+func EventSchemaNames() ([]string, error) {
+	entries, err := fs.ReadDir(eventSchemas, "event_schemas")
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		names = append(names, strings.TrimSuffix(entry.Name(), ".json"))
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+func EventSchema(name string) (json.RawMessage, error) {
+	if strings.ContainsAny(name, "/\\") {
+		return nil, fmt.Errorf("schema event %s tidak ditemukan", name)
+	}
+	body, err := eventSchemas.ReadFile(path.Join("event_schemas", name+".json"))
+	if err != nil {
+		return nil, fmt.Errorf("schema event %s tidak ditemukan", name)
+	}
+	return body, nil
+}
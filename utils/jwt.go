@@ -1,12 +1,22 @@
 package utils
 
 import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
 	"errors"
+	"fmt"
+	"sync"
 	"time"
 
 	"github.com/golang-jwt/jwt/v4"
 )
 
+const keyRefreshCooldown = 10 * time.Second
+
 type Claims struct {
 	UserID int    `json:"user_id"`
 	Email  string `json:"email"`
@@ -14,29 +24,217 @@ type Claims struct {
 	jwt.RegisteredClaims
 }
 
-func GenerateJWT(userID int, email, role, secret string, exp time.Duration) (string, error) {
+// SigningKey adalah pasangan key asimetris untuk menandatangani JWT,
+// diidentifikasi dengan kid di header token.
+type SigningKey struct {
+	KID        string
+	Algorithm  string
+	PrivateKey crypto.Signer
+	PublicKey  crypto.PublicKey
+}
+
+func GenerateSigningKey(kid, algorithm string) (*SigningKey, error) {
+	switch algorithm {
+	case "RS256":
+		privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+		if err != nil {
+			return nil, err
+		}
+		return &SigningKey{KID: kid, Algorithm: algorithm, PrivateKey: privateKey, PublicKey: &privateKey.PublicKey}, nil
+	case "EdDSA":
+		publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			return nil, err
+		}
+		return &SigningKey{KID: kid, Algorithm: algorithm, PrivateKey: privateKey, PublicKey: publicKey}, nil
+	default:
+		return nil, fmt.Errorf("algoritma jwt %s tidak didukung", algorithm)
+	}
+}
+
+func ParseSigningKey(kid, algorithm, privateKeyPEM string) (*SigningKey, error) {
+	block, _ := pem.Decode([]byte(privateKeyPEM))
+	if block == nil {
+		return nil, errors.New("private key pem tidak valid")
+	}
+
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+
+	signer, ok := parsed.(crypto.Signer)
+	if !ok {
+		return nil, errors.New("private key tidak dapat dipakai untuk tanda tangan")
+	}
+	return &SigningKey{KID: kid, Algorithm: algorithm, PrivateKey: signer, PublicKey: signer.Public()}, nil
+}
+
+func (k *SigningKey) PrivateKeyPEM() (string, error) {
+	der, err := x509.MarshalPKCS8PrivateKey(k.PrivateKey)
+	if err != nil {
+		return "", err
+	}
+	return string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})), nil
+}
+
+func (k *SigningKey) method() jwt.SigningMethod {
+	if k.Algorithm == "EdDSA" {
+		return jwt.SigningMethodEdDSA
+	}
+	return jwt.SigningMethodRS256
+}
+
+// JWTKeySet menyimpan key yang sedang aktif untuk menandatangani token dan
+// semua key yang masih boleh dipakai untuk verifikasi. Selama masa migrasi,
+// token HS256 dengan shared secret tetap diterima jika AcceptHS256 aktif.
+type JWTKeySet struct {
+	mu          sync.RWMutex
+	hmacSecret  []byte
+	acceptHS256 bool
+	asymmetric  bool
+	active      *SigningKey
+	keys        map[string]*SigningKey
+
+	refresh     func() error
+	lastRefresh time.Time
+}
+
+// NewJWTKeySet menyiapkan key set untuk algoritma penanda tangan yang
+// dikonfigurasi. Untuk RS256/EdDSA token tidak pernah ditandatangani dengan
+// HS256, termasuk saat key aktif belum dimuat.
+func NewJWTKeySet(hmacSecret, algorithm string, acceptHS256 bool) *JWTKeySet {
+	return &JWTKeySet{
+		hmacSecret:  []byte(hmacSecret),
+		acceptHS256: acceptHS256,
+		asymmetric:  algorithm == "RS256" || algorithm == "EdDSA",
+		keys:        make(map[string]*SigningKey),
+	}
+}
+
+// SetKeys mengganti seluruh isi key set. active hanya boleh nil jika key set
+// dikonfigurasi untuk HS256.
+func (ks *JWTKeySet) SetKeys(active *SigningKey, keys []*SigningKey) {
+	byKID := make(map[string]*SigningKey, len(keys))
+	for _, k := range keys {
+		byKID[k.KID] = k
+	}
+
+	ks.mu.Lock()
+	defer ks.mu.Unlock()
+	ks.active = active
+	ks.keys = byKID
+}
+
+// SetRefreshFunc dipanggil saat token membawa kid yang belum dikenal,
+// misalnya karena instance lain baru saja merotasi key.
+func (ks *JWTKeySet) SetRefreshFunc(refresh func() error) {
+	ks.mu.Lock()
+	defer ks.mu.Unlock()
+	ks.refresh = refresh
+}
+
+func (ks *JWTKeySet) JWKS() JWKS {
+	ks.mu.RLock()
+	defer ks.mu.RUnlock()
+
+	jwks := JWKS{Keys: []JWK{}}
+	for _, k := range ks.keys {
+		jwk, err := NewJWK(k.KID, k.Algorithm, k.PublicKey)
+		if err != nil {
+			continue
+		}
+		jwks.Keys = append(jwks.Keys, jwk)
+	}
+	return jwks
+}
+
+func (ks *JWTKeySet) sign(claims Claims) (string, error) {
+	ks.mu.RLock()
+	active := ks.active
+	secret := ks.hmacSecret
+	asymmetric := ks.asymmetric
+	ks.mu.RUnlock()
+
+	if active == nil {
+		if asymmetric {
+			return "", errors.New("jwt signing key aktif belum dimuat")
+		}
+		return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(secret)
+	}
+
+	token := jwt.NewWithClaims(active.method(), claims)
+	token.Header["kid"] = active.KID
+	return token.SignedString(active.PrivateKey)
+}
+
+func (ks *JWTKeySet) verificationKey(token *jwt.Token) (interface{}, error) {
+	if _, ok := token.Method.(*jwt.SigningMethodHMAC); ok {
+		ks.mu.RLock()
+		defer ks.mu.RUnlock()
+		if ks.asymmetric && !ks.acceptHS256 {
+			return nil, errors.New("token HS256 tidak lagi diterima")
+		}
+		return ks.hmacSecret, nil
+	}
+
+	switch token.Method.(type) {
+	case *jwt.SigningMethodRSA, *jwt.SigningMethodEd25519:
+	default:
+		return nil, errors.New("method tidak valid")
+	}
+
+	kid, _ := token.Header["kid"].(string)
+	if key := ks.lookup(kid); key != nil {
+		if key.Algorithm != token.Method.Alg() {
+			return nil, errors.New("algoritma token tidak cocok dengan key")
+		}
+		return key.PublicKey, nil
+	}
+
+	if ks.tryRefresh() {
+		if key := ks.lookup(kid); key != nil && key.Algorithm == token.Method.Alg() {
+			return key.PublicKey, nil
+		}
+	}
+	return nil, fmt.Errorf("kid %s tidak dikenal", kid)
+}
+
+func (ks *JWTKeySet) lookup(kid string) *SigningKey {
+	ks.mu.RLock()
+	defer ks.mu.RUnlock()
+	return ks.keys[kid]
+}
+
+func (ks *JWTKeySet) tryRefresh() bool {
+	ks.mu.Lock()
+	refresh := ks.refresh
+	if refresh == nil || time.Since(ks.lastRefresh) < keyRefreshCooldown {
+		ks.mu.Unlock()
+		return false
+	}
+	ks.lastRefresh = time.Now()
+	ks.mu.Unlock()
+
+	return refresh() == nil
+}
+
+func GenerateJWT(userID int, email, role string, keys *JWTKeySet, exp time.Duration) (string, error) {
 	claims := Claims{
 		UserID: userID,
-		Email: email,
-		Role: role,
+		Email:  email,
+		Role:   role,
 		RegisteredClaims: jwt.RegisteredClaims{
 			ExpiresAt: jwt.NewNumericDate(time.Now().Add(exp)),
-			IssuedAt: jwt.NewNumericDate(time.Now()),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
 		},
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString([]byte(secret))
+	return keys.sign(claims)
 }
 
-func ValidateJWT(tokenStr, secret string) (*Claims, error) {
-	token, err := jwt.ParseWithClaims(tokenStr, &Claims{}, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, errors.New("method tidak valid")
-		}
-		return []byte(secret), nil
-	})
-
+func ValidateJWT(tokenStr string, keys *JWTKeySet) (*Claims, error) {
+	token, err := jwt.ParseWithClaims(tokenStr, &Claims{}, keys.verificationKey)
 	if err != nil {
 		return nil, err
 	}
@@ -47,6 +245,3 @@ func ValidateJWT(tokenStr, secret string) (*Claims, error) {
 
 	return nil, errors.New("token tidak valid")
 }
-
-
-
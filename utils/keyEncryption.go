@@ -0,0 +1,82 @@
+package utils
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+const encryptedKeyPrefix = "enc:v1:"
+
+// KeyCipher mengenkripsi private key sebelum disimpan ke database dengan
+// AES-256-GCM. kid ikut diautentikasi supaya ciphertext tidak bisa
+// dipindahkan ke baris key lain.
+type KeyCipher struct {
+	aead cipher.AEAD
+}
+
+// NewKeyCipher menerima key 32 byte yang di-encode base64.
+func NewKeyCipher(encodedKey string) (*KeyCipher, error) {
+	if encodedKey == "" {
+		return nil, errors.New("jwt.key_encryption_key belum diisi")
+	}
+
+	key, err := base64.StdEncoding.DecodeString(encodedKey)
+	if err != nil {
+		return nil, fmt.Errorf("jwt.key_encryption_key bukan base64 yang valid: %w", err)
+	}
+	if len(key) != 32 {
+		return nil, fmt.Errorf("jwt.key_encryption_key harus 32 byte, didapat %d", len(key))
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return &KeyCipher{aead: aead}, nil
+}
+
+// IsEncryptedKey membedakan private key terenkripsi dari PEM polos yang
+// tersimpan sebelum enkripsi diaktifkan.
+func IsEncryptedKey(stored string) bool {
+	return strings.HasPrefix(stored, encryptedKeyPrefix)
+}
+
+func (c *KeyCipher) Encrypt(kid, plaintext string) (string, error) {
+	nonce := make([]byte, c.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+
+	sealed := c.aead.Seal(nonce, nonce, []byte(plaintext), []byte(kid))
+	return encryptedKeyPrefix + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+func (c *KeyCipher) Decrypt(kid, stored string) (string, error) {
+	if !IsEncryptedKey(stored) {
+		return "", errors.New("private key tidak terenkripsi")
+	}
+
+	sealed, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(stored, encryptedKeyPrefix))
+	if err != nil {
+		return "", err
+	}
+	if len(sealed) < c.aead.NonceSize() {
+		return "", errors.New("ciphertext private key terlalu pendek")
+	}
+
+	nonce, ciphertext := sealed[:c.aead.NonceSize()], sealed[c.aead.NonceSize():]
+	plaintext, err := c.aead.Open(nil, nonce, ciphertext, []byte(kid))
+	if err != nil {
+		return "", errors.New("gagal mendekripsi private key, periksa jwt.key_encryption_key")
+	}
+	return string(plaintext), nil
+}
@@ -0,0 +1,184 @@
+package utils
+
+import (
+	"bufio"
+	"bytes"
+	"embed"
+	"fmt"
+	htmltemplate "html/template"
+	"math"
+	"strconv"
+	"strings"
+	texttemplate "text/template"
+)
+
+const DefaultLanguage = "id"
+
+var NotificationLanguages = []string{"id", "en"}
+
+//go:embed notification_templates/*.tmpl
+var defaultNotificationTemplates embed.FS
+
+// NotificationTemplate berisi sumber template untuk satu tipe notifikasi
+// dalam satu bahasa. Subject, Text dan Short dirender dengan text/template,
+// sedangkan HTML dengan html/template supaya data otomatis di-escape.
+type NotificationTemplate struct {
+	Subject string
+	Text    string
+	HTML    string
+	Short   string
+}
+
+type RenderedNotification struct {
+	Subject string `json:"subject"`
+	Text    string `json:"text"`
+	HTML    string `json:"html"`
+	Short   string `json:"short"`
+}
+
+var notificationTemplateFuncs = map[string]interface{}{
+	"rupiah": FormatRupiah,
+}
+
+// DefaultNotificationTemplate membaca template bawaan yang di-embed dari
+// notification_templates/<type>.<language>.tmpl.
+func DefaultNotificationTemplate(notificationType, language string) (*NotificationTemplate, error) {
+	content, err := defaultNotificationTemplates.ReadFile(fmt.Sprintf("notification_templates/%s.%s.tmpl", notificationType, language))
+	if err != nil {
+		return nil, fmt.Errorf("template bawaan %s (%s) tidak ditemukan", notificationType, language)
+	}
+	return parseNotificationTemplateFile(content)
+}
+
+// parseNotificationTemplateFile memecah file template menjadi beberapa bagian
+// yang diawali baris "-- +template <nama>", mirip penanda di file migrasi.
+func parseNotificationTemplateFile(content []byte) (*NotificationTemplate, error) {
+	sections := make(map[string]*strings.Builder)
+	var current *strings.Builder
+
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if name, ok := strings.CutPrefix(line, "-- +template "); ok {
+			current = &strings.Builder{}
+			sections[strings.TrimSpace(name)] = current
+			continue
+		}
+		if current == nil {
+			continue
+		}
+		current.WriteString(line)
+		current.WriteString("\n")
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	section := func(name string) string {
+		if b, ok := sections[name]; ok {
+			return strings.TrimRight(b.String(), "\n")
+		}
+		return ""
+	}
+
+	tpl := &NotificationTemplate{
+		Subject: section("subject"),
+		Text:    section("text"),
+		HTML:    section("html"),
+		Short:   section("short"),
+	}
+	if tpl.Subject == "" || tpl.Text == "" {
+		return nil, fmt.Errorf("template wajib memiliki bagian subject dan text")
+	}
+	return tpl, nil
+}
+
+func (t *NotificationTemplate) Render(data interface{}) (*RenderedNotification, error) {
+	subject, err := renderText("subject", t.Subject, data)
+	if err != nil {
+		return nil, err
+	}
+	text, err := renderText("text", t.Text, data)
+	if err != nil {
+		return nil, err
+	}
+	short, err := renderText("short", t.Short, data)
+	if err != nil {
+		return nil, err
+	}
+	html, err := renderHTML(t.HTML, data)
+	if err != nil {
+		return nil, err
+	}
+
+	if short == "" {
+		short = text
+	}
+
+	return &RenderedNotification{
+		// subject dipakai sebagai header email, jadi tidak boleh mengandung baris baru
+		Subject: strings.Join(strings.Fields(subject), " "),
+		Text:    text,
+		HTML:    html,
+		Short:   short,
+	}, nil
+}
+
+func renderText(name, source string, data interface{}) (string, error) {
+	if source == "" {
+		return "", nil
+	}
+	tpl, err := texttemplate.New(name).Funcs(notificationTemplateFuncs).Option("missingkey=error").Parse(source)
+	if err != nil {
+		return "", fmt.Errorf("template %s tidak valid: %w", name, err)
+	}
+	var buf bytes.Buffer
+	if err := tpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("gagal merender template %s: %w", name, err)
+	}
+	return buf.String(), nil
+}
+
+func renderHTML(source string, data interface{}) (string, error) {
+	if source == "" {
+		return "", nil
+	}
+	tpl, err := htmltemplate.New("html").Funcs(notificationTemplateFuncs).Option("missingkey=error").Parse(source)
+	if err != nil {
+		return "", fmt.Errorf("template html tidak valid: %w", err)
+	}
+	var buf bytes.Buffer
+	if err := tpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("gagal merender template html: %w", err)
+	}
+	return buf.String(), nil
+}
+
+// FormatRupiah memformat nominal menjadi "Rp 150.000".
+func FormatRupiah(amount float64) string {
+	digits := strconv.FormatInt(int64(math.Round(amount)), 10)
+	negative := strings.HasPrefix(digits, "-")
+	digits = strings.TrimPrefix(digits, "-")
+
+	var b strings.Builder
+	for i, d := range digits {
+		if i > 0 && (len(digits)-i)%3 == 0 {
+			b.WriteByte('.')
+		}
+		b.WriteRune(d)
+	}
+
+	if negative {
+		return "-Rp " + b.String()
+	}
+	return "Rp " + b.String()
+}
+
+func IsNotificationLanguage(language string) bool {
+	for _, l := range NotificationLanguages {
+		if l == language {
+			return true
+		}
+	}
+	return false
+}
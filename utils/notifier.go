@@ -0,0 +1,430 @@
+package utils
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/tls"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/http"
+	"net/mail"
+	"net/smtp"
+	"net/textproto"
+	"net/url"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+const (
+	ChannelEmail   = "email"
+	ChannelSMS     = "sms"
+	ChannelWebhook = "webhook"
+)
+
+// OutboundMessage adalah satu pesan yang siap dikirim lewat sebuah channel.
+// Recipient berisi alamat email, nomor telepon, atau url webhook sesuai channel.
+type OutboundMessage struct {
+	Type      string
+	Recipient string
+	Subject   string
+	Body      string
+	HTML      string
+	Payload   interface{}
+}
+
+type Notifier interface {
+	Channel() string
+	Send(ctx context.Context, msg OutboundMessage) error
+}
+
+// SMTPNotifier mengirim email lewat server SMTP. Untuk development bisa
+// diarahkan ke SMTP sink lokal seperti MailHog atau Mailpit.
+type SMTPNotifier struct {
+	host     string
+	port     string
+	username string
+	password string
+	from     string
+	timeout  time.Duration
+}
+
+func NewSMTPNotifier(host, port, username, password, from string, timeout time.Duration) *SMTPNotifier {
+	if timeout <= 0 {
+		timeout = 10 * time.Second
+	}
+	return &SMTPNotifier{
+		host:     host,
+		port:     port,
+		username: username,
+		password: password,
+		from:     from,
+		timeout:  timeout,
+	}
+}
+
+func (n *SMTPNotifier) Channel() string {
+	return ChannelEmail
+}
+
+func (n *SMTPNotifier) Send(ctx context.Context, msg OutboundMessage) error {
+	from, err := mail.ParseAddress(n.from)
+	if err != nil {
+		return fmt.Errorf("alamat pengirim tidak valid: %w", err)
+	}
+	to, err := mail.ParseAddress(msg.Recipient)
+	if err != nil {
+		return fmt.Errorf("alamat penerima tidak valid: %w", err)
+	}
+
+	deadline := time.Now().Add(n.timeout)
+	if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+		deadline = d
+	}
+
+	dialer := net.Dialer{Deadline: deadline}
+	conn, err := dialer.DialContext(ctx, "tcp", net.JoinHostPort(n.host, n.port))
+	if err != nil {
+		return err
+	}
+	conn.SetDeadline(deadline)
+
+	client, err := smtp.NewClient(conn, n.host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer client.Close()
+
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		if err := client.StartTLS(&tls.Config{ServerName: n.host}); err != nil {
+			return err
+		}
+	}
+	if n.username != "" {
+		if ok, _ := client.Extension("AUTH"); ok {
+			if err := client.Auth(smtp.PlainAuth("", n.username, n.password, n.host)); err != nil {
+				return err
+			}
+		}
+	}
+
+	if err := client.Mail(from.Address); err != nil {
+		return err
+	}
+	if err := client.Rcpt(to.Address); err != nil {
+		return err
+	}
+
+	message, err := buildEmail(from, to, msg.Subject, msg.Body, msg.HTML)
+	if err != nil {
+		return err
+	}
+
+	w, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(message); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return client.Quit()
+}
+
+// buildEmail menyusun pesan email. Jika ada versi HTML, pesan dikirim sebagai
+// multipart/alternative dengan versi teks biasa sebagai cadangan.
+func buildEmail(from, to *mail.Address, subject, text, html string) ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteString("From: " + from.String() + "\r\n")
+	buf.WriteString("To: " + to.String() + "\r\n")
+	buf.WriteString("Subject: " + mime.QEncoding.Encode("utf-8", subject) + "\r\n")
+	buf.WriteString("Date: " + time.Now().Format(time.RFC1123Z) + "\r\n")
+	buf.WriteString("MIME-Version: 1.0\r\n")
+
+	if html == "" {
+		buf.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+		buf.WriteString("Content-Transfer-Encoding: quoted-printable\r\n\r\n")
+		if err := writeQuotedPrintable(&buf, text); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	}
+
+	mw := multipart.NewWriter(&buf)
+	buf.WriteString("Content-Type: multipart/alternative; boundary=" + mw.Boundary() + "\r\n\r\n")
+
+	for _, part := range []struct {
+		contentType string
+		body        string
+	}{
+		{"text/plain; charset=UTF-8", text},
+		{"text/html; charset=UTF-8", html},
+	} {
+		w, err := mw.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {part.contentType},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		})
+		if err != nil {
+			return nil, err
+		}
+		if err := writeQuotedPrintable(w, part.body); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := mw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func writeQuotedPrintable(w io.Writer, body string) error {
+	qp := quotedprintable.NewWriter(w)
+	if _, err := qp.Write([]byte(strings.ReplaceAll(body, "\n", "\r\n"))); err != nil {
+		return err
+	}
+	return qp.Close()
+}
+
+// HTTPSMSNotifier adalah adapter untuk provider SMS yang menerima request
+// JSON {from, to, message} dengan api key di header Authorization.
+type HTTPSMSNotifier struct {
+	url        string
+	apiKey     string
+	sender     string
+	httpClient *http.Client
+}
+
+func NewHTTPSMSNotifier(url, apiKey, sender string, timeout time.Duration) *HTTPSMSNotifier {
+	if timeout <= 0 {
+		timeout = 10 * time.Second
+	}
+	return &HTTPSMSNotifier{
+		url:        url,
+		apiKey:     apiKey,
+		sender:     sender,
+		httpClient: &http.Client{Timeout: timeout},
+	}
+}
+
+func (n *HTTPSMSNotifier) Channel() string {
+	return ChannelSMS
+}
+
+func (n *HTTPSMSNotifier) Send(ctx context.Context, msg OutboundMessage) error {
+	body, err := json.Marshal(map[string]string{
+		"from":    n.sender,
+		"to":      msg.Recipient,
+		"message": msg.Body,
+	})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+n.apiKey)
+
+	resp, err := n.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("provider sms mengembalikan status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// FakeSMSNotifier tidak mengirim SMS sungguhan, hanya mencatat pesan di log
+// dan menyimpannya di memori. Dipakai untuk development dan pengujian.
+type FakeSMSNotifier struct {
+	mu   sync.Mutex
+	sent []OutboundMessage
+}
+
+func NewFakeSMSNotifier() *FakeSMSNotifier {
+	return &FakeSMSNotifier{}
+}
+
+func (n *FakeSMSNotifier) Channel() string {
+	return ChannelSMS
+}
+
+func (n *FakeSMSNotifier) Send(ctx context.Context, msg OutboundMessage) error {
+	n.mu.Lock()
+	n.sent = append(n.sent, msg)
+	n.mu.Unlock()
+
+	log.Printf("[SMS] ke %s: %s", msg.Recipient, msg.Body)
+	return nil
+}
+
+func (n *FakeSMSNotifier) Sent() []OutboundMessage {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return append([]OutboundMessage(nil), n.sent...)
+}
+
+// WebhookNotifier mengirim payload notifikasi sebagai JSON ke url milik user.
+// Body ditandatangani HMAC-SHA256 di header X-Tiketsepur-Signature supaya
+// penerima bisa memastikan request memang berasal dari kita.
+type WebhookNotifier struct {
+	secret     []byte
+	httpClient *http.Client
+}
+
+func NewWebhookNotifier(secret string, timeout time.Duration) *WebhookNotifier {
+	if timeout <= 0 {
+		timeout = 5 * time.Second
+	}
+	return &WebhookNotifier{
+		secret:     []byte(secret),
+		httpClient: newWebhookHTTPClient(timeout, publicAddressControl),
+	}
+}
+
+// newWebhookHTTPClient tidak memakai proxy dan memeriksa ulang alamat tujuan
+// tepat sebelum koneksi dibuka, sehingga DNS rebinding setelah url disimpan
+// tetap tidak bisa mengarahkan webhook ke jaringan internal.
+func newWebhookHTTPClient(timeout time.Duration, control func(network, address string, c syscall.RawConn) error) *http.Client {
+	dialer := &net.Dialer{Timeout: timeout, Control: control}
+	return &http.Client{
+		Timeout: timeout,
+		Transport: &http.Transport{
+			Proxy:               nil,
+			DialContext:         dialer.DialContext,
+			TLSHandshakeTimeout: timeout,
+		},
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
+
+func (n *WebhookNotifier) Channel() string {
+	return ChannelWebhook
+}
+
+func (n *WebhookNotifier) Send(ctx context.Context, msg OutboundMessage) error {
+	if len(n.secret) == 0 {
+		return errors.New("webhook secret belum dikonfigurasi, webhook tidak dikirim")
+	}
+
+	body, err := json.Marshal(map[string]interface{}{
+		"type":    msg.Type,
+		"sent_at": time.Now().UTC().Format(time.RFC3339),
+		"data":    msg.Payload,
+	})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, msg.Recipient, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Tiketsepur-Event", msg.Type)
+	req.Header.Set("X-Tiketsepur-Signature", "sha256="+SignWebhookPayload(n.secret, body))
+
+	resp, err := n.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook mengembalikan status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// ValidateWebhookURL memastikan url webhook memakai http/https dan semua
+// alamat hasil resolve host-nya adalah alamat publik.
+func ValidateWebhookURL(ctx context.Context, rawURL string) error {
+	parsed, err := url.Parse(rawURL)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Hostname() == "" {
+		return errors.New("webhook_url harus berupa url http atau https")
+	}
+
+	addrs, err := net.DefaultResolver.LookupIPAddr(ctx, parsed.Hostname())
+	if err != nil {
+		return fmt.Errorf("host webhook_url tidak dapat di-resolve: %w", err)
+	}
+	for _, addr := range addrs {
+		if !IsPublicIP(addr.IP) {
+			return fmt.Errorf("webhook_url tidak boleh mengarah ke alamat internal %s", addr.IP)
+		}
+	}
+	return nil
+}
+
+// IsPublicIP menolak alamat loopback, private, link-local, multicast, dan
+// unspecified, termasuk bentuk IPv4-mapped IPv6-nya.
+func IsPublicIP(ip net.IP) bool {
+	if ip4 := ip.To4(); ip4 != nil {
+		ip = ip4
+	}
+	return !(ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() || ip.IsMulticast())
+}
+
+func publicAddressControl(network, address string, c syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	ip := net.ParseIP(host)
+	if ip == nil || !IsPublicIP(ip) {
+		return fmt.Errorf("koneksi webhook ke alamat internal %s ditolak", host)
+	}
+	return nil
+}
+
+func SignWebhookPayload(secret, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// LogNotifier hanya menulis pesan ke log. Dipakai sebagai pengganti channel
+// yang belum dikonfigurasi supaya notifikasi tetap terlihat saat development.
+type LogNotifier struct {
+	channel string
+}
+
+func NewLogNotifier(channel string) *LogNotifier {
+	return &LogNotifier{channel: channel}
+}
+
+func (n *LogNotifier) Channel() string {
+	return n.channel
+}
+
+func (n *LogNotifier) Send(ctx context.Context, msg OutboundMessage) error {
+	if msg.Recipient == "" {
+		return errors.New("penerima notifikasi kosong")
+	}
+	log.Printf("[%s] ke %s: %s\n%s", strings.ToUpper(n.channel), msg.Recipient, msg.Subject, msg.Body)
+	return nil
+}
@@ -0,0 +1,287 @@
+package utils
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// smtpSink adalah server SMTP minimal yang menerima satu sesi dan menyimpan
+// isi DATA, cukup untuk menguji SMTPNotifier tanpa MailHog.
+type smtpSink struct {
+	listener net.Listener
+	mu       sync.Mutex
+	from     string
+	rcpt     []string
+	data     string
+	done     chan struct{}
+}
+
+func newSMTPSink(t *testing.T) *smtpSink {
+	t.Helper()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	sink := &smtpSink{listener: l, done: make(chan struct{})}
+	t.Cleanup(func() { l.Close() })
+	go sink.serve()
+	return sink
+}
+
+func (s *smtpSink) addr() (string, string) {
+	host, port, _ := net.SplitHostPort(s.listener.Addr().String())
+	return host, port
+}
+
+func (s *smtpSink) serve() {
+	defer close(s.done)
+	conn, err := s.listener.Accept()
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+
+	r := bufio.NewReader(conn)
+	reply := func(line string) { io.WriteString(conn, line+"\r\n") }
+	reply("220 sink ESMTP")
+
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		cmd := strings.TrimRight(line, "\r\n")
+		upper := strings.ToUpper(cmd)
+
+		switch {
+		case strings.HasPrefix(upper, "EHLO"), strings.HasPrefix(upper, "HELO"):
+			reply("250 sink")
+		case strings.HasPrefix(upper, "MAIL FROM:"):
+			s.mu.Lock()
+			s.from = strings.Trim(cmd[len("MAIL FROM:"):], "<> ")
+			s.mu.Unlock()
+			reply("250 OK")
+		case strings.HasPrefix(upper, "RCPT TO:"):
+			s.mu.Lock()
+			s.rcpt = append(s.rcpt, strings.Trim(cmd[len("RCPT TO:"):], "<> "))
+			s.mu.Unlock()
+			reply("250 OK")
+		case upper == "DATA":
+			reply("354 kirim data")
+			var data strings.Builder
+			for {
+				l, err := r.ReadString('\n')
+				if err != nil {
+					return
+				}
+				if l == ".\r\n" {
+					break
+				}
+				data.WriteString(l)
+			}
+			s.mu.Lock()
+			s.data = data.String()
+			s.mu.Unlock()
+			reply("250 OK")
+		case upper == "QUIT":
+			reply("221 bye")
+			return
+		default:
+			reply("502 tidak didukung")
+		}
+	}
+}
+
+func TestSMTPNotifierSendsToSink(t *testing.T) {
+	sink := newSMTPSink(t)
+	host, port := sink.addr()
+
+	n := NewSMTPNotifier(host, port, "", "", "TiketSepur <no-reply@tiketsepur.id>", 5*time.Second)
+	err := n.Send(context.Background(), OutboundMessage{
+		Type:      "booking",
+		Recipient: "Budi <budi@example.com>",
+		Subject:   "Pemesanan berhasil",
+		Body:      "Kode booking TS123",
+		HTML:      "<p>Kode booking <b>TS123</b></p>",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	<-sink.done
+
+	sink.mu.Lock()
+	defer sink.mu.Unlock()
+	if sink.from != "no-reply@tiketsepur.id" {
+		t.Errorf("MAIL FROM = %q", sink.from)
+	}
+	if len(sink.rcpt) != 1 || sink.rcpt[0] != "budi@example.com" {
+		t.Errorf("RCPT TO = %v", sink.rcpt)
+	}
+	for _, want := range []string{
+		"Subject: Pemesanan berhasil",
+		"Content-Type: multipart/alternative",
+		"Kode booking TS123",
+		"<b>TS123</b>",
+	} {
+		if !strings.Contains(sink.data, want) {
+			t.Errorf("email tidak memuat %q:\n%s", want, sink.data)
+		}
+	}
+}
+
+func TestSMTPNotifierRejectsInvalidRecipient(t *testing.T) {
+	n := NewSMTPNotifier("127.0.0.1", "1", "", "", "no-reply@tiketsepur.id", time.Second)
+	if err := n.Send(context.Background(), OutboundMessage{Recipient: "bukan email"}); err == nil {
+		t.Fatal("alamat penerima tidak valid seharusnya ditolak sebelum koneksi dibuka")
+	}
+}
+
+func TestHTTPSMSNotifierSendsToProvider(t *testing.T) {
+	var got map[string]string
+	var auth string
+	provider := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth = r.Header.Get("Authorization")
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer provider.Close()
+
+	n := NewHTTPSMSNotifier(provider.URL, "sms-key", "TIKETSEPUR", time.Second)
+	if err := n.Send(context.Background(), OutboundMessage{Recipient: "+628123", Body: "Kereta berangkat 1 jam lagi"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if auth != "Bearer sms-key" {
+		t.Errorf("Authorization = %q", auth)
+	}
+	if got["from"] != "TIKETSEPUR" || got["to"] != "+628123" || got["message"] != "Kereta berangkat 1 jam lagi" {
+		t.Errorf("payload = %v", got)
+	}
+}
+
+func TestHTTPSMSNotifierReportsProviderError(t *testing.T) {
+	provider := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer provider.Close()
+
+	n := NewHTTPSMSNotifier(provider.URL, "sms-key", "TIKETSEPUR", time.Second)
+	if err := n.Send(context.Background(), OutboundMessage{Recipient: "+628123", Body: "x"}); err == nil {
+		t.Fatal("status 500 dari provider seharusnya menjadi error")
+	}
+}
+
+func TestFakeSMSNotifierRecordsMessages(t *testing.T) {
+	n := NewFakeSMSNotifier()
+	n.Send(context.Background(), OutboundMessage{Recipient: "+628123", Body: "halo"})
+
+	sent := n.Sent()
+	if len(sent) != 1 || sent[0].Recipient != "+628123" {
+		t.Fatalf("sent = %v", sent)
+	}
+}
+
+func TestWebhookNotifierSignsPayload(t *testing.T) {
+	var signature string
+	var body []byte
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		signature = r.Header.Get("X-Tiketsepur-Signature")
+		body, _ = io.ReadAll(r.Body)
+	}))
+	defer receiver.Close()
+
+	n := NewWebhookNotifier("rahasia", time.Second)
+	// server uji berjalan di loopback, jadi pemeriksaan alamat publik dilewati
+	n.httpClient = newWebhookHTTPClient(time.Second, nil)
+
+	if err := n.Send(context.Background(), OutboundMessage{Type: "booking", Recipient: receiver.URL, Payload: map[string]string{"code": "TS123"}}); err != nil {
+		t.Fatal(err)
+	}
+	if want := "sha256=" + SignWebhookPayload([]byte("rahasia"), body); signature != want {
+		t.Errorf("signature = %q, want %q", signature, want)
+	}
+}
+
+func TestWebhookNotifierRefusesEmptySecret(t *testing.T) {
+	called := false
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+	}))
+	defer receiver.Close()
+
+	n := NewWebhookNotifier("", time.Second)
+	n.httpClient = newWebhookHTTPClient(time.Second, nil)
+
+	if err := n.Send(context.Background(), OutboundMessage{Type: "booking", Recipient: receiver.URL}); err == nil {
+		t.Fatal("webhook tanpa secret seharusnya tidak dikirim")
+	}
+	if called {
+		t.Fatal("receiver tidak boleh menerima request tanpa tanda tangan")
+	}
+}
+
+func TestWebhookNotifierBlocksInternalAddressAtDial(t *testing.T) {
+	called := false
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+	}))
+	defer receiver.Close()
+
+	// url loopback dikirim langsung, seolah-olah lolos validasi saat disimpan
+	// lalu host-nya di-rebind ke alamat internal
+	n := NewWebhookNotifier("rahasia", time.Second)
+	if err := n.Send(context.Background(), OutboundMessage{Type: "booking", Recipient: receiver.URL}); err == nil {
+		t.Fatal("koneksi ke loopback seharusnya ditolak")
+	}
+	if called {
+		t.Fatal("receiver internal tidak boleh menerima request")
+	}
+}
+
+func TestValidateWebhookURL(t *testing.T) {
+	rejected := []string{
+		"ftp://example.com/hook",
+		"http:///hook",
+		"http://127.0.0.1/hook",
+		"http://localhost:8080/hook",
+		"http://10.1.2.3/hook",
+		"http://192.168.1.1/hook",
+		"http://169.254.169.254/latest/meta-data",
+		"http://0.0.0.0/hook",
+		"http://[::1]/hook",
+		"http://[fe80::1]/hook",
+		"http://[::ffff:127.0.0.1]/hook",
+	}
+	for _, raw := range rejected {
+		if err := ValidateWebhookURL(context.Background(), raw); err == nil {
+			t.Errorf("ValidateWebhookURL(%q) seharusnya ditolak", raw)
+		}
+	}
+
+	if err := ValidateWebhookURL(context.Background(), "https://8.8.8.8/hook"); err != nil {
+		t.Errorf("alamat publik seharusnya diterima: %v", err)
+	}
+}
+
+func TestPublicAddressControl(t *testing.T) {
+	var raw syscall.RawConn
+	if err := publicAddressControl("tcp4", "10.0.0.5:443", raw); err == nil {
+		t.Error("alamat private seharusnya ditolak")
+	}
+	if err := publicAddressControl("tcp4", "8.8.8.8:443", raw); err != nil {
+		t.Errorf("alamat publik seharusnya diterima: %v", err)
+	}
+}
@@ -0,0 +1,290 @@
+package utils
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+const jwksRefreshInterval = time.Hour
+
+type OIDCDiscovery struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+type OIDCTokenResponse struct {
+	AccessToken string `json:"access_token"`
+	IDToken     string `json:"id_token"`
+	TokenType   string `json:"token_type"`
+	ExpiresIn   int    `json:"expires_in"`
+}
+
+type OIDCIDTokenClaims struct {
+	Email         string      `json:"email"`
+	EmailVerified interface{} `json:"email_verified"`
+	Name          string      `json:"name"`
+	Nonce         string      `json:"nonce"`
+	jwt.RegisteredClaims
+}
+
+// IsEmailVerified menangani provider yang mengirim email_verified sebagai
+// boolean maupun string.
+func (c *OIDCIDTokenClaims) IsEmailVerified() bool {
+	switch v := c.EmailVerified.(type) {
+	case bool:
+		return v
+	case string:
+		return v == "true"
+	default:
+		return false
+	}
+}
+
+// OIDCProvider adalah client authorization code + PKCE untuk satu provider
+// OpenID Connect. Metadata discovery dan JWKS di-cache di memori.
+type OIDCProvider struct {
+	Name         string
+	issuer       string
+	clientID     string
+	clientSecret string
+	redirectURL  string
+	scopes       []string
+	httpClient   *http.Client
+
+	mu            sync.Mutex
+	discovery     *OIDCDiscovery
+	keys          map[string]interface{}
+	keysFetchedAt time.Time
+}
+
+func NewOIDCProvider(name, issuer, clientID, clientSecret, redirectURL string, scopes []string) *OIDCProvider {
+	if len(scopes) == 0 {
+		scopes = []string{"openid", "email", "profile"}
+	}
+	return &OIDCProvider{
+		Name:         name,
+		issuer:       strings.TrimSuffix(issuer, "/"),
+		clientID:     clientID,
+		clientSecret: clientSecret,
+		redirectURL:  redirectURL,
+		scopes:       scopes,
+		httpClient:   &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+func (p *OIDCProvider) Discover(ctx context.Context) (*OIDCDiscovery, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.discovery != nil {
+		return p.discovery, nil
+	}
+
+	var discovery OIDCDiscovery
+	if err := p.getJSON(ctx, p.issuer+"/.well-known/openid-configuration", &discovery); err != nil {
+		return nil, fmt.Errorf("gagal discovery provider %s: %w", p.Name, err)
+	}
+	if strings.TrimSuffix(discovery.Issuer, "/") != p.issuer {
+		return nil, fmt.Errorf("issuer provider %s tidak cocok: %s", p.Name, discovery.Issuer)
+	}
+
+	p.discovery = &discovery
+	return p.discovery, nil
+}
+
+func (p *OIDCProvider) AuthCodeURL(ctx context.Context, state, nonce, codeChallenge string) (string, error) {
+	discovery, err := p.Discover(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	params := url.Values{}
+	params.Set("response_type", "code")
+	params.Set("client_id", p.clientID)
+	params.Set("redirect_uri", p.redirectURL)
+	params.Set("scope", strings.Join(p.scopes, " "))
+	params.Set("state", state)
+	params.Set("nonce", nonce)
+	params.Set("code_challenge", codeChallenge)
+	params.Set("code_challenge_method", "S256")
+
+	separator := "?"
+	if strings.Contains(discovery.AuthorizationEndpoint, "?") {
+		separator = "&"
+	}
+	return discovery.AuthorizationEndpoint + separator + params.Encode(), nil
+}
+
+func (p *OIDCProvider) Exchange(ctx context.Context, code, codeVerifier string) (*OIDCTokenResponse, error) {
+	discovery, err := p.Discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	form := url.Values{}
+	form.Set("grant_type", "authorization_code")
+	form.Set("code", code)
+	form.Set("redirect_uri", p.redirectURL)
+	form.Set("client_id", p.clientID)
+	form.Set("client_secret", p.clientSecret)
+	form.Set("code_verifier", codeVerifier)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, discovery.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("token endpoint mengembalikan status %d", resp.StatusCode)
+	}
+
+	var token OIDCTokenResponse
+	if err := json.NewDecoder(resp.Body).Decode(&token); err != nil {
+		return nil, err
+	}
+	if token.IDToken == "" {
+		return nil, errors.New("response token tidak berisi id_token")
+	}
+	return &token, nil
+}
+
+func (p *OIDCProvider) VerifyIDToken(ctx context.Context, rawIDToken, nonce string) (*OIDCIDTokenClaims, error) {
+	claims := &OIDCIDTokenClaims{}
+	_, err := jwt.ParseWithClaims(rawIDToken, claims, func(token *jwt.Token) (interface{}, error) {
+		switch token.Method.(type) {
+		case *jwt.SigningMethodRSA, *jwt.SigningMethodECDSA, *jwt.SigningMethodEd25519:
+		default:
+			return nil, errors.New("method tidak valid")
+		}
+		kid, _ := token.Header["kid"].(string)
+		return p.publicKey(ctx, kid)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if !claims.VerifyIssuer(p.issuer, true) && !claims.VerifyIssuer(p.issuer+"/", true) {
+		return nil, errors.New("issuer id_token tidak valid")
+	}
+	if !claims.VerifyAudience(p.clientID, true) {
+		return nil, errors.New("audience id_token tidak valid")
+	}
+	if claims.Nonce != nonce {
+		return nil, errors.New("nonce id_token tidak valid")
+	}
+
+	return claims, nil
+}
+
+func (p *OIDCProvider) publicKey(ctx context.Context, kid string) (interface{}, error) {
+	p.mu.Lock()
+	key, ok := p.keys[kid]
+	stale := time.Since(p.keysFetchedAt) > jwksRefreshInterval
+	p.mu.Unlock()
+
+	if ok && !stale {
+		return key, nil
+	}
+
+	// kid yang belum dikenal biasanya berarti provider baru saja merotasi key
+	if err := p.refreshKeys(ctx); err != nil {
+		return nil, err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	key, ok = p.keys[kid]
+	if !ok {
+		return nil, fmt.Errorf("key %s tidak ditemukan di jwks", kid)
+	}
+	return key, nil
+}
+
+func (p *OIDCProvider) refreshKeys(ctx context.Context) error {
+	discovery, err := p.Discover(ctx)
+	if err != nil {
+		return err
+	}
+
+	var jwks JWKS
+	if err := p.getJSON(ctx, discovery.JWKSURI, &jwks); err != nil {
+		return fmt.Errorf("gagal mengambil jwks provider %s: %w", p.Name, err)
+	}
+
+	keys := make(map[string]interface{}, len(jwks.Keys))
+	for _, k := range jwks.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+		publicKey, err := k.PublicKey()
+		if err != nil {
+			continue
+		}
+		keys[k.Kid] = publicKey
+	}
+
+	p.mu.Lock()
+	p.keys = keys
+	p.keysFetchedAt = time.Now()
+	p.mu.Unlock()
+	return nil
+}
+
+func (p *OIDCProvider) getJSON(ctx context.Context, endpoint string, target interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s mengembalikan status %d", endpoint, resp.StatusCode)
+	}
+	return json.NewDecoder(resp.Body).Decode(target)
+}
+
+// GeneratePKCE membuat code_verifier acak dan code_challenge S256-nya.
+func GeneratePKCE() (string, string, error) {
+	verifier, err := RandomURLString(32)
+	if err != nil {
+		return "", "", err
+	}
+	sum := sha256.Sum256([]byte(verifier))
+	return verifier, base64.RawURLEncoding.EncodeToString(sum[:]), nil
+}
+
+func RandomURLString(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
@@ -1,88 +1,154 @@
 package utils
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"os"
+	"sync"
+	"time"
 
 	amqp "github.com/rabbitmq/amqp091-go"
 )
 
+var ErrRabbitMQDisconnected = errors.New("koneksi rabbitmq terputus")
+
+// RabbitMQ menjaga satu koneksi ke broker dan menyambung ulang dengan backoff
+// jika koneksi terputus. Publish memakai pool channel dalam mode confirm
+// karena amqp.Channel tidak aman dipakai bersamaan dari banyak goroutine.
 type RabbitMQ struct {
-	conn    *amqp.Connection
-	channel *amqp.Channel
-	queue   amqp.Queue
+	url      string
+	queue    amqp.Queue
+	exchange string
+	options  RabbitMQOptions
+
+	mu        sync.RWMutex
+	conn      *amqp.Connection
+	connected chan struct{}
+	pool      chan *amqp.Channel
+	status    BrokerStatus
+	closing   bool
+}
+
+type RabbitMQOptions struct {
+	QueueName         string
+	Exchange          string
+	PublishTimeout    time.Duration
+	ChannelPoolSize   int
+	ReconnectMaxDelay time.Duration
+}
+
+// BrokerStatus adalah kondisi koneksi ke broker untuk endpoint health.
+type BrokerStatus struct {
+	Connected   bool       `json:"connected"`
+	Since       time.Time  `json:"since"`
+	Reconnects  int        `json:"reconnects"`
+	LastError   string     `json:"last_error,omitempty"`
+	LastErrorAt *time.Time `json:"last_error_at,omitempty"`
 }
 
 type NotificationMessage struct {
-	Type        string  `json:"type"`
-	Email       string  `json:"email"`
-	BookingCode string  `json:"booking_code"`
-	TrainName   string  `json:"train_name"`
-	Departure   string  `json:"departure"`
-	Arrival     string  `json:"arrival"`
-	SeatNumber  string  `json:"seat_number"`
-	TotalPrice  float64 `json:"total_price"`
-	PaymentCode string  `json:"payment_code,omitempty"`
-	PaymentMethod string `json:"payment_method,omitempty"`
+	Type          string  `json:"type"`
+	UserID        int     `json:"user_id,omitempty"`
+	Email         string  `json:"email"`
+	BookingCode   string  `json:"booking_code"`
+	TrainName     string  `json:"train_name"`
+	Departure     string  `json:"departure"`
+	Arrival       string  `json:"arrival"`
+	SeatNumber    string  `json:"seat_number"`
+	TotalPrice    float64 `json:"total_price"`
+	PaymentCode   string  `json:"payment_code,omitempty"`
+	PaymentMethod string  `json:"payment_method,omitempty"`
 	DepartureTime string  `json:"departure_time,omitempty"`
+	LockedUntil   string  `json:"locked_until,omitempty"`
+	PassengerName string  `json:"passenger_name,omitempty"`
+	Platform      string  `json:"platform,omitempty"`
+	Coach         string  `json:"coach,omitempty"`
+
+	DisruptionType         string                    `json:"disruption_type,omitempty"`
+	DelayMinutes           int                       `json:"delay_minutes,omitempty"`
+	EstimatedDepartureTime string                    `json:"estimated_departure_time,omitempty"`
+	Reason                 string                    `json:"reason,omitempty"`
+	RefundAmount           float64                   `json:"refund_amount,omitempty"`
+	Alternatives           []NotificationAlternative `json:"alternatives,omitempty"`
 }
 
-func NewRabbitMQ(url, queueName string) (*RabbitMQ, error) {
+type NotificationAlternative struct {
+	TrainName     string  `json:"train_name"`
+	DepartureTime string  `json:"departure_time"`
+	Price         float64 `json:"price"`
+}
+
+// NewRabbitMQ menyiapkan topic exchange untuk domain event dan queue
+// notifikasi yang di-bind ke event yang perlu dikirim ke user. Koneksi awal
+// harus berhasil; setelah itu putusnya koneksi ditangani di latar belakang.
+func NewRabbitMQ(url string, opts RabbitMQOptions) (*RabbitMQ, error) {
 	rabbitURL := os.Getenv("RABBITMQ_URL")
 	if rabbitURL == "" {
 		return nil, fmt.Errorf("RABBITMQ_URL environment variable belum diatur")
 	}
-	
-	conn, err := amqp.Dial(rabbitURL)
-	if err != nil {
+
+	if opts.Exchange == "" {
+		opts.Exchange = DefaultEventsExchange
+	}
+	if opts.PublishTimeout <= 0 {
+		opts.PublishTimeout = 5 * time.Second
+	}
+	if opts.ChannelPoolSize <= 0 {
+		opts.ChannelPoolSize = 8
+	}
+	if opts.ReconnectMaxDelay <= 0 {
+		opts.ReconnectMaxDelay = 30 * time.Second
+	}
+
+	r := &RabbitMQ{
+		url:       rabbitURL,
+		exchange:  opts.Exchange,
+		options:   opts,
+		connected: make(chan struct{}),
+		pool:      make(chan *amqp.Channel, opts.ChannelPoolSize),
+	}
+	if err := r.connect(); err != nil {
 		return nil, fmt.Errorf("failed to connect: %w", err)
 	}
-	channel, err := conn.Channel()
+	return r, nil
+}
+
+func (r *RabbitMQ) connect() error {
+	conn, err := amqp.Dial(r.url)
 	if err != nil {
-		return nil, err
+		return err
 	}
-	queue, err := channel.QueueDeclare(
-		queueName,
-		true,
-		false,
-		false,
-		false,
-		nil,
-	)
+
+	queue, err := r.declareTopology(conn)
 	if err != nil {
-		return nil, err
+		conn.Close()
+		return err
 	}
-	return &RabbitMQ{
-		conn:    conn,
-		channel: channel,
-		queue: queue,
-	}, nil
+
+	r.mu.Lock()
+	r.conn = conn
+	r.queue = queue
+	r.status.Connected = true
+	r.status.Since = time.Now()
+	close(r.connected)
+	r.mu.Unlock()
+
+	go r.watch(conn)
+	return nil
 }
 
-func (r *RabbitMQ) PublishNotification(msg NotificationMessage) error {
-	body, err := json.Marshal(msg)
+func (r *RabbitMQ) declareTopology(conn *amqp.Connection) (amqp.Queue, error) {
+	ch, err := conn.Channel()
 	if err != nil {
-		return err
+		return amqp.Queue{}, err
 	}
-	return r.channel.Publish(
-		"",
-		r.queue.Name,
-		false,
-		false,
-		amqp.Publishing{
-			ContentType: "application/json",
-			Body: body,
-			DeliveryMode: amqp.Persistent,
-		},
-	)
-}
+	defer ch.Close()
 
-func (r *RabbitMQ) ConsumeNotifications() {
-	msgs, err := r.channel.Consume(
-		r.queue.Name,
-		"",
+	queue, err := ch.QueueDeclare(
+		r.options.QueueName,
 		true,
 		false,
 		false,
@@ -90,57 +156,246 @@ func (r *RabbitMQ) ConsumeNotifications() {
 		nil,
 	)
 	if err != nil {
-		log.Print("gagal mendaftarkan konsumen: ", err)
+		return queue, err
+	}
+	if err := ch.ExchangeDeclare(r.exchange, "topic", true, false, false, false, nil); err != nil {
+		return queue, err
+	}
+	for _, key := range NotificationEventKeys {
+		if err := ch.QueueBind(queue.Name, key, r.exchange, false, nil); err != nil {
+			return queue, err
+		}
+	}
+	return queue, nil
+}
+
+// watch menunggu koneksi tertutup lalu menyambung ulang dengan backoff
+// eksponensial sampai berhasil atau Close dipanggil.
+func (r *RabbitMQ) watch(conn *amqp.Connection) {
+	closeErr := <-conn.NotifyClose(make(chan *amqp.Error, 1))
+
+	r.mu.Lock()
+	if r.closing {
+		r.mu.Unlock()
 		return
 	}
-	go func() {
-		for msg := range msgs {
-			var notification NotificationMessage
-			if err := json.Unmarshal(msg.Body, &notification); err != nil {
-				log.Print("error: ", err)
-				continue
+	r.status.Connected = false
+	r.status.Since = time.Now()
+	if closeErr != nil {
+		r.recordError(closeErr)
+	}
+	r.connected = make(chan struct{})
+	r.mu.Unlock()
+	r.drainPool()
+
+	log.Printf("koneksi rabbitmq terputus: %v", closeErr)
+
+	delay := time.Second
+	for {
+		time.Sleep(delay)
+
+		r.mu.RLock()
+		closing := r.closing
+		r.mu.RUnlock()
+		if closing {
+			return
+		}
+
+		if err := r.connect(); err != nil {
+			r.mu.Lock()
+			r.recordError(err)
+			r.mu.Unlock()
+			log.Printf("gagal menyambung ulang ke rabbitmq, dicoba lagi dalam %s: %v", delay, err)
+
+			delay *= 2
+			if delay > r.options.ReconnectMaxDelay {
+				delay = r.options.ReconnectMaxDelay
 			}
-			switch notification.Type {
-			case "booking":
-				r.handleBookingNotification(notification)
-			case "payment":
-				r.handlePaymentNotification(notification)
-			case "cancellation":
-				r.handleCancellationNotification(notification)
-			default:
-				log.Printf("Unknown notification type: %s", notification.Type)
+			continue
+		}
+
+		r.mu.Lock()
+		r.status.Reconnects++
+		r.mu.Unlock()
+		log.Println("koneksi rabbitmq tersambung kembali")
+		return
+	}
+}
+
+// recordError harus dipanggil dengan r.mu terkunci.
+func (r *RabbitMQ) recordError(err error) {
+	now := time.Now()
+	r.status.LastError = err.Error()
+	r.status.LastErrorAt = &now
+}
+
+func (r *RabbitMQ) Status() BrokerStatus {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.status
+}
+
+// WaitConnected menunggu sampai koneksi tersedia atau ctx selesai.
+func (r *RabbitMQ) WaitConnected(ctx context.Context) error {
+	r.mu.RLock()
+	connected := r.connected
+	r.mu.RUnlock()
+
+	select {
+	case <-connected:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// openChannel membuka channel baru di koneksi yang sedang aktif.
+func (r *RabbitMQ) openChannel() (*amqp.Channel, error) {
+	r.mu.RLock()
+	conn, connected := r.conn, r.status.Connected
+	r.mu.RUnlock()
+
+	if !connected || conn == nil || conn.IsClosed() {
+		return nil, ErrRabbitMQDisconnected
+	}
+	return conn.Channel()
+}
+
+func (r *RabbitMQ) queueName() string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.queue.Name
+}
+
+// acquireChannel mengambil channel confirm dari pool, atau membuka yang baru
+// jika pool kosong. Channel dikembalikan lewat releaseChannel.
+func (r *RabbitMQ) acquireChannel() (*amqp.Channel, error) {
+	for {
+		select {
+		case ch := <-r.pool:
+			if ch.IsClosed() {
+				continue
 			}
+			return ch, nil
+		default:
+		}
+
+		ch, err := r.openChannel()
+		if err != nil {
+			return nil, err
+		}
+		if err := ch.Confirm(false); err != nil {
+			ch.Close()
+			return nil, err
 		}
-	}()
+		return ch, nil
+	}
 }
 
-func (r *RabbitMQ) handleBookingNotification(n NotificationMessage) {
-	log.Printf("[BOOKING] Sending notification to %s", n.Email)
-	log.Printf("Booking Code: %s", n.BookingCode)
-	log.Printf("Train: %s", n.TrainName)
-	log.Printf("Route: %s → %s", n.Departure, n.Arrival)
-	log.Printf("Seat: %s | Price: Rp %.0f", n.SeatNumber, n.TotalPrice)
+// releaseChannel mengembalikan channel ke pool. Channel yang bermasalah
+// ditutup supaya tidak dipakai lagi.
+func (r *RabbitMQ) releaseChannel(ch *amqp.Channel, healthy bool) {
+	if !healthy || ch.IsClosed() {
+		ch.Close()
+		return
+	}
+	select {
+	case r.pool <- ch:
+	default:
+		ch.Close()
+	}
 }
 
-func (r *RabbitMQ) handlePaymentNotification(n NotificationMessage) {
-	log.Printf("[PAYMENT] Sending payment confirmation to %s", n.Email)
-	log.Printf("Booking Code: %s", n.BookingCode)
-	log.Printf("Payment Code: %s", n.PaymentCode)
-	log.Printf("Amount: Rp %.0f", n.TotalPrice)
-	log.Printf("Train: %s (%s → %s)", n.TrainName, n.Departure, n.Arrival)
-	log.Printf("Departure: %s", n.DepartureTime)
-	log.Printf("Seat: %s", n.SeatNumber)
-	log.Printf("Payment Confirmed - Ticket is now ACTIVE")
+func (r *RabbitMQ) drainPool() {
+	for {
+		select {
+		case ch := <-r.pool:
+			ch.Close()
+		default:
+			return
+		}
+	}
 }
 
-func (r *RabbitMQ) handleCancellationNotification(n NotificationMessage) {
-	log.Printf("[CANCELLATION] Sending cancellation notification to %s", n.Email)
-	log.Printf("Booking Code: %s", n.BookingCode)
-	log.Printf("Train: %s", n.TrainName)
+// publish mengirim pesan dan menunggu konfirmasi broker paling lama
+// PublishTimeout. Pesan baru dianggap terkirim setelah di-ack broker.
+func (r *RabbitMQ) publish(ctx context.Context, exchange, key string, msg amqp.Publishing) error {
+	ctx, cancel := context.WithTimeout(ctx, r.options.PublishTimeout)
+	defer cancel()
+
+	ch, err := r.acquireChannel()
+	if err != nil {
+		return err
+	}
+
+	confirmation, err := ch.PublishWithDeferredConfirmWithContext(ctx, exchange, key, false, false, msg)
+	if err != nil {
+		r.releaseChannel(ch, false)
+		return err
+	}
+	acked, err := confirmation.WaitContext(ctx)
+	if err != nil {
+		// konfirmasi yang terlambat akan membingungkan publish berikutnya
+		// di channel yang sama, jadi channel ini dibuang
+		r.releaseChannel(ch, false)
+		return fmt.Errorf("konfirmasi publish tidak diterima: %w", err)
+	}
+	r.releaseChannel(ch, true)
+
+	if !acked {
+		return errors.New("pesan ditolak oleh broker")
+	}
+	return nil
 }
 
-func (r *RabbitMQ) Close() {
-	r.channel.Close()
-	r.conn.Close()
+// PublishEvent mempublikasikan event ke topic exchange dengan routing key
+// <type>.v<version>. Id event dipakai sebagai message id.
+func (r *RabbitMQ) PublishEvent(ctx context.Context, event DomainEvent) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	return r.PublishEventJSON(ctx, event.RoutingKey(), body, event.ID)
 }
 
+func (r *RabbitMQ) PublishEventJSON(ctx context.Context, routingKey string, body []byte, messageID string) error {
+	return r.publish(ctx, r.exchange, routingKey, amqp.Publishing{
+		ContentType:  "application/json",
+		Type:         routingKey,
+		Body:         body,
+		DeliveryMode: amqp.Persistent,
+		MessageId:    messageID,
+		Timestamp:    time.Now(),
+	})
+}
+
+// PublishJSON mengirim body yang sudah di-encode langsung ke queue notifikasi.
+// Hanya dipakai untuk pesan outbox format lama; pesan baru dikirim sebagai
+// event lewat PublishEvent.
+func (r *RabbitMQ) PublishJSON(ctx context.Context, body []byte, messageID string) error {
+	if messageID == "" {
+		id, err := RandomURLString(16)
+		if err != nil {
+			return err
+		}
+		messageID = id
+	}
+	return r.publish(ctx, "", r.queueName(), amqp.Publishing{
+		ContentType:  "application/json",
+		Body:         body,
+		DeliveryMode: amqp.Persistent,
+		MessageId:    messageID,
+	})
+}
+
+func (r *RabbitMQ) Close() {
+	r.mu.Lock()
+	r.closing = true
+	conn := r.conn
+	r.mu.Unlock()
+
+	r.drainPool()
+	if conn != nil {
+		conn.Close()
+	}
+}
@@ -0,0 +1,383 @@
+package utils
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"sync"
+	"time"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+const (
+	headerRetryCount = "x-retry-count"
+	headerLastError  = "x-last-error"
+	headerFailedAt   = "x-failed-at"
+)
+
+type NotificationHandler func(ctx context.Context, messageID string, msg NotificationMessage) error
+
+type ConsumerOptions struct {
+	Prefetch    int
+	Concurrency int
+	RetryDelays []time.Duration
+}
+
+// DeadLetter adalah pesan yang gagal diproses setelah semua percobaan ulang.
+type DeadLetter struct {
+	MessageID  string          `json:"message_id"`
+	RetryCount int             `json:"retry_count"`
+	LastError  string          `json:"last_error"`
+	FailedAt   string          `json:"failed_at"`
+	Body       json.RawMessage `json:"body" swaggertype:"object"`
+}
+
+func (r *RabbitMQ) deadLetterExchange() string {
+	return r.queueName() + ".dlx"
+}
+
+func (r *RabbitMQ) deadLetterQueue() string {
+	return r.queueName() + ".dlq"
+}
+
+func (r *RabbitMQ) retryQueue(delay time.Duration) string {
+	return fmt.Sprintf("%s.retry.%s", r.queueName(), delay)
+}
+
+// declareRetryTopology menyiapkan delay queue untuk setiap jeda retry dan
+// dead-letter exchange beserta queue-nya. Delay queue tidak punya consumer;
+// pesan di dalamnya kembali ke queue utama lewat dead-lettering setelah TTL habis.
+func (r *RabbitMQ) declareRetryTopology(ch *amqp.Channel, delays []time.Duration) error {
+	if err := ch.ExchangeDeclare(r.deadLetterExchange(), "fanout", true, false, false, false, nil); err != nil {
+		return err
+	}
+	if _, err := ch.QueueDeclare(r.deadLetterQueue(), true, false, false, false, nil); err != nil {
+		return err
+	}
+	if err := ch.QueueBind(r.deadLetterQueue(), "", r.deadLetterExchange(), false, nil); err != nil {
+		return err
+	}
+
+	for _, delay := range delays {
+		_, err := ch.QueueDeclare(r.retryQueue(delay), true, false, false, false, amqp.Table{
+			"x-message-ttl":             delay.Milliseconds(),
+			"x-dead-letter-exchange":    "",
+			"x-dead-letter-routing-key": r.queueName(),
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ConsumeNotifications memproses pesan dengan manual ack sampai ctx selesai.
+// Pesan yang gagal dikirim ke delay queue sesuai jumlah percobaannya, dan
+// setelah semua jeda di RetryDelays habis dipindahkan ke dead-letter queue.
+// Jika koneksi ke broker terputus, konsumen mendaftar ulang setelah koneksi
+// tersambung kembali. Saat ctx selesai konsumen berhenti menerima pesan baru
+// dan menunggu pesan yang sedang diproses selesai sebelum kembali.
+func (r *RabbitMQ) ConsumeNotifications(ctx context.Context, opts ConsumerOptions, handler NotificationHandler) error {
+	if opts.Prefetch <= 0 {
+		opts.Prefetch = 10
+	}
+	if opts.Concurrency <= 0 {
+		opts.Concurrency = 1
+	}
+
+	delay := time.Second
+	for {
+		started := time.Now()
+		err := r.consume(ctx, opts, handler)
+		if ctx.Err() != nil {
+			return nil
+		}
+
+		// sesi yang sempat berjalan lama berarti putus karena gangguan baru,
+		// jadi backoff dimulai lagi dari awal
+		if time.Since(started) > r.options.ReconnectMaxDelay {
+			delay = time.Second
+		}
+		log.Printf("konsumen notifikasi berhenti, didaftarkan ulang dalam %s: %v", delay, err)
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(delay):
+		}
+		if err := r.WaitConnected(ctx); err != nil {
+			return nil
+		}
+
+		delay *= 2
+		if delay > r.options.ReconnectMaxDelay {
+			delay = r.options.ReconnectMaxDelay
+		}
+	}
+}
+
+func (r *RabbitMQ) consume(ctx context.Context, opts ConsumerOptions, handler NotificationHandler) error {
+	consumeCh, err := r.openChannel()
+	if err != nil {
+		return err
+	}
+	defer consumeCh.Close()
+	if err := consumeCh.Qos(opts.Prefetch, 0, false); err != nil {
+		return err
+	}
+	if err := r.declareRetryTopology(consumeCh, opts.RetryDelays); err != nil {
+		return fmt.Errorf("gagal menyiapkan retry queue: %w", err)
+	}
+
+	tag := consumerTag()
+	msgs, err := consumeCh.Consume(
+		r.queueName(),
+		tag,
+		false,
+		false,
+		false,
+		false,
+		nil,
+	)
+	if err != nil {
+		return fmt.Errorf("gagal mendaftarkan konsumen: %w", err)
+	}
+
+	// handler memakai context sendiri supaya pesan yang sedang diproses
+	// tetap selesai walaupun ctx sudah dibatalkan
+	handlerCtx := context.WithoutCancel(ctx)
+
+	var wg sync.WaitGroup
+	for i := 0; i < opts.Concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for d := range msgs {
+				r.handleDelivery(handlerCtx, d, opts.RetryDelays, handler)
+			}
+		}()
+	}
+
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-ctx.Done():
+		// setelah cancel broker berhenti mengirim pesan dan channel msgs
+		// ditutup begitu pesan yang sudah terkirim habis diproses
+		if err := consumeCh.Cancel(tag, false); err != nil {
+			return err
+		}
+		<-done
+		return nil
+	case <-done:
+		return errors.New("koneksi konsumen notifikasi terputus")
+	}
+}
+
+func consumerTag() string {
+	host, _ := os.Hostname()
+	return fmt.Sprintf("notification-worker-%s-%d", host, os.Getpid())
+}
+
+func (r *RabbitMQ) handleDelivery(ctx context.Context, d amqp.Delivery, delays []time.Duration, handler NotificationHandler) {
+	notification, ok, err := DecodeNotificationBody(d.Body)
+	if err != nil {
+		// pesan yang tidak bisa dibaca tidak akan berhasil walau dicoba ulang
+		r.deadLetter(ctx, d, err)
+		return
+	}
+	if !ok {
+		// event tanpa notifikasi untuk user, misalnya dari binding lama
+		d.Ack(false)
+		return
+	}
+
+	err = handler(ctx, d.MessageId, notification)
+	if err == nil {
+		d.Ack(false)
+		return
+	}
+
+	retries := headerInt(d.Headers, headerRetryCount)
+	if retries >= len(delays) {
+		log.Printf("notifikasi %s (%s) gagal setelah %d percobaan ulang: %v", notification.Type, d.MessageId, retries, err)
+		r.deadLetter(ctx, d, err)
+		return
+	}
+
+	headers := copyHeaders(d.Headers)
+	headers[headerRetryCount] = int32(retries + 1)
+	headers[headerLastError] = err.Error()
+
+	// pesan asli baru di-ack setelah salinannya dikonfirmasi broker,
+	// jadi tidak ada pesan yang hilang jika proses mati di tengah jalan
+	if pubErr := r.publish(ctx, "", r.retryQueue(delays[retries]), republish(d, headers)); pubErr != nil {
+		log.Printf("gagal menjadwalkan ulang notifikasi %s: %v", d.MessageId, pubErr)
+		d.Nack(false, true)
+		return
+	}
+	log.Printf("notifikasi %s (%s) gagal, dicoba lagi dalam %s: %v", notification.Type, d.MessageId, delays[retries], err)
+	d.Ack(false)
+}
+
+func (r *RabbitMQ) deadLetter(ctx context.Context, d amqp.Delivery, cause error) {
+	headers := copyHeaders(d.Headers)
+	headers[headerLastError] = cause.Error()
+	headers[headerFailedAt] = time.Now().UTC().Format(time.RFC3339)
+
+	if err := r.publish(ctx, r.deadLetterExchange(), "", republish(d, headers)); err != nil {
+		log.Printf("gagal memindahkan notifikasi %s ke dead-letter queue: %v", d.MessageId, err)
+		d.Nack(false, true)
+		return
+	}
+	d.Ack(false)
+}
+
+// PeekDeadLetters membaca pesan di dead-letter queue tanpa menghapusnya.
+func (r *RabbitMQ) PeekDeadLetters(limit int) ([]DeadLetter, error) {
+	ch, err := r.openChannel()
+	if err != nil {
+		return nil, err
+	}
+	defer ch.Close()
+
+	letters := []DeadLetter{}
+	var lastTag uint64
+	for len(letters) < limit {
+		d, ok, err := ch.Get(r.deadLetterQueue(), false)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			break
+		}
+		lastTag = d.DeliveryTag
+		letters = append(letters, toDeadLetter(d))
+	}
+
+	if lastTag > 0 {
+		if err := ch.Nack(lastTag, true, true); err != nil {
+			return nil, err
+		}
+	}
+	return letters, nil
+}
+
+// ReplayDeadLetters mengembalikan pesan dari dead-letter queue ke queue utama
+// dengan hitungan retry direset. Jika messageIDs kosong, semua pesan sampai
+// limit dikembalikan. Mengembalikan id pesan yang berhasil dikirim ulang.
+func (r *RabbitMQ) ReplayDeadLetters(ctx context.Context, messageIDs []string, limit int) ([]string, error) {
+	ch, err := r.openChannel()
+	if err != nil {
+		return nil, err
+	}
+	defer ch.Close()
+
+	queue, err := ch.QueueDeclarePassive(r.deadLetterQueue(), true, false, false, false, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	wanted := make(map[string]bool, len(messageIDs))
+	for _, id := range messageIDs {
+		wanted[id] = true
+	}
+
+	replayed := []string{}
+	var skipped []uint64
+	// setiap pesan di queue dibaca paling banyak sekali; pesan yang tidak
+	// dipilih tetap dipegang channel ini sampai di-nack di akhir
+	for i := 0; i < queue.Messages && len(replayed) < limit; i++ {
+		d, ok, err := ch.Get(r.deadLetterQueue(), false)
+		if err != nil {
+			return replayed, err
+		}
+		if !ok {
+			break
+		}
+
+		if len(wanted) > 0 && !wanted[d.MessageId] {
+			skipped = append(skipped, d.DeliveryTag)
+			continue
+		}
+
+		headers := copyHeaders(d.Headers)
+		delete(headers, headerRetryCount)
+		delete(headers, headerLastError)
+		delete(headers, headerFailedAt)
+
+		if err := r.publish(ctx, "", r.queueName(), republish(d, headers)); err != nil {
+			d.Nack(false, true)
+			return replayed, err
+		}
+		if err := d.Ack(false); err != nil {
+			return replayed, err
+		}
+		replayed = append(replayed, d.MessageId)
+	}
+
+	for _, tag := range skipped {
+		ch.Nack(tag, false, true)
+	}
+	return replayed, nil
+}
+
+func republish(d amqp.Delivery, headers amqp.Table) amqp.Publishing {
+	return amqp.Publishing{
+		ContentType:  d.ContentType,
+		Body:         d.Body,
+		DeliveryMode: amqp.Persistent,
+		MessageId:    d.MessageId,
+		Headers:      headers,
+	}
+}
+
+func toDeadLetter(d amqp.Delivery) DeadLetter {
+	lastError, _ := d.Headers[headerLastError].(string)
+	failedAt, _ := d.Headers[headerFailedAt].(string)
+
+	body := json.RawMessage(d.Body)
+	if !json.Valid(body) {
+		quoted, _ := json.Marshal(string(d.Body))
+		body = quoted
+	}
+
+	return DeadLetter{
+		MessageID:  d.MessageId,
+		RetryCount: headerInt(d.Headers, headerRetryCount),
+		LastError:  lastError,
+		FailedAt:   failedAt,
+		Body:       body,
+	}
+}
+
+func copyHeaders(headers amqp.Table) amqp.Table {
+	copied := amqp.Table{}
+	for k, v := range headers {
+		copied[k] = v
+	}
+	return copied
+}
+
+func headerInt(headers amqp.Table, key string) int {
+	switch v := headers[key].(type) {
+	case int:
+		return v
+	case int16:
+		return int(v)
+	case int32:
+		return int(v)
+	case int64:
+		return int(v)
+	default:
+		return 0
+	}
+}
@@ -2,6 +2,7 @@ package utils
 
 import (
 	"context"
+	"errors"
 	"log"
 	"time"
 
@@ -13,13 +14,18 @@ type RedisClient struct {
 }
 
 func NewRedisClient(url string) *RedisClient {
-    opts, err := redis.ParseURL(url)
-    if err != nil {
-        log.Fatalf("Invalid Redis URL: %v", err)
-    }
-    
-    client := redis.NewClient(opts)
-    return &RedisClient{client: client}
+	opts, err := redis.ParseURL(url)
+	if err != nil {
+		log.Fatalf("Invalid Redis URL: %v", err)
+	}
+
+	client := redis.NewClient(opts)
+	return &RedisClient{client: client}
+}
+
+// IsNil bernilai true jika error berasal dari key yang tidak ada.
+func IsNil(err error) bool {
+	return errors.Is(err, redis.Nil)
 }
 
 func (r *RedisClient) Set(ctx context.Context, key string, value interface{}, expiration time.Duration) error {
@@ -30,8 +36,8 @@ func (r *RedisClient) Get(ctx context.Context, key string) (string, error) {
 	return r.client.Get(ctx, key).Result()
 }
 
-func (r *RedisClient) Delete(ctx context.Context, key string) error {
-	return r.client.Del(ctx, key).Err()
+func (r *RedisClient) Delete(ctx context.Context, keys ...string) error {
+	return r.client.Del(ctx, keys...).Err()
 }
 
 func (r *RedisClient) Exists(ctx context.Context, key string) (bool, error) {
@@ -39,8 +45,62 @@ func (r *RedisClient) Exists(ctx context.Context, key string) (bool, error) {
 	return result > 0, err
 }
 
+// ExistsAny bernilai true jika minimal satu key ada.
+func (r *RedisClient) ExistsAny(ctx context.Context, keys ...string) (bool, error) {
+	result, err := r.client.Exists(ctx, keys...).Result()
+	return result > 0, err
+}
+
+func (r *RedisClient) Incr(ctx context.Context, key string) (int64, error) {
+	return r.client.Incr(ctx, key).Result()
+}
+
+func (r *RedisClient) Expire(ctx context.Context, key string, expiration time.Duration) error {
+	return r.client.Expire(ctx, key, expiration).Err()
+}
+
+func (r *RedisClient) TTL(ctx context.Context, key string) (time.Duration, error) {
+	return r.client.TTL(ctx, key).Result()
+}
 
+func (r *RedisClient) SetNX(ctx context.Context, key string, value interface{}, expiration time.Duration) (bool, error) {
+	return r.client.SetNX(ctx, key, value, expiration).Result()
+}
 
+func (r *RedisClient) Ping(ctx context.Context) error {
+	return r.client.Ping(ctx).Err()
+}
 
+func (r *RedisClient) Publish(ctx context.Context, channel string, message interface{}) error {
+	return r.client.Publish(ctx, channel, message).Err()
+}
 
+func (r *RedisClient) PSubscribe(ctx context.Context, patterns ...string) *redis.PubSub {
+	return r.client.PSubscribe(ctx, patterns...)
+}
+
+// SetWithTags menyimpan key lalu mencatatnya di set setiap tag supaya bisa
+// dihapus bersama lewat DeleteTags. Set tag ikut kedaluwarsa bersama key.
+func (r *RedisClient) SetWithTags(ctx context.Context, key string, value interface{}, expiration time.Duration, tags ...string) error {
+	pipe := r.client.TxPipeline()
+	pipe.Set(ctx, key, value, expiration)
+	for _, tag := range tags {
+		pipe.SAdd(ctx, tag, key)
+		pipe.Expire(ctx, tag, expiration)
+	}
+	_, err := pipe.Exec(ctx)
+	return err
+}
 
+// DeleteTags menghapus semua key yang tercatat di tag beserta set tag itu sendiri.
+func (r *RedisClient) DeleteTags(ctx context.Context, tags ...string) error {
+	keys := append([]string(nil), tags...)
+	for _, tag := range tags {
+		members, err := r.client.SMembers(ctx, tag).Result()
+		if err != nil {
+			return err
+		}
+		keys = append(keys, members...)
+	}
+	return r.client.Del(ctx, keys...).Err()
+}
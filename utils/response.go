@@ -1,12 +1,18 @@
 package utils
 
-import "github.com/gin-gonic/gin"
+import (
+	"errors"
+
+	"github.com/gin-gonic/gin"
+)
 
 type Response struct {
-	Success bool        `json:"success"`
-	Message string      `json:"message"`
-	Data    interface{} `json:"data,omitempty"`
-	Error   string      `json:"error,omitempty"`
+	Success bool         `json:"success"`
+	Message string       `json:"message"`
+	Data    interface{}  `json:"data,omitempty"`
+	Meta    interface{}  `json:"meta,omitempty"`
+	Error   string       `json:"error,omitempty"`
+	Errors  []FieldError `json:"errors,omitempty"`
 }
 
 func SuccessResponse(c *gin.Context, code int, message string, data interface{}) {
@@ -17,16 +23,34 @@ func SuccessResponse(c *gin.Context, code int, message string, data interface{})
 	})
 }
 
+// PaginatedResponse sama dengan SuccessResponse dengan tambahan metadata
+// pagination di field meta.
+func PaginatedResponse(c *gin.Context, code int, message string, data interface{}, meta interface{}) {
+	c.JSON(code, Response{
+		Success: true,
+		Message: message,
+		Data: data,
+		Meta: meta,
+	})
+}
+
 func ErrorResponse(c *gin.Context, code int, message string, err error) {
 	errMessage := ""
 	if err != nil {
 		errMessage = err.Error()
 	}
 
+	var fieldErrors []FieldError
+	var validationErr *ValidationError
+	if errors.As(err, &validationErr) {
+		fieldErrors = validationErr.Fields
+	}
+
 	c.JSON(code, Response{
 		Success: false,
 		Message: message,
 		Error: errMessage,
+		Errors: fieldErrors,
 	})
 }
 
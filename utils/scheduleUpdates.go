@@ -0,0 +1,47 @@
+package utils
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ScheduleUpdatesChannelPrefix adalah prefix channel redis pub/sub untuk
+// perubahan jadwal. Setiap jadwal punya channel sendiri, misalnya
+// schedule_updates:42, sehingga semua instance api bisa berlangganan lewat
+// pattern schedule_updates:*.
+const ScheduleUpdatesChannelPrefix = "schedule_updates:"
+
+const (
+	ScheduleUpdateSnapshot = "snapshot"
+	ScheduleUpdateChanged  = "updated"
+	ScheduleUpdateDeleted  = "deleted"
+)
+
+// ScheduleUpdate selalu berisi kondisi lengkap jadwal, bukan selisihnya,
+// jadi client cukup memakai update terakhir yang diterima.
+type ScheduleUpdate struct {
+	ScheduleID     int       `json:"schedule_id"`
+	Event          string    `json:"event"`
+	AvailableSeats int       `json:"available_seats"`
+	Status         string    `json:"status"`
+	DelayMinutes   int       `json:"delay_minutes"`
+	Platform       *string   `json:"platform"`
+	DepartureTime  time.Time `json:"departure_time"`
+	ArrivalTime    time.Time `json:"arrival_time"`
+	Price          float64   `json:"price"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+func ScheduleUpdatesChannel(scheduleID int) string {
+	return ScheduleUpdatesChannelPrefix + strconv.Itoa(scheduleID)
+}
+
+// ScheduleIDFromChannel mengambil id jadwal dari nama channel.
+func ScheduleIDFromChannel(channel string) (int, bool) {
+	id, err := strconv.Atoi(strings.TrimPrefix(channel, ScheduleUpdatesChannelPrefix))
+	if err != nil {
+		return 0, false
+	}
+	return id, true
+}
@@ -0,0 +1,70 @@
+package utils
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	// database zona waktu ikut di-embed supaya tidak bergantung pada tzdata di server
+	_ "time/tzdata"
+)
+
+// DefaultTimezone dipakai untuk stasiun yang belum terdaftar dan untuk data
+// lama yang disimpan sebelum ada kolom zona waktu.
+const DefaultTimezone = "Asia/Jakarta"
+
+var locations sync.Map
+
+// LoadTimezone memuat zona waktu IANA dan menyimpannya di memori.
+func LoadTimezone(name string) (*time.Location, error) {
+	if cached, ok := locations.Load(name); ok {
+		return cached.(*time.Location), nil
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, err
+	}
+	locations.Store(name, loc)
+	return loc, nil
+}
+
+// Location sama dengan LoadTimezone, tetapi jatuh ke DefaultTimezone jika
+// nama kosong atau tidak dikenal.
+func Location(name string) *time.Location {
+	if name != "" {
+		if loc, err := LoadTimezone(name); err == nil {
+			return loc
+		}
+	}
+	loc, err := LoadTimezone(DefaultTimezone)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
+
+// ZoneLabel mengembalikan singkatan zona waktu Indonesia (WIB, WITA, WIT)
+// berdasarkan offset waktu t, atau UTC+hh:mm untuk offset lain.
+func ZoneLabel(t time.Time) string {
+	_, offset := t.Zone()
+	switch offset {
+	case 7 * 3600:
+		return "WIB"
+	case 8 * 3600:
+		return "WITA"
+	case 9 * 3600:
+		return "WIT"
+	}
+	sign := "+"
+	if offset < 0 {
+		sign = "-"
+		offset = -offset
+	}
+	return fmt.Sprintf("UTC%s%02d:%02d", sign, offset/3600, offset%3600/60)
+}
+
+// FormatLocalTime memformat t di zona waktu yang dibawanya beserta
+// singkatannya, misalnya "2026-01-02 08:30 WIB".
+func FormatLocalTime(t time.Time) string {
+	return t.Format("2006-01-02 15:04") + " " + ZoneLabel(t)
+}
@@ -0,0 +1,36 @@
+package utils
+
+import "strings"
+
+// FieldError adalah pelanggaran aturan pada satu field.
+type FieldError struct {
+	Field   string `json:"field"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// ValidationError mengumpulkan FieldError dari satu validasi. ErrorResponse
+// menyertakan daftarnya di field errors.
+type ValidationError struct {
+	Fields []FieldError
+}
+
+func (e *ValidationError) Add(field, code, message string) {
+	e.Fields = append(e.Fields, FieldError{Field: field, Code: code, Message: message})
+}
+
+// Err mengembalikan nil jika tidak ada pelanggaran.
+func (e *ValidationError) Err() error {
+	if len(e.Fields) == 0 {
+		return nil
+	}
+	return e
+}
+
+func (e *ValidationError) Error() string {
+	messages := make([]string, len(e.Fields))
+	for i, field := range e.Fields {
+		messages[i] = field.Message
+	}
+	return strings.Join(messages, "; ")
+}